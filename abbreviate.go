@@ -0,0 +1,45 @@
+package did
+
+import "unicode/utf8"
+
+// ellipsis marks the elided part of an abbreviation.
+const ellipsis = "…"
+
+// Abbreviate returns the String encoding, shortened to at most maxLen
+// characters for display purposes. The method and the fragment are always
+// kept. The middle of the method-specific-id, the path and the query is
+// replaced with an ellipsis, as in "did:ion:EiDyOQ…x4Zg#keys-1". The result
+// exceeds maxLen when the method and fragment alone do not fit. The result is
+// not a valid DID URL when shortened. The zero value abbreviates to the empty
+// string, for any maxLen.
+func (d *DIDURL) Abbreviate(maxLen int) string {
+	s := d.String()
+	if s == "" || utf8.RuneCountInString(s) <= maxLen {
+		// no DID to slice
+		return s
+	}
+
+	prefix := "did:" + d.Method + ":"
+	var suffix string
+	if d.Fragment != "" || d.ForceFragment {
+		suffix = s[len(s)-len(d.Fragment)-1:]
+	}
+	body := s[len(prefix) : len(s)-len(suffix)]
+
+	avail := maxLen - utf8.RuneCountInString(prefix) - utf8.RuneCountInString(suffix) - 1
+	if avail < 2 {
+		// at least one character each side of the ellipsis
+		avail = 2
+	}
+	if avail >= len(body) {
+		return s
+	}
+	head, tail := (avail+1)/2, avail/2
+	return prefix + body[:head] + ellipsis + body[len(body)-tail:] + suffix
+}
+
+// Abbreviate returns the String encoding, shortened to at most maxLen
+// characters for display purposes. See DIDURL.Abbreviate for details.
+func (d *DID) Abbreviate(maxLen int) string {
+	return d.URL().Abbreviate(maxLen)
+}
@@ -0,0 +1,44 @@
+package did
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAbbreviate(t *testing.T) {
+	golden := []struct {
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"did:example:123", 100, "did:example:123"},
+		{"did:example:123", 15, "did:example:123"},
+		{"did:ion:EiDyOQbbZAa3aiRzeCkV7LOx3SERjjH93EXoIM3UoN4oWg#keys-1", 28, "did:ion:EiDyOQ…oN4oWg#keys-1"},
+		{"did:ion:EiDyOQbbZAa3aiRzeCkV7LOx3SERjjH93EXoIM3UoN4oWg#keys-1", 29, "did:ion:EiDyOQb…oN4oWg#keys-1"},
+		{"did:web:example.com:user:alice/path/did.json?q=1", 30, "did:web:example.com…d.json?q=1"},
+		{"did:ion:EiDyOQbbZAa3aiRzeCkV7LOx3SERjjH93EXoIM3UoN4oWg#keys-1", 5, "did:ion:E…g#keys-1"},
+		{"did:ion:abc#", 11, "did:ion:a…c#"},
+		{"did:ion:abc#", -1, "did:ion:a…c#"},
+	}
+	for _, g := range golden {
+		u, err := ParseURL(g.input)
+		assert(t, nil, err)
+		got := u.Abbreviate(g.maxLen)
+		assert(t, g.want, got, "Input: %q, maxLen: %d", g.input, g.maxLen)
+		if n := utf8.RuneCountInString(got); n > g.maxLen && g.maxLen >= 20 {
+			t.Errorf("%q abbreviates to %d characters, more than %d", g.input, n, g.maxLen)
+		}
+	}
+
+	d, err := Parse("did:key:" + strings.Repeat("z", 100))
+	assert(t, nil, err)
+	assert(t, "did:key:zzzzzz…zzzzz", d.Abbreviate(20))
+
+	var zero DIDURL
+	for _, maxLen := range []int{-1, 0, 5} {
+		assert(t, "", zero.Abbreviate(maxLen), "maxLen: %d", maxLen)
+	}
+	noID := DIDURL{DID: DID{Method: "example"}, Fragment: "f"}
+	assert(t, "", noID.Abbreviate(-1))
+}
@@ -9,6 +9,8 @@ import (
 
 var parsed *did.DID
 
+var parsedDIDURL *did.DIDURL
+
 func BenchmarkParse(b *testing.B) {
 	var p *did.DID
 	for n := 0; n < b.N; n++ {
@@ -18,27 +20,38 @@ func BenchmarkParse(b *testing.B) {
 }
 
 func BenchmarkParseWithPath(b *testing.B) {
-	var p *did.DID
+	var p *did.DIDURL
 	for n := 0; n < b.N; n++ {
-		p, _ = did.Parse("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82/6pkmkw5pteabvtzm7p6qe106ysiawmo")
+		p, _ = did.ParseURL("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82/6pkmkw5pteabvtzm7p6qe106ysiawmo")
 	}
-	parsed = p
+	parsedDIDURL = p
 }
 
 func BenchmarkParseWithQuery(b *testing.B) {
-	var p *did.DID
+	var p *did.DIDURL
 	for n := 0; n < b.N; n++ {
-		p, _ = did.Parse("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82?6pkmkw5pteabvtzm7p6qe106ysiawmo")
+		p, _ = did.ParseURL("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82?6pkmkw5pteabvtzm7p6qe106ysiawmo")
 	}
-	parsed = p
+	parsedDIDURL = p
 }
 
 func BenchmarkParseWithFragment(b *testing.B) {
-	var p *did.DID
+	var p *did.DIDURL
 	for n := 0; n < b.N; n++ {
-		p, _ = did.Parse("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82#6pkmkw5pteabvtzm7p6qe106ysiawmo")
+		p, _ = did.ParseURL("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82#6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	}
+	parsedDIDURL = p
+}
+
+func BenchmarkValidateAll(b *testing.B) {
+	inputs := make([]string, 10000)
+	for i := range inputs {
+		inputs[i] = "did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82m6pkmkw5pteabvtzm7p6qe106ysiawmo"
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		did.ValidateAll(inputs, did.ValidateOptions{})
 	}
-	parsed = p
 }
 
 // Sanity check against Go's URL parsing to make sure we're in the same order of magnitude
@@ -76,3 +89,114 @@ func BenchmarkUrlParseWithFragment(b *testing.B) {
 	}
 	parsedURL = u
 }
+
+var valid bool
+
+func BenchmarkValid(b *testing.B) {
+	var ok bool
+	for n := 0; n < b.N; n++ {
+		ok = did.Valid("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82m6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	}
+	valid = ok
+}
+
+func BenchmarkValidURL(b *testing.B) {
+	var ok bool
+	for n := 0; n < b.N; n++ {
+		ok = did.ValidURL("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82/6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	}
+	valid = ok
+}
+
+var encoded string
+
+func BenchmarkString(b *testing.B) {
+	d := &did.DIDURL{
+		DID:      did.DID{Method: "ockam", ID: "amzbjdl8etgpgwoe841sfi6fc4q9yh82"},
+		Path:     "6pkmkw5pteabvtzm7p6qe106ysiawmo",
+		Fragment: "keys-1",
+	}
+	var s string
+	for n := 0; n < b.N; n++ {
+		s = d.String()
+	}
+	encoded = s
+}
+
+func BenchmarkAppendString(b *testing.B) {
+	d := &did.DIDURL{
+		DID:      did.DID{Method: "ockam", ID: "amzbjdl8etgpgwoe841sfi6fc4q9yh82"},
+		Path:     "6pkmkw5pteabvtzm7p6qe106ysiawmo",
+		Fragment: "keys-1",
+	}
+	buf := make([]byte, 0, 128)
+	for n := 0; n < b.N; n++ {
+		buf = d.AppendString(buf[:0])
+	}
+	encoded = string(buf)
+}
+
+func BenchmarkRoundTrip(b *testing.B) {
+	var s string
+	for n := 0; n < b.N; n++ {
+		u, err := did.ParseURL("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82/6pkmkw5pteabvtzm7p6qe106ysiawmo#keys-1")
+		if err != nil {
+			b.Fatal(err)
+		}
+		u.Fragment = "keys-2"
+		s = u.String()
+	}
+	encoded = s
+}
+
+func BenchmarkMarshalText(b *testing.B) {
+	d, err := did.Parse("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82m6pkmkw5pteabvtzm7p6qe106ysiawmo")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var text []byte
+	for n := 0; n < b.N; n++ {
+		text, _ = d.MarshalText()
+	}
+	encoded = string(text)
+}
+
+// TestAllocs guards the allocation counts which the benchmarks advertise.
+func TestAllocs(t *testing.T) {
+	const id = "did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82m6pkmkw5pteabvtzm7p6qe106ysiawmo"
+	const ref = "did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82/6pkmkw5pteabvtzm7p6qe106ysiawmo?q#keys-1"
+	d, err := did.Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := did.ParseURL(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified := u.Clone()
+	modified.Fragment = "keys-2"
+	buf := make([]byte, 0, 128)
+
+	tests := []struct {
+		name string
+		want float64
+		f    func()
+	}{
+		{"Parse", 1, func() { parsed, _ = did.Parse(id) }},
+		{"ParseURL", 1, func() {
+			parsedDIDURL, _ = did.ParseURL("did:ockam:amzbjdl8etgpgwoe841sfi6fc4q9yh82?6pkmkw5pteabvtzm7p6qe106ysiawmo")
+		}},
+		{"Valid", 0, func() { valid = did.Valid(id) }},
+		{"ValidURL", 0, func() { valid = did.ValidURL(ref) }},
+		{"String parsed", 0, func() { encoded = d.String() }},
+		{"String parsed URL", 0, func() { encoded = u.String() }},
+		{"String modified", 1, func() { encoded = modified.String() }},
+		{"AppendString", 0, func() { buf = modified.AppendString(buf[:0]) }},
+		{"MarshalText", 1, func() { _, _ = d.MarshalText() }},
+	}
+	for _, test := range tests {
+		if got := testing.AllocsPerRun(100, test.f); got != test.want {
+			t.Errorf("%s got %v allocations, want %v", test.name, got, test.want)
+		}
+	}
+}
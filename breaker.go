@@ -0,0 +1,197 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen means a CircuitBreaker refused the resolution, because its
+// upstream failed recently.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the condition of a CircuitBreaker.
+type BreakerState int
+
+// Circuit breaker states.
+const (
+	// BreakerClosed passes resolutions to the upstream.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen refuses resolutions with ErrCircuitOpen.
+	BreakerOpen
+	// BreakerHalfOpen passes a single resolution to the upstream, as a
+	// trial, and it refuses the others.
+	BreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// CircuitBreaker is a Resolver which stops calling its upstream after a
+// number of consecutive failures. Once OpenTimeout passed, a single trial
+// resolution decides whether the upstream recovered. The zero value is not
+// usable; Resolver must be set.
+type CircuitBreaker struct {
+	// Resolver is the upstream.
+	Resolver Resolver
+
+	// FailureThreshold is the number of consecutive failures which opens
+	// the circuit. Zero defaults to 5.
+	FailureThreshold int
+
+	// OpenTimeout is the duration of the open state, before the trial.
+	// Zero defaults to 30 s.
+	OpenTimeout time.Duration
+
+	// IsFailure returns whether an error counts as a failure of the
+	// upstream. The nil value defaults to IsTransient, such that a DID
+	// which is not found does not count.
+	IsFailure func(error) bool
+
+	// Probe is a DID which resolves when the upstream is healthy, for use
+	// by CheckHealth.
+	Probe *DID
+
+	// Now is the clock. The nil value defaults to time.Now.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int       // consecutive count
+	openedAt time.Time // start of the open state
+}
+
+// Resolve implements the Resolver interface. Refusals get an error which
+// wraps ErrCircuitOpen.
+func (b *CircuitBreaker) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	b.mu.Lock()
+	switch {
+	case b.state == BreakerHalfOpen, b.state == BreakerOpen && b.now().Before(b.openedAt.Add(b.openTimeout())):
+		b.mu.Unlock()
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, ErrCircuitOpen)
+	case b.state == BreakerOpen:
+		// timeout passed; this resolution is the trial
+		b.state = BreakerHalfOpen
+	}
+	b.mu.Unlock()
+
+	// A panic counts as a failure, such that a trial can not leave the
+	// circuit half-open.
+	completed := false
+	defer func() {
+		if !completed {
+			b.recordFailure(true)
+		}
+	}()
+	doc, meta, err := b.Resolver.Resolve(ctx, d, opts)
+	completed = true
+	b.record(err)
+	return doc, meta, err
+}
+
+// State returns the current state. An open state remains open until the
+// trial starts.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// CheckHealth resolves the Probe DID with the upstream, regardless of the
+// state, and it records the outcome like any other resolution, such that a
+// healthy probe closes the circuit. Run it periodically to recover without
+// trial, or to serve a health endpoint. The error is nil without Probe.
+func (b *CircuitBreaker) CheckHealth(ctx context.Context) error {
+	if b.Probe == nil {
+		return nil
+	}
+	_, _, err := b.Resolver.Resolve(ctx, b.Probe, ResolutionOptions{NoCache: true})
+	b.record(err)
+	return err
+}
+
+// record updates the state with the outcome of an upstream resolution.
+func (b *CircuitBreaker) record(err error) {
+	isFailure := b.IsFailure
+	if isFailure == nil {
+		isFailure = IsTransient
+	}
+	failed := err != nil && isFailure(err)
+	if err != nil && !failed && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		// no verdict on the upstream
+		b.mu.Lock()
+		if b.state == BreakerHalfOpen {
+			b.state = BreakerOpen
+		}
+		b.mu.Unlock()
+		return
+	}
+	b.recordFailure(failed)
+}
+
+// recordFailure updates the state with the verdict on the upstream.
+func (b *CircuitBreaker) recordFailure(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	threshold := b.FailureThreshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if b.state == BreakerHalfOpen || b.failures >= threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+func (b *CircuitBreaker) openTimeout() time.Duration {
+	if b.OpenTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return b.OpenTimeout
+}
+
+func (b *CircuitBreaker) now() time.Time {
+	if b.Now != nil {
+		return b.Now()
+	}
+	return time.Now()
+}
+
+// Failover returns a Resolver which tries each of the resolvers in order, for
+// redundant backends of the same methods. A resolver is skipped on failures
+// which are transient, or which wrap ErrCircuitOpen, so wrap each backend in a
+// CircuitBreaker to keep degraded ones out of rotation. Other outcomes are
+// final. When all resolvers fail, the last error is returned.
+func Failover(resolvers ...Resolver) Resolver {
+	return ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		err := fmt.Errorf("did: resolve %s: no resolvers: %w", d, ErrMethodNotSupported)
+		for _, r := range resolvers {
+			var doc *Document
+			var meta *Metadata
+			doc, meta, err = r.Resolve(ctx, d, opts)
+			if err == nil || !(IsTransient(err) || errors.Is(err, ErrCircuitOpen)) || ctx.Err() != nil {
+				return doc, meta, err
+			}
+		}
+		return nil, nil, err
+	})
+}
@@ -0,0 +1,133 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyResolver fails with a transient error while down is set.
+type flakyResolver struct {
+	down  bool
+	calls int
+}
+
+func (r *flakyResolver) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	r.calls++
+	if r.down {
+		return nil, nil, errors.New("backend unavailable")
+	}
+	if d.ID == "missing" {
+		return nil, nil, ErrNotFound
+	}
+	return &Document{ID: d.String()}, new(Metadata), nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	upstream := &flakyResolver{down: true}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := &CircuitBreaker{
+		Resolver:         upstream,
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		Probe:            &DID{Method: "example", ID: "probe"},
+		Now:              func() time.Time { return now },
+	}
+	ctx := context.Background()
+	d := &DID{Method: "example", ID: "123"}
+
+	for range 2 {
+		_, _, err := b.Resolve(ctx, d, ResolutionOptions{})
+		assert(t, false, errors.Is(err, ErrCircuitOpen), "error %v before threshold", err)
+	}
+	assert(t, BreakerOpen, b.State())
+	_, _, err := b.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrCircuitOpen), "error %v when open", err)
+	assert(t, 2, upstream.calls, "upstream calls")
+
+	// failed trial
+	now = now.Add(time.Minute)
+	_, _, err = b.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, false, errors.Is(err, ErrCircuitOpen), "trial error %v", err)
+	assert(t, BreakerOpen, b.State())
+	assert(t, 3, upstream.calls, "upstream calls")
+
+	// successful trial
+	upstream.down = false
+	now = now.Add(time.Minute)
+	_, _, err = b.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, BreakerClosed, b.State())
+
+	// not found does not count
+	for range 3 {
+		_, _, err = b.Resolve(ctx, &DID{Method: "example", ID: "missing"}, ResolutionOptions{})
+		assert(t, true, errors.Is(err, ErrNotFound))
+	}
+	assert(t, BreakerClosed, b.State())
+
+	// health check recovers without trial
+	upstream.down = true
+	assert(t, true, b.CheckHealth(ctx) != nil)
+	assert(t, true, b.CheckHealth(ctx) != nil)
+	assert(t, BreakerOpen, b.State())
+	upstream.down = false
+	assert(t, nil, b.CheckHealth(ctx))
+	assert(t, BreakerClosed, b.State())
+}
+
+func TestCircuitBreakerPanic(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := &CircuitBreaker{
+		Resolver: ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+			panic("upstream failure")
+		}),
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+		Now:              func() time.Time { return now },
+	}
+	d := &DID{Method: "example", ID: "123"}
+	resolve := func() (p any) {
+		defer func() { p = recover() }()
+		b.Resolve(context.Background(), d, ResolutionOptions{})
+		return nil
+	}
+
+	assert(t, "upstream failure", resolve(), "panic when closed")
+	assert(t, BreakerOpen, b.State(), "state after panic")
+
+	// panicked trial
+	now = now.Add(time.Minute)
+	assert(t, "upstream failure", resolve(), "panic of trial")
+	assert(t, BreakerOpen, b.State(), "state after panicked trial")
+
+	// next trial
+	b.Resolver = &flakyResolver{}
+	now = now.Add(time.Minute)
+	_, _, err := b.Resolve(context.Background(), d, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, BreakerClosed, b.State())
+}
+
+func TestFailover(t *testing.T) {
+	primary := &flakyResolver{down: true}
+	secondary := new(flakyResolver)
+	r := Failover(&CircuitBreaker{Resolver: primary, FailureThreshold: 1}, secondary)
+	ctx := context.Background()
+
+	for range 2 {
+		doc, _, err := r.Resolve(ctx, &DID{Method: "example", ID: "123"}, ResolutionOptions{})
+		assert(t, nil, err)
+		assert(t, "did:example:123", doc.ID)
+	}
+	assert(t, 1, primary.calls, "primary calls with open circuit")
+	assert(t, 2, secondary.calls, "secondary calls")
+
+	secondary.down = true
+	_, _, err := r.Resolve(ctx, &DID{Method: "example", ID: "123"}, ResolutionOptions{})
+	assert(t, "backend unavailable", err.Error())
+
+	_, _, err = Failover().Resolve(ctx, &DID{Method: "example", ID: "123"}, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrMethodNotSupported))
+}
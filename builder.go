@@ -0,0 +1,64 @@
+package did
+
+import (
+	"errors"
+	"strings"
+)
+
+// A Builder constructs a DID or a DID URL step by step. The zero value is not
+// usable; use NewBuilder instead.
+//
+//	d, err := did.NewBuilder("example").ID("123", "456").PathSegments("a", "b").
+//		Param("service", "agent").Fragment("keys-1").Build()
+type Builder struct {
+	d      DIDURL
+	params []string
+}
+
+// NewBuilder starts a DID with the given DID Method.
+func NewBuilder(method string) *Builder {
+	return &Builder{d: DIDURL{DID: DID{Method: method}}}
+}
+
+// ID sets the method-specific-id from its `:` separated idstrings. Any
+// characters not permitted in an idstring, including ':', are escaped. The
+// idchars of a registered MethodSpec apply.
+func (b *Builder) ID(idstrings ...string) *Builder {
+	b.d.IDStrings = append([]string(nil), idstrings...)
+	b.d.ID = strings.Join(idstrings, ":")
+	return b
+}
+
+// PathSegments sets the DID Path from unescaped segments.
+func (b *Builder) PathSegments(segments ...string) *Builder {
+	b.d.PathSegments = append([]string(nil), segments...)
+	return b
+}
+
+// Param appends a name-value pair to the DID Query. Both name and value are
+// escaped as needed.
+func (b *Builder) Param(name, value string) *Builder {
+	b.params = append(b.params, encodeParam(name, value))
+	return b
+}
+
+// Fragment sets the DID Fragment. Any characters not permitted in a fragment
+// are escaped.
+func (b *Builder) Fragment(fragment string) *Builder {
+	b.d.Fragment = escape(fragment, IsFragmentChar)
+	b.d.ForceFragment = fragment == ""
+	return b
+}
+
+// Build validates the result, and it returns the DID URL on success.
+func (b *Builder) Build() (*DIDURL, error) {
+	if b.d.Method == "" {
+		return nil, errors.New("no method")
+	}
+	if b.d.ID == "" {
+		return nil, errors.New("no method-specific id")
+	}
+	d := b.d
+	d.Query = strings.Join(b.params, "&")
+	return ParseURL(d.String())
+}
@@ -0,0 +1,64 @@
+package did
+
+import "testing"
+
+func TestBuilder(t *testing.T) {
+	t.Run("builds a DID", func(t *testing.T) {
+		d, err := NewBuilder("example").ID("123").Build()
+		assert(t, nil, err)
+		assert(t, "did:example:123", d.String())
+		assert(t, false, d.IsURL())
+	})
+
+	t.Run("builds a DID URL", func(t *testing.T) {
+		d, err := NewBuilder("example").ID("123", "456").PathSegments("a", "b").
+			Param("service", "agent").Fragment("keys-1").Build()
+		assert(t, nil, err)
+		assert(t, "example", d.Method)
+		assert(t, []string{"123", "456"}, d.IDStrings)
+		assert(t, []string{"a", "b"}, d.PathSegments)
+		assert(t, "service=agent", d.Query)
+		assert(t, "keys-1", d.Fragment)
+	})
+
+	t.Run("escapes idstrings", func(t *testing.T) {
+		d, err := NewBuilder("web").ID("localhost:8443", "user alice").Build()
+		assert(t, nil, err)
+		assert(t, "did:web:localhost%3A8443:user%20alice", d.String())
+		assert(t, []string{"localhost:8443", "user alice"}, d.IDStrings)
+	})
+
+	t.Run("escapes params", func(t *testing.T) {
+		d, err := NewBuilder("example").ID("123").
+			Param("a&b", "c=d").Param("e", "f g+h").Build()
+		assert(t, nil, err)
+		assert(t, "a%26b=c%3Dd&e=f%20g%2Bh", d.Query)
+	})
+
+	t.Run("escapes fragment", func(t *testing.T) {
+		d, err := NewBuilder("example").ID("123").Fragment("a b#c").Build()
+		assert(t, nil, err)
+		assert(t, "a%20b%23c", d.Fragment)
+	})
+
+	t.Run("keeps empty fragment", func(t *testing.T) {
+		d, err := NewBuilder("example").ID("123").Fragment("").Build()
+		assert(t, nil, err)
+		assert(t, "did:example:123#", d.String())
+	})
+
+	t.Run("returns error if no method", func(t *testing.T) {
+		_, err := NewBuilder("").ID("123").Build()
+		assert(t, false, err == nil)
+	})
+
+	t.Run("returns error if no ID", func(t *testing.T) {
+		_, err := NewBuilder("example").Build()
+		assert(t, false, err == nil)
+	})
+
+	t.Run("returns error if method is invalid", func(t *testing.T) {
+		_, err := NewBuilder("Example").ID("123").Build()
+		assert(t, false, err == nil)
+	})
+}
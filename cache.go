@@ -0,0 +1,289 @@
+package did
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is the expiry of cached resolutions when no TTL applies.
+const DefaultCacheTTL = 5 * time.Minute
+
+// CachingResolver is a Resolver which caches the results of another Resolver.
+// Concurrent resolutions of the same DID, with the same options, share a
+// single upstream call. Failed resolutions are cached only when NegativeTTL
+// applies. The zero value is not usable; Resolver must be set.
+//
+// Cached documents and metadata are shared between callers, and they must not
+// be modified.
+type CachingResolver struct {
+	// Resolver is the upstream.
+	Resolver Resolver
+
+	// TTL is the expiry of cached resolutions. Zero defaults to
+	// DefaultCacheTTL. Negative values disable caching, with concurrent
+	// resolutions still shared.
+	TTL time.Duration
+
+	// MethodTTL overrides TTL per DID method, if set.
+	MethodTTL map[string]time.Duration
+
+	// NegativeTTL is the expiry of failed resolutions with ErrNotFound or
+	// with ErrInvalidDID, if positive. Other failures are never cached.
+	// Keep it short, as a DID may get created at any time.
+	NegativeTTL time.Duration
+
+	// MaxEntries limits the number of cached resolutions, if positive.
+	// The least recently used ones are evicted first.
+	MaxEntries int
+
+	// MaxBytes limits the size of the cached resolutions, as estimated by
+	// their JSON encoding, if positive. The least recently used ones are
+	// evicted first.
+	MaxBytes int64
+
+	// Now is the clock. The nil value defaults to time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element // with *cacheEntry values
+	lru     list.List                  // most recently used at the front
+	bytes   int64                      // sum of entry sizes
+	calls   map[cacheKey]*cacheCall
+	sweepAt int // number of entries which triggers the next sweep
+	stats   CacheStats
+}
+
+// CacheStats has the counters of a CachingResolver.
+type CacheStats struct {
+	Hits      int64 // resolutions served from the cache
+	Misses    int64 // resolutions passed to the upstream
+	Shared    int64 // resolutions which waited on the upstream call of another
+	Evictions int64 // entries removed to respect the limits
+
+	Entries int   // number of cached resolutions
+	Bytes   int64 // estimated size of the cached resolutions
+}
+
+// cacheKey identifies a resolution.
+type cacheKey struct {
+	did         string
+	accept      string
+	versionID   string
+	versionTime int64 // Unix nanoseconds, or zero for none
+}
+
+func newCacheKey(d *DID, opts ResolutionOptions) cacheKey {
+	k := cacheKey{did: d.String(), accept: opts.Accept, versionID: opts.VersionID}
+	if !opts.VersionTime.IsZero() {
+		k.versionTime = opts.VersionTime.UnixNano()
+	}
+	return k
+}
+
+// cacheEntry is a resolution result.
+type cacheEntry struct {
+	key     cacheKey
+	doc     *Document
+	meta    *Metadata
+	err     error
+	expires time.Time
+	size    int64
+}
+
+// estimateSize returns the approximate number of bytes held by e.
+func (e *cacheEntry) estimateSize() int64 {
+	n := len(e.key.did) + len(e.key.accept) + len(e.key.versionID) + 128
+	if e.doc != nil {
+		b, _ := json.Marshal(e.doc)
+		n += len(b)
+	}
+	if e.meta != nil {
+		b, _ := json.Marshal(e.meta)
+		n += len(b)
+	}
+	if e.err != nil {
+		n += len(e.err.Error())
+	}
+	return int64(n)
+}
+
+// cacheCall is an upstream resolution in progress.
+type cacheCall struct {
+	done chan struct{} // closed on completion
+	doc  *Document
+	meta *Metadata
+	err  error
+}
+
+// Resolve implements the Resolver interface. Callers which share an upstream
+// call get its outcome, including any context error of the first caller.
+// Resolutions with NoCache always call the upstream, and they replace any
+// cached result. When the upstream panics, the panic passes on to the caller
+// which made the call, and the callers which share it get ErrPanic.
+func (c *CachingResolver) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	key := newCacheKey(d, opts)
+
+	c.mu.Lock()
+	now := c.now()
+	if elem, ok := c.entries[key]; ok && !opts.NoCache {
+		e := elem.Value.(*cacheEntry)
+		if now.Before(e.expires) {
+			c.lru.MoveToFront(elem)
+			c.stats.Hits++
+			c.mu.Unlock()
+			markCacheHit(ctx)
+			return e.doc, e.meta, e.err
+		}
+		c.remove(elem)
+	}
+	if call, ok := c.calls[key]; ok && !opts.NoCache {
+		c.stats.Shared++
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			markCacheHit(ctx)
+			return call.doc, call.meta, call.err
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	if c.calls == nil {
+		c.calls = make(map[cacheKey]*cacheCall)
+	}
+	if _, ok := c.calls[key]; !ok {
+		c.calls[key] = call
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// outcome for the shared callers in case of a panic
+	call.err = fmt.Errorf("did: resolve %s: %w", d, ErrPanic)
+	var e *cacheEntry
+	var ttl time.Duration
+	defer func() {
+		c.mu.Lock()
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+		if e != nil {
+			e.expires = c.now().Add(ttl)
+			c.put(e)
+		}
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.doc, call.meta, call.err = c.Resolver.Resolve(ctx, d, opts)
+
+	ttl = c.ttl(d.Method, call.err)
+	if ttl > 0 {
+		e = &cacheEntry{key: key, doc: call.doc, meta: call.meta, err: call.err}
+		e.size = e.estimateSize()
+	}
+
+	return call.doc, call.meta, call.err
+}
+
+// Purge removes all cached resolutions.
+func (c *CachingResolver) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+	c.lru.Init()
+	c.bytes = 0
+	c.sweepAt = 0
+}
+
+// Forget removes any cached resolutions of d, regardless of the options.
+func (c *CachingResolver) Forget(d *DID) {
+	s := d.String()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, elem := range c.entries {
+		if key.did == s {
+			c.remove(elem)
+		}
+	}
+}
+
+// Stats returns a snapshot of the counters.
+func (c *CachingResolver) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	stats.Bytes = c.bytes
+	return stats
+}
+
+func (c *CachingResolver) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// ttl returns the expiry for the outcome of a resolution with method.
+func (c *CachingResolver) ttl(method string, err error) time.Duration {
+	return cacheTTL(c.TTL, c.MethodTTL, c.NegativeTTL, method, err)
+}
+
+// cacheTTL returns the expiry for the outcome of a resolution with method,
+// given the TTL settings of a cache.
+func cacheTTL(ttl time.Duration, methodTTL map[string]time.Duration, negativeTTL time.Duration, method string, err error) time.Duration {
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidDID) {
+			return negativeTTL
+		}
+		return 0
+	}
+	if ttl, ok := methodTTL[method]; ok {
+		return ttl
+	}
+	if ttl == 0 {
+		return DefaultCacheTTL
+	}
+	return ttl
+}
+
+// put adds an entry, and it evicts the least recently used entries beyond
+// the limits, which includes e itself when it exceeds MaxBytes. Expired
+// entries are swept each time the number of entries doubles, which keeps the
+// cost amortized. The caller must hold mu.
+func (c *CachingResolver) put(e *cacheEntry) {
+	if c.entries == nil {
+		c.entries = make(map[cacheKey]*list.Element)
+	}
+	if elem, ok := c.entries[e.key]; ok {
+		c.remove(elem)
+	}
+	if len(c.entries) >= c.sweepAt {
+		now := c.now()
+		for _, elem := range c.entries {
+			if !now.Before(elem.Value.(*cacheEntry).expires) {
+				c.remove(elem)
+			}
+		}
+		c.sweepAt = max(2*len(c.entries), 64)
+	}
+
+	c.entries[e.key] = c.lru.PushFront(e)
+	c.bytes += e.size
+	for c.lru.Len() > 0 && ((c.MaxEntries > 0 && c.lru.Len() > c.MaxEntries) || (c.MaxBytes > 0 && c.bytes > c.MaxBytes)) {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove deletes an entry. The caller must hold mu.
+func (c *CachingResolver) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, e.key)
+	c.bytes -= e.size
+}
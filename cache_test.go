@@ -0,0 +1,245 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingResolver resolves each DID to a document, and it counts the calls.
+type countingResolver struct {
+	calls   atomic.Int64
+	release chan struct{} // blocks resolution until closed, if not nil
+}
+
+func (r *countingResolver) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	r.calls.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	if d.ID == "missing" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, ErrNotFound)
+	}
+	return &Document{ID: d.String()}, &Metadata{Document: DocumentMetadata{VersionID: opts.VersionID}}, nil
+}
+
+func TestCachingResolver(t *testing.T) {
+	upstream := new(countingResolver)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &CachingResolver{
+		Resolver:  upstream,
+		TTL:       time.Minute,
+		MethodTTL: map[string]time.Duration{"nocache": -1},
+		Now:       func() time.Time { return now },
+	}
+	ctx := context.Background()
+	d := &DID{Method: "example", ID: "123"}
+
+	for range 3 {
+		doc, _, err := c.Resolve(ctx, d, ResolutionOptions{})
+		assert(t, nil, err)
+		assert(t, "did:example:123", doc.ID)
+	}
+	assert(t, int64(1), upstream.calls.Load(), "upstream calls after cache hits")
+
+	_, meta, err := c.Resolve(ctx, d, ResolutionOptions{VersionID: "2"})
+	assert(t, nil, err)
+	assert(t, "2", meta.Document.VersionID)
+	assert(t, int64(2), upstream.calls.Load(), "upstream calls with other options")
+
+	now = now.Add(time.Minute)
+	_, _, err = c.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, int64(3), upstream.calls.Load(), "upstream calls after expiry")
+
+	c.Forget(d)
+	_, _, err = c.Resolve(ctx, d, ResolutionOptions{VersionID: "2"})
+	assert(t, nil, err)
+	assert(t, int64(4), upstream.calls.Load(), "upstream calls after Forget")
+
+	_, _, err = c.Resolve(ctx, d, ResolutionOptions{VersionID: "2", NoCache: true})
+	assert(t, nil, err)
+	_, _, err = c.Resolve(ctx, d, ResolutionOptions{VersionID: "2"})
+	assert(t, nil, err)
+	assert(t, int64(5), upstream.calls.Load(), "upstream calls with NoCache")
+
+	for range 2 {
+		_, _, err = c.Resolve(ctx, &DID{Method: "nocache", ID: "123"}, ResolutionOptions{})
+		assert(t, nil, err)
+	}
+	assert(t, int64(7), upstream.calls.Load(), "upstream calls with caching disabled")
+
+	for range 2 {
+		_, _, err = c.Resolve(ctx, &DID{Method: "example", ID: "missing"}, ResolutionOptions{})
+		assert(t, true, errors.Is(err, ErrNotFound), "got error %v", err)
+	}
+	assert(t, int64(9), upstream.calls.Load(), "upstream calls for failures")
+}
+
+func TestCachingResolverCoalescing(t *testing.T) {
+	upstream := &countingResolver{release: make(chan struct{})}
+	c := &CachingResolver{Resolver: upstream}
+	d := &DID{Method: "example", ID: "123"}
+
+	var wg sync.WaitGroup
+	docs := make([]*Document, 10)
+	for i := range docs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doc, _, err := c.Resolve(context.Background(), d, ResolutionOptions{})
+			if err != nil {
+				t.Error(err)
+			}
+			docs[i] = doc
+		}()
+	}
+	// wait for the upstream call
+	for upstream.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := c.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, context.Canceled, err, "waiter with canceled context")
+
+	close(upstream.release)
+	wg.Wait()
+	assert(t, int64(1), upstream.calls.Load(), "upstream calls")
+	for _, doc := range docs {
+		assert(t, true, doc == docs[0], "documents not shared")
+	}
+}
+
+func TestCachingResolverPanic(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	c := &CachingResolver{Resolver: ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		if calls.Add(1) == 1 {
+			<-release
+			panic("upstream failure")
+		}
+		return &Document{ID: d.String()}, nil, nil
+	})}
+	d := &DID{Method: "example", ID: "123"}
+
+	panicked := make(chan any)
+	go func() {
+		defer func() { panicked <- recover() }()
+		c.Resolve(context.Background(), d, ResolutionOptions{})
+	}()
+	// wait for the upstream call
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waited := make(chan error)
+	go func() {
+		_, _, err := c.Resolve(context.Background(), d, ResolutionOptions{})
+		waited <- err
+	}()
+	// wait for the shared call
+	for c.Stats().Shared == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	assert(t, "upstream failure", <-panicked, "panic of the first caller")
+	select {
+	case err := <-waited:
+		assert(t, true, errors.Is(err, ErrPanic), "waiter error %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("waiter hangs after upstream panic")
+	}
+
+	doc, _, err := c.Resolve(context.Background(), d, ResolutionOptions{})
+	assert(t, nil, err, "resolve after panic")
+	assert(t, "did:example:123", doc.ID, "document ID after panic")
+	assert(t, int64(2), calls.Load(), "upstream calls")
+}
+
+func TestCachingResolverNegative(t *testing.T) {
+	upstream := new(countingResolver)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &CachingResolver{
+		Resolver:    upstream,
+		NegativeTTL: 10 * time.Second,
+		Now:         func() time.Time { return now },
+	}
+	ctx := context.Background()
+	missing := &DID{Method: "example", ID: "missing"}
+
+	for range 3 {
+		_, _, err := c.Resolve(ctx, missing, ResolutionOptions{})
+		assert(t, true, errors.Is(err, ErrNotFound), "got error %v", err)
+	}
+	assert(t, int64(1), upstream.calls.Load(), "upstream calls for cached failure")
+
+	now = now.Add(10 * time.Second)
+	_, _, err := c.Resolve(ctx, missing, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrNotFound), "got error %v", err)
+	assert(t, int64(2), upstream.calls.Load(), "upstream calls after expiry")
+
+	// other failures are not cached
+	failing := &CachingResolver{
+		Resolver: ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+			upstream.calls.Add(1)
+			return nil, nil, errors.New("connection refused")
+		}),
+		NegativeTTL: time.Minute,
+	}
+	for range 2 {
+		_, _, err := failing.Resolve(ctx, missing, ResolutionOptions{})
+		assert(t, true, err != nil, "no error")
+	}
+	assert(t, int64(4), upstream.calls.Load(), "upstream calls for internal errors")
+}
+
+func TestCachingResolverLRU(t *testing.T) {
+	upstream := new(countingResolver)
+	c := &CachingResolver{Resolver: upstream, MaxEntries: 2}
+	ctx := context.Background()
+	a := &DID{Method: "example", ID: "a"}
+	b := &DID{Method: "example", ID: "b"}
+	x := &DID{Method: "example", ID: "x"}
+
+	for _, d := range []*DID{a, b, a, x, a, b} {
+		_, _, err := c.Resolve(ctx, d, ResolutionOptions{})
+		assert(t, nil, err)
+	}
+	// b got evicted by x, as a was used more recently
+	assert(t, int64(4), upstream.calls.Load(), "upstream calls")
+	stats := c.Stats()
+	assert(t, int64(2), stats.Hits, "hits")
+	assert(t, int64(4), stats.Misses, "misses")
+	assert(t, int64(2), stats.Evictions, "evictions")
+	assert(t, 2, stats.Entries, "entries")
+
+	c.Forget(a)
+	assert(t, 1, c.Stats().Entries, "entries after Forget")
+	c.Purge()
+	assert(t, CacheStats{Hits: 2, Misses: 4, Evictions: 2}, c.Stats(), "stats after Purge")
+
+	t.Run("bytes", func(t *testing.T) {
+		c := &CachingResolver{Resolver: upstream, MaxBytes: 600}
+		for i := range 10 {
+			_, _, err := c.Resolve(ctx, &DID{Method: "example", ID: fmt.Sprint(i)}, ResolutionOptions{})
+			assert(t, nil, err)
+			stats := c.Stats()
+			assert(t, true, stats.Bytes <= 600, "%d bytes cached", stats.Bytes)
+		}
+		stats := c.Stats()
+		assert(t, true, stats.Entries > 0 && stats.Evictions > 0, "stats %+v", stats)
+		assert(t, int64(10), int64(stats.Entries)+stats.Evictions, "entries plus evictions")
+
+		small := &CachingResolver{Resolver: upstream, MaxBytes: 1}
+		_, _, err := small.Resolve(ctx, a, ResolutionOptions{})
+		assert(t, nil, err)
+		assert(t, 0, small.Stats().Entries, "entries larger than MaxBytes")
+	})
+}
@@ -0,0 +1,101 @@
+package did
+
+// The predicates in this file classify single bytes according to the DID
+// grammar. None of them accept the '%' of a pct-encoded octet, which consists
+// of three bytes instead.
+// https://www.w3.org/TR/did-core/#did-syntax
+
+// A charset is a lookup table with one bit for each byte value.
+type charset [4]uint64
+
+// makeCharset returns the set with each byte in chars.
+func makeCharset(chars string) (s charset) {
+	for i := 0; i < len(chars); i++ {
+		s[chars[i]>>6] |= 1 << (chars[i] & 63)
+	}
+	return s
+}
+
+// contains returns whether c is in the set.
+func (s *charset) contains(c byte) bool {
+	return s[c>>6]&(1<<(c&63)) != 0
+}
+
+const (
+	alphaDigitChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	unreservedChars = alphaDigitChars + "-._~"
+	pcharChars      = unreservedChars + "!$&'()*+,;=" + ":@"
+)
+
+// Character classes of the DID grammar.
+var (
+	methodChars    = makeCharset("abcdefghijklmnopqrstuvwxyz0123456789")
+	idChars        = makeCharset(alphaDigitChars + ".-_")
+	idOrColonChars = makeCharset(alphaDigitChars + ".-_" + ":")
+	pathChars      = makeCharset(pcharChars)
+	pathOrSlash    = makeCharset(pcharChars + "/")
+	queryChars     = makeCharset(pcharChars + "/?")
+	fragmentChars  = makeCharset(pcharChars + "/?")
+	paramChars     = makeCharset("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~!$'()*,;:@/?")
+	hexChars       = makeCharset("0123456789abcdefABCDEF")
+)
+
+// IsMethodChar returns whether c is a method-char.
+//
+//	method-char = %x61-7A / DIGIT
+func IsMethodChar(c byte) bool {
+	return methodChars.contains(c)
+}
+
+// IsIDChar returns whether c is an idchar, excluding pct-encoded. The ':'
+// separators of a method-specific-id are not idchars.
+//
+//	idchar = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+func IsIDChar(c byte) bool {
+	return idChars.contains(c)
+}
+
+// IsPathChar returns whether c is a pchar of a path segment, excluding
+// pct-encoded. The '/' separators of a path are not pchars.
+//
+//	pchar = unreserved / pct-encoded / sub-delims / ":" / "@"
+func IsPathChar(c byte) bool {
+	return pathChars.contains(c)
+}
+
+// IsQueryChar returns whether c is permitted in a query, excluding
+// pct-encoded.
+//
+//	query = *( pchar / "/" / "?" )
+func IsQueryChar(c byte) bool {
+	return queryChars.contains(c)
+}
+
+// IsFragmentChar returns whether c is permitted in a fragment, excluding
+// pct-encoded.
+//
+//	fragment = *( pchar / "/" / "?" )
+func IsFragmentChar(c byte) bool {
+	return fragmentChars.contains(c)
+}
+
+// isParamChar returns whether c is permitted in the name or value of a query
+// parameter, excluding pct-encoded.
+func isParamChar(c byte) bool {
+	return paramChars.contains(c)
+}
+
+func isHex(c byte) bool {
+	return hexChars.contains(c)
+}
+
+// validMethod returns whether name is a method name of one or more method
+// characters.
+func validMethod(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if !methodChars.contains(name[i]) {
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,53 @@
+package did
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCharPredicates(t *testing.T) {
+	const (
+		lower  = "abcdefghijklmnopqrstuvwxyz"
+		upper  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		digits = "0123456789"
+	)
+	golden := []struct {
+		name string
+		f    func(byte) bool
+		want string
+	}{
+		{"IsMethodChar", IsMethodChar, lower + digits},
+		{"IsIDChar", IsIDChar, lower + upper + digits + ".-_"},
+		{"IsPathChar", IsPathChar, lower + upper + digits + "-._~!$&'()*+,;=:@"},
+		{"IsQueryChar", IsQueryChar, lower + upper + digits + "-._~!$&'()*+,;=:@/?"},
+		{"IsFragmentChar", IsFragmentChar, lower + upper + digits + "-._~!$&'()*+,;=:@/?"},
+	}
+	for _, g := range golden {
+		for c := 0; c < 256; c++ {
+			want := strings.IndexByte(g.want, byte(c)) >= 0
+			if got := g.f(byte(c)); got != want {
+				t.Errorf("%s(%q) got %t, want %t", g.name, c, got, want)
+			}
+		}
+	}
+}
+
+func TestCharsets(t *testing.T) {
+	golden := []struct {
+		name string
+		set  *charset
+		want func(byte) bool
+	}{
+		{"idOrColonChars", &idOrColonChars, func(c byte) bool { return IsIDChar(c) || c == ':' }},
+		{"pathOrSlash", &pathOrSlash, func(c byte) bool { return IsPathChar(c) || c == '/' }},
+		{"paramChars", &paramChars, func(c byte) bool { return IsQueryChar(c) && c != '&' && c != '=' && c != '+' }},
+		{"hexChars", &hexChars, func(c byte) bool { return strings.IndexByte("0123456789abcdefABCDEF", c) >= 0 }},
+	}
+	for _, g := range golden {
+		for c := 0; c < 256; c++ {
+			if got, want := g.set.contains(byte(c)), g.want(byte(c)); got != want {
+				t.Errorf("%s contains %q got %t, want %t", g.name, c, got, want)
+			}
+		}
+	}
+}
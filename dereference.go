@@ -0,0 +1,232 @@
+package did
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DereferencingOptions are the input metadata of a dereferencing.
+type DereferencingOptions struct {
+	// Accept is the media type of the preferred representation, which
+	// applies to the resolution of the DID.
+	Accept string
+
+	// NoCache requests a fresh resolution of the DID.
+	NoCache bool
+}
+
+// Dereferenced is the outcome of a dereferencing.
+type Dereferenced struct {
+	// Content is a *Document for DID URLs without fragment, and a
+	// *VerificationMethod or a *Service for fragments. The service
+	// parameter selects the URL of the endpoint, as a string.
+	Content any
+
+	// Metadata is of the resolution of the DID.
+	Metadata *Metadata
+
+	// Hashlink is the hl parameter with service selection, as it applies
+	// to the content at the endpoint URL. Verify the content with
+	// VerifyHashlink once retrieved.
+	Hashlink string
+}
+
+// Dereference returns the resource which u identifies, conform the DID URL
+// dereferencing algorithm, with r to resolve the DID. The versionId and
+// versionTime parameters select the document version. The service parameter
+// selects the endpoint URL of the service with the fragment of its id, and
+// the relativeRef parameter is resolved against that URL. Any fragment of u
+// applies to the endpoint URL then, unless it has a fragment already.
+// Otherwise, a fragment selects the verification method or the service in
+// the document. The transformKeys parameter converts verification methods,
+// including those in a document, to one of TypeMultikey, TypeJSONWebKey or
+// TypeJSONWebKey2020. DID paths are not supported, as their semantics are
+// method specific.
+//
+// The hl parameter must match the CanonicalContent, after any transformKeys,
+// or the error wraps ErrHashlinkMismatch. With service selection, the
+// parameter applies to the content at the endpoint URL instead, and it is
+// passed as the Hashlink of the result.
+//
+// Failures with a missing resource wrap ErrNotFound. Unknown transformKeys
+// values get ErrRepresentationNotSupported.
+// https://w3c.github.io/did-resolution/#dereferencing
+func Dereference(ctx context.Context, r Resolver, u *DIDURL, opts DereferencingOptions) (*Dereferenced, error) {
+	var service, relativeRef, transformKeys, hl string
+	var hasService, hasRelativeRef bool
+	ropts := ResolutionOptions{Accept: opts.Accept, NoCache: opts.NoCache}
+	for name, value := range u.Params() {
+		switch name {
+		case "service":
+			service, hasService = value, true
+		case "relativeRef":
+			relativeRef, hasRelativeRef = value, true
+		case "transformKeys":
+			transformKeys = value
+		case "hl":
+			hl = value
+		case "versionId":
+			ropts.VersionID = value
+		case "versionTime":
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("did: dereference %s: %w: versionTime: %w", u, ErrInvalidDID, err)
+			}
+			ropts.VersionTime = t
+		}
+	}
+	switch transformKeys {
+	case "", TypeMultikey, TypeJSONWebKey, TypeJSONWebKey2020:
+		break
+	default:
+		return nil, fmt.Errorf("did: dereference %s: transformKeys %q: %w", u, transformKeys, ErrRepresentationNotSupported)
+	}
+	if hasRelativeRef && !hasService {
+		return nil, fmt.Errorf("did: dereference %s: relativeRef without service: %w", u, ErrInvalidDID)
+	}
+	if u.Key().path != "" {
+		return nil, fmt.Errorf("did: dereference %s: DID path: %w", u, ErrNotFound)
+	}
+
+	doc, meta, err := r.Resolve(ctx, &u.DID, ropts)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil && meta.Document.Deactivated {
+		return nil, fmt.Errorf("did: dereference %s: DID deactivated: %w", u, ErrNotFound)
+	}
+
+	if hasService {
+		location, err := serviceURL(doc, service, relativeRef, u)
+		if err != nil {
+			return nil, fmt.Errorf("did: dereference %s: %w", u, err)
+		}
+		return &Dereferenced{Content: location, Metadata: meta, Hashlink: hl}, nil
+	}
+
+	var content any = doc
+	if u.Fragment != "" {
+		content, err = doc.Dereference("#" + u.Fragment)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if transformKeys != "" {
+		content, err = transform(content, transformKeys)
+		if err != nil {
+			return nil, fmt.Errorf("did: dereference %s: transformKeys: %w", u, err)
+		}
+	}
+	if hl != "" {
+		data, err := CanonicalContent(content)
+		if err == nil {
+			err = VerifyHashlink(hl, data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("did: dereference %s: %w", u, err)
+		}
+	}
+	return &Dereferenced{Content: content, Metadata: meta}, nil
+}
+
+// serviceURL returns the endpoint URL of the service with name as fragment,
+// with relativeRef and the fragment of u applied.
+func serviceURL(doc *Document, name, relativeRef string, u *DIDURL) (string, error) {
+	svc, err := doc.Dereference("#" + name)
+	if err != nil {
+		return "", err
+	}
+	s, ok := svc.(*Service)
+	if !ok {
+		return "", fmt.Errorf("service %q is a verification method: %w", name, ErrNotFound)
+	}
+	uris := s.ServiceEndpoint.URIs()
+	if len(uris) == 0 {
+		return "", fmt.Errorf("service %q has no endpoint URI: %w", name, ErrNotFound)
+	}
+
+	location, err := url.Parse(uris[0])
+	if err != nil {
+		return "", fmt.Errorf("service %q endpoint: %w", name, err)
+	}
+	if relativeRef != "" {
+		ref, err := url.Parse(relativeRef)
+		if err != nil {
+			return "", fmt.Errorf("relativeRef: %w: %w", ErrInvalidDID, err)
+		}
+		location = location.ResolveReference(ref)
+	}
+	if u.Fragment != "" && location.Fragment == "" {
+		location.RawFragment = u.Fragment
+		location.Fragment = unescape(u.Fragment)
+	}
+	return location.String(), nil
+}
+
+// transform converts the verification methods in content to the type.
+func transform(content any, typ string) (any, error) {
+	switch c := content.(type) {
+	case *VerificationMethod:
+		vm, err := transformKey(c, typ)
+		return vm, err
+	case *Document:
+		doc := *c
+		doc.VerificationMethod = make([]VerificationMethod, len(c.VerificationMethod))
+		for i := range c.VerificationMethod {
+			vm, err := transformKey(&c.VerificationMethod[i], typ)
+			if err != nil {
+				return nil, err
+			}
+			doc.VerificationMethod[i] = *vm
+		}
+		for _, list := range [...]*[]Relationship{&doc.Authentication, &doc.AssertionMethod, &doc.KeyAgreement, &doc.CapabilityInvocation, &doc.CapabilityDelegation} {
+			rels := make([]Relationship, len(*list))
+			for i, r := range *list {
+				if r.Embedded != nil {
+					vm, err := transformKey(r.Embedded, typ)
+					if err != nil {
+						return nil, err
+					}
+					r.Embedded = vm
+				}
+				rels[i] = r
+			}
+			if *list != nil {
+				*list = rels
+			}
+		}
+		return &doc, nil
+	default:
+		return content, nil
+	}
+}
+
+// transformKey returns vm as the type. Methods without public key, like the
+// ones with just a blockchain account, remain as is.
+func transformKey(vm *VerificationMethod, typ string) (*VerificationMethod, error) {
+	if vm.Type == typ || (vm.BlockchainAccountID != "" && vm.PublicKeyMultibase == "" && len(vm.PublicKeyJWK) == 0 && vm.PublicKeyBase58 == "") {
+		return vm, nil
+	}
+	k, err := vm.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("verification method %q: %w", vm.ID, err)
+	}
+	out := &VerificationMethod{ID: vm.ID, Type: typ, Controller: vm.Controller}
+	switch typ {
+	case TypeMultikey:
+		out.PublicKeyMultibase, err = k.Multikey()
+	default:
+		var jwk *JWK
+		jwk, err = k.JWK()
+		if err == nil {
+			out.PublicKeyJWK, err = json.Marshal(jwk)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("verification method %q: %w", vm.ID, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package did
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDereference(t *testing.T) {
+	const sample = `{"id":"did:example:123","verificationMethod":[{"id":"#key-1","type":"Ed25519VerificationKey2020","controller":"did:example:123","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"},{"id":"#key-2","type":"JsonWebKey2020","controller":"did:example:123","publicKeyJwk":{"kty":"EC","crv":"secp256k1","x":"eb5mfvncu6xVoGKVzocLBwKb_NstzijZWfKBWxb4F5g","y":"SDradyajxGVdpPv8DhEIqP0XtEimhVQZnEfQj_sQ1Lg"}}],"authentication":["#key-1"],"service":[{"id":"#files","type":"LinkedDomains","serviceEndpoint":"https://example.com/files/"},{"id":"#didcomm","type":"DIDCommMessaging","serviceEndpoint":[{"uri":"https://example.com/didcomm#inbox","accept":["didcomm/v2"]}]}]}`
+	var doc Document
+	assert(t, nil, json.Unmarshal([]byte(sample), &doc))
+	var r StaticResolver
+	assert(t, nil, r.Add(&doc))
+	ctx := context.Background()
+
+	dereference := func(s string) (any, error) {
+		u, err := ParseURL(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := Dereference(ctx, &r, u, DereferencingOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return res.Content, nil
+	}
+
+	tests := []struct {
+		url  string
+		want any
+	}{
+		{"did:example:123", &doc},
+		{"did:example:123#key-1", &doc.VerificationMethod[0]},
+		{"did:example:123#files", &doc.Service[0]},
+		{"did:example:123?service=files", "https://example.com/files/"},
+		{"did:example:123?service=files&relativeRef=%2Fa%2Fb.json#part", "https://example.com/a/b.json#part"},
+		{"did:example:123?service=files&relativeRef=c.json", "https://example.com/files/c.json"},
+		{"did:example:123?service=didcomm#other", "https://example.com/didcomm#inbox"},
+	}
+	for _, test := range tests {
+		got, err := dereference(test.url)
+		assert(t, nil, err, test.url)
+		assert(t, test.want, got, test.url)
+	}
+
+	got, err := dereference("did:example:123?transformKeys=JsonWebKey2020#key-1")
+	assert(t, nil, err)
+	vm := got.(*VerificationMethod)
+	assert(t, "JsonWebKey2020", vm.Type)
+	assert(t, `{"kty":"OKP","crv":"Ed25519","x":"Lm_M42cB3HkUiODQsXRcweM6TByfzEHGO9ND274JcOY"}`, string(vm.PublicKeyJWK))
+
+	got, err = dereference("did:example:123?transformKeys=Multikey")
+	assert(t, nil, err)
+	transformed := got.(*Document)
+	assert(t, "Multikey", transformed.VerificationMethod[1].Type)
+	assert(t, "zQ3shVc2UkAfJCdc1TR8E66J85h48P43r93q8jGPkPpjF9Ef9", transformed.VerificationMethod[1].PublicKeyMultibase)
+	assert(t, "JsonWebKey2020", doc.VerificationMethod[1].Type, "original modified")
+
+	// round trip of an EC key through JWK
+	got, err = dereference("did:example:123?transformKeys=JsonWebKey#key-2")
+	assert(t, nil, err)
+	k, err := got.(*VerificationMethod).JWK()
+	assert(t, nil, err)
+	assert(t, "SDradyajxGVdpPv8DhEIqP0XtEimhVQZnEfQj_sQ1Lg", k.Y)
+
+	// hashlink of the canonical JSON
+	data, err := CanonicalContent(&doc.VerificationMethod[0])
+	assert(t, nil, err)
+	hl := Hashlink(data)
+	got, err = dereference("did:example:123?hl=" + hl + "#key-1")
+	assert(t, nil, err)
+	assert(t, &doc.VerificationMethod[0], got)
+	u, err := ParseURL("did:example:123?service=files&hl=" + hl)
+	assert(t, nil, err)
+	res, err := Dereference(ctx, &r, u, DereferencingOptions{})
+	assert(t, nil, err)
+	assert(t, hl, res.Hashlink)
+
+	errTests := []struct {
+		url  string
+		want error
+	}{
+		{"did:example:123#key-9", ErrNotFound},
+		{"did:example:123?service=none", ErrNotFound},
+		{"did:example:123?service=key-1", ErrNotFound},
+		{"did:example:123/path", ErrNotFound},
+		{"did:example:456", ErrNotFound},
+		{"did:example:123?relativeRef=a", ErrInvalidDID},
+		{"did:example:123?versionTime=yesterday", ErrInvalidDID},
+		{"did:example:123?transformKeys=Ed25519VerificationKey2018", ErrRepresentationNotSupported},
+		{"did:example:123?hl=" + hl + "#key-2", ErrHashlinkMismatch},
+		{"did:example:123?hl=" + hl + "&transformKeys=JsonWebKey#key-1", ErrHashlinkMismatch},
+	}
+	for _, test := range errTests {
+		_, err := dereference(test.url)
+		assert(t, true, errors.Is(err, test.want), "%s got error %v, want %v", test.url, err, test.want)
+	}
+}
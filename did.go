@@ -3,25 +3,45 @@
 package did
 
 import (
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"iter"
+	"slices"
 	"strings"
-
-	didlib "github.com/pascaldekloe/did"
+	"unicode/utf8"
+	"unsafe"
 )
 
-// A DID represents a parsed DID or a DID URL
+// A DID represents a parsed DID, which is the method-specific-id of a DID
+// Method.
+// https://w3c.github.io/did-core/#did-syntax
 type DID struct {
 	// DID Method
 	// https://w3c.github.io/did-core/#method-specific-syntax
 	Method string
 
-	// The method-specific-id component of a DID
+	// The method-specific-id component of a DID, with its pct-encoded
+	// octets decoded
 	// method-specific-id = *idchar *( ":" *idchar )
 	ID string
 
-	// method-specific-id may be composed of multiple `:` separated idstrings
+	// method-specific-id may be composed of multiple `:` separated idstrings,
+	// each with its pct-encoded octets decoded, such that an escaped colon
+	// ("%3A") stays within its idstring
 	IDStrings []string
 
+	// raw is the input of Parse, valid as long as the fields match rawKey
+	raw    string
+	rawKey Key
+}
+
+// A DIDURL represents a parsed DID URL, which is a DID with an optional Path,
+// Query and Fragment.
+// https://w3c.github.io/did-core/#did-url-syntax
+type DIDURL struct {
+	DID
+
 	// DID Path, the portion of a DID reference that follows the first forward slash character.
 	// https://w3c.github.io/did-core/#path
 	Path string
@@ -35,84 +55,1103 @@ type DID struct {
 	// query = *( pchar / "/" / "?" )
 	Query string
 
+	// ForceQuery appends a query ('?') even if Query is empty
+	ForceQuery bool
+
 	// DID Fragment, the portion of a DID reference that follows the first hash sign character ("#")
 	// https://w3c.github.io/did-core/#fragment
 	Fragment string
+
+	// ForceFragment appends a fragment ('#') even if Fragment is empty
+	ForceFragment bool
+
+	// raw is the input of ParseURL, valid as long as the fields match rawKey
+	raw    string
+	rawKey Key
 }
 
-// IsURL returns true if a DID has a Path, a Query or a Fragment
+// IsURL returns true if a DID URL has a Path, a Query or a Fragment
 // https://w3c-ccg.github.io/did-spec/#dfn-did-reference
-func (d *DID) IsURL() bool {
-	return (d.Path != "" || len(d.PathSegments) > 0 || d.Query != "" || d.Fragment != "")
+func (d *DIDURL) IsURL() bool {
+	return (d.Path != "" || len(d.PathSegments) > 0 || d.Query != "" || d.ForceQuery ||
+		d.Fragment != "" || d.ForceFragment)
 }
 
-// String encodes a DID struct into a valid DID string.
-// nolint: gocyclo
+// IsZero returns whether no DID is set, i.e., whether d has no Method, no ID
+// and no IDStrings. The encoding/json "omitzero" option uses IsZero.
+func (d DID) IsZero() bool {
+	return d.Method == "" && d.ID == "" && len(d.IDStrings) == 0
+}
+
+// IsZero returns whether no DID URL is set, i.e., whether d has no DID and no
+// Path, Query or Fragment. The encoding/json "omitzero" option uses IsZero.
+func (d DIDURL) IsZero() bool {
+	return d.DID.IsZero() && !d.IsURL()
+}
+
+// Clone returns a deep copy of a DID. The copy does not share the backing
+// array of IDStrings with the original.
+func (d *DID) Clone() *DID {
+	c := *d
+	if d.IDStrings != nil {
+		c.IDStrings = append([]string(nil), d.IDStrings...)
+	}
+	return &c
+}
+
+// Clone returns a deep copy of a DID URL. The copy does not share the backing
+// arrays of IDStrings and PathSegments with the original.
+func (d *DIDURL) Clone() *DIDURL {
+	c := *d
+	c.DID = *d.DID.Clone()
+	if d.PathSegments != nil {
+		c.PathSegments = append([]string(nil), d.PathSegments...)
+	}
+	return &c
+}
+
+// URL returns the DID as a DID URL, without Path, Query and Fragment.
+func (d *DID) URL() *DIDURL {
+	return &DIDURL{DID: *d.Clone()}
+}
+
+// Base returns a copy of the DID without Path, Query and Fragment, i.e., the
+// bare DID to resolve for a DID URL.
+func (d *DIDURL) Base() *DID {
+	return d.DID.Clone()
+}
+
+// WithPath returns a copy of the DID URL with Path replaced, and with the
+// PathSegments to match. An empty path removes the DID Path.
+func (d *DIDURL) WithPath(path string) *DIDURL {
+	c := d.Clone()
+	c.Path = path
+	c.PathSegments = nil
+	if path != "" {
+		c.PathSegments = strings.Split(path, "/")
+		for i, s := range c.PathSegments {
+			c.PathSegments[i] = unescape(s)
+		}
+	}
+	c.memoize()
+	return c
+}
+
+// Segments returns an iterator over the unescaped segments of the DID Path.
+// Unlike PathSegments, the segments are only decoded on demand.
+func (d *DIDURL) Segments() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if d.Path == "" {
+			for _, s := range d.PathSegments {
+				if !yield(s) {
+					return
+				}
+			}
+			return
+		}
+
+		path := d.Path
+		for {
+			i := strings.IndexByte(path, '/')
+			if i < 0 {
+				yield(unescape(path))
+				return
+			}
+			if !yield(unescape(path[:i])) {
+				return
+			}
+			path = path[i+1:]
+		}
+	}
+}
+
+// AppendPathSegment adds a segment to the end of the DID Path. The segment is
+// percent-encoded as needed, and both Path and PathSegments are updated.
+func (d *DIDURL) AppendPathSegment(segment string) {
+	hasPath := d.Path != "" || len(d.PathSegments) != 0
+	if d.Path == "" && len(d.PathSegments) != 0 {
+		// derive the path from the segments first
+		escaped := make([]string, len(d.PathSegments))
+		for i, s := range d.PathSegments {
+			escaped[i] = escape(s, IsPathChar)
+		}
+		d.Path = strings.Join(escaped, "/")
+	}
+
+	if hasPath {
+		d.Path += "/" + escape(segment, IsPathChar)
+	} else {
+		d.Path = escape(segment, IsPathChar)
+	}
+	d.PathSegments = append(d.PathSegments, segment)
+	d.memoize()
+}
+
+// Params returns an iterator over the name-value pairs of the DID Query, in
+// order of appearance. Both name and value are unescaped. Repeated names are
+// yielded once for each occurrence.
+func (d *DIDURL) Params() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		query := d.Query
+		for query != "" {
+			param := query
+			if i := strings.IndexByte(query, '&'); i >= 0 {
+				param, query = query[:i], query[i+1:]
+			} else {
+				query = ""
+			}
+			if param == "" {
+				continue
+			}
+
+			name, value := param, ""
+			if i := strings.IndexByte(param, '='); i >= 0 {
+				name, value = param[:i], param[i+1:]
+			}
+			if !yield(unescape(name), unescape(value)) {
+				return
+			}
+		}
+	}
+}
+
+// SetQueryParam sets the value of a DID Query parameter. The first occurrence
+// of name is replaced in place, and any further occurrences are removed. The
+// parameter is appended when name is absent. Both name and value are escaped
+// as needed.
+func (d *DIDURL) SetQueryParam(name, value string) {
+	param := encodeParam(name, value)
+
+	var params []string
+	var found bool
+	for _, p := range splitQuery(d.Query) {
+		if paramName(p) != name {
+			params = append(params, p)
+		} else if !found {
+			params = append(params, param)
+			found = true
+		}
+	}
+	if !found {
+		params = append(params, param)
+	}
+	d.Query = strings.Join(params, "&")
+	d.memoize()
+}
+
+// DelQueryParam removes all occurrences of a DID Query parameter. The query
+// is removed as a whole when no parameters remain.
+func (d *DIDURL) DelQueryParam(name string) {
+	var params []string
+	for _, p := range splitQuery(d.Query) {
+		if paramName(p) != name {
+			params = append(params, p)
+		}
+	}
+	d.Query = strings.Join(params, "&")
+	if d.Query == "" {
+		d.ForceQuery = false
+	}
+	d.memoize()
+}
+
+// WithQuery returns a copy of the DID URL with Query replaced. An empty query
+// removes the DID Query.
+func (d *DIDURL) WithQuery(query string) *DIDURL {
+	c := d.Clone()
+	c.Query = query
+	c.ForceQuery = false
+	c.memoize()
+	return c
+}
+
+// WithFragment returns a copy of the DID URL with Fragment replaced. An empty
+// fragment removes the DID Fragment.
+func (d *DIDURL) WithFragment(fragment string) *DIDURL {
+	c := d.Clone()
+	c.Fragment = fragment
+	c.ForceFragment = false
+	c.memoize()
+	return c
+}
+
+// WithoutQuery returns a copy of the DID URL without DID Query.
+func (d *DIDURL) WithoutQuery() *DIDURL {
+	return d.WithQuery("")
+}
+
+// WithoutFragment returns a copy of the DID URL without DID Fragment.
+func (d *DIDURL) WithoutFragment() *DIDURL {
+	return d.WithFragment("")
+}
+
+// String encodes a DID struct into a valid DID string. A DID from Parse
+// encodes to the original input, byte for byte, as long as none of its
+// fields were modified. Otherwise, the idstrings are escaped as needed, and
+// they are separated by colons. IDStrings apply when they match the ID. A DID
+// with IDStrings only has them joined and escaped as a whole, such that any
+// and all colons are escaped (as "%3A").
 func (d *DID) String() string {
-	if d.Method == "" {
-		// if there is no Method, return an empty string
-		return ""
+	if d.rawValid() {
+		return d.raw
 	}
+	return bytesToString(d.AppendString(make([]byte, 0, d.stringLen())))
+}
 
-	var u didlib.URL
-	u.Method = d.Method
+// AppendString appends the String encoding to dst, and it returns the
+// extended buffer.
+func (d *DID) AppendString(dst []byte) []byte {
+	if d.rawValid() {
+		return append(dst, d.raw...)
+	}
+	return d.appendFields(dst)
+}
 
+// appendFields is like AppendString, without the original input of Parse.
+func (d *DID) appendFields(dst []byte) []byte {
+	if d.Method == "" || (d.ID == "" && len(d.IDStrings) == 0) {
+		// zero Key
+		return dst
+	}
+
+	dst = append(dst, "did:"...)
+	dst = append(dst, d.Method...)
+	dst = append(dst, ':')
+	return d.appendID(dst, "%3A")
+}
+
+// appendID appends the method-specific-id to dst, with the idstrings
+// escaped as needed. Sep goes in between the IDStrings of a DID without ID.
+func (d *DID) appendID(dst []byte, sep string) []byte {
+	valid := idCharsOf(lookupMethod(d.Method))
 	if d.ID != "" {
-		u.SpecID = d.ID
-	} else if len(d.IDStrings) > 0 {
-		u.SpecID = strings.Join(d.IDStrings[:], ":")
+		if !d.idStringsMatch() {
+			// colons separate the idstrings
+			return appendEscape(dst, d.ID, idOrColonCharsOf(lookupMethod(d.Method)).contains)
+		}
+		sep = ":"
+	}
+	for i, s := range d.IDStrings {
+		if i != 0 {
+			dst = append(dst, sep...)
+		}
+		dst = appendEscape(dst, s, valid.contains)
+	}
+	return dst
+}
+
+// idLen returns the length of appendID with a "%3A" separator.
+func (d *DID) idLen() int {
+	valid := idCharsOf(lookupMethod(d.Method))
+	if d.ID != "" && !d.idStringsMatch() {
+		return escapedLen(d.ID, idOrColonCharsOf(lookupMethod(d.Method)).contains)
+	}
+	sepLen := len("%3A")
+	if d.ID != "" {
+		sepLen = len(":")
+	}
+	n := 0
+	for i, s := range d.IDStrings {
+		if i != 0 {
+			n += sepLen
+		}
+		n += escapedLen(s, valid.contains)
+	}
+	return n
+}
+
+// idStringsMatch returns whether the IDStrings joined with colons equal the
+// ID, without allocation.
+func (d *DID) idStringsMatch() bool {
+	if len(d.IDStrings) == 0 {
+		return false
+	}
+	id := d.ID
+	for i, s := range d.IDStrings {
+		if i != 0 {
+			if id == "" || id[0] != ':' {
+				return false
+			}
+			id = id[1:]
+		}
+		if !strings.HasPrefix(id, s) {
+			return false
+		}
+		id = id[len(s):]
+	}
+	return id == ""
+}
+
+// stringLen returns the length of the String encoding.
+func (d *DID) stringLen() int {
+	if d.Method == "" || (d.ID == "" && len(d.IDStrings) == 0) {
+		return 0
+	}
+	return len("did:") + len(d.Method) + 1 + d.idLen()
+}
+
+// rawValid returns whether the fields still match the parsed input.
+func (d *DID) rawValid() bool {
+	return d.raw != "" && d.Method == d.rawKey.method && d.ID == d.rawKey.id
+}
+
+// String encodes a DIDURL struct into a valid DID URL string. A DID URL from
+// ParseURL encodes to the original input, byte for byte, as long as none of
+// its fields were modified. The encoding is memoized for DID URLs from
+// ParseURL, the With methods, the query and path mutators, and Builder, such
+// that repeated calls do not allocate.
+func (d *DIDURL) String() string {
+	if d.rawValid() {
+		return d.raw
+	}
+	return bytesToString(d.AppendString(make([]byte, 0, d.stringLen())))
+}
+
+// AppendString appends the String encoding to dst, and it returns the
+// extended buffer.
+func (d *DIDURL) AppendString(dst []byte) []byte {
+	if d.rawValid() {
+		return append(dst, d.raw...)
+	}
+	offset := len(dst)
+	dst = d.DID.appendFields(dst)
+	if len(dst) == offset {
+		// zero Key
+		return dst
+	}
+
+	if d.Path != "" {
+		dst = append(dst, '/')
+		dst = append(dst, d.Path...)
 	} else {
-		// if there is no ID, return an empty string
-		return ""
+		for _, s := range d.PathSegments {
+			dst = append(dst, '/')
+			dst = appendEscape(dst, s, IsPathChar)
+		}
+	}
+	if d.Query != "" || d.ForceQuery {
+		dst = append(dst, '?')
+		dst = append(dst, d.Query...)
+	}
+	if d.Fragment != "" || d.ForceFragment {
+		dst = append(dst, '#')
+		dst = append(dst, d.Fragment...)
+	}
+	return dst
+}
+
+// stringLen returns the length of the String encoding.
+func (d *DIDURL) stringLen() int {
+	n := d.DID.stringLen()
+	if n == 0 {
+		return 0
 	}
 
 	if d.Path != "" {
-		u.RawPath = "/" + d.Path
-	} else if len(d.PathSegments) > 0 {
-		u.SetPathSegments(d.PathSegments...)
+		n += 1 + len(d.Path)
+	} else {
+		for _, s := range d.PathSegments {
+			n += 1 + escapedLen(s, IsPathChar)
+		}
+	}
+	if d.Query != "" || d.ForceQuery {
+		n += 1 + len(d.Query)
+	}
+	if d.Fragment != "" || d.ForceFragment {
+		n += 1 + len(d.Fragment)
+	}
+	return n
+}
+
+// rawValid returns whether the fields still match the parsed input.
+func (d *DIDURL) rawValid() bool {
+	k := &d.rawKey
+	if d.raw == "" || d.Method != k.method || d.ID != k.id {
+		return false
 	}
 
-	if d.Query != "" {
-		u.RawQuery = "?" + d.Query
+	switch {
+	case d.Path != "":
+		if !isDelimited(k.path, '/', d.Path) {
+			return false
+		}
+	case len(d.PathSegments) != 0:
+		if d.keyPath() != k.path {
+			return false
+		}
+	case k.path != "":
+		return false
 	}
 
-	if d.Fragment != "" {
-		u.RawFragment = "#" + d.Fragment
+	if d.Query != "" || d.ForceQuery {
+		if !isDelimited(k.query, '?', d.Query) {
+			return false
+		}
+	} else if k.query != "" {
+		return false
 	}
 
-	return u.String()
+	if d.Fragment != "" || d.ForceFragment {
+		return isDelimited(k.fragment, '#', d.Fragment)
+	}
+	return k.fragment == ""
 }
 
-// Parse parses the input string into a DID structure.
-func Parse(input string) (*DID, error) {
-	u, err := didlib.ParseURL(input)
+// memoize assembles the String encoding ahead of time, such that it can be
+// reused until any of the fields change. Values with IDStrings only, i.e.,
+// without ID, are not memoized.
+func (d *DIDURL) memoize() {
+	if d.rawValid() || d.ID == "" {
+		return
+	}
+	d.raw = ""
+	s := d.String()
+	if s == "" {
+		return
+	}
+
+	idEnd := d.DID.stringLen()
+	queryEnd := len(s)
+	if d.Fragment != "" || d.ForceFragment {
+		queryEnd -= 1 + len(d.Fragment)
+	}
+	pathEnd := queryEnd
+	if d.Query != "" || d.ForceQuery {
+		pathEnd -= 1 + len(d.Query)
+	}
+
+	d.raw = s
+	d.rawKey = Key{
+		method:   d.Method,
+		id:       d.ID,
+		path:     s[idEnd:pathEnd],
+		query:    s[pathEnd:queryEnd],
+		fragment: s[queryEnd:],
+	}
+	d.DID.raw = s[:idEnd]
+	d.DID.rawKey = Key{method: d.Method, id: d.ID}
+}
+
+// isDelimited returns whether s equals delim followed by v.
+func isDelimited(s string, delim byte, v string) bool {
+	return len(s) == len(v)+1 && s[0] == delim && s[1:] == v
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, which makes
+// a DID encode as a JSON string.
+func (d DID) MarshalText() ([]byte, error) {
+	return d.AppendString(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, which
+// makes a DID decode from a JSON string. The backing array of IDStrings is
+// reused, such that decoding into existing values only allocates a copy of
+// text. Copies of the receiver share that array, so their IDStrings are
+// overwritten too. Use Clone for copies which must remain intact. The
+// receiver is not modified on error.
+func (d *DID) UnmarshalText(text []byte) error {
+	input := string(text)
+	errs := parseErrors{}
+	o, _ := scanDID(input, &errs)
+	if len(errs.list) != 0 {
+		return errs.list[0]
+	}
+	d.set(input, o, d.IDStrings[:0])
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, which makes
+// a DIDURL encode as a JSON string.
+func (d DIDURL) MarshalText() ([]byte, error) {
+	return d.AppendString(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, which
+// makes a DIDURL decode from a JSON string. The backing arrays of IDStrings
+// and PathSegments are reused, such that decoding into existing values only
+// allocates a copy of text, and any unescaped path segments. Copies of the
+// receiver share those arrays, so their IDStrings and PathSegments are
+// overwritten too. Use Clone for copies which must remain intact. The
+// receiver is not modified on error.
+func (d *DIDURL) UnmarshalText(text []byte) error {
+	input := string(text)
+	errs := parseErrors{}
+	o, _ := scanURL(input, &errs)
+	if len(errs.list) != 0 {
+		return errs.list[0]
+	}
+	d.set(input, o, d.IDStrings[:0], d.PathSegments[:0])
+	return nil
+}
+
+// Scan implements the fmt.Scanner interface for the 's' and 'v' verbs. The
+// input is read up to the next white space, as a DID.
+func (d *DID) Scan(state fmt.ScanState, verb rune) error {
+	if verb != 's' && verb != 'v' {
+		return fmt.Errorf("did: unsupported scan verb %%%c", verb)
+	}
+	token, err := state.Token(true, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if u.IsRelative() {
-		return nil, errors.New("relative URL denied")
+	return d.UnmarshalText(token)
+}
+
+// Scan implements the fmt.Scanner interface for the 's' and 'v' verbs. The
+// input is read up to the next white space, as a DID URL.
+func (d *DIDURL) Scan(state fmt.ScanState, verb rune) error {
+	if verb != 's' && verb != 'v' {
+		return fmt.Errorf("did: unsupported scan verb %%%c", verb)
 	}
+	token, err := state.Token(true, nil)
+	if err != nil {
+		return err
+	}
+	return d.UnmarshalText(token)
+}
+
+// A Key is a comparable representation of a DID or a DID URL. Values which
+// encode to the same string have equal keys, so keys can be used to index
+// maps and sets. The IDStrings of a DID without ID count as colon separated
+// for the key, like they do with ID set to the joined IDStrings.
+type Key struct {
+	// the id has its idstrings escaped as needed, separated by colons, and
+	// the path, query and fragment include their leading delimiter, if any
+	method, id, path, query, fragment string
+}
 
-	d := DID{
-		Method:       u.Method,
-		ID:           u.SpecID,
-		IDStrings:    strings.Split(u.SpecID, ":"),
-		Path:         u.RawPath,
-		PathSegments: u.PathSegments(),
-		Query:        u.RawQuery,
-		Fragment:     u.RawFragment,
+// Key returns the comparable representation of a DID. The zero Key is
+// returned when either the Method or the ID is missing.
+func (d *DID) Key() Key {
+	rawID := ""
+	if d.rawValid() {
+		rawID = d.raw[len("did:")+len(d.Method)+1:]
 	}
+	return d.key(rawID)
+}
 
-	// trim leading characters
+// key returns the Key with the method-specific-id as written in rawID, if
+// not empty.
+func (d *DID) key(rawID string) Key {
+	if d.Method == "" {
+		// if there is no Method, there is no DID
+		return Key{}
+	}
+
+	k := Key{method: d.Method}
+	switch {
+	case d.ID == "" && len(d.IDStrings) == 0:
+		// if there is no ID, there is no DID
+		return Key{}
+	case rawID != "":
+		k.id = rawID
+	case d.ID != "" && d.idLen() == len(d.ID):
+		// no escapes
+		k.id = d.ID
+	default:
+		k.id = string(d.appendID(nil, ":"))
+	}
+	return k
+}
+
+// Key returns the comparable representation of a DID URL. The zero Key is
+// returned when either the Method or the ID is missing.
+func (d *DIDURL) Key() Key {
+	rawID := ""
+	if d.rawValid() {
+		r := &d.rawKey
+		rawID = d.raw[len("did:")+len(d.Method)+1 : len(d.raw)-len(r.path)-len(r.query)-len(r.fragment)]
+	}
+	k := d.DID.key(rawID)
+	if k.method == "" {
+		return Key{}
+	}
+
+	k.path = d.keyPath()
+
+	if d.Query != "" || d.ForceQuery {
+		k.query = "?" + d.Query
+	}
+
+	if d.Fragment != "" || d.ForceFragment {
+		k.fragment = "#" + d.Fragment
+	}
+
+	return k
+}
+
+// keyPath returns the path of the Key, including its leading slash.
+func (d *DIDURL) keyPath() string {
 	if d.Path != "" {
-		d.Path = d.Path[1:]
+		return "/" + d.Path
+	}
+	if len(d.PathSegments) == 0 {
+		return ""
+	}
+	escaped := make([]string, len(d.PathSegments))
+	for i, s := range d.PathSegments {
+		escaped[i] = escape(s, IsPathChar)
+	}
+	return "/" + strings.Join(escaped, "/")
+}
+
+// string returns the textual representation, which is empty for the zero
+// Key.
+func (k Key) string() string {
+	if k.method == "" {
+		return ""
+	}
+	return "did:" + k.method + ":" + k.id + k.path + k.query + k.fragment
+}
+
+// EqualConstantTime returns whether a and b encode to the same DID, in time
+// independent of their content, for use in access control. Only the lengths
+// of the methods and the method-specific-ids may leak through timing. A nil
+// DID, or one without Method or ID, never matches.
+func EqualConstantTime(a, b *DID) bool {
+	var ka, kb Key
+	if a != nil {
+		ka = a.Key()
+	}
+	if b != nil {
+		kb = b.Key()
+	}
+	nonZero := subtle.ConstantTimeEq(int32(len(ka.method)), 0) ^ 1
+	return nonZero&constantTimeEq(ka.method, kb.method)&constantTimeEq(ka.id, kb.id) == 1
+}
+
+// constantTimeEq returns 1 when a and b are equal, and 0 otherwise, with only
+// the lengths leaking through timing.
+func constantTimeEq(a, b string) int {
+	if len(a) != len(b) {
+		return 0
+	}
+	var v byte
+	for i := 0; i < len(a); i++ {
+		v |= a[i] ^ b[i]
+	}
+	return subtle.ConstantTimeByteEq(v, 0)
+}
+
+// Parse parses the input string into a DID structure. DID URLs are denied;
+// see ParseURL for those. Any error is a *ParseError.
+func Parse(input string) (*DID, error) {
+	errs := parseErrors{}
+	d := parse(input, &errs)
+	if len(errs.list) != 0 {
+		return nil, errs.list[0]
+	}
+	return d, nil
+}
+
+// parse is like parseURL, with DID URLs denied.
+func parse(input string, errs *parseErrors) *DID {
+	o, ok := scanDID(input, errs)
+	if !ok {
+		return nil
+	}
+	a := new(didAlloc)
+	a.d.set(input, o, a.ids[:0])
+	return &a.d
+}
+
+// scanDID is like scanURL, with DID URLs denied.
+func scanDID(input string, errs *parseErrors) (o offsets, ok bool) {
+	o, ok = scanURL(input, errs)
+	if !ok || o.idEnd == len(input) {
+		return o, ok
+	}
+
+	c := ComponentPath
+	switch input[o.idEnd] {
+	case '?':
+		c = ComponentQuery
+	case '#':
+		c = ComponentFragment
+	}
+	errs.add(newParseError(input, o.idEnd, c, ErrURLDenied))
+	return o, false
+}
+
+// ParseURL parses the input string into a DIDURL structure. The input may be
+// either a DID or a DID URL. Any error is a *ParseError.
+func ParseURL(input string) (*DIDURL, error) {
+	errs := parseErrors{}
+	u := parseURL(input, &errs)
+	if len(errs.list) != 0 {
+		return nil, errs.list[0]
+	}
+	return u, nil
+}
+
+// parseErrors collects the problems found in input.
+type parseErrors struct {
+	// all continues parsing after the first problem.
+	all  bool
+	list []error
+}
+
+// add records a problem, and it reports whether parsing should stop.
+func (e *parseErrors) add(err *ParseError) (stop bool) {
+	e.list = append(e.list, err)
+	return !e.all
+}
+
+// err returns the problems ordered by offset, or nil for none. A single
+// problem is returned as is.
+func (e *parseErrors) err() error {
+	switch len(e.list) {
+	case 0:
+		return nil
+	case 1:
+		return e.list[0]
+	}
+	slices.SortStableFunc(e.list, func(a, b error) int {
+		return a.(*ParseError).Offset - b.(*ParseError).Offset
+	})
+	return errors.Join(e.list...)
+}
+
+// didAlloc holds a DID together with the backing array of IDStrings for the
+// common case of a single idstring, such that Parse allocates once.
+type didAlloc struct {
+	d   DID
+	ids [1]string
+}
+
+// urlAlloc is the DIDURL equivalent of didAlloc.
+type urlAlloc struct {
+	u   DIDURL
+	ids [1]string
+}
+
+// parseURL parses input with any problems recorded in errs. The return is
+// not valid when errs has entries.
+func parseURL(input string, errs *parseErrors) *DIDURL {
+	o, ok := scanURL(input, errs)
+	if !ok {
+		return nil
+	}
+
+	a := new(urlAlloc)
+	a.u.set(input, o, a.ids[:0], nil)
+	return &a.u
+}
+
+// set assigns the components of input, with the IDStrings appended to ids,
+// and with the PathSegments appended to segs.
+func (u *DIDURL) set(input string, o offsets, ids, segs []string) {
+	u.DID.set(input, o, ids)
+
+	u.Path = ""
+	u.PathSegments = segs
+	if o.pathEnd > o.idEnd {
+		u.Path = input[o.idEnd+1 : o.pathEnd]
+		u.PathSegments = splitAppend(segs, u.Path, '/')
+		for i, s := range u.PathSegments {
+			u.PathSegments[i] = unescape(s)
+		}
+	}
+	u.Query = input[min(o.pathEnd+1, o.queryEnd):o.queryEnd]
+	u.ForceQuery = o.queryEnd > o.pathEnd && u.Query == ""
+	u.Fragment = input[min(o.queryEnd+1, len(input)):]
+	u.ForceFragment = o.queryEnd < len(input) && u.Fragment == ""
+
+	u.raw = input
+	u.rawKey = Key{
+		method:   u.Method,
+		id:       u.ID,
+		path:     input[o.idEnd:o.pathEnd],
+		query:    input[o.pathEnd:o.queryEnd],
+		fragment: input[o.queryEnd:],
+	}
+}
+
+// set assigns the DID components of input, with the IDStrings appended to
+// buf.
+func (d *DID) set(input string, o offsets, buf []string) {
+	d.Method = input[len("did:"):o.methodEnd]
+	id := input[o.methodEnd+1 : o.idEnd]
+	d.ID = unescape(id)
+	d.IDStrings = splitAppend(buf, id, ':')
+	if len(d.ID) != len(id) {
+		for i, s := range d.IDStrings {
+			d.IDStrings[i] = unescape(s)
+		}
 	}
-	if d.Query != "" {
-		d.Query = d.Query[1:]
+	d.raw = input[:o.idEnd]
+	d.rawKey = Key{method: d.Method, id: d.ID}
+}
+
+// splitAppend appends the sep separated substrings of s to dst. It only
+// allocates when dst has insufficient capacity.
+func splitAppend(dst []string, s string, sep byte) []string {
+	if n := len(dst) + strings.Count(s, string(sep)) + 1; n > cap(dst) {
+		dst = append(make([]string, 0, n), dst...)
+	}
+	for {
+		i := strings.IndexByte(s, sep)
+		if i < 0 {
+			return append(dst, s)
+		}
+		dst = append(dst, s[:i])
+		s = s[i+1:]
+	}
+}
+
+// offsets locate the components of a DID URL in its input. Each end is
+// exclusive, and the path, query and fragment start with their delimiter.
+// Scanning sets the offsets in order of appearance, up to the component
+// with the first problem.
+type offsets struct {
+	methodEnd int // at the colon which precedes the method-specific-id
+	idEnd     int
+	pathEnd   int
+	queryEnd  int
+}
+
+// scanURL locates the components of input in a single pass, with any
+// problems recorded in errs. The offsets are not valid when errs has entries.
+// Scanning halted when ok is false.
+// nolint: gocyclo
+func scanURL(input string, errs *parseErrors) (o offsets, ok bool) {
+	const scheme = "did:"
+	for i := 0; i < len(scheme); i++ {
+		if i >= len(input) || input[i] != scheme[i] {
+			errs.add(newParseError(input, i, ComponentScheme, ErrMissingScheme))
+			// components can't be located without the scheme
+			return o, false
+		}
+	}
+
+	// method-name = 1*method-char
+	i := len(scheme)
+	for i < len(input) && input[i] != ':' {
+		if !methodChars.contains(input[i]) && errs.add(newParseError(input, i, ComponentMethod, ErrInvalidMethodChar)) {
+			return o, false
+		}
+		i++
+	}
+	if i == len(scheme) && errs.add(newParseError(input, i, ComponentMethod, ErrEmptyMethod)) {
+		return o, false
+	}
+	o.methodEnd = i
+	if i >= len(input) {
+		errs.add(newParseError(input, i, ComponentID, ErrEmptyID))
+		return o, false
+	}
+
+	// method-specific-id = *( *idchar ":" ) 1*idchar
+	idStart := o.methodEnd + 1
+	spec := lookupMethod(input[len(scheme):o.methodEnd])
+	o.idEnd, ok = scanComponent(input, idStart, ComponentID, ErrInvalidIDChar, idOrColonCharsOf(spec), "/?#", errs)
+	if !ok {
+		return o, false
+	}
+	if o.idEnd == idStart || input[o.idEnd-1] == ':' {
+		if errs.add(newParseError(input, o.idEnd, ComponentID, ErrEmptyID)) {
+			return o, false
+		}
+	}
+	if spec != nil && !spec.check(input, idStart, o.idEnd, errs) {
+		return o, false
+	}
+
+	// path-abempty = *( "/" segment )
+	o.pathEnd = o.idEnd
+	if o.pathEnd < len(input) && input[o.pathEnd] == '/' {
+		o.pathEnd, ok = scanComponent(input, o.pathEnd+1, ComponentPath, ErrInvalidPathChar, &pathOrSlash, "?#", errs)
+		if !ok {
+			return o, false
+		}
+	}
+
+	o.queryEnd = o.pathEnd
+	if o.queryEnd < len(input) && input[o.queryEnd] == '?' {
+		o.queryEnd, ok = scanComponent(input, o.queryEnd+1, ComponentQuery, ErrInvalidQueryChar, &queryChars, "#", errs)
+		if !ok {
+			return o, false
+		}
+	}
+
+	if o.queryEnd < len(input) {
+		// must be '#'
+		_, ok = scanComponent(input, o.queryEnd+1, ComponentFragment, ErrInvalidFragmentChar, &fragmentChars, "", errs)
+	}
+	return o, ok
+}
+
+// scanComponent verifies input from start on for the characters in valid,
+// and for pct-encoded octets. The end is at the first delimiter, or at
+// the end of input. Problems are recorded in errs, and ok is false when
+// scanning should halt.
+func scanComponent(input string, start int, c Component, invalid error, valid *charset, delims string, errs *parseErrors) (end int, ok bool) {
+	i := start
+	for i < len(input) {
+		switch b := input[i]; {
+		case valid.contains(b):
+			i++
+		case b == '%':
+			if i+2 < len(input) && hexChars.contains(input[i+1]) && hexChars.contains(input[i+2]) {
+				i += 3
+				continue
+			}
+			if errs.add(newParseError(input, i, c, ErrBadPercentEncoding)) {
+				return i, false
+			}
+			i++
+		case strings.IndexByte(delims, b) >= 0:
+			return i, true
+		default:
+			if errs.add(newParseError(input, i, c, invalid)) {
+				return i, false
+			}
+			i++
+		}
+	}
+	return i, true
+}
+
+// scan is like scanComponent, with the first problem returned, if any.
+func scan(input string, start int, c Component, invalid error, valid *charset, delims string) error {
+	errs := parseErrors{}
+	scanComponent(input, start, c, invalid, valid, delims, &errs)
+	if len(errs.list) != 0 {
+		return errs.list[0]
+	}
+	return nil
+}
+
+// newParseError returns a *ParseError for the given offset in input.
+func newParseError(input string, offset int, c Component, err error) *ParseError {
+	r := rune(-1)
+	if offset < len(input) {
+		r, _ = utf8.DecodeRuneInString(input[offset:])
+	}
+	return &ParseError{Err: err, Component: c, Offset: offset, Rune: r}
+}
+
+// SplitKeyID breaks a key identifier, such as the "kid" of a JWS, into its
+// DID and its fragment. Key identifiers with a path or a query are denied.
+func SplitKeyID(keyID string) (*DID, string, error) {
+	u, err := ParseURL(keyID)
+	if err != nil {
+		return nil, "", err
+	}
+	if u.Path != "" || len(u.PathSegments) != 0 || u.Query != "" || u.ForceQuery {
+		return nil, "", errors.New("key ID with path or query denied")
+	}
+	if u.Fragment == "" {
+		return nil, "", errors.New("key ID has no fragment")
+	}
+	return u.Base(), u.Fragment, nil
+}
+
+// JoinKeyID returns the key identifier of a fragment within the DID. It is
+// the reverse of SplitKeyID. The fragment is not escaped.
+func JoinKeyID(d *DID, fragment string) string {
+	return d.String() + "#" + fragment
+}
+
+// encodeParam returns the escaped name-value pair for a DID Query.
+func encodeParam(name, value string) string {
+	return escape(name, isParamChar) + "=" + escape(value, isParamChar)
+}
+
+// splitQuery returns the `&` separated parameters of a query, without any
+// empty ones.
+func splitQuery(query string) []string {
+	var params []string
+	for _, p := range strings.Split(query, "&") {
+		if p != "" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// paramName returns the unescaped name of a query parameter.
+func paramName(param string) string {
+	if i := strings.IndexByte(param, '='); i >= 0 {
+		param = param[:i]
+	}
+	return unescape(param)
+}
+
+const upperhex = "0123456789ABCDEF"
+
+// escape percent-encodes all bytes in s for which valid returns false.
+func escape(s string, valid func(byte) bool) string {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if !valid(s[i]) {
+			n++
+		}
 	}
-	if d.Fragment != "" {
-		d.Fragment = d.Fragment[1:]
+	if n == 0 {
+		return s
 	}
 
-	return &d, nil
+	return bytesToString(appendEscape(make([]byte, 0, len(s)+2*n), s, valid))
+}
+
+// appendEscape appends s to dst with each character which does not pass
+// valid percent-encoded.
+func appendEscape(dst []byte, s string, valid func(byte) bool) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if valid(c) {
+			dst = append(dst, c)
+		} else {
+			dst = append(dst, '%', upperhex[c>>4], upperhex[c&15])
+		}
+	}
+	return dst
+}
+
+// bytesToString converts b without a copy, like strings.Builder does. The
+// bytes must not be modified afterwards.
+func bytesToString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// escapedLen returns the length of s after escape.
+func escapedLen(s string, valid func(byte) bool) int {
+	n := len(s)
+	for i := 0; i < len(s); i++ {
+		if !valid(s[i]) {
+			n += 2
+		}
+	}
+	return n
+}
+
+// unescape decodes all pct-encoded octets in s. Malformed encodings are left
+// as is.
+func unescape(s string) string {
+	i := strings.IndexByte(s, '%')
+	if i < 0 {
+		return s
+	}
+
+	b := append(make([]byte, 0, len(s)), s[:i]...)
+	for ; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			b = append(b, unhex(s[i+1])<<4|unhex(s[i+2]))
+			i += 2
+		} else {
+			b = append(b, s[i])
+		}
+	}
+	return string(b)
+}
+
+func unhex(c byte) byte {
+	switch {
+	case 'a' <= c:
+		return c - 'a' + 10
+	case 'A' <= c:
+		return c - 'A' + 10
+	}
+	return c - '0'
 }
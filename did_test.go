@@ -1,6 +1,8 @@
 package did
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"reflect"
@@ -10,32 +12,42 @@ import (
 
 func TestIsURL(t *testing.T) {
 	t.Run("returns false if no Path or Fragment", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}}
 		assert(t, false, d.IsURL())
 	})
 
 	t.Run("returns true if Path", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Path: "a/b"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Path: "a/b"}
 		assert(t, true, d.IsURL())
 	})
 
 	t.Run("returns true if PathSegements", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", PathSegments: []string{"a", "b"}}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, PathSegments: []string{"a", "b"}}
 		assert(t, true, d.IsURL())
 	})
 
 	t.Run("returns true if Query", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Query: "abc"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Query: "abc"}
 		assert(t, true, d.IsURL())
 	})
 
 	t.Run("returns true if Fragment", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Fragment: "00000"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Fragment: "00000"}
+		assert(t, true, d.IsURL())
+	})
+
+	t.Run("returns true if ForceQuery", func(t *testing.T) {
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, ForceQuery: true}
+		assert(t, true, d.IsURL())
+	})
+
+	t.Run("returns true if ForceFragment", func(t *testing.T) {
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, ForceFragment: true}
 		assert(t, true, d.IsURL())
 	})
 
 	t.Run("returns true if Path and Fragment", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Path: "a/b", Fragment: "00000"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Path: "a/b", Fragment: "00000"}
 		assert(t, true, d.IsURL())
 	})
 }
@@ -62,46 +74,435 @@ func TestString(t *testing.T) {
 	})
 
 	t.Run("includes Path", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Path: "a/b"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Path: "a/b"}
 		assert(t, "did:example:123/a/b", d.String())
 	})
 
 	t.Run("includes Path assembled from PathSegements", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", PathSegments: []string{"a", "b"}}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, PathSegments: []string{"a", "b"}}
 		assert(t, "did:example:123/a/b", d.String())
 	})
 
 	t.Run("includes Query after IDString", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Query: "abc"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Query: "abc"}
 		assert(t, "did:example:123?abc", d.String())
 	})
 
 	t.Run("includes Query after Path", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Path: "x/y", Query: "abc"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Path: "x/y", Query: "abc"}
 		assert(t, "did:example:123/x/y?abc", d.String())
 	})
 
 	t.Run("includes Query after before Fragment", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Fragment: "zyx", Query: "abc"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Fragment: "zyx", Query: "abc"}
 		assert(t, "did:example:123?abc#zyx", d.String())
 	})
 
 	t.Run("includes Query", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Query: "abc"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Query: "abc"}
 		assert(t, "did:example:123?abc", d.String())
 	})
 
 	t.Run("includes Fragment", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Fragment: "00000"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Fragment: "00000"}
 		assert(t, "did:example:123#00000", d.String())
 	})
 
+	t.Run("includes empty Query if ForceQuery", func(t *testing.T) {
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, ForceQuery: true}
+		assert(t, "did:example:123?", d.String())
+	})
+
+	t.Run("includes empty Fragment if ForceFragment", func(t *testing.T) {
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, ForceQuery: true, ForceFragment: true}
+		assert(t, "did:example:123?#", d.String())
+	})
+
+	t.Run("returns parsed input unchanged", func(t *testing.T) {
+		d, err := ParseURL("did:example:%61bc:%7a/x%2dy?q#f")
+		assert(t, nil, err)
+		assert(t, "did:example:%61bc:%7a/x%2dy?q#f", d.String())
+	})
+
+	t.Run("encodes parsed input after modification", func(t *testing.T) {
+		d, err := ParseURL("did:example:%61bc#f")
+		assert(t, nil, err)
+		d.Fragment = "g"
+		assert(t, "did:example:abc#g", d.String())
+
+		d, err = ParseURL("did:example:%61bc/x")
+		assert(t, nil, err)
+		d.Path = ""
+		d.PathSegments[0] = "y"
+		assert(t, "did:example:abc/y", d.String())
+	})
+
+	t.Run("keeps escaped colons within idstrings", func(t *testing.T) {
+		d, err := ParseURL("did:web:localhost%3a8443:user#f")
+		assert(t, nil, err)
+		assert(t, "localhost:8443:user", d.ID)
+		assert(t, []string{"localhost:8443", "user"}, d.IDStrings)
+		d.Fragment = "g"
+		assert(t, "did:web:localhost%3A8443:user#g", d.String())
+	})
+
 	t.Run("includes Fragment after Param", func(t *testing.T) {
-		d := &DID{Method: "example", ID: "123", Fragment: "00000"}
+		d := &DIDURL{DID: DID{Method: "example", ID: "123"}, Fragment: "00000"}
 		assert(t, "did:example:123#00000", d.String())
 	})
 }
 
+func TestClone(t *testing.T) {
+	t.Run("copies all fields", func(t *testing.T) {
+		d, err := ParseURL("did:a:123:456/x/y?q#f")
+		assert(t, nil, err)
+		assert(t, d, d.Clone())
+	})
+
+	t.Run("does not share slices", func(t *testing.T) {
+		d, err := ParseURL("did:a:123:456/x/y")
+		assert(t, nil, err)
+		c := d.Clone()
+		c.IDStrings[0] = "789"
+		c.PathSegments[0] = "z"
+		assert(t, "123", d.IDStrings[0])
+		assert(t, "x", d.PathSegments[0])
+	})
+
+	t.Run("preserves nil slices", func(t *testing.T) {
+		d := &DID{Method: "example", ID: "123"}
+		assert(t, d, d.Clone())
+	})
+}
+
+func TestURL(t *testing.T) {
+	d, err := Parse("did:a:123:456")
+	assert(t, nil, err)
+	u := d.URL()
+	assert(t, false, u.IsURL())
+	assert(t, d.Key(), u.Key())
+	u.IDStrings[0] = "789"
+	assert(t, "123", d.IDStrings[0])
+}
+
+func TestBase(t *testing.T) {
+	t.Run("strips URL parts", func(t *testing.T) {
+		d, err := ParseURL("did:a:123:456/x?q#f")
+		assert(t, nil, err)
+		b := d.Base()
+		assert(t, "123:456", b.ID)
+		assert(t, []string{"123", "456"}, b.IDStrings)
+
+		d, err = ParseURL("did:a:123/x?q#f")
+		assert(t, nil, err)
+		assert(t, "did:a:123", d.Base().String())
+	})
+
+	t.Run("strips empty query and fragment", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?#")
+		assert(t, nil, err)
+		assert(t, "did:a:123", d.Base().String())
+	})
+
+	t.Run("preserves parsed input", func(t *testing.T) {
+		d, err := ParseURL("did:a:123:%34%356/x")
+		assert(t, nil, err)
+		assert(t, "did:a:123:%34%356", d.Base().String())
+	})
+
+	t.Run("does not share slices", func(t *testing.T) {
+		d, err := ParseURL("did:a:123:456")
+		assert(t, nil, err)
+		d.Base().IDStrings[0] = "789"
+		assert(t, "123", d.IDStrings[0])
+	})
+}
+
+func TestWith(t *testing.T) {
+	base, err := ParseURL("did:a:123/x?q#f")
+	assert(t, nil, err)
+
+	t.Run("replaces Path and PathSegments", func(t *testing.T) {
+		d := base.WithPath("y/%7A")
+		assert(t, "did:a:123/y/%7A?q#f", d.String())
+		assert(t, []string{"y", "z"}, d.PathSegments)
+
+		d = base.WithPath("")
+		assert(t, "did:a:123?q#f", d.String())
+		assert(t, []string(nil), d.PathSegments)
+	})
+
+	t.Run("replaces Query", func(t *testing.T) {
+		assert(t, "did:a:123/x?r#f", base.WithQuery("r").String())
+		assert(t, "did:a:123/x#f", base.WithQuery("").String())
+	})
+
+	t.Run("replaces Fragment", func(t *testing.T) {
+		assert(t, "did:a:123/x?q#keys-2", base.WithFragment("keys-2").String())
+		assert(t, "did:a:123/x?q", base.WithFragment("").String())
+	})
+
+	t.Run("removes Query", func(t *testing.T) {
+		assert(t, "did:a:123/x#f", base.WithoutQuery().String())
+
+		d, err := ParseURL("did:a:123?#f")
+		assert(t, nil, err)
+		assert(t, "did:a:123#f", d.WithoutQuery().String())
+	})
+
+	t.Run("removes Fragment", func(t *testing.T) {
+		assert(t, "did:a:123/x?q", base.WithoutFragment().String())
+
+		d, err := ParseURL("did:a:123?q#")
+		assert(t, nil, err)
+		assert(t, "did:a:123?q", d.WithoutFragment().String())
+	})
+
+	t.Run("chains", func(t *testing.T) {
+		d := base.WithPath("").WithQuery("").WithFragment("keys-1")
+		assert(t, "did:a:123#keys-1", d.String())
+	})
+
+	t.Run("leaves original unchanged", func(t *testing.T) {
+		base.WithPath("y").WithQuery("r").WithFragment("g")
+		base.WithoutQuery().WithoutFragment()
+		assert(t, "did:a:123/x?q#f", base.String())
+		assert(t, []string{"x"}, base.PathSegments)
+	})
+}
+
+func TestSegments(t *testing.T) {
+	t.Run("yields decoded segments", func(t *testing.T) {
+		d, err := ParseURL("did:a:123/x/%7A//w%20v/")
+		assert(t, nil, err)
+		var got []string
+		for s := range d.Segments() {
+			got = append(got, s)
+		}
+		assert(t, []string{"x", "z", "", "w v", ""}, got)
+		assert(t, d.PathSegments, got)
+	})
+
+	t.Run("yields PathSegments without Path", func(t *testing.T) {
+		d := &DIDURL{DID: DID{Method: "a", ID: "123"}, PathSegments: []string{"x", "y"}}
+		var got []string
+		for s := range d.Segments() {
+			got = append(got, s)
+		}
+		assert(t, []string{"x", "y"}, got)
+	})
+
+	t.Run("yields nothing without path", func(t *testing.T) {
+		d, err := ParseURL("did:a:123")
+		assert(t, nil, err)
+		for s := range d.Segments() {
+			t.Errorf("got segment %q", s)
+		}
+	})
+
+	t.Run("stops on break", func(t *testing.T) {
+		d, err := ParseURL("did:a:123/x/y/z")
+		assert(t, nil, err)
+		var got []string
+		for s := range d.Segments() {
+			got = append(got, s)
+			break
+		}
+		assert(t, []string{"x"}, got)
+	})
+}
+
+func TestAppendPathSegment(t *testing.T) {
+	t.Run("starts a path", func(t *testing.T) {
+		d, err := ParseURL("did:a:123#f")
+		assert(t, nil, err)
+		d.AppendPathSegment("x")
+		assert(t, "x", d.Path)
+		assert(t, []string{"x"}, d.PathSegments)
+		assert(t, "did:a:123/x#f", d.String())
+	})
+
+	t.Run("extends a path", func(t *testing.T) {
+		d, err := ParseURL("did:a:123/x%20y?q")
+		assert(t, nil, err)
+		d.AppendPathSegment("z")
+		assert(t, "x%20y/z", d.Path)
+		assert(t, []string{"x y", "z"}, d.PathSegments)
+		assert(t, "did:a:123/x%20y/z?q", d.String())
+	})
+
+	t.Run("escapes the segment", func(t *testing.T) {
+		d := &DIDURL{DID: DID{Method: "a", ID: "123"}}
+		d.AppendPathSegment("a/b c?")
+		d.AppendPathSegment("")
+		d.AppendPathSegment("%")
+		assert(t, "a%2Fb%20c%3F//%25", d.Path)
+		assert(t, []string{"a/b c?", "", "%"}, d.PathSegments)
+
+		p, err := ParseURL(d.String())
+		assert(t, nil, err)
+		assert(t, d.PathSegments, p.PathSegments)
+	})
+
+	t.Run("syncs from PathSegments", func(t *testing.T) {
+		d := &DIDURL{DID: DID{Method: "a", ID: "123"}, PathSegments: []string{"x y"}}
+		d.AppendPathSegment("z")
+		assert(t, "x%20y/z", d.Path)
+
+		d, err := ParseURL("did:a:123/")
+		assert(t, nil, err)
+		d.AppendPathSegment("z")
+		assert(t, "did:a:123//z", d.String())
+	})
+}
+
+func TestQueryParam(t *testing.T) {
+	t.Run("sets a new parameter", func(t *testing.T) {
+		d, err := ParseURL("did:a:123#f")
+		assert(t, nil, err)
+		d.SetQueryParam("versionId", "1")
+		assert(t, "did:a:123?versionId=1#f", d.String())
+
+		d.SetQueryParam("service", "agent")
+		assert(t, "versionId=1&service=agent", d.Query)
+	})
+
+	t.Run("replaces in place", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?a=1&b=2&a=3&c=4")
+		assert(t, nil, err)
+		d.SetQueryParam("a", "5")
+		assert(t, "a=5&b=2&c=4", d.Query)
+
+		d.SetQueryParam("b", "")
+		assert(t, "a=5&b=&c=4", d.Query)
+	})
+
+	t.Run("matches escaped names", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?x%20y=1&z")
+		assert(t, nil, err)
+		d.SetQueryParam("x y", "2")
+		d.SetQueryParam("z", "3")
+		assert(t, "x%20y=2&z=3", d.Query)
+	})
+
+	t.Run("escapes names and values", func(t *testing.T) {
+		d, err := ParseURL("did:a:123")
+		assert(t, nil, err)
+		d.SetQueryParam("relativeRef", "/a b?c=d&e#f")
+		assert(t, "relativeRef=/a%20b?c%3Dd%26e%23f", d.Query)
+
+		_, err = ParseURL(d.String())
+		assert(t, nil, err)
+	})
+
+	t.Run("deletes all occurrences", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?a=1&b=2&a=3#f")
+		assert(t, nil, err)
+		d.DelQueryParam("a")
+		assert(t, "did:a:123?b=2#f", d.String())
+
+		d.DelQueryParam("c")
+		assert(t, "b=2", d.Query)
+
+		d.DelQueryParam("b")
+		assert(t, "did:a:123#f", d.String())
+	})
+
+	t.Run("deletes empty query", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?")
+		assert(t, nil, err)
+		d.DelQueryParam("a")
+		assert(t, "did:a:123", d.String())
+	})
+}
+
+func TestParams(t *testing.T) {
+	t.Run("yields decoded pairs in order", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?service=agent&x%20y=a%3Db&&flag&service=hub&=v")
+		assert(t, nil, err)
+		var got []string
+		for name, value := range d.Params() {
+			got = append(got, name+"|"+value)
+		}
+		assert(t, []string{"service|agent", "x y|a=b", "flag|", "service|hub", "|v"}, got)
+	})
+
+	t.Run("yields nothing without query", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?")
+		assert(t, nil, err)
+		for name := range d.Params() {
+			t.Errorf("got parameter %q", name)
+		}
+	})
+
+	t.Run("stops on break", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?a=1&b=2")
+		assert(t, nil, err)
+		var got []string
+		for name := range d.Params() {
+			got = append(got, name)
+			break
+		}
+		assert(t, []string{"a"}, got)
+	})
+}
+
+func TestKey(t *testing.T) {
+	t.Run("equal for ID and IDStrings", func(t *testing.T) {
+		a := &DID{Method: "example", ID: "123:456"}
+		b := &DID{Method: "example", IDStrings: []string{"123", "456"}}
+		assert(t, a.Key(), b.Key())
+	})
+
+	t.Run("equal for Path and PathSegments", func(t *testing.T) {
+		a := &DIDURL{DID: DID{Method: "example", ID: "123"}, Path: "a/b"}
+		b := &DIDURL{DID: DID{Method: "example", ID: "123"}, PathSegments: []string{"a", "b"}}
+		assert(t, a.Key(), b.Key())
+	})
+
+	t.Run("differs per component", func(t *testing.T) {
+		keys := make(map[Key]bool)
+		for _, d := range []*DIDURL{
+			{DID: DID{Method: "example", ID: "123"}},
+			{DID: DID{Method: "example", ID: "1234"}},
+			{DID: DID{Method: "other", ID: "123"}},
+			{DID: DID{Method: "example", ID: "123"}, Path: "a"},
+			{DID: DID{Method: "example", ID: "123"}, Query: "a"},
+			{DID: DID{Method: "example", ID: "123"}, Fragment: "a"},
+		} {
+			keys[d.Key()] = true
+		}
+		assert(t, 6, len(keys))
+	})
+
+	t.Run("zero if no Method or ID", func(t *testing.T) {
+		assert(t, Key{}, (&DID{ID: "123"}).Key())
+		assert(t, Key{}, (&DID{Method: "example"}).Key())
+	})
+}
+
+func TestKeyID(t *testing.T) {
+	t.Run("splits", func(t *testing.T) {
+		d, fragment, err := SplitKeyID("did:example:123:456#keys-1")
+		assert(t, nil, err)
+		assert(t, "did:example:123:456", d.String())
+		assert(t, "keys-1", fragment)
+	})
+
+	t.Run("joins", func(t *testing.T) {
+		d, err := Parse("did:example:123:456")
+		assert(t, nil, err)
+		assert(t, "did:example:123:456#keys-1", JoinKeyID(d, "keys-1"))
+	})
+
+	t.Run("returns error on anything but DID with fragment", func(t *testing.T) {
+		for _, s := range []string{"did:example:123", "did:example:123#", "did:example:123/a#keys-1", "did:example:123?a#keys-1", "#keys-1", "keys-1"} {
+			_, _, err := SplitKeyID(s)
+			assert(t, false, err == nil, "Input: %s", s)
+		}
+	})
+}
+
 func TestParse(t *testing.T) {
 
 	t.Run("returns error if input is empty", func(t *testing.T) {
@@ -140,6 +541,22 @@ func TestParse(t *testing.T) {
 		assert(t, true, d == nil)
 	})
 
+	t.Run("returns error if input is a DID URL", func(t *testing.T) {
+		for _, s := range []string{"did:a:1/", "did:a:1/b", "did:a:1?", "did:a:1?b", "did:a:1#", "did:a:1#b"} {
+			d, err := Parse(s)
+			assert(t, false, err == nil, "Input: %s", s)
+			assert(t, true, d == nil, "Input: %s", s)
+		}
+	})
+
+	t.Run("succeeds with DID input as DID URL", func(t *testing.T) {
+		d, err := ParseURL("did:a:1")
+		assert(t, nil, err)
+		assert(t, false, d.IsURL())
+		assert(t, "did:a:1", d.String())
+		assert(t, "did:a:1", d.DID.String())
+	})
+
 	t.Run("succeeds if it has did prefix and length is greater than 7", func(t *testing.T) {
 		d, err := Parse("did:a:1")
 		assert(t, nil, err)
@@ -185,13 +602,13 @@ func TestParse(t *testing.T) {
 	})
 
 	t.Run("succeeds to extract path", func(t *testing.T) {
-		d, err := Parse("did:a:123:456/someService")
+		d, err := ParseURL("did:a:123:456/someService")
 		assert(t, nil, err)
 		assert(t, "someService", d.Path)
 	})
 
 	t.Run("succeeds to extract path segements", func(t *testing.T) {
-		d, err := Parse("did:a:123:456/a/b")
+		d, err := ParseURL("did:a:123:456/a/b")
 		assert(t, nil, err)
 
 		segments := d.PathSegments
@@ -200,7 +617,7 @@ func TestParse(t *testing.T) {
 	})
 
 	t.Run("succeeds with percent encoded chars in path", func(t *testing.T) {
-		d, err := Parse("did:a:123:456/a/%20a")
+		d, err := ParseURL("did:a:123:456/a/%20a")
 		assert(t, nil, err)
 		assert(t, "a/%20a", d.Path)
 	})
@@ -215,28 +632,28 @@ func TestParse(t *testing.T) {
 			"did:a:123:456/%A%",
 		}
 		for _, did := range dids {
-			_, err := Parse(did)
+			_, err := ParseURL(did)
 			assert(t, false, err == nil, "Input: %s", did)
 		}
 	})
 
 	t.Run("does not fail if second path segment is empty", func(t *testing.T) {
-		_, err := Parse("did:a:123:456/abc//pqr")
+		_, err := ParseURL("did:a:123:456/abc//pqr")
 		assert(t, nil, err)
 	})
 
 	t.Run("returns error  if path has invalid char", func(t *testing.T) {
-		_, err := Parse("did:a:123:456/ssss^sss")
+		_, err := ParseURL("did:a:123:456/ssss^sss")
 		assert(t, false, err == nil)
 	})
 
 	t.Run("does not fail if path has atleast one segment and a trailing slash", func(t *testing.T) {
-		_, err := Parse("did:a:123:456/a/b/")
+		_, err := ParseURL("did:a:123:456/a/b/")
 		assert(t, nil, err)
 	})
 
 	t.Run("succeeds to extract query after idstring", func(t *testing.T) {
-		d, err := Parse("did:a:123?abc")
+		d, err := ParseURL("did:a:123?abc")
 		assert(t, nil, err)
 		assert(t, "a", d.Method)
 		assert(t, "123", d.ID)
@@ -244,7 +661,7 @@ func TestParse(t *testing.T) {
 	})
 
 	t.Run("succeeds to extract query after path", func(t *testing.T) {
-		d, err := Parse("did:a:123/a/b/c?abc")
+		d, err := ParseURL("did:a:123/a/b/c?abc")
 		assert(t, nil, err)
 		assert(t, "a", d.Method)
 		assert(t, "123", d.ID)
@@ -253,14 +670,26 @@ func TestParse(t *testing.T) {
 	})
 
 	t.Run("succeeds to extract fragment after query", func(t *testing.T) {
-		d, err := Parse("did:a:123?abc#xyz")
+		d, err := ParseURL("did:a:123?abc#xyz")
 		assert(t, nil, err)
 		assert(t, "abc", d.Query)
 		assert(t, "xyz", d.Fragment)
 	})
 
+	t.Run("distinguishes empty from absent query", func(t *testing.T) {
+		d, err := ParseURL("did:a:123?")
+		assert(t, nil, err)
+		assert(t, "", d.Query)
+		assert(t, true, d.ForceQuery)
+		assert(t, "did:a:123?", d.String())
+
+		d, err = ParseURL("did:a:123")
+		assert(t, nil, err)
+		assert(t, false, d.ForceQuery)
+	})
+
 	t.Run("succeeds with percent encoded chars in query", func(t *testing.T) {
-		d, err := Parse("did:a:123?ab%20c")
+		d, err := ParseURL("did:a:123?ab%20c")
 		assert(t, nil, err)
 		assert(t, "ab%20c", d.Query)
 	})
@@ -275,24 +704,36 @@ func TestParse(t *testing.T) {
 			"did:a:123:456?%A%",
 		}
 		for _, did := range dids {
-			_, err := Parse(did)
+			_, err := ParseURL(did)
 			assert(t, false, err == nil, "Input: %s", did)
 		}
 	})
 
 	t.Run("returns error if query has invalid char", func(t *testing.T) {
-		_, err := Parse("did:a:123:456?ssss^sss")
+		_, err := ParseURL("did:a:123:456?ssss^sss")
 		assert(t, false, err == nil)
 	})
 
 	t.Run("succeeds to extract fragment", func(t *testing.T) {
-		d, err := Parse("did:a:123:456#keys-1")
+		d, err := ParseURL("did:a:123:456#keys-1")
 		assert(t, nil, err)
 		assert(t, "keys-1", d.Fragment)
 	})
 
+	t.Run("distinguishes empty from absent fragment", func(t *testing.T) {
+		d, err := ParseURL("did:a:123#")
+		assert(t, nil, err)
+		assert(t, "", d.Fragment)
+		assert(t, true, d.ForceFragment)
+		assert(t, "did:a:123#", d.String())
+
+		d, err = ParseURL("did:a:123")
+		assert(t, nil, err)
+		assert(t, false, d.ForceFragment)
+	})
+
 	t.Run("succeeds with percent encoded chars in fragment", func(t *testing.T) {
-		d, err := Parse("did:a:123:456#aaaaaa%20a")
+		d, err := ParseURL("did:a:123:456#aaaaaa%20a")
 		assert(t, nil, err)
 		assert(t, "aaaaaa%20a", d.Fragment)
 	})
@@ -307,13 +748,13 @@ func TestParse(t *testing.T) {
 			"did:xyz:pqr#%A%",
 		}
 		for _, did := range dids {
-			_, err := Parse(did)
+			_, err := ParseURL(did)
 			assert(t, false, err == nil, "Input: %s", did)
 		}
 	})
 
 	t.Run("fails if fragment has invalid char", func(t *testing.T) {
-		_, err := Parse("did:a:123:456#ssss^sss")
+		_, err := ParseURL("did:a:123:456#ssss^sss")
 		assert(t, false, err == nil)
 	})
 }
@@ -343,3 +784,225 @@ func assert(t *testing.T, expected interface{}, actual interface{}, args ...inte
 		t.FailNow()
 	}
 }
+
+func TestParseAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := Parse("did:example:q7ckgxeq1lxmra0r"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert(t, 1.0, allocs, "Parse allocations")
+
+	allocs = testing.AllocsPerRun(100, func() {
+		if _, err := ParseURL("did:example:q7ckgxeq1lxmra0r?service=agent#keys-1"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert(t, 1.0, allocs, "ParseURL allocations")
+
+	t.Run("multiple idstrings", func(t *testing.T) {
+		d, err := Parse("did:example:a:b::c")
+		assert(t, nil, err)
+		assert(t, []string{"a", "b", "", "c"}, d.IDStrings)
+		assert(t, 4, cap(d.IDStrings))
+	})
+}
+
+func TestAppendString(t *testing.T) {
+	d := &DIDURL{
+		DID:          DID{Method: "example", IDStrings: []string{"123", "456"}},
+		PathSegments: []string{"a b", "c"},
+		ForceQuery:   true,
+		Fragment:     "f",
+	}
+	const want = "did:example:123%3A456/a%20b/c?#f"
+	assert(t, want, string(d.AppendString(nil)))
+	assert(t, "x "+want, string(d.AppendString([]byte("x "))))
+	assert(t, want, d.String())
+	assert(t, len(want), d.stringLen())
+
+	assert(t, "", string((&DIDURL{Fragment: "f"}).AppendString(nil)))
+	assert(t, "", (&DID{Method: "example"}).String())
+
+	t.Run("allocations", func(t *testing.T) {
+		u, err := ParseURL("did:example:123/a?q#f")
+		assert(t, nil, err)
+		var s string
+		allocs := testing.AllocsPerRun(100, func() { s = u.String() })
+		assert(t, 0.0, allocs, "parsed")
+
+		u.Fragment = "g"
+		allocs = testing.AllocsPerRun(100, func() { s = u.String() })
+		assert(t, 1.0, allocs, "modified")
+		assert(t, "did:example:123/a?q#g", s)
+
+		buf := make([]byte, 0, 64)
+		allocs = testing.AllocsPerRun(100, func() { buf = u.AppendString(buf[:0]) })
+		assert(t, 0.0, allocs, "append")
+	})
+}
+
+func TestText(t *testing.T) {
+	t.Run("JSON round trip", func(t *testing.T) {
+		type doc struct {
+			ID         DID    `json:"id"`
+			Controller DIDURL `json:"controller"`
+		}
+		const sample = `{"id":"did:example:123:456","controller":"did:example:123/a%20b?q#f"}`
+		var v doc
+		assert(t, nil, json.Unmarshal([]byte(sample), &v))
+		assert(t, []string{"123", "456"}, v.ID.IDStrings)
+		assert(t, []string{"a b"}, v.Controller.PathSegments)
+		assert(t, "f", v.Controller.Fragment)
+
+		bytes, err := json.Marshal(v)
+		assert(t, nil, err)
+		assert(t, sample, string(bytes))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		d := &DID{Method: "example", ID: "123"}
+		err := d.UnmarshalText([]byte("did:example:123#f"))
+		assert(t, true, errors.Is(err, ErrURLDenied))
+		assert(t, "did:example:123", d.String())
+
+		u := &DIDURL{}
+		err = u.UnmarshalText([]byte("did:example:1 2"))
+		assert(t, true, errors.Is(err, ErrInvalidIDChar))
+	})
+
+	t.Run("reuse", func(t *testing.T) {
+		ids := make([]string, 0, 4)
+		d := &DID{IDStrings: ids}
+		assert(t, nil, d.UnmarshalText([]byte("did:example:a:b:c")))
+		assert(t, []string{"a", "b", "c"}, d.IDStrings)
+		assert(t, &ids[:1][0], &d.IDStrings[0])
+
+		// copies share the backing array, unlike clones
+		alias, clone := *d, d.Clone()
+		assert(t, nil, d.UnmarshalText([]byte("did:example:x:y:z")))
+		assert(t, []string{"x", "y", "z"}, alias.IDStrings)
+		assert(t, []string{"a", "b", "c"}, clone.IDStrings)
+
+		u := &DIDURL{}
+		assert(t, nil, u.UnmarshalText([]byte("did:example:a/b/c?q")))
+		assert(t, nil, u.UnmarshalText([]byte("did:example:x#f")))
+		assert(t, "did:example:x#f", u.String())
+		assert(t, 0, len(u.PathSegments))
+		assert(t, "", u.Query)
+		assert(t, false, u.ForceQuery)
+
+		text := []byte("did:example:123:456/a/b")
+		allocs := testing.AllocsPerRun(100, func() {
+			if err := u.UnmarshalText(text); err != nil {
+				t.Fatal(err)
+			}
+		})
+		assert(t, 1.0, allocs)
+	})
+}
+
+func TestMemoizedString(t *testing.T) {
+	u, err := ParseURL("did:example:123/a?q=1#f")
+	assert(t, nil, err)
+
+	golden := []struct {
+		name string
+		u    *DIDURL
+		want string
+	}{
+		{"WithPath", u.WithPath("b%20c/d"), "did:example:123/b%20c/d?q=1#f"},
+		{"WithQuery", u.WithQuery("r"), "did:example:123/a?r#f"},
+		{"WithoutQuery", u.WithoutQuery(), "did:example:123/a#f"},
+		{"WithFragment", u.WithFragment("g"), "did:example:123/a?q=1#g"},
+		{"WithoutFragment", u.WithoutFragment(), "did:example:123/a?q=1"},
+	}
+	for _, g := range golden {
+		var s string
+		allocs := testing.AllocsPerRun(10, func() { s = g.u.String() })
+		assert(t, g.want, s, g.name)
+		assert(t, 0.0, allocs, g.name)
+		assert(t, g.want, g.u.DID.String()+g.want[len("did:example:123"):], g.name)
+	}
+
+	t.Run("mutators", func(t *testing.T) {
+		c := u.Clone()
+		c.AppendPathSegment("x y")
+		c.SetQueryParam("q", "2")
+		c.DelQueryParam("r")
+		var s string
+		allocs := testing.AllocsPerRun(10, func() { s = c.String() })
+		assert(t, "did:example:123/a/x%20y?q=2#f", s)
+		assert(t, 0.0, allocs)
+	})
+
+	t.Run("invalidated on assignment", func(t *testing.T) {
+		c := u.WithFragment("g")
+		c.Fragment = "h"
+		assert(t, "did:example:123/a?q=1#h", c.String())
+		c.ForceQuery, c.Query = false, ""
+		assert(t, "did:example:123/a#h", c.String())
+		c.Path, c.PathSegments = "", nil
+		assert(t, "did:example:123#h", c.String())
+	})
+}
+
+func TestEqualConstantTime(t *testing.T) {
+	a, err := Parse("did:example:123:456")
+	assert(t, nil, err)
+
+	golden := []struct {
+		b    *DID
+		want bool
+	}{
+		{&DID{Method: "example", ID: "123:456"}, true},
+		{&DID{Method: "example", IDStrings: []string{"123", "456"}}, true},
+		{&DID{Method: "example", ID: "123:457"}, false},
+		{&DID{Method: "example", ID: "123:4567"}, false},
+		{&DID{Method: "other", ID: "123:456"}, false},
+		{&DID{ID: "123:456"}, false},
+		{nil, false},
+	}
+	for _, g := range golden {
+		assert(t, g.want, EqualConstantTime(a, g.b), "DID: %+v", g.b)
+		assert(t, g.want, EqualConstantTime(g.b, a), "DID: %+v", g.b)
+	}
+
+	assert(t, false, EqualConstantTime(nil, nil))
+	assert(t, false, EqualConstantTime(&DID{}, &DID{}))
+}
+
+func TestIsZero(t *testing.T) {
+	assert(t, true, DID{}.IsZero())
+	assert(t, true, DID{IDStrings: []string{}}.IsZero())
+	assert(t, false, DID{Method: "example"}.IsZero())
+	assert(t, false, DID{IDStrings: []string{"123"}}.IsZero())
+
+	assert(t, true, DIDURL{}.IsZero())
+	assert(t, false, DIDURL{ForceFragment: true}.IsZero())
+	assert(t, false, DIDURL{Path: "a"}.IsZero())
+
+	u, err := ParseURL("did:example:123#k")
+	assert(t, nil, err)
+	assert(t, false, u.IsZero())
+	assert(t, false, u.Base().IsZero())
+}
+
+func TestScan(t *testing.T) {
+	var d DID
+	var u DIDURL
+	var n int
+	count, err := fmt.Sscan("  did:example:123\n did:example:456#keys-1 42", &d, &u, &n)
+	assert(t, nil, err)
+	assert(t, 3, count)
+	assert(t, "did:example:123", d.String())
+	assert(t, "did:example:456#keys-1", u.String())
+	assert(t, 42, n)
+
+	_, err = fmt.Sscan("did:example:123#keys-1", &d)
+	assert(t, true, errors.Is(err, ErrURLDenied))
+	_, err = fmt.Sscan("did:Example:123", &u)
+	assert(t, true, errors.Is(err, ErrInvalidMethodChar))
+	_, err = fmt.Sscanf("did:example:123", "%d", &d)
+	assert(t, "did: unsupported scan verb %d", err.Error())
+}
@@ -0,0 +1,111 @@
+// Package didtest provides fixtures and helpers for testing with DIDs.
+package didtest
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+// Fixture DIDs for common methods and shapes.
+const (
+	Example      = "did:example:123456789abcdefghi"
+	Key          = "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+	Web          = "did:web:example.com"
+	WebPort      = "did:web:example.com%3A3000"
+	WebPath      = "did:web:example.com:user:alice"
+	Ethr         = "did:ethr:0xb9c5714089478a327f09197987f16f9e5d936e8a"
+	PKH          = "did:pkh:eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a"
+	ION          = "did:ion:EiClkZMDxPKqC9c-umQfTkR8vvZ9JPhl_xLDI9Nfk38w5w"
+	PercentHeavy = "did:example:%E2%82%AC%20%3A%2F%25"
+	EmptyIDStr   = "did:example:a::b"
+)
+
+// Fixture DID URLs for common shapes.
+const (
+	KeyRef     = Example + "#keys-1"
+	Service    = Example + "?service=agent&relativeRef=/credentials#degree"
+	VersionID  = Example + "?versionId=1"
+	Path       = Example + "/path/to/resource"
+	EmptyParts = Example + "/?#"
+	WebKeyRef  = WebPath + "#owner"
+)
+
+// IONLongForm is a long-form did:ion with a synthetic initial state, which
+// exceeds a kilobyte in length.
+var IONLongForm = ION + ":" + base64.RawURLEncoding.EncodeToString([]byte(`{"delta":{"patches":[{"action":"replace","document":{"publicKeys":[{"id":"key-1","purposes":["authentication","assertionMethod"],"publicKeyJwk":{"crv":"secp256k1","kty":"EC","x":"WfY7Px6AgH6x-_dgAoRbg8weYRJA36ON-gQQQ7TkjvQ","y":"ueYGrFZ5eSbHcTl7RZsijDVI5LzwIn4ZaeZ5jOcPMHs"},"type":"EcdsaSecp256k1VerificationKey2019"}],"services":[{"id":"linkedDomains","serviceEndpoint":"https://example.com/","type":"LinkedDomains"},{"id":"hub","serviceEndpoint":{"instances":["https://hub.example.com/.identity/did:ion:EiClkZMDxPKqC9c-umQfTkR8vvZ9JPhl_xLDI9Nfk38w5w/"]},"type":"IdentityHub"}]}}],"updateCommitment":"EiDKIkwqO69IPG3pOlHkdb86nYt0aNxSHZu2r-bhEznjdA"},"suffixData":{"deltaHash":"EiBfOZdMtU6OBw8Pk879QtZ-2J-9FbbjSZyoaA_bqD4zhA","recoveryCommitment":"EiDKIkwqO69IPG3pOlHkdb86nYt0aNxSHZu2r-bhEznjdA"}}`))
+
+// DIDs has each of the fixture DIDs.
+var DIDs = []string{Example, Key, Web, WebPort, WebPath, Ethr, PKH, ION, IONLongForm, PercentHeavy, EmptyIDStr}
+
+// URLs has each of the fixture DID URLs.
+var URLs = []string{KeyRef, Service, VersionID, Path, EmptyParts, WebKeyRef}
+
+// InvalidCase is input which violates the DID syntax.
+type InvalidCase struct {
+	Input string
+	// Err is the reason, for use with errors.Is.
+	Err error
+}
+
+// Invalid has edge cases which Parse and ParseURL deny.
+var Invalid = []InvalidCase{
+	{"", did.ErrMissingScheme},
+	{"DID:example:123", did.ErrMissingScheme},
+	{"did::123", did.ErrEmptyMethod},
+	{"did:Example:123", did.ErrInvalidMethodChar},
+	{"did:ex-ample:123", did.ErrInvalidMethodChar},
+	{"did:example", did.ErrEmptyID},
+	{"did:example:", did.ErrEmptyID},
+	{"did:example:123:", did.ErrEmptyID},
+	{"did:example:123 456", did.ErrInvalidIDChar},
+	{"did:example:%zz", did.ErrBadPercentEncoding},
+	{"did:example:123/a b", did.ErrInvalidPathChar},
+	{"did:example:123?<q>", did.ErrInvalidQueryChar},
+	{"did:example:123#a#b", did.ErrInvalidFragmentChar},
+}
+
+// Must returns d, or it panics when err is not nil. It is intended for use
+// with Parse, as in didtest.Must(did.Parse(didtest.Example)).
+func Must(d *did.DID, err error) *did.DID {
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// MustURL returns u, or it panics when err is not nil. It is intended for
+// use with ParseURL.
+func MustURL(u *did.DIDURL, err error) *did.DIDURL {
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// AssertEqual fails the test when got does not encode to the same DID as
+// want.
+func AssertEqual(t testing.TB, want string, got *did.DID) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("got nil DID, want %s", want)
+		return
+	}
+	if s := got.String(); s != want {
+		t.Errorf("got DID %s, want %s", s, want)
+	}
+}
+
+// AssertEqualURL fails the test when got does not encode to the same DID URL
+// as want.
+func AssertEqualURL(t testing.TB, want string, got *did.DIDURL) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("got nil DID URL, want %s", want)
+		return
+	}
+	if s := got.String(); s != want {
+		t.Errorf("got DID URL %s, want %s", s, want)
+	}
+}
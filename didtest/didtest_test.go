@@ -0,0 +1,36 @@
+package didtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+func TestFixtures(t *testing.T) {
+	for _, s := range DIDs {
+		AssertEqual(t, s, Must(did.Parse(s)))
+	}
+	for _, s := range URLs {
+		AssertEqualURL(t, s, MustURL(did.ParseURL(s)))
+	}
+	if len(IONLongForm) < 1024 {
+		t.Errorf("long-form did:ion has %d bytes, want more than a kilobyte", len(IONLongForm))
+	}
+
+	for _, c := range Invalid {
+		_, err := did.ParseURL(c.Input)
+		if !errors.Is(err, c.Err) {
+			t.Errorf("%q got error %v, want %v", c.Input, err, c.Err)
+		}
+	}
+}
+
+func TestMust(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+	Must(did.Parse("did:example"))
+}
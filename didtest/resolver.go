@@ -0,0 +1,145 @@
+package didtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+// MockResolver is a did.Resolver with expectations. Resolutions of DIDs
+// without expectation fail the test. The expectations are verified when the
+// test completes.
+//
+//	r := didtest.NewMockResolver(t)
+//	r.Expect(didtest.Web).Return(&did.Document{ID: didtest.Web}, nil).Times(1)
+//	r.Expect(didtest.Key).ReturnError(did.ErrNotFound)
+type MockResolver struct {
+	t testing.TB
+
+	mu           sync.Mutex
+	expectations map[string]*Expectation
+	order        []*Expectation // in order of registration
+}
+
+// NewMockResolver returns a resolver without expectations, which verifies
+// its expectations on cleanup of t.
+func NewMockResolver(t testing.TB) *MockResolver {
+	m := &MockResolver{t: t, expectations: make(map[string]*Expectation)}
+	t.Cleanup(func() { m.AssertExpectations(t) })
+	return m
+}
+
+// Expectation is the behaviour of a MockResolver for a DID. Without Return or
+// ReturnError, resolution produces a document with just the DID as its id.
+type Expectation struct {
+	did string
+
+	// configured before resolution
+	times int // exact number of calls, or -1 for at least once
+	doc   *did.Document
+	meta  *did.Metadata
+	err   error
+
+	calls []did.ResolutionOptions // guarded by the MockResolver mutex
+}
+
+// Expect registers the expectation that s gets resolved at least once. An
+// expectation of s which is already present is returned as is.
+func (m *MockResolver) Expect(s string) *Expectation {
+	d := Must(did.Parse(s))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := d.String()
+	if e, ok := m.expectations[key]; ok {
+		return e
+	}
+	e := &Expectation{did: key, times: -1}
+	m.expectations[key] = e
+	m.order = append(m.order, e)
+	return e
+}
+
+// Return sets the outcome of resolution. A nil meta gets the media type of
+// JSON in its resolution metadata.
+func (e *Expectation) Return(doc *did.Document, meta *did.Metadata) *Expectation {
+	e.doc, e.meta, e.err = doc, meta, nil
+	return e
+}
+
+// ReturnError sets the failure of resolution. Errors which don't mention the
+// DID get wrapped in one that does, such that errors.Is still applies.
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.doc, e.meta, e.err = nil, nil, err
+	return e
+}
+
+// Times sets the exact number of resolutions expected.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Resolve implements the did.Resolver interface.
+func (m *MockResolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	key := d.String()
+	m.mu.Lock()
+	e, ok := m.expectations[key]
+	if !ok {
+		m.mu.Unlock()
+		m.t.Errorf("didtest: unexpected resolution of %s", key)
+		return nil, nil, fmt.Errorf("did: resolve %s: no expectation: %w", key, did.ErrNotFound)
+	}
+	e.calls = append(e.calls, opts)
+	if len(e.calls) == e.times+1 && e.times >= 0 {
+		m.t.Errorf("didtest: %s resolved %d times, want %d", key, len(e.calls), e.times)
+	}
+	doc, meta, err := e.doc, e.meta, e.err
+	m.mu.Unlock()
+
+	if err != nil {
+		if !strings.Contains(err.Error(), key) {
+			err = fmt.Errorf("did: resolve %s: %w", key, err)
+		}
+		return nil, nil, err
+	}
+	if doc == nil {
+		doc = &did.Document{ID: key}
+	}
+	if meta == nil {
+		meta = new(did.Metadata)
+		meta.Resolution.ContentType = did.MediaTypeJSON
+	}
+	return doc, meta, nil
+}
+
+// Calls returns the options of each resolution of s, in order of arrival.
+func (m *MockResolver) Calls(s string) []did.ResolutionOptions {
+	key := Must(did.Parse(s)).String()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.expectations[key]
+	if !ok {
+		return nil
+	}
+	return append([]did.ResolutionOptions(nil), e.calls...)
+}
+
+// AssertExpectations fails the test for each expectation with too few
+// resolutions. Resolve reports any excess immediately.
+func (m *MockResolver) AssertExpectations(t testing.TB) {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.order {
+		switch {
+		case e.times < 0 && len(e.calls) == 0:
+			t.Errorf("didtest: %s not resolved, want at least once", e.did)
+		case len(e.calls) < e.times:
+			t.Errorf("didtest: %s resolved %d times, want %d", e.did, len(e.calls), e.times)
+		}
+	}
+}
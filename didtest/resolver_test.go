@@ -0,0 +1,100 @@
+package didtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+// recorder is a testing.TB which records failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Cleanup(func()) {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestMockResolver(t *testing.T) {
+	ctx := context.Background()
+	m := NewMockResolver(t)
+	m.Expect(Web).Return(&did.Document{ID: Web, AlsoKnownAs: []string{"https://example.com/"}}, nil).Times(2)
+	m.Expect(Key).ReturnError(did.ErrNotFound)
+	m.Expect(Example)
+
+	for range 2 {
+		doc, meta, err := m.Resolve(ctx, Must(did.Parse(Web)), did.ResolutionOptions{Accept: did.MediaTypeJSON})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.AlsoKnownAs) != 1 {
+			t.Errorf("got document %+v, want the canned one", doc)
+		}
+		if meta.Resolution.ContentType != did.MediaTypeJSON {
+			t.Errorf("got content type %q, want %q", meta.Resolution.ContentType, did.MediaTypeJSON)
+		}
+	}
+	if calls := m.Calls(Web); len(calls) != 2 || calls[0].Accept != did.MediaTypeJSON {
+		t.Errorf("got calls %+v, want 2 with Accept", calls)
+	}
+
+	_, _, err := m.Resolve(ctx, Must(did.Parse(Key)), did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, did.ErrNotFound)
+	}
+	if want := "did: resolve " + Key + ": " + did.ErrNotFound.Error(); err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+	mentioned := fmt.Errorf("did: resolve %s: gone: %w", Key, did.ErrNotFound)
+	m.Expect(Key).ReturnError(mentioned)
+	if _, _, err := m.Resolve(ctx, Must(did.Parse(Key)), did.ResolutionOptions{}); err != mentioned {
+		t.Errorf("got error %q, want %q unwrapped", err, mentioned)
+	}
+	doc, _, err := m.Resolve(ctx, Must(did.Parse(Example)), did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertEqual(t, Example, Must(did.Parse(doc.ID)))
+}
+
+func TestMockResolverFailures(t *testing.T) {
+	ctx := context.Background()
+	rec := new(recorder)
+	m := NewMockResolver(rec)
+	m.Expect(Web).Times(1)
+	m.Expect(Key).Times(2)
+	m.Expect(Example)
+
+	for range 3 {
+		m.Resolve(ctx, Must(did.Parse(Web)), did.ResolutionOptions{})
+	}
+	m.Resolve(ctx, Must(did.Parse(Key)), did.ResolutionOptions{})
+	_, _, err := m.Resolve(ctx, Must(did.Parse(Ethr)), did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("unexpected DID got error %v, want %v", err, did.ErrNotFound)
+	}
+	m.AssertExpectations(rec)
+
+	want := []string{
+		"didtest: " + Web + " resolved 2 times, want 1",
+		"didtest: unexpected resolution of " + Ethr,
+		"didtest: " + Key + " resolved 1 times, want 2",
+		"didtest: " + Example + " not resolved, want at least once",
+	}
+	if len(rec.errs) != len(want) {
+		t.Fatalf("got failures %q, want %q", rec.errs, want)
+	}
+	for i := range want {
+		if rec.errs[i] != want[i] {
+			t.Errorf("got failure %q, want %q", rec.errs[i], want[i])
+		}
+	}
+}
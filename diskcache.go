@@ -0,0 +1,164 @@
+package did
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DiskCache is a Resolver which caches the results of another Resolver in a
+// directory, such that they survive the process, e.g., for command-line tools
+// and serverless functions with frequent cold starts. Combine with a
+// CachingResolver in front to keep hot entries in memory.
+//
+// Each DID has its own subdirectory, named by the SHA-256 of the DID, with a
+// JSON file per combination of resolution options. Files are replaced
+// atomically, so processes may share the directory. Failures to read or write
+// the cache fall back to the upstream silently.
+type DiskCache struct {
+	// Resolver is the upstream.
+	Resolver Resolver
+
+	// Dir is the location of the cache, which is created when absent.
+	Dir string
+
+	// TTL is the expiry of cached resolutions. Zero defaults to
+	// DefaultCacheTTL. Negative values disable caching.
+	TTL time.Duration
+
+	// MethodTTL overrides TTL per DID method, if set.
+	MethodTTL map[string]time.Duration
+
+	// NegativeTTL is the expiry of failed resolutions with ErrNotFound or
+	// with ErrInvalidDID, if positive. Other failures are never cached.
+	NegativeTTL time.Duration
+
+	// Now is the clock. The nil value defaults to time.Now.
+	Now func() time.Time
+}
+
+// diskEntry is the file content of a cached resolution.
+type diskEntry struct {
+	Expires  time.Time `json:"expires"`
+	Document *Document `json:"didDocument,omitempty"`
+	Metadata *Metadata `json:"metadata,omitempty"`
+	Error    string    `json:"error,omitempty"` // message of a failure
+}
+
+// Resolve implements the Resolver interface. Resolutions with NoCache always
+// call the upstream, and they replace any cached result.
+func (c *DiskCache) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	path := c.path(d, opts)
+	if !opts.NoCache {
+		if e, ok := c.load(path); ok {
+			markCacheHit(ctx)
+			if e.Error != "" {
+				return nil, e.Metadata, &cachedError{msg: e.Error, reason: e.Metadata.Resolution.Err()}
+			}
+			return e.Document, e.Metadata, nil
+		}
+	}
+
+	doc, meta, err := c.Resolver.Resolve(ctx, d, opts)
+	if ttl := cacheTTL(c.TTL, c.MethodTTL, c.NegativeTTL, d.Method, err); ttl > 0 {
+		e := diskEntry{Expires: c.now().Add(ttl), Document: doc, Metadata: meta}
+		if err != nil {
+			m := new(Metadata)
+			if meta != nil {
+				*m = *meta
+			}
+			m.Resolution.Error = ErrorCode(err)
+			e.Document, e.Metadata, e.Error = nil, m, err.Error()
+		}
+		c.store(path, &e)
+	}
+	return doc, meta, err
+}
+
+// Forget removes any cached resolutions of d, regardless of the options.
+func (c *DiskCache) Forget(d *DID) error {
+	return os.RemoveAll(filepath.Join(c.Dir, hashHex(d.String())))
+}
+
+// Purge removes all cached resolutions, including the directory.
+func (c *DiskCache) Purge() error {
+	return os.RemoveAll(c.Dir)
+}
+
+func (c *DiskCache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// path returns the file location for a resolution.
+func (c *DiskCache) path(d *DID, opts ResolutionOptions) string {
+	var versionTime string
+	if !opts.VersionTime.IsZero() {
+		versionTime = strconv.FormatInt(opts.VersionTime.UnixNano(), 10)
+	}
+	options := opts.Accept + "\x00" + opts.VersionID + "\x00" + versionTime
+	return filepath.Join(c.Dir, hashHex(d.String()), hashHex(options)+".json")
+}
+
+// load returns the cached resolution at path, if any, with ok false for
+// absent, expired and unreadable entries.
+func (c *DiskCache) load(path string) (e *diskEntry, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	e = new(diskEntry)
+	if json.Unmarshal(data, e) != nil || !c.now().Before(e.Expires) || (e.Error != "" && e.Metadata == nil) {
+		os.Remove(path)
+		return nil, false
+	}
+	return e, true
+}
+
+// store writes e to path atomically, with any failure ignored.
+func (c *DiskCache) store(path string, e *diskEntry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// cachedError is a failure from the cache, with its original message.
+type cachedError struct {
+	msg    string
+	reason error
+}
+
+func (e *cachedError) Error() string { return e.msg + " (cached)" }
+func (e *cachedError) Unwrap() error { return e.reason }
+
+// hashHex returns the SHA-256 of s in hexadecimal.
+func hashHex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDiskCache(t *testing.T) {
+	upstream := new(countingResolver)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newCache := func() *DiskCache {
+		return &DiskCache{
+			Resolver:    upstream,
+			Dir:         t.TempDir() + "/cache",
+			TTL:         time.Minute,
+			NegativeTTL: time.Second,
+			Now:         func() time.Time { return now },
+		}
+	}
+	c := newCache()
+	ctx := context.Background()
+	d := &DID{Method: "example", ID: "123"}
+
+	doc, _, err := c.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "did:example:123", doc.ID)
+
+	// new instance, as with a cold start
+	c2 := *c
+	doc, _, err = c2.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "did:example:123", doc.ID)
+	assert(t, int64(1), upstream.calls.Load(), "upstream calls after restart")
+
+	_, meta, err := c.Resolve(ctx, d, ResolutionOptions{VersionID: "7"})
+	assert(t, nil, err)
+	assert(t, "7", meta.Document.VersionID)
+	assert(t, int64(2), upstream.calls.Load(), "upstream calls with other options")
+
+	c.Resolve(ctx, d, ResolutionOptions{NoCache: true})
+	assert(t, int64(3), upstream.calls.Load(), "upstream calls with NoCache")
+
+	now = now.Add(time.Minute)
+	c.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, int64(4), upstream.calls.Load(), "upstream calls after expiry")
+
+	missing := &DID{Method: "example", ID: "missing"}
+	for range 2 {
+		_, _, err = c.Resolve(ctx, missing, ResolutionOptions{})
+		assert(t, true, errors.Is(err, ErrNotFound), "error %v", err)
+	}
+	assert(t, int64(5), upstream.calls.Load(), "upstream calls with negative caching")
+
+	assert(t, nil, c.Forget(d))
+	c.Resolve(ctx, d, ResolutionOptions{})
+	assert(t, int64(6), upstream.calls.Load(), "upstream calls after Forget")
+
+	assert(t, nil, c.Purge())
+	_, err = os.Stat(c.Dir)
+	assert(t, true, os.IsNotExist(err), "directory after Purge")
+
+	t.Run("unwritable", func(t *testing.T) {
+		c := newCache()
+		os.WriteFile(c.Dir, nil, 0o644) // file in place of the directory
+		_, _, err := c.Resolve(ctx, d, ResolutionOptions{})
+		assert(t, nil, err)
+	})
+}
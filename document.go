@@ -0,0 +1,290 @@
+package did
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ContextV1 is the JSON-LD context of DID Core documents.
+const ContextV1 = "https://www.w3.org/ns/did/v1"
+
+// ContextMultikey is the JSON-LD context of the Multikey type.
+const ContextMultikey = "https://w3id.org/security/multikey/v1"
+
+// A Document is a DID Document conform the DID Core data model.
+type Document struct {
+	// Context has the JSON-LD context, as URL strings or as embedded
+	// objects.
+	Context []any `json:"@context,omitempty"`
+
+	ID                 string               `json:"id"`
+	AlsoKnownAs        []string             `json:"alsoKnownAs,omitempty"`
+	Controller         []string             `json:"controller,omitempty"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod,omitempty"`
+
+	// Verification relationships.
+	Authentication       []Relationship `json:"authentication,omitempty"`
+	AssertionMethod      []Relationship `json:"assertionMethod,omitempty"`
+	KeyAgreement         []Relationship `json:"keyAgreement,omitempty"`
+	CapabilityInvocation []Relationship `json:"capabilityInvocation,omitempty"`
+	CapabilityDelegation []Relationship `json:"capabilityDelegation,omitempty"`
+
+	Service []Service `json:"service,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The properties
+// with a set of values also accept a single value, as found in real
+// documents, including a string @context.
+func (doc *Document) UnmarshalJSON(data []byte) error {
+	type plain Document // without methods
+	var v struct {
+		*plain
+		Context              json.RawMessage `json:"@context"`
+		AlsoKnownAs          json.RawMessage `json:"alsoKnownAs"`
+		Controller           json.RawMessage `json:"controller"`
+		VerificationMethod   json.RawMessage `json:"verificationMethod"`
+		Authentication       json.RawMessage `json:"authentication"`
+		AssertionMethod      json.RawMessage `json:"assertionMethod"`
+		KeyAgreement         json.RawMessage `json:"keyAgreement"`
+		CapabilityInvocation json.RawMessage `json:"capabilityInvocation"`
+		CapabilityDelegation json.RawMessage `json:"capabilityDelegation"`
+		Service              json.RawMessage `json:"service"`
+	}
+	v.plain = (*plain)(doc)
+	*doc = Document{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	for _, err := range [...]error{
+		unmarshalSet(v.Context, &doc.Context),
+		unmarshalSet(v.AlsoKnownAs, &doc.AlsoKnownAs),
+		unmarshalSet(v.Controller, &doc.Controller),
+		unmarshalSet(v.VerificationMethod, &doc.VerificationMethod),
+		unmarshalSet(v.Authentication, &doc.Authentication),
+		unmarshalSet(v.AssertionMethod, &doc.AssertionMethod),
+		unmarshalSet(v.KeyAgreement, &doc.KeyAgreement),
+		unmarshalSet(v.CapabilityInvocation, &doc.CapabilityInvocation),
+		unmarshalSet(v.CapabilityDelegation, &doc.CapabilityDelegation),
+		unmarshalSet(v.Service, &doc.Service),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalSet decodes either a JSON array, or a single value as a set of
+// one. Absent values and null leave dst as is.
+func unmarshalSet[T any](data json.RawMessage, dst *[]T) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if data[0] == '[' {
+		return json.Unmarshal(data, dst)
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*dst = []T{v}
+	return nil
+}
+
+// A VerificationMethod is a public key, or a similar means to verify proofs.
+type VerificationMethod struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Controller string `json:"controller"`
+
+	// Public key material, if any.
+	PublicKeyMultibase string          `json:"publicKeyMultibase,omitempty"`
+	PublicKeyJWK       json.RawMessage `json:"publicKeyJwk,omitempty"`
+
+	// PublicKeyBase58 is the raw key in base58, as used by the legacy
+	// Ed25519VerificationKey2018 type.
+	PublicKeyBase58 string `json:"publicKeyBase58,omitempty"`
+
+	// BlockchainAccountID is a CAIP-10 account, for methods which
+	// verify with the key of a blockchain account.
+	BlockchainAccountID string `json:"blockchainAccountId,omitempty"`
+}
+
+// A Relationship either references a verification method by its DID URL, or
+// it embeds the verification method.
+type Relationship struct {
+	// Reference is the DID URL of a verification method. It is ignored
+	// when Embedded is set.
+	Reference string
+
+	Embedded *VerificationMethod
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r Relationship) MarshalJSON() ([]byte, error) {
+	if r.Embedded != nil {
+		return json.Marshal(r.Embedded)
+	}
+	return json.Marshal(r.Reference)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Relationship) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*r = Relationship{Reference: s}
+		return nil
+	}
+	m := new(VerificationMethod)
+	if err := json.Unmarshal(data, m); err != nil {
+		return err
+	}
+	*r = Relationship{Embedded: m}
+	return nil
+}
+
+// A Service is a means of communicating or interacting with the DID subject.
+type Service struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	ServiceEndpoint ServiceEndpoint `json:"serviceEndpoint"`
+}
+
+// ErrInvalidDocument means a DID Document does not conform to DID Core.
+var ErrInvalidDocument = errors.New("invalid DID document")
+
+// Validate checks conformance to the DID Core data model. The id must be a
+// DID, and controllers must be DIDs too. Verification methods, including the
+// ones embedded in relationships, need a DID URL as id, a type, a DID as
+// controller and public key material. Relative ids resolve against the id of
+// the document. References to verification methods of the document must
+// exist. Services need an id, a type and an endpoint other than an empty set.
+// The ids of verification methods and services must be unique. The key
+// material must conform to the type, for the types with a constant, like
+// TypeMultikey. The error wraps ErrInvalidDocument, and it has a line per
+// violation.
+// https://www.w3.org/TR/did-core/#core-properties
+func (doc *Document) Validate() error {
+	var v violations
+	d, err := Parse(doc.ID)
+	if err != nil {
+		v.add("id", err)
+		return v.err(doc)
+	}
+	base := d.URL()
+
+	for i, s := range doc.Controller {
+		if _, err := Parse(s); err != nil {
+			v.add(fmt.Sprintf("controller[%d]", i), err)
+		}
+	}
+
+	ids := make(map[Key]string) // property path per id
+	for i := range doc.VerificationMethod {
+		v.method(fmt.Sprintf("verificationMethod[%d]", i), &doc.VerificationMethod[i], base, ids)
+	}
+	relationships := [...]struct {
+		name string
+		list []Relationship
+	}{
+		{"authentication", doc.Authentication},
+		{"assertionMethod", doc.AssertionMethod},
+		{"keyAgreement", doc.KeyAgreement},
+		{"capabilityInvocation", doc.CapabilityInvocation},
+		{"capabilityDelegation", doc.CapabilityDelegation},
+	}
+	for _, rel := range relationships {
+		for i, r := range rel.list {
+			if r.Embedded != nil {
+				v.method(fmt.Sprintf("%s[%d]", rel.name, i), r.Embedded, base, ids)
+			}
+		}
+	}
+	for _, rel := range relationships {
+		for i, r := range rel.list {
+			if r.Embedded != nil {
+				continue
+			}
+			path := fmt.Sprintf("%s[%d]", rel.name, i)
+			u, err := base.ResolveReference(r.Reference)
+			if err != nil {
+				v.add(path, err)
+				continue
+			}
+			if _, ok := ids[u.Key()]; !ok && u.DID.Key() == d.Key() {
+				v.add(path, fmt.Errorf("no verification method %s in document", u))
+			}
+		}
+	}
+
+	for i, s := range doc.Service {
+		path := fmt.Sprintf("service[%d]", i)
+		v.id(path, s.ID, base, ids)
+		if s.Type == "" {
+			v.add(path+".type", errors.New("missing"))
+		}
+		switch {
+		case s.ServiceEndpoint.IsZero():
+			v.add(path+".serviceEndpoint", errors.New("missing"))
+		case s.ServiceEndpoint.Set != nil && len(s.ServiceEndpoint.Set) == 0:
+			v.add(path+".serviceEndpoint", errors.New("empty set"))
+		}
+	}
+
+	return v.err(doc)
+}
+
+// violations collects the failures of Validate.
+type violations []error
+
+// add records a violation at the property path.
+func (v *violations) add(path string, err error) {
+	*v = append(*v, fmt.Errorf("%s: %w", path, err))
+}
+
+// err returns the violations as a single error, if any.
+func (v violations) err(doc *Document) error {
+	if len(v) == 0 {
+		return nil
+	}
+	return fmt.Errorf("did: document %q: %w:\n%w", doc.ID, ErrInvalidDocument, errors.Join(v...))
+}
+
+// id checks the id at the property path to be unique, and it records the id
+// in ids.
+func (v *violations) id(path, id string, base *DIDURL, ids map[Key]string) {
+	if id == "" {
+		v.add(path+".id", errors.New("missing"))
+		return
+	}
+	u, err := base.ResolveReference(id)
+	if err != nil {
+		v.add(path+".id", err)
+		return
+	}
+	if other, ok := ids[u.Key()]; ok {
+		v.add(path+".id", fmt.Errorf("%s also in %s", u, other))
+		return
+	}
+	ids[u.Key()] = path
+}
+
+// method checks the verification method at the property path.
+func (v *violations) method(path string, m *VerificationMethod, base *DIDURL, ids map[Key]string) {
+	v.id(path, m.ID, base, ids)
+	if m.Type == "" {
+		v.add(path+".type", errors.New("missing"))
+	}
+	if m.Controller == "" {
+		v.add(path+".controller", errors.New("missing"))
+	} else if _, err := Parse(m.Controller); err != nil {
+		v.add(path+".controller", err)
+	}
+	if err := m.checkMaterial(); err != nil {
+		v.add(path, err)
+	}
+}
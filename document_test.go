@@ -0,0 +1,93 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDocumentJSON(t *testing.T) {
+	const sample = `{"@context":["https://www.w3.org/ns/did/v1"],"id":"did:example:123","verificationMethod":[{"id":"did:example:123#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6Mkf5rGMoatrSj1f4CyvuHBeXJELe9RPdzo2PKGNCKVtZxP"}],"authentication":["#key-1",{"id":"did:example:123#key-2","type":"JsonWebKey2020","controller":"did:example:123","publicKeyJwk":{"kty":"OKP","crv":"Ed25519","x":"VCpo2LMLhn6iWku8MKvSLg2ZAoC-nlOyPVQaO3FxVeQ"}}],"service":[{"id":"#linked","type":"LinkedDomains","serviceEndpoint":"https://example.com/"}]}`
+
+	var doc Document
+	assert(t, nil, json.Unmarshal([]byte(sample), &doc))
+	assert(t, "did:example:123", doc.ID)
+	assert(t, 2, len(doc.Authentication))
+	assert(t, "#key-1", doc.Authentication[0].Reference)
+	assert(t, (*VerificationMethod)(nil), doc.Authentication[0].Embedded)
+	assert(t, "JsonWebKey2020", doc.Authentication[1].Embedded.Type)
+	assert(t, "https://example.com/", doc.Service[0].ServiceEndpoint.URI)
+
+	bytes, err := json.Marshal(&doc)
+	assert(t, nil, err)
+	assert(t, sample, string(bytes))
+}
+
+func TestDocumentJSONSingleValues(t *testing.T) {
+	const sample = `{"@context":"https://www.w3.org/ns/did/v1","id":"did:example:123","alsoKnownAs":"https://example.com/","controller":"did:example:456","verificationMethod":{"id":"did:example:123#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6Mkf5rGMoatrSj1f4CyvuHBeXJELe9RPdzo2PKGNCKVtZxP"},"authentication":"#key-1","assertionMethod":null,"service":{"id":"#linked","type":"LinkedDomains","serviceEndpoint":"https://example.com/"}}`
+
+	var doc Document
+	assert(t, nil, json.Unmarshal([]byte(sample), &doc))
+	assert(t, []any{ContextV1}, doc.Context)
+	assert(t, "did:example:123", doc.ID)
+	assert(t, []string{"https://example.com/"}, doc.AlsoKnownAs)
+	assert(t, []string{"did:example:456"}, doc.Controller)
+	assert(t, 1, len(doc.VerificationMethod))
+	assert(t, "Multikey", doc.VerificationMethod[0].Type)
+	assert(t, []Relationship{{Reference: "#key-1"}}, doc.Authentication)
+	assert(t, []Relationship(nil), doc.AssertionMethod)
+	assert(t, 1, len(doc.Service))
+
+	// reuse resets the previous content
+	assert(t, nil, json.Unmarshal([]byte(`{"id":"did:example:789"}`), &doc))
+	assert(t, Document{ID: "did:example:789"}, doc)
+
+	for _, s := range []string{
+		`{"id":"did:example:123","controller":1}`,
+		`{"id":"did:example:123","authentication":[1]}`,
+		`{"id":"did:example:123","service":"#linked"}`,
+		`{"id":1}`,
+	} {
+		assert(t, true, json.Unmarshal([]byte(s), new(Document)) != nil, "%s accepted", s)
+	}
+}
+
+func TestDocumentValidate(t *testing.T) {
+	const valid = `{"id":"did:example:123","controller":"did:example:456","verificationMethod":[{"id":"#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6Mkf5rGMoatrSj1f4CyvuHBeXJELe9RPdzo2PKGNCKVtZxP"}],"authentication":["did:example:123#key-1","#key-2","did:example:456#key-1"],"assertionMethod":[{"id":"did:example:123#key-2","type":"JsonWebKey2020","controller":"did:example:123","publicKeyJwk":{"kty":"OKP","crv":"Ed25519","x":"VCpo2LMLhn6iWku8MKvSLg2ZAoC-nlOyPVQaO3FxVeQ"}}],"service":[{"id":"#linked","type":"LinkedDomains","serviceEndpoint":"https://example.com/"}]}`
+	var doc Document
+	assert(t, nil, json.Unmarshal([]byte(valid), &doc))
+	assert(t, nil, doc.Validate())
+
+	tests := []struct {
+		doc  string
+		want string // violation
+	}{
+		{`{"id":"example:123"}`, "id: "},
+		{`{"id":"did:example:123#frag"}`, "id: "},
+		{`{"id":"did:example:123","controller":["did:example:456","456"]}`, "controller[1]: "},
+		{`{"id":"did:example:123","verificationMethod":[{"type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6Mk"}]}`, "verificationMethod[0].id: missing"},
+		{`{"id":"did:example:123","verificationMethod":[{"id":"#k","controller":"did:example:123","publicKeyMultibase":"z6Mk"}]}`, "verificationMethod[0].type: missing"},
+		{`{"id":"did:example:123","verificationMethod":[{"id":"#k","type":"Multikey","controller":"example","publicKeyMultibase":"z6Mk"}]}`, "verificationMethod[0].controller: "},
+		{`{"id":"did:example:123","verificationMethod":[{"id":"#k","type":"ExampleKey","controller":"did:example:123"}]}`, "verificationMethod[0]: no public key material"},
+		{`{"id":"did:example:123","verificationMethod":[{"id":"#k","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6Mk"}],"keyAgreement":[{"id":"did:example:123#k","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6LS"}]}`, "keyAgreement[0].id: did:example:123#k also in verificationMethod[0]"},
+		{`{"id":"did:example:123","authentication":["#key-9"]}`, "authentication[0]: no verification method did:example:123#key-9 in document"},
+		{`{"id":"did:example:123","service":[{"id":"#s","serviceEndpoint":"https://example.com/"}]}`, "service[0].type: missing"},
+		{`{"id":"did:example:123","service":[{"id":"#s","type":"LinkedDomains"}]}`, "service[0].serviceEndpoint: missing"},
+	}
+	for _, test := range tests {
+		var doc Document
+		assert(t, nil, json.Unmarshal([]byte(test.doc), &doc))
+		err := doc.Validate()
+		assert(t, true, errors.Is(err, ErrInvalidDocument), "%s got error %v", test.doc, err)
+		if err != nil {
+			assert(t, true, strings.Contains(err.Error(), "\n"+test.want), "%s got error %q, want violation %q", test.doc, err, test.want)
+		}
+	}
+
+	// the JSON decoding rejects empty sets already
+	doc = Document{ID: "did:example:123", Service: []Service{{ID: "#s", Type: "LinkedDomains", ServiceEndpoint: ServiceEndpoint{Set: []ServiceEndpoint{}}}}}
+	err := doc.Validate()
+	assert(t, true, err != nil && strings.Contains(err.Error(), "\nservice[0].serviceEndpoint: empty set"), "empty set got error %v", err)
+	assert(t, true, json.Unmarshal([]byte(`{"id":"did:example:123","service":[{"id":"#s","type":"LinkedDomains","serviceEndpoint":[]}]}`), new(Document)) != nil, "empty set decoded")
+}
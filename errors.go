@@ -0,0 +1,104 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A Component identifies a part of the DID URL syntax.
+type Component int
+
+// DID URL components in order of appearance.
+const (
+	ComponentScheme Component = iota + 1
+	ComponentMethod
+	ComponentID
+	ComponentPath
+	ComponentQuery
+	ComponentFragment
+)
+
+var componentNames = [...]string{
+	ComponentScheme:   "scheme",
+	ComponentMethod:   "method",
+	ComponentID:       "method-specific-id",
+	ComponentPath:     "path",
+	ComponentQuery:    "query",
+	ComponentFragment: "fragment",
+}
+
+// String returns the name of the component.
+func (c Component) String() string {
+	if c > 0 && int(c) < len(componentNames) {
+		return componentNames[c]
+	}
+	return fmt.Sprintf("component(%d)", int(c))
+}
+
+// Reasons for a ParseError. Callers can test for them with errors.Is.
+var (
+	// ErrMissingScheme means the input does not start with "did:".
+	ErrMissingScheme = errors.New("missing did: scheme")
+	// ErrEmptyMethod means the method name has no characters.
+	ErrEmptyMethod = errors.New("empty method")
+	// ErrInvalidMethodChar means the method name has a character other
+	// than a lower-case letter or a digit.
+	ErrInvalidMethodChar = errors.New("invalid method character")
+	// ErrEmptyID means the method-specific-id is absent, or it ends with
+	// a colon.
+	ErrEmptyID = errors.New("empty idstring at end of method-specific-id")
+	// ErrInvalidIDChar means the method-specific-id has a character not
+	// permitted by the grammar.
+	ErrInvalidIDChar = errors.New("invalid method-specific-id character")
+	// ErrBadPercentEncoding means a '%' is not followed by two
+	// hexadecimal digits.
+	ErrBadPercentEncoding = errors.New("% not followed by 2 hex digits")
+	// ErrInvalidPathChar means the DID Path has a character not
+	// permitted by the grammar.
+	ErrInvalidPathChar = errors.New("invalid path character")
+	// ErrInvalidQueryChar means the DID Query has a character not
+	// permitted by the grammar.
+	ErrInvalidQueryChar = errors.New("invalid query character")
+	// ErrInvalidFragmentChar means the DID Fragment has a character not
+	// permitted by the grammar.
+	ErrInvalidFragmentChar = errors.New("invalid fragment character")
+	// ErrIDLength means the method-specific-id is shorter or longer than
+	// the MethodSpec of its method permits.
+	ErrIDLength = errors.New("method-specific-id length out of range")
+	// ErrChecksum means the method-specific-id failed the checksum of the
+	// MethodSpec of its method. The ParseError wraps the failure of the
+	// checksum function too.
+	ErrChecksum = errors.New("method-specific-id checksum mismatch")
+	// ErrURLDenied means Parse got a DID URL with a path, a query or a
+	// fragment.
+	ErrURLDenied = errors.New("DID URL denied")
+)
+
+// A ParseError describes why and where input was rejected.
+type ParseError struct {
+	// Err is the reason for rejection.
+	Err error
+
+	// Component is the part of the syntax in which the problem occurred.
+	Component Component
+
+	// Offset is the position in the input, in bytes.
+	Offset int
+
+	// Rune is the offending character at Offset, or -1 when the input
+	// ended prematurely. Malformed UTF-8 reads as utf8.RuneError.
+	Rune rune
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	if e.Rune < 0 {
+		return fmt.Sprintf("did: %s: %s at end of input (offset %d)", e.Component, e.Err, e.Offset)
+	}
+	return fmt.Sprintf("did: %s: %s at offset %d: %q", e.Component, e.Err, e.Offset, e.Rune)
+}
+
+// Unwrap returns the reason for rejection.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
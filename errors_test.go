@@ -0,0 +1,68 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		input     string
+		component Component
+		offset    int
+		r         rune
+		err       error
+	}{
+		{"", ComponentScheme, 0, -1, ErrMissingScheme},
+		{"urn:example:1", ComponentScheme, 0, 'u', ErrMissingScheme},
+		{"did:", ComponentMethod, 4, -1, ErrEmptyMethod},
+		{"did::1", ComponentMethod, 4, ':', ErrEmptyMethod},
+		{"did:aA:1", ComponentMethod, 5, 'A', ErrInvalidMethodChar},
+		{"did:a", ComponentID, 5, -1, ErrEmptyID},
+		{"did:a:", ComponentID, 6, -1, ErrEmptyID},
+		{"did:a:1:", ComponentID, 8, -1, ErrEmptyID},
+		{"did:a:1!", ComponentID, 7, '!', ErrInvalidIDChar},
+		{"did:a:1%4", ComponentID, 7, '%', ErrBadPercentEncoding},
+		{"did:a:1%zz", ComponentID, 7, '%', ErrBadPercentEncoding},
+		{"did:a:1/x%g0", ComponentPath, 9, '%', ErrBadPercentEncoding},
+		{"did:a:1/x y", ComponentPath, 9, ' ', ErrInvalidPathChar},
+		{"did:a:1?q\"", ComponentQuery, 9, '"', ErrInvalidQueryChar},
+		{"did:a:1#f#", ComponentFragment, 9, '#', ErrInvalidFragmentChar},
+		{"did:a:1#f\xff", ComponentFragment, 9, '�', ErrInvalidFragmentChar},
+	}
+	for _, test := range tests {
+		_, err := ParseURL(test.input)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("ParseURL(%q) got error %v, want a *ParseError", test.input, err)
+			continue
+		}
+		assert(t, test.component, perr.Component, test.input)
+		assert(t, test.offset, perr.Offset, test.input)
+		assert(t, test.r, perr.Rune, test.input)
+		if !errors.Is(err, test.err) {
+			t.Errorf("ParseURL(%q) got error %v, want errors.Is %v", test.input, err, test.err)
+		}
+	}
+
+	t.Run("URL denied", func(t *testing.T) {
+		_, err := Parse("did:a:1/x")
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("got error %v, want a *ParseError", err)
+		}
+		assert(t, ComponentPath, perr.Component)
+		assert(t, 7, perr.Offset)
+		assert(t, '/', perr.Rune)
+		if !errors.Is(err, ErrURLDenied) {
+			t.Errorf("got error %v, want errors.Is ErrURLDenied", err)
+		}
+	})
+
+	t.Run("message", func(t *testing.T) {
+		_, err := Parse("did:aA:1")
+		assert(t, `did: method: invalid method character at offset 5: 'A'`, err.Error())
+		_, err = Parse("did:a:")
+		assert(t, "did: method-specific-id: empty idstring at end of method-specific-id at end of input (offset 6)", err.Error())
+	})
+}
@@ -1,8 +1,10 @@
 package did_test
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/ockam-network/did"
 )
@@ -16,8 +18,26 @@ func ExampleParse() {
 	// Output: Method - example, ID - q7ckgxeq1lxmra0r
 }
 
-func ExampleParse_withPath() {
-	d, err := did.Parse("did:example:q7ckgxeq1lxmra0r/a/b")
+func ExampleParse_errors() {
+	_, err := did.Parse("did:Example:q7ckgxeq1lxmra0r")
+	switch {
+	case errors.Is(err, did.ErrInvalidMethodChar):
+		fmt.Println("method names are lower-case")
+	case err != nil:
+		fmt.Println("malformed DID")
+	}
+
+	var perr *did.ParseError
+	if errors.As(err, &perr) {
+		fmt.Println(perr.Component, "at offset", perr.Offset)
+	}
+	// Output:
+	// method names are lower-case
+	// method at offset 4
+}
+
+func ExampleParseURL_withPath() {
+	d, err := did.ParseURL("did:example:q7ckgxeq1lxmra0r/a/b")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -25,8 +45,8 @@ func ExampleParse_withPath() {
 	// Output: Method - example, ID - q7ckgxeq1lxmra0r, Path - a/b
 }
 
-func ExampleParse_withQuery() {
-	d, err := did.Parse("did:example:q7ckgxeq1lxmra0r?dskjsdjj")
+func ExampleParseURL_withQuery() {
+	d, err := did.ParseURL("did:example:q7ckgxeq1lxmra0r?dskjsdjj")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -34,8 +54,8 @@ func ExampleParse_withQuery() {
 	// Output: Method - example, ID - q7ckgxeq1lxmra0r, Query - dskjsdjj
 }
 
-func ExampleParse_withFragment() {
-	d, err := did.Parse("did:example:q7ckgxeq1lxmra0r#keys-1")
+func ExampleParseURL_withFragment() {
+	d, err := did.ParseURL("did:example:q7ckgxeq1lxmra0r#keys-1")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -49,44 +69,157 @@ func ExampleDID_String() {
 	// Output: did:example:q7ckgxeq1lxmra0r
 }
 
-func ExampleDID_String_withPath() {
-	d := &did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r", Path: "a/b"}
+func ExampleDIDURL_String_withPath() {
+	d := &did.DIDURL{DID: did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}, Path: "a/b"}
 	fmt.Println(d.String())
 	// Output: did:example:q7ckgxeq1lxmra0r/a/b
 }
 
-func ExampleDID_String_withPathSegments() {
-	d := &did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r", PathSegments: []string{"a", "b"}}
+func ExampleDIDURL_String_withPathSegments() {
+	d := &did.DIDURL{DID: did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}, PathSegments: []string{"a", "b"}}
 	fmt.Println(d.String())
 	// Output: did:example:q7ckgxeq1lxmra0r/a/b
 }
 
-func ExampleDID_String_withQuery() {
-	d := &did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r", Query: "abc"}
+func ExampleDIDURL_String_withQuery() {
+	d := &did.DIDURL{DID: did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}, Query: "abc"}
 	fmt.Println(d.String())
 	// Output: did:example:q7ckgxeq1lxmra0r?abc
 }
 
-func ExampleDID_String_withFragment() {
-	d := &did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r", Fragment: "keys-1"}
+func ExampleDIDURL_String_withFragment() {
+	d := &did.DIDURL{DID: did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}, Fragment: "keys-1"}
 	fmt.Println(d.String())
 	// Output: did:example:q7ckgxeq1lxmra0r#keys-1
 }
 
-func ExampleDID_IsURL_withPath() {
-	d := &did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r", Path: "a/b"}
+func ExampleDIDURL_Key() {
+	seen := make(map[did.Key]bool)
+	for _, s := range []string{"did:example:123#keys-1", "did:example:123", "did:example:123#keys-1"} {
+		d, err := did.ParseURL(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if seen[d.Key()] {
+			fmt.Println("duplicate", d)
+		}
+		seen[d.Key()] = true
+	}
+	// Output: duplicate did:example:123#keys-1
+}
+
+func ExampleDIDURL_IsURL_withPath() {
+	d := &did.DIDURL{DID: did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}, Path: "a/b"}
 	fmt.Println(d.IsURL())
 	// Output: true
 }
 
-func ExampleDID_IsURL_withFragment() {
-	d := &did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r", Fragment: "keys-1"}
+func ExampleDIDURL_IsURL_withFragment() {
+	d := &did.DIDURL{DID: did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}, Fragment: "keys-1"}
 	fmt.Println(d.IsURL())
 	// Output: true
 }
 
-func ExampleDID_IsURL_noPathOrFragment() {
-	d := &did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}
+func ExampleDIDURL_IsURL_noPathOrFragment() {
+	d := &did.DIDURL{DID: did.DID{Method: "example", ID: "q7ckgxeq1lxmra0r"}}
 	fmt.Println(d.IsURL())
 	// Output: false
 }
+
+func ExampleBuilder() {
+	d, err := did.NewBuilder("example").ID("q7ckgxeq1lxmra0r").
+		Param("service", "agent").Fragment("keys-1").Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(d)
+	// Output: did:example:q7ckgxeq1lxmra0r?service=agent#keys-1
+}
+
+func ExampleDIDURL_WithFragment() {
+	d, err := did.ParseURL("did:example:q7ckgxeq1lxmra0r")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(d.WithFragment("keys-1"))
+	fmt.Println(d.WithFragment("keys-2"))
+	// Output:
+	// did:example:q7ckgxeq1lxmra0r#keys-1
+	// did:example:q7ckgxeq1lxmra0r#keys-2
+}
+
+func ExampleDIDURL_ResolveReference() {
+	d, err := did.ParseURL("did:example:q7ckgxeq1lxmra0r")
+	if err != nil {
+		log.Fatal(err)
+	}
+	ref, err := d.ResolveReference("#keys-1")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(ref)
+	// Output: did:example:q7ckgxeq1lxmra0r#keys-1
+}
+
+func ExampleSplitKeyID() {
+	d, fragment, err := did.SplitKeyID("did:example:q7ckgxeq1lxmra0r#keys-1")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(d, fragment)
+	// Output: did:example:q7ckgxeq1lxmra0r keys-1
+}
+
+func ExampleIsMethodChar() {
+	for _, method := range []string{"example", "Example"} {
+		valid := true
+		for i := 0; i < len(method); i++ {
+			valid = valid && did.IsMethodChar(method[i])
+		}
+		fmt.Println(method, valid)
+	}
+	// Output:
+	// example true
+	// Example false
+}
+
+func ExampleExtract() {
+	text := "Signed by did:example:q7ckgxeq1lxmra0r (key did:example:q7ckgxeq1lxmra0r#keys-1)."
+	for _, u := range did.Extract(text) {
+		fmt.Println(u)
+	}
+	// Output:
+	// did:example:q7ckgxeq1lxmra0r
+	// did:example:q7ckgxeq1lxmra0r#keys-1
+}
+
+func ExampleScanner() {
+	s := did.NewScanner(strings.NewReader("did:example:123\ndid:example:456#keys-1\ndid:Example:789\n"))
+	for s.Scan() {
+		u, err := s.URL()
+		if err != nil {
+			fmt.Printf("line %d: %q denied\n", s.Line(), s.Text())
+			continue
+		}
+		fmt.Println(u)
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// did:example:123
+	// did:example:456#keys-1
+	// line 3: "did:Example:789" denied
+}
+
+func ExampleParseURLPrefix() {
+	u, rest, err := did.ParseURLPrefix("did:example:123#keys-1 did:example:456")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(u.Fragment)
+	fmt.Printf("%q\n", rest)
+	// Output:
+	// keys-1
+	// " did:example:456"
+}
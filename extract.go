@@ -0,0 +1,67 @@
+package did
+
+import "strings"
+
+// Extract returns all DIDs and DID URLs found in text, in order of
+// appearance. Punctuation which commonly surrounds identifiers in prose,
+// Markdown and logs is excluded, such as a trailing full stop or a closing
+// parenthesis without its opening counterpart.
+func Extract(text string) []*DIDURL {
+	var found []*DIDURL
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], "did:")
+		if i < 0 {
+			return found
+		}
+		start := offset + i
+		offset = start + len("did:")
+
+		// must not continue a word, like "xdid:" or "sub.did:"
+		if start > 0 && isWordChar(text[start-1]) {
+			continue
+		}
+
+		end := start
+		for end < len(text) && isURLChar(text[end]) {
+			end++
+		}
+		candidate := trimTrailing(text[start:end])
+
+		u, err := ParseURL(candidate)
+		if err != nil {
+			continue
+		}
+		found = append(found, u)
+		offset = start + len(candidate)
+	}
+}
+
+// isWordChar returns whether c continues a word, an identifier or a URI.
+func isWordChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '.' || c == '-' || c == '_' || c == ':' || c == '/' || c == '%'
+}
+
+// isURLChar returns whether c may occur anywhere in a DID URL.
+func isURLChar(c byte) bool {
+	return IsFragmentChar(c) || c == '%' || c == '#'
+}
+
+// trimTrailing removes the punctuation from the end of s which is unlikely
+// part of a DID URL.
+func trimTrailing(s string) string {
+	for s != "" {
+		switch c := s[len(s)-1]; c {
+		case '.', ',', ';', ':', '!', '?', '\'', '*', '#':
+			s = s[:len(s)-1]
+			continue
+		case ')':
+			if strings.Count(s, "(") < strings.Count(s, ")") {
+				s = s[:len(s)-1]
+				continue
+			}
+		}
+		return s
+	}
+	return s
+}
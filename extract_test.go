@@ -0,0 +1,38 @@
+package did
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	golden := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"no identifiers here", nil},
+		{"did:example:123", []string{"did:example:123"}},
+		{"Issued by did:example:123.", []string{"did:example:123"}},
+		{"did:example:123, did:example:456; and did:example:789!", []string{"did:example:123", "did:example:456", "did:example:789"}},
+		{"Is it did:example:123?", []string{"did:example:123"}},
+		{"(see did:example:123#keys-1)", []string{"did:example:123#keys-1"}},
+		{"did:example:123/a(b)", []string{"did:example:123/a(b)"}},
+		{"[did:example:123](did:example:123)", []string{"did:example:123", "did:example:123"}},
+		{"**did:example:123**", []string{"did:example:123"}},
+		{"'did:example:123'", []string{"did:example:123"}},
+		{`"did:example:123"`, []string{"did:example:123"}},
+		{"<did:example:123>", []string{"did:example:123"}},
+		{"kid=did:example:123#keys-1&alg=EdDSA", []string{"did:example:123#keys-1&alg=EdDSA"}},
+		{"did:web:example.com:user:alice/path?x=1#frag.", []string{"did:web:example.com:user:alice/path?x=1#frag"}},
+		{"did:example:123:", []string{"did:example:123"}},
+		{"xdid:example:123 a.did:example:456", nil},
+		{"did:Example:123 did:example:456", []string{"did:example:456"}},
+		{"did:did:example:123", []string{"did:did:example:123"}},
+		{"did:\ndid:example:1\n", []string{"did:example:1"}},
+	}
+	for _, g := range golden {
+		var got []string
+		for _, u := range Extract(g.text) {
+			got = append(got, u.String())
+		}
+		assert(t, g.want, got, "Input: %q", g.text)
+	}
+}
@@ -0,0 +1,73 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// FSResolver is a Resolver which reads each document from a JSON file, for
+// fixtures and for offline bundles of documents. Use os.DirFS to serve a
+// directory. Files are read on each resolution; wrap the resolver in a
+// CachingResolver to keep them in memory.
+type FSResolver struct {
+	// FS has the documents.
+	FS fs.FS
+
+	// Layout maps a DID to the path of its document in FS. The nil value
+	// defaults to MethodLayout.
+	Layout func(d *DID) string
+}
+
+// MethodLayout is the default layout of an FSResolver. The path has a
+// directory for the method, and a directory for each of the colon-separated
+// parts of the method-specific-id, except for the last one, which is the
+// file name with a ".json" extension. For example, the document of
+// did:web:example.com:user:alice is at web/example.com/user/alice.json.
+// Percent-encodings remain as is.
+func MethodLayout(d *DID) string {
+	return d.Method + "/" + strings.ReplaceAll(d.Key().id, ":", "/") + ".json"
+}
+
+// Resolve implements the Resolver interface. DIDs without file get an error
+// which wraps ErrNotFound. The document must have the DID as its id. The
+// options other than Accept are ignored.
+func (r *FSResolver) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	accept := baseMediaType(opts.Accept)
+	switch accept {
+	case "", MediaTypeJSON, MediaTypeJSONLD, MediaTypeCBOR:
+		break
+	default:
+		return nil, nil, fmt.Errorf("did: resolve %s: media type %q: %w", d, opts.Accept, ErrRepresentationNotSupported)
+	}
+
+	layout := r.Layout
+	if layout == nil {
+		layout = MethodLayout
+	}
+	name := layout(d)
+	if !fs.ValidPath(name) {
+		return nil, nil, fmt.Errorf("did: resolve %s: no valid path for file %q: %w", d, name, ErrNotFound)
+	}
+	data, err := fs.ReadFile(r.FS, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("did: resolve %s: %w: %w", d, ErrNotFound, err)
+		}
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	doc, err := UnmarshalDocument(data, MediaTypeJSON)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: file %q: %w", d, name, err)
+	}
+	if doc.ID != d.String() {
+		return nil, nil, fmt.Errorf("did: resolve %s: file %q has document id %q", d, name, doc.ID)
+	}
+
+	doc, contentType := represent(doc, accept)
+	meta := new(Metadata)
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
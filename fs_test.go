@@ -0,0 +1,52 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSResolver(t *testing.T) {
+	r := &FSResolver{FS: fstest.MapFS{
+		"web/example.com.json":            {Data: []byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"did:web:example.com"}`)},
+		"web/example.com/user/alice.json": {Data: []byte(`{"id":"did:web:example.com:user:alice"}`)},
+		"web/example.com/user/bob.json":   {Data: []byte(`{"id":"did:web:example.com:user:alice"}`)},
+		"web/example.com/user/carol.json": {Data: []byte(`{"id":`)},
+	}}
+	ctx := context.Background()
+
+	alice := &DID{Method: "web", ID: "example.com:user:alice"}
+	doc, meta, err := r.Resolve(ctx, alice, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "did:web:example.com:user:alice", doc.ID)
+	assert(t, MediaTypeJSON, meta.Resolution.ContentType)
+
+	doc, meta, err = r.Resolve(ctx, alice, ResolutionOptions{Accept: MediaTypeJSONLD})
+	assert(t, nil, err)
+	assert(t, []any{ContextV1}, doc.Context)
+	assert(t, MediaTypeJSONLD, meta.Resolution.ContentType)
+
+	tests := []struct {
+		d    *DID
+		want error
+	}{
+		{&DID{Method: "web", ID: "example.com:user:dave"}, ErrNotFound},
+		{&DID{Method: "web", ID: "example.com:..:user:alice"}, ErrNotFound},
+		{&DID{Method: "key", ID: "z6Mk"}, ErrNotFound},
+	}
+	for _, test := range tests {
+		_, _, err := r.Resolve(ctx, test.d, ResolutionOptions{})
+		assert(t, true, errors.Is(err, test.want), "%s got error %v", test.d, err)
+	}
+	for _, id := range []string{"example.com:user:bob", "example.com:user:carol"} {
+		_, _, err := r.Resolve(ctx, &DID{Method: "web", ID: id}, ResolutionOptions{})
+		assert(t, true, err != nil && !errors.Is(err, ErrNotFound), "%s got error %v", id, err)
+	}
+
+	r.Layout = func(d *DID) string { return "flat/" + d.Method + "_" + d.ID + ".json" }
+	r.FS.(fstest.MapFS)["flat/web_example.com.json"] = &fstest.MapFile{Data: []byte(`{"id":"did:web:example.com"}`)}
+	doc, _, err = r.Resolve(ctx, &DID{Method: "web", ID: "example.com"}, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "did:web:example.com", doc.ID)
+}
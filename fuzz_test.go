@@ -0,0 +1,121 @@
+package did
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func addFuzzSeeds(f *testing.F) {
+	for _, s := range regexpSamples {
+		f.Add(s)
+	}
+	f.Add("did:ion:" + strings.Repeat("%41", 100))
+	f.Add("did:a:1/" + strings.Repeat("x/", 100))
+	f.Add("did:a:\xff")
+	f.Add("did:a:1#\x80")
+}
+
+func FuzzParse(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, input string) {
+		d, err := Parse(input)
+		if err != nil {
+			if d != nil {
+				t.Errorf("Parse(%q) got both a result and error %v", input, err)
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse(%q) got error %v, want a *ParseError", input, err)
+			}
+			if perr.Offset < 0 || perr.Offset > len(input) {
+				t.Errorf("Parse(%q) got error offset %d, out of range", input, perr.Offset)
+			}
+		}
+		if Valid(input) != (err == nil) {
+			t.Errorf("Valid(%q) got %t, Parse error %v", input, Valid(input), err)
+		}
+		if Regexp.MatchString(input) != (err == nil) {
+			t.Errorf("Regexp match %q got %t, Parse error %v", input, !(err == nil), err)
+		}
+
+		u, err := ParseURL(input)
+		if err != nil {
+			if u != nil {
+				t.Errorf("ParseURL(%q) got both a result and error %v", input, err)
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("ParseURL(%q) got error %v, want a *ParseError", input, err)
+			}
+			if perr.Offset < 0 || perr.Offset > len(input) {
+				t.Errorf("ParseURL(%q) got error offset %d, out of range", input, perr.Offset)
+			}
+		}
+		if ValidURL(input) != (err == nil) {
+			t.Errorf("ValidURL(%q) got %t, ParseURL error %v", input, ValidURL(input), err)
+		}
+		if URLRegexp.MatchString(input) != (err == nil) {
+			t.Errorf("URLRegexp match %q got %t, ParseURL error %v", input, !(err == nil), err)
+		}
+
+		insp := Inspect(input, InspectOptions{})
+		if (len(insp.Errors) == 0) != (err == nil) {
+			t.Errorf("Inspect(%q) got %d errors, ParseURL error %v", input, len(insp.Errors), err)
+		}
+	})
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, input string) {
+		u, err := ParseURL(input)
+		if err != nil {
+			return
+		}
+		if s := u.String(); s != input {
+			t.Fatalf("ParseURL(%q) encodes as %q", input, s)
+		}
+
+		// encode without the original input
+		k := u.Key()
+		s := k.string()
+		again, err := ParseURL(s)
+		if err != nil {
+			t.Fatalf("ParseURL(%q) encodes as %q, which got parse error: %v", input, s, err)
+		}
+		if again.Key() != k {
+			t.Errorf("ParseURL(%q) encodes as %q, which parses with another key", input, s)
+		}
+
+		// relative reference, unless dot segments prevent an exact match
+		if u.Path == "" || removeDotSegments("/"+u.Path) == "/"+u.Path {
+			base := u.WithPath("b/c").WithQuery("q")
+			ref := u.RelativeTo(base)
+			resolved, err := base.ResolveReference(ref)
+			if err != nil {
+				t.Fatalf("%q relative to %q got unresolvable %q: %s", input, base, ref, err)
+			}
+			if resolved.String() != input {
+				t.Errorf("%q relative to %q got %q, which resolves to %q", input, base, ref, resolved)
+			}
+		}
+
+		// assemble from the components
+		c := DIDURL{
+			DID:           DID{Method: u.Method, IDStrings: u.IDStrings},
+			PathSegments:  u.PathSegments,
+			Query:         u.Query,
+			ForceQuery:    u.ForceQuery,
+			Fragment:      u.Fragment,
+			ForceFragment: u.ForceFragment,
+		}
+		again, err = ParseURL(c.String())
+		if err != nil {
+			t.Fatalf("ParseURL(%q) assembles as %q, which got parse error: %v", input, c.String(), err)
+		}
+		if again.String() != c.String() {
+			t.Errorf("ParseURL(%q) assembles as %q, which encodes as %q", input, c.String(), again.String())
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package did
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+)
+
+// GenerateOptions configures GenerateRandom.
+type GenerateOptions struct {
+	// Rand is the source of randomness. Nil defaults to the global source
+	// of math/rand.
+	Rand *rand.Rand
+
+	// Methods has the method names to pick from. Nil defaults to random
+	// method names.
+	Methods []string
+
+	// MaxIDLength limits the number of characters in the
+	// method-specific-id, with pct-encoded octets counting as one. Zero
+	// defaults to 32.
+	MaxIDLength int
+
+	// Path, Query and Fragment include the respective components, each
+	// with a chance of one in two.
+	Path, Query, Fragment bool
+}
+
+// character sets without pct-encoded
+const (
+	generateMethodChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+	generateIDChars     = alphaDigitChars + ".-_"
+	generateQueryChars  = pcharChars + "/?"
+)
+
+// GenerateRandom returns a random DID URL which is valid according to the
+// DID syntax, for use in property-based tests. Use Base on the result to get
+// a DID.
+func GenerateRandom(opts GenerateOptions) *DIDURL {
+	g := generator{opts.Rand}
+
+	var method string
+	if len(opts.Methods) != 0 {
+		method = opts.Methods[g.intn(len(opts.Methods))]
+	} else {
+		method = g.string(generateMethodChars, 1+g.intn(8), false)
+	}
+
+	maxIDLength := opts.MaxIDLength
+	if maxIDLength <= 0 {
+		maxIDLength = 32
+	}
+	var id strings.Builder
+	for n := 1 + g.intn(maxIDLength); n > 0; n-- {
+		switch {
+		case n > 1 && id.Len() != 0 && g.intn(8) == 0:
+			// separator between non-empty idstrings
+			id.WriteByte(':')
+		case g.intn(16) == 0:
+			id.WriteString(g.pctEncoded())
+		default:
+			id.WriteByte(generateIDChars[g.intn(len(generateIDChars))])
+		}
+	}
+
+	s := "did:" + method + ":" + id.String()
+	if opts.Path && g.intn(2) == 0 {
+		for n := 1 + g.intn(4); n > 0; n-- {
+			s += "/" + g.string(pcharChars, g.intn(8), true)
+		}
+	}
+	if opts.Query && g.intn(2) == 0 {
+		s += "?" + g.string(generateQueryChars, g.intn(16), true)
+	}
+	if opts.Fragment && g.intn(2) == 0 {
+		s += "#" + g.string(generateQueryChars, g.intn(16), true)
+	}
+
+	u, err := ParseURL(s)
+	if err != nil {
+		panic("did: generated invalid DID URL " + s + ": " + err.Error())
+	}
+	return u
+}
+
+// Generate implements the testing/quick.Generator interface. The size limits
+// the length of the method-specific-id.
+func (DID) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(*GenerateRandom(GenerateOptions{Rand: r, MaxIDLength: size}).Base())
+}
+
+// Generate implements the testing/quick.Generator interface. The size limits
+// the length of the method-specific-id.
+func (DIDURL) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(*GenerateRandom(GenerateOptions{
+		Rand:        r,
+		MaxIDLength: size,
+		Path:        true,
+		Query:       true,
+		Fragment:    true,
+	}))
+}
+
+// generator provides randomness from an optional source.
+type generator struct {
+	r *rand.Rand
+}
+
+func (g generator) intn(n int) int {
+	if g.r == nil {
+		return rand.Intn(n)
+	}
+	return g.r.Intn(n)
+}
+
+// string returns n random characters from chars, with pct-encoded octets
+// included when pct is set.
+func (g generator) string(chars string, n int, pct bool) string {
+	var b strings.Builder
+	for ; n > 0; n-- {
+		if pct && g.intn(16) == 0 {
+			b.WriteString(g.pctEncoded())
+		} else {
+			b.WriteByte(chars[g.intn(len(chars))])
+		}
+	}
+	return b.String()
+}
+
+func (g generator) pctEncoded() string {
+	c := g.intn(256)
+	return string([]byte{'%', upperhex[c>>4], upperhex[c&15]})
+}
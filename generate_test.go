@@ -0,0 +1,57 @@
+package did
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+func TestGenerateRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	var sawPath, sawQuery, sawFragment, sawColon bool
+	for i := 0; i < 1000; i++ {
+		u := GenerateRandom(GenerateOptions{
+			Rand:        r,
+			Methods:     []string{"example", "web"},
+			MaxIDLength: 10,
+			Path:        true,
+			Query:       true,
+			Fragment:    true,
+		})
+		if u.Method != "example" && u.Method != "web" {
+			t.Fatalf("got method %q", u.Method)
+		}
+		if _, err := ParseURL(u.String()); err != nil {
+			t.Fatalf("generated %q got parse error: %s", u, err)
+		}
+		sawPath = sawPath || u.Path != "" || len(u.PathSegments) != 0
+		sawQuery = sawQuery || u.Query != "" || u.ForceQuery
+		sawFragment = sawFragment || u.Fragment != "" || u.ForceFragment
+		sawColon = sawColon || len(u.IDStrings) > 1
+	}
+	assert(t, true, sawPath)
+	assert(t, true, sawQuery)
+	assert(t, true, sawFragment)
+	assert(t, true, sawColon)
+
+	u := GenerateRandom(GenerateOptions{})
+	assert(t, false, u.IsURL())
+}
+
+func TestQuickGenerator(t *testing.T) {
+	roundTrip := func(d DID) bool {
+		parsed, err := Parse(d.String())
+		return err == nil && parsed.Key() == d.Key()
+	}
+	if err := quick.Check(roundTrip, nil); err != nil {
+		t.Error(err)
+	}
+
+	roundTripURL := func(u DIDURL) bool {
+		parsed, err := ParseURL(u.String())
+		return err == nil && parsed.Key() == u.Key()
+	}
+	if err := quick.Check(roundTripURL, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,5 +1,3 @@
 module github.com/ockam-network/did
 
-go 1.19
-
-require github.com/pascaldekloe/did v1.0.1 // indirect
+go 1.24
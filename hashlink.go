@@ -0,0 +1,81 @@
+package did
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+
+	"github.com/ockam-network/did/internal/jcs"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// ErrHashlinkMismatch means content does not match the hl parameter of the
+// DID URL which identifies it.
+var ErrHashlinkMismatch = errors.New("hashlink mismatch")
+
+// Multihash function identifiers of hashlinks.
+const (
+	multihashSHA2_256 = 0x12
+	multihashSHA2_512 = 0x13
+)
+
+// VerifyHashlink checks content against a hashlink value, as in the hl
+// parameter of DID URLs, which is a multihash in a multibase encoding. The
+// SHA2-256 and SHA2-512 hash functions are supported. A different digest gets
+// an error which wraps ErrHashlinkMismatch.
+// https://datatracker.ietf.org/doc/html/draft-sporny-hashlink
+func VerifyHashlink(hl string, content []byte) error {
+	data, err := multibase.Decode(hl)
+	if err != nil {
+		return fmt.Errorf("did: hashlink %q: %w", hl, err)
+	}
+	code, rest, err := multibase.SplitCodec(data)
+	if err != nil {
+		return fmt.Errorf("did: hashlink %q: %w", hl, err)
+	}
+	size, digest, err := multibase.SplitCodec(rest)
+	if err != nil {
+		return fmt.Errorf("did: hashlink %q: %w", hl, err)
+	}
+	if uint64(len(digest)) != size {
+		return fmt.Errorf("did: hashlink %q: %d-byte digest, want %d", hl, len(digest), size)
+	}
+
+	var sum []byte
+	switch code {
+	case multihashSHA2_256:
+		s := sha256.Sum256(content)
+		sum = s[:]
+	case multihashSHA2_512:
+		s := sha512.Sum512(content)
+		sum = s[:]
+	default:
+		return fmt.Errorf("did: hashlink %q: unsupported multihash %#x", hl, code)
+	}
+	if !bytes.Equal(sum, digest) {
+		return fmt.Errorf("did: hashlink %q: %w", hl, ErrHashlinkMismatch)
+	}
+	return nil
+}
+
+// Hashlink returns the hashlink value of content with SHA2-256, in base58btc.
+func Hashlink(content []byte) string {
+	sum := sha256.Sum256(content)
+	mh := multibase.AppendCodec(nil, multihashSHA2_256)
+	mh = multibase.AppendCodec(mh, uint64(len(sum)))
+	return multibase.Encode(multibase.Base58BTC, append(mh, sum[:]...))
+}
+
+// CanonicalContent returns the bytes which the hl parameter of a DID URL
+// covers for the content of a dereferencing: the canonical JSON, conform RFC
+// 8785, of a document, a verification method or a service.
+func CanonicalContent(content any) ([]byte, error) {
+	switch content.(type) {
+	case *Document, *VerificationMethod, *Service:
+		return jcs.Marshal(content)
+	default:
+		return nil, fmt.Errorf("did: no canonical form for content of type %T", content)
+	}
+}
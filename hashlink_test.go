@@ -0,0 +1,24 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyHashlink(t *testing.T) {
+	content := []byte("Hello World!")
+	// sample of draft-sporny-hashlink, section 3.4
+	const hl = "zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3e"
+	assert(t, hl, Hashlink(content))
+	assert(t, nil, VerifyHashlink(hl, content))
+
+	err := VerifyHashlink(hl, []byte("Hello World?"))
+	assert(t, true, errors.Is(err, ErrHashlinkMismatch), "got error %v, want %v", err, ErrHashlinkMismatch)
+
+	for _, s := range []string{"", "Qm", "zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3", "z2Drjgb4TEPvaZm5"} {
+		err := VerifyHashlink(s, content)
+		if err == nil || errors.Is(err, ErrHashlinkMismatch) {
+			t.Errorf("%q got error %v, want a malformed hashlink", s, err)
+		}
+	}
+}
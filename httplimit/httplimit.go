@@ -0,0 +1,147 @@
+// Package httplimit provides client-side rate limiting for the HTTP-backed
+// resolvers, such that a busy verifier doesn't overload public resolver
+// instances or small did:web hosts.
+//
+//	client := &http.Client{Transport: &httplimit.Transport{Rate: 2, Burst: 5}}
+//	r := &web.Resolver{Client: client}
+package httplimit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrLimited means a request would have to wait longer than MaxWait.
+var ErrLimited = errors.New("httplimit: rate limit exceeded")
+
+// Transport is an http.RoundTripper with a token bucket per host. Requests
+// wait for a token, or until their context expires. The zero value does not
+// limit.
+type Transport struct {
+	// Base does the requests. The nil value defaults to
+	// http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Rate is the number of requests per second per host, if positive.
+	Rate float64
+
+	// Burst is the number of requests a host may get at once, with a
+	// minimum of one.
+	Burst int
+
+	// HostRate overrides Rate for specific hosts, as in URL.Host.
+	HostRate map[string]float64
+
+	// MaxWait fails requests with ErrLimited instead of waiting any
+	// longer, if positive.
+	MaxWait time.Duration
+
+	// Now is the clock. The nil value defaults to time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket has the tokens of a host as of a point in time.
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.reserve(req.URL.Host); wait > 0 {
+		if t.MaxWait > 0 && wait > t.MaxWait {
+			t.cancel(req.URL.Host)
+			return nil, fmt.Errorf("%w: %s needs a wait of %s", ErrLimited, req.URL.Host, wait)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+			break
+		case <-req.Context().Done():
+			timer.Stop()
+			t.cancel(req.URL.Host)
+			return nil, req.Context().Err()
+		}
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// rate returns the limit for host, with zero for none.
+func (t *Transport) rate(host string) float64 {
+	if r, ok := t.HostRate[host]; ok {
+		return r
+	}
+	return t.Rate
+}
+
+func (t *Transport) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+// reserve takes a token from the bucket of host, and it returns the time to
+// wait until the token is available. Tokens may go negative, which queues
+// the requests in order of reservation.
+func (t *Transport) reserve(host string) time.Duration {
+	rate := t.rate(host)
+	if rate <= 0 {
+		return 0
+	}
+	burst := float64(max(t.Burst, 1))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	b, ok := t.buckets[host]
+	if !ok {
+		if t.buckets == nil {
+			t.buckets = make(map[string]*bucket)
+		}
+		t.sweep(now)
+		b = &bucket{tokens: burst, at: now}
+		t.buckets[host] = b
+	}
+	b.tokens = min(burst, b.tokens+now.Sub(b.at).Seconds()*rate)
+	b.at = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / rate * float64(time.Second))
+}
+
+// cancel returns a token which was not used.
+func (t *Transport) cancel(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if b, ok := t.buckets[host]; ok {
+		b.tokens++
+	}
+}
+
+// sweep removes the buckets which are full, as they are equivalent to new
+// ones. The caller must hold mu.
+func (t *Transport) sweep(now time.Time) {
+	if len(t.buckets) < 64 {
+		return
+	}
+	burst := float64(max(t.Burst, 1))
+	for host, b := range t.buckets {
+		if b.tokens+now.Sub(b.at).Seconds()*t.rate(host) >= burst {
+			delete(t.buckets, host)
+		}
+	}
+}
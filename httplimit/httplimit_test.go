@@ -0,0 +1,78 @@
+package httplimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReserve(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := &Transport{
+		Rate:     2,
+		Burst:    3,
+		HostRate: map[string]float64{"unlimited.example": 0},
+		Now:      func() time.Time { return now },
+	}
+
+	for i := range 3 {
+		if wait := tr.reserve("example.com"); wait != 0 {
+			t.Errorf("burst request %d got wait %s", i, wait)
+		}
+	}
+	if wait := tr.reserve("example.com"); wait != 500*time.Millisecond {
+		t.Errorf("got wait %s, want 500ms", wait)
+	}
+	if wait := tr.reserve("example.com"); wait != time.Second {
+		t.Errorf("queued request got wait %s, want 1s", wait)
+	}
+	if wait := tr.reserve("other.example"); wait != 0 {
+		t.Errorf("other host got wait %s", wait)
+	}
+	if wait := tr.reserve("unlimited.example"); wait != 0 {
+		t.Errorf("unlimited host got wait %s", wait)
+	}
+
+	now = now.Add(time.Minute)
+	if wait := tr.reserve("example.com"); wait != 0 {
+		t.Errorf("refilled bucket got wait %s", wait)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &Transport{Rate: 1, MaxWait: 10 * time.Millisecond}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	_, err = client.Get(srv.URL)
+	if !errors.Is(err, ErrLimited) {
+		t.Errorf("got error %v, want %v", err, ErrLimited)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Transport.(*Transport).MaxWait = 0
+	_, err = client.Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if requests != 1 {
+		t.Errorf("server got %d requests, want 1", requests)
+	}
+}
@@ -0,0 +1,140 @@
+package did
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Reasons for a warning from Inspect. The input is valid, yet it may cause
+// trouble, e.g., with string comparison.
+var (
+	// WarnLowercaseHex means a percent-encoding uses lower-case
+	// hexadecimal digits, while upper-case is the normalized form.
+	WarnLowercaseHex = errors.New("lower-case hexadecimal in percent-encoding")
+	// WarnEmptySegment means the DID Path has an empty segment, like in
+	// "did:example:123/a//b" or "did:example:123/".
+	WarnEmptySegment = errors.New("empty path segment")
+	// WarnLongID means the method-specific-id exceeds the maximum of
+	// InspectOptions.
+	WarnLongID = errors.New("unusually long method-specific-id")
+	// WarnUnknownMethod means the method is not recognized by
+	// InspectOptions.
+	WarnUnknownMethod = errors.New("unknown method")
+)
+
+// InspectOptions configures Inspect.
+type InspectOptions struct {
+	// MaxIDLength is the number of bytes in a method-specific-id above
+	// which WarnLongID applies. Zero defaults to 256.
+	MaxIDLength int
+
+	// KnownMethod reports whether a method is recognized. Methods which
+	// are not get WarnUnknownMethod. The nil value defaults to
+	// IsRegisteredMethod.
+	KnownMethod func(method string) bool
+}
+
+// Inspection is the outcome of Inspect.
+type Inspection struct {
+	// URL is the parse result, or nil when there are Errors.
+	URL *DIDURL
+
+	// Errors has each violation of the syntax, in order of appearance.
+	Errors []*ParseError
+
+	// Warnings has each non-fatal issue, in order of appearance.
+	Warnings []*ParseError
+}
+
+// Inspect is like ParseURL, yet it reports all errors instead of the first,
+// and it reports warnings for valid input that is likely to cause trouble.
+func Inspect(input string, opts InspectOptions) *Inspection {
+	errs := parseErrors{all: true}
+	u := parseURL(input, &errs)
+
+	var insp Inspection
+	for _, err := range errs.list {
+		insp.Errors = append(insp.Errors, err.(*ParseError))
+	}
+	slices.SortStableFunc(insp.Errors, func(a, b *ParseError) int {
+		return a.Offset - b.Offset
+	})
+	if u == nil {
+		return &insp
+	}
+	if len(insp.Errors) == 0 {
+		insp.URL = u
+	}
+
+	warn := func(offset int, c Component, err error) {
+		insp.Warnings = append(insp.Warnings, newParseError(input, offset, c, err))
+	}
+	warnHex := func(s string, offset int, c Component) {
+		for i := 0; i+2 < len(s); i++ {
+			if s[i] != '%' {
+				continue
+			}
+			if isLowerHex(s[i+1]) || isLowerHex(s[i+2]) {
+				warn(offset+i, c, WarnLowercaseHex)
+			}
+			i += 2
+		}
+	}
+
+	knownMethod := opts.KnownMethod
+	if knownMethod == nil {
+		knownMethod = IsRegisteredMethod
+	}
+	methodOK := !slices.ContainsFunc(insp.Errors, func(e *ParseError) bool {
+		return e.Component == ComponentMethod
+	})
+	offset := len("did:")
+	if methodOK && !knownMethod(u.Method) {
+		warn(offset, ComponentMethod, WarnUnknownMethod)
+	}
+	offset += len(u.Method) + 1
+
+	maxIDLength := opts.MaxIDLength
+	if maxIDLength == 0 {
+		maxIDLength = 256
+	}
+	// the method-specific-id as written, as ID has it decoded
+	id := input[offset:]
+	if i := strings.IndexAny(id, "/?#"); i >= 0 {
+		id = id[:i]
+	}
+	if len(id) > maxIDLength {
+		warn(offset+maxIDLength, ComponentID, WarnLongID)
+	}
+	warnHex(id, offset, ComponentID)
+	offset += len(id)
+
+	if offset < len(input) && input[offset] == '/' {
+		offset++
+		segOffset := offset
+		for _, seg := range strings.Split(u.Path, "/") {
+			if seg == "" {
+				warn(segOffset, ComponentPath, WarnEmptySegment)
+			}
+			segOffset += len(seg) + 1
+		}
+		warnHex(u.Path, offset, ComponentPath)
+		offset += len(u.Path)
+	}
+	if offset < len(input) && input[offset] == '?' {
+		offset++
+		warnHex(u.Query, offset, ComponentQuery)
+		offset += len(u.Query)
+	}
+	if offset < len(input) && input[offset] == '#' {
+		offset++
+		warnHex(u.Fragment, offset, ComponentFragment)
+	}
+
+	return &insp
+}
+
+func isLowerHex(c byte) bool {
+	return c >= 'a' && c <= 'f'
+}
@@ -0,0 +1,76 @@
+package did
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	type issue struct {
+		Err       error
+		Component Component
+		Offset    int
+	}
+	issues := func(list []*ParseError) []issue {
+		var a []issue
+		for _, e := range list {
+			a = append(a, issue{e.Err, e.Component, e.Offset})
+		}
+		return a
+	}
+	example := InspectOptions{KnownMethod: func(method string) bool { return method == "example" }}
+
+	t.Run("clean", func(t *testing.T) {
+		insp := Inspect("did:example:123%3A456/a/b?q%2F#f", example)
+		assert(t, "did:example:123%3A456/a/b?q%2F#f", insp.URL.String())
+		assert(t, 0, len(insp.Errors))
+		assert(t, 0, len(insp.Warnings))
+	})
+
+	t.Run("warnings", func(t *testing.T) {
+		known := func(method string) bool { return method == "example" }
+		insp := Inspect("did:other:a%3ab//c/?%2f#%aA", InspectOptions{KnownMethod: known})
+		assert(t, "did:other:a%3ab//c/?%2f#%aA", insp.URL.String())
+		assert(t, 0, len(insp.Errors))
+		assert(t, []issue{
+			{WarnUnknownMethod, ComponentMethod, 4},
+			{WarnLowercaseHex, ComponentID, 11},
+			{WarnEmptySegment, ComponentPath, 16},
+			{WarnEmptySegment, ComponentPath, 19},
+			{WarnLowercaseHex, ComponentQuery, 20},
+			{WarnLowercaseHex, ComponentFragment, 24},
+		}, issues(insp.Warnings))
+	})
+
+	t.Run("registered methods by default", func(t *testing.T) {
+		insp := Inspect("did:example:123", InspectOptions{})
+		assert(t, []issue{{WarnUnknownMethod, ComponentMethod, 4}}, issues(insp.Warnings))
+
+		insp = Inspect("did:web:example.com", InspectOptions{})
+		assert(t, 0, len(insp.Warnings))
+	})
+
+	t.Run("long ID", func(t *testing.T) {
+		input := "did:example:" + strings.Repeat("x", 300)
+		insp := Inspect(input, example)
+		assert(t, []issue{{WarnLongID, ComponentID, 12 + 256}}, issues(insp.Warnings))
+
+		insp = Inspect(input, InspectOptions{MaxIDLength: 300, KnownMethod: example.KnownMethod})
+		assert(t, 0, len(insp.Warnings))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		insp := Inspect("did:example:1 2/%2f#x y", example)
+		assert(t, true, insp.URL == nil)
+		assert(t, []issue{
+			{ErrInvalidIDChar, ComponentID, 13},
+			{ErrInvalidFragmentChar, ComponentFragment, 21},
+		}, issues(insp.Errors))
+		assert(t, []issue{{WarnLowercaseHex, ComponentPath, 16}}, issues(insp.Warnings))
+
+		insp = Inspect("urn:example:1", InspectOptions{})
+		assert(t, true, insp.URL == nil)
+		assert(t, []issue{{ErrMissingScheme, ComponentScheme, 0}}, issues(insp.Errors))
+		assert(t, 0, len(insp.Warnings))
+	})
+}
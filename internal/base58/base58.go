@@ -0,0 +1,69 @@
+// Package base58 implements the Bitcoin alphabet of base58 encoding.
+package base58
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Alphabet is the Bitcoin alphabet.
+const Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ErrInvalidChar means the encoding has a character outside the Alphabet.
+var ErrInvalidChar = errors.New("base58: invalid character")
+
+var decodeMap = func() (m [256]int8) {
+	for i := range m {
+		m[i] = -1
+	}
+	for i := 0; i < len(Alphabet); i++ {
+		m[Alphabet[i]] = int8(i)
+	}
+	return
+}()
+
+var radix = big.NewInt(58)
+
+// Encode returns the base58 encoding of data. Each leading zero byte encodes
+// as a leading '1'.
+func Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(data)
+	mod := new(big.Int)
+	var buf []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		buf = append(buf, Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		buf = append(buf, '1')
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
+
+// Decode returns the data of a base58 encoding.
+func Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	n := new(big.Int)
+	digit := new(big.Int)
+	for i := zeros; i < len(s); i++ {
+		v := decodeMap[s[i]]
+		if v < 0 {
+			return nil, ErrInvalidChar
+		}
+		n.Mul(n, radix)
+		n.Add(n, digit.SetInt64(int64(v)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,36 @@
+package base58
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		data    []byte
+		encoded string
+	}{
+		{nil, ""},
+		{[]byte{0}, "1"},
+		{[]byte{0, 0, 1}, "112"},
+		{[]byte("Hello World!"), "2NEpo7TZRRrLZSi2U"},
+		{[]byte{0x00, 0x00, 0x28, 0x7f, 0xb4, 0xcd}, "11233QC4"},
+	}
+	for _, test := range tests {
+		if got := Encode(test.data); got != test.encoded {
+			t.Errorf("%#x got encoding %q, want %q", test.data, got, test.encoded)
+		}
+		got, err := Decode(test.encoded)
+		if err != nil {
+			t.Errorf("%q got error: %s", test.encoded, err)
+		} else if !bytes.Equal(got, test.data) && len(test.data) != 0 {
+			t.Errorf("%q got data %#x, want %#x", test.encoded, got, test.data)
+		}
+	}
+
+	for _, s := range []string{"0", "O", "I", "l", "abc+"} {
+		if _, err := Decode(s); err != ErrInvalidChar {
+			t.Errorf("%q got error %v, want %v", s, err, ErrInvalidChar)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package bech32 implements the bech32 (BIP 173) and the bech32m (BIP 350)
+// encoding.
+package bech32
+
+import (
+	"errors"
+	"strings"
+)
+
+// Charset maps 5-bit values to characters.
+const Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Variant selects the checksum constant.
+type Variant uint32
+
+// Checksum variants.
+const (
+	Bech32  Variant = 1
+	Bech32m Variant = 0x2bc830a3
+)
+
+// Errors from Decode.
+var (
+	ErrInvalidChar = errors.New("bech32: invalid character")
+	ErrMixedCase   = errors.New("bech32: mixed case")
+	ErrNoSeparator = errors.New("bech32: no separator")
+	ErrChecksum    = errors.New("bech32: checksum mismatch")
+)
+
+var decodeMap = func() (m [256]int8) {
+	for i := range m {
+		m[i] = -1
+	}
+	for i := 0; i < len(Charset); i++ {
+		m[Charset[i]] = int8(i)
+	}
+	return
+}()
+
+func polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := range gen {
+			if (b>>i)&1 != 0 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	b := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		b = append(b, hrp[i]>>5)
+	}
+	b = append(b, 0)
+	for i := 0; i < len(hrp); i++ {
+		b = append(b, hrp[i]&31)
+	}
+	return b
+}
+
+// Checksum returns the six 5-bit checksum values of data.
+func Checksum(hrp string, data []byte, v Variant) [6]byte {
+	values := append(hrpExpand(hrp), data...)
+	mod := polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ uint32(v)
+	var sum [6]byte
+	for i := range sum {
+		sum[i] = byte(mod>>(5*(5-i))) & 31
+	}
+	return sum
+}
+
+// Encode returns the encoding of the 5-bit values with a lower-case hrp.
+func Encode(hrp string, data []byte, v Variant) string {
+	var buf strings.Builder
+	buf.Grow(len(hrp) + 1 + len(data) + 6)
+	buf.WriteString(hrp)
+	buf.WriteByte('1')
+	for _, b := range data {
+		buf.WriteByte(Charset[b])
+	}
+	sum := Checksum(hrp, data, v)
+	for _, b := range sum {
+		buf.WriteByte(Charset[b])
+	}
+	return buf.String()
+}
+
+// Decode returns the content of an encoding, with the 5-bit values and
+// without the checksum. The hrp is in lower case.
+func Decode(s string, v Variant) (hrp string, data []byte, err error) {
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, ErrMixedCase
+	}
+	i := strings.LastIndexByte(lower, '1')
+	if i < 1 || len(lower)-i-1 < 6 {
+		return "", nil, ErrNoSeparator
+	}
+	hrp = lower[:i]
+	for j := 0; j < len(hrp); j++ {
+		if hrp[j] < 33 || hrp[j] > 126 {
+			return "", nil, ErrInvalidChar
+		}
+	}
+	data = make([]byte, 0, len(lower)-i-1)
+	for j := i + 1; j < len(lower); j++ {
+		c := decodeMap[lower[j]]
+		if c < 0 {
+			return "", nil, ErrInvalidChar
+		}
+		data = append(data, byte(c))
+	}
+	if polymod(append(hrpExpand(hrp), data...)) != uint32(v) {
+		return "", nil, ErrChecksum
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// ConvertBits regroups the bits of data from groups of size from into
+// groups of size to. Any incomplete group fails unless pad is set.
+func ConvertBits(data []byte, from, to uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, b := range data {
+		if uint(b)>>from != 0 {
+			return nil, errors.New("bech32: value exceeds group size")
+		}
+		acc = acc<<from | uint(b)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, errors.New("bech32: incomplete group")
+	}
+	return out, nil
+}
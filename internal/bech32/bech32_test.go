@@ -0,0 +1,56 @@
+package bech32
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	// valid vectors from BIP 173 and BIP 350
+	tests := []struct {
+		s string
+		v Variant
+	}{
+		{"A12UEL5L", Bech32},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", Bech32},
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", Bech32},
+		{"A1LQFN3A", Bech32m},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", Bech32m},
+	}
+	for _, test := range tests {
+		hrp, data, err := Decode(test.s, test.v)
+		if err != nil {
+			t.Errorf("%q got error: %s", test.s, err)
+			continue
+		}
+		if got := Encode(hrp, data, test.v); got != strings.ToLower(test.s) {
+			t.Errorf("%q got re-encoding %q", test.s, got)
+		}
+	}
+
+	if _, _, err := Decode("A12UEL5L", Bech32m); err != ErrChecksum {
+		t.Errorf("bech32 as bech32m got error %v, want %v", err, ErrChecksum)
+	}
+	if _, _, err := Decode("a12UEL5L", Bech32); err != ErrMixedCase {
+		t.Errorf("got error %v, want %v", err, ErrMixedCase)
+	}
+}
+
+func TestConvertBits(t *testing.T) {
+	data := []byte{0xff, 0x00, 0x80}
+	five, err := ConvertBits(data, 8, 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(five) != 5 {
+		t.Errorf("got %d groups, want 5", len(five))
+	}
+	back, err := ConvertBits(five, 5, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(back, data) {
+		t.Errorf("got %#x, want %#x", back, data)
+	}
+}
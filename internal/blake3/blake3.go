@@ -0,0 +1,143 @@
+// Package blake3 implements the 256-bit BLAKE3 hash, as used by KERI.
+// https://github.com/BLAKE3-team/BLAKE3-specs
+package blake3
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	blockLen = 64
+	chunkLen = 1024
+)
+
+// domain flags
+const (
+	chunkStart = 1 << 0
+	chunkEnd   = 1 << 1
+	parent     = 1 << 2
+	root       = 1 << 3
+)
+
+var iv = [8]uint32{0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A, 0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19}
+
+var permutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+func g(s *[16]uint32, a, b, c, d int, mx, my uint32) {
+	s[a] += s[b] + mx
+	s[d] = bits.RotateLeft32(s[d]^s[a], -16)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -12)
+	s[a] += s[b] + my
+	s[d] = bits.RotateLeft32(s[d]^s[a], -8)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -7)
+}
+
+// compress returns the full output state of the compression function.
+func compress(cv *[8]uint32, block *[16]uint32, counter uint64, n uint32, flags uint32) [16]uint32 {
+	s := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		iv[0], iv[1], iv[2], iv[3],
+		uint32(counter), uint32(counter >> 32), n, flags,
+	}
+	m := *block
+	for round := 0; round < 7; round++ {
+		g(&s, 0, 4, 8, 12, m[0], m[1])
+		g(&s, 1, 5, 9, 13, m[2], m[3])
+		g(&s, 2, 6, 10, 14, m[4], m[5])
+		g(&s, 3, 7, 11, 15, m[6], m[7])
+		g(&s, 0, 5, 10, 15, m[8], m[9])
+		g(&s, 1, 6, 11, 12, m[10], m[11])
+		g(&s, 2, 7, 8, 13, m[12], m[13])
+		g(&s, 3, 4, 9, 14, m[14], m[15])
+		var next [16]uint32
+		for i, p := range permutation {
+			next[i] = m[p]
+		}
+		m = next
+	}
+	for i := 0; i < 8; i++ {
+		s[i] ^= s[i+8]
+		s[i+8] ^= cv[i]
+	}
+	return s
+}
+
+// node is a pending compression, either the last block of a chunk or a
+// parent.
+type node struct {
+	cv      [8]uint32
+	block   [16]uint32
+	counter uint64
+	n       uint32
+	flags   uint32
+}
+
+func (nd *node) chainingValue() (cv [8]uint32) {
+	s := compress(&nd.cv, &nd.block, nd.counter, nd.n, nd.flags)
+	copy(cv[:], s[:8])
+	return
+}
+
+func parentNode(left, right [8]uint32) node {
+	nd := node{cv: iv, n: blockLen, flags: parent}
+	copy(nd.block[:8], left[:])
+	copy(nd.block[8:], right[:])
+	return nd
+}
+
+// chunkNode compresses all but the last block of a chunk.
+func chunkNode(chunk []byte, counter uint64) node {
+	cv := iv
+	flags := uint32(chunkStart)
+	for len(chunk) > blockLen {
+		var block [16]uint32
+		for i := range block {
+			block[i] = binary.LittleEndian.Uint32(chunk[i*4:])
+		}
+		s := compress(&cv, &block, counter, blockLen, flags)
+		copy(cv[:], s[:8])
+		chunk = chunk[blockLen:]
+		flags = 0
+	}
+	var buf [blockLen]byte
+	copy(buf[:], chunk)
+	nd := node{cv: cv, counter: counter, n: uint32(len(chunk)), flags: flags | chunkEnd}
+	for i := range nd.block {
+		nd.block[i] = binary.LittleEndian.Uint32(buf[i*4:])
+	}
+	return nd
+}
+
+// Sum256 returns the BLAKE3 hash of data.
+func Sum256(data []byte) [32]byte {
+	var stack [][8]uint32
+	var counter uint64
+	for len(data) > chunkLen {
+		nd := chunkNode(data[:chunkLen], counter)
+		cv := nd.chainingValue()
+		counter++
+		// merge completed subtrees
+		for total := counter; total&1 == 0; total >>= 1 {
+			p := parentNode(stack[len(stack)-1], cv)
+			cv = p.chainingValue()
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, cv)
+		data = data[chunkLen:]
+	}
+
+	nd := chunkNode(data, counter)
+	for i := len(stack) - 1; i >= 0; i-- {
+		nd = parentNode(stack[i], nd.chainingValue())
+	}
+	nd.flags |= root
+	s := compress(&nd.cv, &nd.block, 0, nd.n, nd.flags)
+	var sum [32]byte
+	for i := 0; i < 8; i++ {
+		binary.LittleEndian.PutUint32(sum[i*4:], s[i])
+	}
+	return sum
+}
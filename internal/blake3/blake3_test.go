@@ -0,0 +1,36 @@
+package blake3
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestSum256(t *testing.T) {
+	// official test vectors, with input bytes repeating 0–250
+	tests := []struct {
+		n    int
+		hash string
+	}{
+		{0, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{1, "2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213"},
+		{1024, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+		{1025, "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444"},
+		{2048, "e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a"},
+		{3072, "b98cb0ff3623be03326b373de6b9095218513e64f1ee2edd2525c7ad1e5cffd2"},
+	}
+	for _, test := range tests {
+		data := make([]byte, test.n)
+		for i := range data {
+			data[i] = byte(i % 251)
+		}
+		sum := Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != test.hash {
+			t.Errorf("%d bytes got %s, want %s", test.n, got, test.hash)
+		}
+	}
+
+	sum := Sum256([]byte("abc"))
+	if got, want := hex.EncodeToString(sum[:]), "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"; got != want {
+		t.Errorf(`"abc" got %s, want %s`, got, want)
+	}
+}
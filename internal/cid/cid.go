@@ -0,0 +1,145 @@
+// Package cid implements content identifiers of IPFS and IPLD.
+// https://github.com/multiformats/cid
+package cid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ockam-network/did/internal/base58"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// Multicodec identifiers of content.
+const (
+	Raw       = 0x55
+	DagPB     = 0x70
+	DagCBOR   = 0x71
+	LibP2PKey = 0x72
+	DagJSON   = 0x0129
+	JSON      = 0x0200
+)
+
+// Multihash function identifiers.
+const (
+	Identity   = 0x00
+	SHA2_256   = 0x12
+	SHA2_512   = 0x13
+	SHA3_256   = 0x16
+	Blake2b256 = 0xb220
+)
+
+// digestSizes has the fixed sizes per hash function.
+var digestSizes = map[uint64]int{
+	SHA2_256:   32,
+	SHA2_512:   64,
+	SHA3_256:   32,
+	Blake2b256: 32,
+}
+
+// MaxIdentitySize limits inline content of the identity hash.
+const MaxIdentitySize = 128
+
+// CID is a decoded content identifier.
+type CID struct {
+	Version  int    // 0 or 1
+	Codec    uint64 // content multicodec
+	HashCode uint64 // multihash function
+	Digest   []byte
+}
+
+// Multihash returns the binary multihash.
+func (c *CID) Multihash() []byte {
+	buf := multibase.AppendCodec(nil, c.HashCode)
+	buf = multibase.AppendCodec(buf, uint64(len(c.Digest)))
+	return append(buf, c.Digest...)
+}
+
+// String returns the canonical notation: base58 for version 0, and base32
+// for version 1.
+func (c *CID) String() string {
+	if c.Version == 0 {
+		return base58.Encode(c.Multihash())
+	}
+	buf := multibase.AppendCodec([]byte{1}, c.Codec)
+	return multibase.Encode(multibase.Base32, append(buf, c.Multihash()...))
+}
+
+// ErrCID means a content identifier is malformed.
+var ErrCID = errors.New("cid: malformed content identifier")
+
+// Parse decodes either a CIDv0, as base58 of a SHA2-256 multihash, or a
+// CIDv1 in a multibase encoding.
+func Parse(s string) (*CID, error) {
+	if len(s) == 46 && strings.HasPrefix(s, "Qm") {
+		data, err := base58.Decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCID, err)
+		}
+		c := &CID{Version: 0, Codec: DagPB}
+		if err := c.readMultihash(data); err != nil {
+			return nil, err
+		}
+		if c.HashCode != SHA2_256 {
+			return nil, fmt.Errorf("%w: CIDv0 not SHA2-256", ErrCID)
+		}
+		return c, nil
+	}
+
+	data, err := multibase.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCID, err)
+	}
+	version, rest, err := multibase.SplitCodec(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCID, err)
+	}
+	if version != 1 {
+		return nil, fmt.Errorf("%w: version %d not supported", ErrCID, version)
+	}
+	c := &CID{Version: 1}
+	c.Codec, rest, err = multibase.SplitCodec(rest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCID, err)
+	}
+	if err := c.readMultihash(rest); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ParseMultihash decodes a bare multihash in base58, as in legacy peer IDs.
+func ParseMultihash(s string) (*CID, error) {
+	data, err := base58.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCID, err)
+	}
+	c := &CID{Version: 0, Codec: LibP2PKey}
+	if err := c.readMultihash(data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *CID) readMultihash(data []byte) error {
+	code, rest, err := multibase.SplitCodec(data)
+	if err != nil {
+		return fmt.Errorf("%w: multihash: %w", ErrCID, err)
+	}
+	size, digest, err := multibase.SplitCodec(rest)
+	if err != nil {
+		return fmt.Errorf("%w: multihash: %w", ErrCID, err)
+	}
+	if uint64(len(digest)) != size {
+		return fmt.Errorf("%w: multihash has %d-byte digest, want %d", ErrCID, len(digest), size)
+	}
+	if want, ok := digestSizes[code]; ok && int(size) != want {
+		return fmt.Errorf("%w: multihash %#x with %d-byte digest, want %d", ErrCID, code, size, want)
+	}
+	if code == Identity && size > MaxIdentitySize {
+		return fmt.Errorf("%w: identity multihash exceeds %d bytes", ErrCID, MaxIdentitySize)
+	}
+	c.HashCode, c.Digest = code, digest
+	return nil
+}
@@ -0,0 +1,39 @@
+package cid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s       string
+		version int
+		codec   uint64
+		hash    uint64
+	}{
+		{"QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG", 0, DagPB, SHA2_256},
+		{"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi", 1, DagPB, SHA2_256},
+		{"bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua", 1, DagCBOR, SHA2_256},
+		{"k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8", 1, LibP2PKey, Identity},
+	}
+	for _, test := range tests {
+		c, err := Parse(test.s)
+		if err != nil {
+			t.Errorf("%s got error: %s", test.s, err)
+			continue
+		}
+		if c.Version != test.version || c.Codec != test.codec || c.HashCode != test.hash {
+			t.Errorf("%s got version %d, codec %#x and hash %#x", test.s, c.Version, c.Codec, c.HashCode)
+		}
+		if test.s[0] != 'k' && c.String() != test.s {
+			t.Errorf("%s got string %s", test.s, c.String())
+		}
+	}
+
+	for _, s := range []string{"", "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPb", "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbz", "zQmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"} {
+		if _, err := Parse(s); !errors.Is(err, ErrCID) {
+			t.Errorf("%q got error %v, want %v", s, err, ErrCID)
+		}
+	}
+}
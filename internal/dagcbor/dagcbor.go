@@ -0,0 +1,260 @@
+// Package dagcbor encodes JSON values in the deterministic DAG-CBOR form of
+// IPLD, and it computes their content identifiers. Decoding covers the JSON
+// data model only.
+// https://ipld.io/specs/codecs/dag-cbor/spec/
+package dagcbor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Marshal returns the encoding of a JSON value, as decoded by encoding/json
+// into an interface, with json.Number for numbers. Numbers must be integers.
+func Marshal(v any) ([]byte, error) {
+	return appendValue(nil, v)
+}
+
+// MarshalJSON returns the encoding of a JSON text.
+func MarshalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("dagcbor: %w", err)
+	}
+	return Marshal(v)
+}
+
+func appendHead(dst []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= 0xff:
+		return append(dst, major|24, byte(n))
+	case n <= 0xffff:
+		return append(dst, major|25, byte(n>>8), byte(n))
+	case n <= 0xffffffff:
+		return append(dst, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(dst, major|27, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func appendValue(dst []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(dst, 0xf6), nil
+	case bool:
+		if v {
+			return append(dst, 0xf5), nil
+		}
+		return append(dst, 0xf4), nil
+	case json.Number:
+		if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendHead(dst, 0, n), nil
+		}
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return appendHead(dst, 1, uint64(-1-n)), nil
+		}
+		return nil, fmt.Errorf("dagcbor: number %s not an integer", v)
+	case string:
+		dst = appendHead(dst, 3, uint64(len(v)))
+		return append(dst, v...), nil
+	case []byte:
+		dst = appendHead(dst, 2, uint64(len(v)))
+		return append(dst, v...), nil
+	case []any:
+		dst = appendHead(dst, 4, uint64(len(v)))
+		for _, e := range v {
+			var err error
+			dst, err = appendValue(dst, e)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		// length first, then bytewise
+		slices.SortFunc(keys, func(a, b string) int {
+			if len(a) != len(b) {
+				return len(a) - len(b)
+			}
+			return strings.Compare(a, b)
+		})
+		dst = appendHead(dst, 5, uint64(len(v)))
+		for _, k := range keys {
+			dst = appendHead(dst, 3, uint64(len(k)))
+			dst = append(dst, k...)
+			var err error
+			dst, err = appendValue(dst, v[k])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	default:
+		return nil, fmt.Errorf("dagcbor: unsupported type %T", v)
+	}
+}
+
+// ErrSyntax means the input is not CBOR of the JSON data model.
+var ErrSyntax = errors.New("dagcbor: malformed or unsupported CBOR")
+
+// maxDepth limits the nesting of arrays and maps.
+const maxDepth = 64
+
+// Unmarshal decodes a single value into the types of Marshal, with floats
+// as json.Number too. Byte strings, tags and map keys other than text are
+// not in the JSON data model, and they are denied.
+func Unmarshal(data []byte) (any, error) {
+	v, rest, err := decodeValue(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: %d bytes after value", ErrSyntax, len(rest))
+	}
+	return v, nil
+}
+
+// UnmarshalJSON returns the JSON text of a single value.
+func UnmarshalJSON(data []byte) ([]byte, error) {
+	v, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// decodeHead reads the major type with its argument.
+func decodeHead(data []byte) (major byte, n uint64, rest []byte, err error) {
+	if len(data) == 0 {
+		return 0, 0, nil, fmt.Errorf("%w: unexpected end", ErrSyntax)
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+	switch {
+	case info < 24:
+		return major, uint64(info), data, nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < size {
+			return 0, 0, nil, fmt.Errorf("%w: unexpected end", ErrSyntax)
+		}
+		for _, b := range data[:size] {
+			n = n<<8 | uint64(b)
+		}
+		return major, n, data[size:], nil
+	default:
+		return 0, 0, nil, fmt.Errorf("%w: indefinite length", ErrSyntax)
+	}
+}
+
+func decodeValue(data []byte, depth int) (v any, rest []byte, err error) {
+	if depth > maxDepth {
+		return nil, nil, fmt.Errorf("%w: nesting exceeds %d", ErrSyntax, maxDepth)
+	}
+	if len(data) != 0 && data[0] == 0xfb {
+		if len(data) < 9 {
+			return nil, nil, fmt.Errorf("%w: unexpected end", ErrSyntax)
+		}
+		f := math.Float64frombits(binary.BigEndian.Uint64(data[1:9]))
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, nil, fmt.Errorf("%w: float %v", ErrSyntax, f)
+		}
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), data[9:], nil
+	}
+
+	major, n, data, err := decodeHead(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch major {
+	case 0:
+		return json.Number(strconv.FormatUint(n, 10)), data, nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, nil, fmt.Errorf("%w: negative integer out of range", ErrSyntax)
+		}
+		return json.Number(strconv.FormatInt(-1-int64(n), 10)), data, nil
+	case 3:
+		if uint64(len(data)) < n {
+			return nil, nil, fmt.Errorf("%w: unexpected end", ErrSyntax)
+		}
+		return string(data[:n]), data[n:], nil
+	case 4:
+		if n > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: unexpected end", ErrSyntax)
+		}
+		a := make([]any, n)
+		for i := range a {
+			a[i], data, err = decodeValue(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return a, data, nil
+	case 5:
+		if n > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: unexpected end", ErrSyntax)
+		}
+		m := make(map[string]any, n)
+		for range n {
+			k, rest, err := decodeValue(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: map key not text", ErrSyntax)
+			}
+			if _, ok := m[key]; ok {
+				return nil, nil, fmt.Errorf("%w: duplicate map key %q", ErrSyntax, key)
+			}
+			m[key], data, err = decodeValue(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return m, data, nil
+	case 7:
+		switch n {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22:
+			return nil, data, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: major type %d with argument %d", ErrSyntax, major, n)
+}
+
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// CID returns the CIDv1 of an encoding, with the SHA2-256 multihash, in the
+// base32 multibase.
+func CID(encoding []byte) string {
+	sum := sha256.Sum256(encoding)
+	return "b" + base32Lower.EncodeToString(append([]byte{0x01, 0x71, 0x12, 0x20}, sum[:]...))
+}
+
+// Base32 returns the lower-case base32 of data, without padding.
+func Base32(data []byte) string {
+	return base32Lower.EncodeToString(data)
+}
@@ -0,0 +1,74 @@
+package dagcbor
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct{ json, cbor string }{
+		{`null`, "f6"},
+		{`[true,false]`, "82f5f4"},
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000`, "1903e8"},
+		{`-1`, "20"},
+		{`-1000`, "3903e7"},
+		{`"a"`, "6161"},
+		// keys in length-first order
+		{`{"bb":1,"a":2,"c":3}`, "a3616102616303626262" + "01"},
+	}
+	for _, test := range tests {
+		got, err := MarshalJSON([]byte(test.json))
+		if err != nil {
+			t.Errorf("%s got error: %s", test.json, err)
+			continue
+		}
+		if s := hex.EncodeToString(got); s != test.cbor {
+			t.Errorf("%s got %s, want %s", test.json, s, test.cbor)
+		}
+	}
+
+	if _, err := MarshalJSON([]byte(`1.5`)); err == nil {
+		t.Error("float got no error")
+	}
+}
+
+func TestCID(t *testing.T) {
+	// CID of the empty map
+	const want = "bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua"
+	if got := CID([]byte{0xa0}); got != want {
+		t.Errorf("got CID %q, want %q", got, want)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	for _, s := range []string{`null`, `[true,false]`, `0`, `23`, `1000`, `-1`, `-1000`, `"a"`, `{"a":[{"b":null}],"bb":1}`} {
+		encoded, err := MarshalJSON([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := UnmarshalJSON(encoded)
+		if err != nil {
+			t.Errorf("%s got error: %s", s, err)
+			continue
+		}
+		if string(got) != s {
+			t.Errorf("%s got %s", s, got)
+		}
+	}
+
+	// float64 of 1.5
+	if got, err := UnmarshalJSON([]byte{0xfb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}); err != nil || string(got) != "1.5" {
+		t.Errorf("float got %s, %v", got, err)
+	}
+
+	for _, hexInput := range []string{"", "42ffff", "a10101", "a2616101616102", "9f", "c11a514b67b0", "1818ff", "6261"} {
+		data, _ := hex.DecodeString(hexInput)
+		if v, err := Unmarshal(data); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%s got %v, %v, want %v", hexInput, v, err, ErrSyntax)
+		}
+	}
+}
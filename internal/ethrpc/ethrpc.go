@@ -0,0 +1,203 @@
+// Package ethrpc provides the subset of the Ethereum JSON-RPC API in use by
+// DID methods.
+package ethrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ockam-network/did/internal/keccak"
+)
+
+// Client calls an Ethereum node.
+type Client struct {
+	// Endpoint is the URL of the node.
+	Endpoint string
+
+	// HTTP does the requests. The nil value defaults to
+	// http.DefaultClient.
+	HTTP *http.Client
+
+	seq atomic.Uint64
+}
+
+// An Error is a failure reported by the node.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("ethrpc: node error %d: %s", e.Code, e.Message)
+}
+
+// Call invokes a method, and it decodes the result into v.
+func (c *Client) Call(ctx context.Context, v any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      c.seq.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ethrpc: %s %s got HTTP %q", method, c.Endpoint, resp.Status)
+	}
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 32<<20)).Decode(&reply); err != nil {
+		return fmt.Errorf("ethrpc: %s reply: %w", method, err)
+	}
+	if reply.Error != nil {
+		return reply.Error
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(reply.Result, v)
+}
+
+// EthCall executes a contract call on the latest block, and it returns the
+// output.
+func (c *Client) EthCall(ctx context.Context, to string, data []byte) ([]byte, error) {
+	var out string
+	err := c.Call(ctx, &out, "eth_call", map[string]string{"to": to, "data": EncodeHex(data)}, "latest")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeHex(out)
+}
+
+// A Log is an event emitted by a contract.
+type Log struct {
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+	BlockNumber string   `json:"blockNumber"`
+}
+
+// Logs returns the events of a contract within a single block, filtered by
+// topics.
+func (c *Client) Logs(ctx context.Context, contract string, block uint64, topics ...any) ([]Log, error) {
+	var logs []Log
+	err := c.Call(ctx, &logs, "eth_getLogs", map[string]any{
+		"address":   contract,
+		"fromBlock": EncodeUint(block),
+		"toBlock":   EncodeUint(block),
+		"topics":    topics,
+	})
+	return logs, err
+}
+
+// BlockTime returns the timestamp of a block in Unix seconds.
+func (c *Client) BlockTime(ctx context.Context, block uint64) (int64, error) {
+	var header struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := c.Call(ctx, &header, "eth_getBlockByNumber", EncodeUint(block), false); err != nil {
+		return 0, err
+	}
+	ts, err := DecodeUint(header.Timestamp)
+	return int64(ts), err
+}
+
+// EncodeHex returns the 0x-prefixed hexadecimals of data.
+func EncodeHex(data []byte) string {
+	return "0x" + hex.EncodeToString(data)
+}
+
+// DecodeHex returns the data of 0x-prefixed hexadecimals.
+func DecodeHex(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, fmt.Errorf("ethrpc: hex %q without 0x prefix", s)
+	}
+	return hex.DecodeString(s[2:])
+}
+
+// EncodeUint returns the quantity encoding of n.
+func EncodeUint(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+// DecodeUint returns the number of a quantity encoding.
+func DecodeUint(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, fmt.Errorf("ethrpc: quantity %q without 0x prefix", s)
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
+
+// Selector returns the function selector of a signature, like
+// "changed(address)".
+func Selector(signature string) []byte {
+	sum := keccak.Sum256([]byte(signature))
+	return sum[:4]
+}
+
+// Topic returns the event topic of a signature.
+func Topic(signature string) string {
+	sum := keccak.Sum256([]byte(signature))
+	return EncodeHex(sum[:])
+}
+
+// ErrAddress means an address is not 0x with 40 hexadecimals.
+var ErrAddress = errors.New("ethrpc: malformed address")
+
+// AddressWord returns the ABI encoding of an address.
+func AddressWord(address string) ([]byte, error) {
+	b, err := DecodeHex(address)
+	if err != nil || len(b) != 20 {
+		return nil, ErrAddress
+	}
+	return append(make([]byte, 12), b...), nil
+}
+
+// ChecksumAddress returns the mixed-case encoding of EIP-55.
+func ChecksumAddress(address []byte) string {
+	lower := hex.EncodeToString(address)
+	sum := keccak.Sum256([]byte(lower))
+	buf := []byte(lower)
+	for i, c := range buf {
+		nibble := sum[i/2] >> 4
+		if i&1 == 1 {
+			nibble = sum[i/2] & 0xf
+		}
+		if c >= 'a' && nibble >= 8 {
+			buf[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(buf)
+}
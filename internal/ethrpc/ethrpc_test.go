@@ -0,0 +1,66 @@
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChecksumAddress(t *testing.T) {
+	// test vectors from EIP-55
+	for _, want := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		b, err := DecodeHex(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ChecksumAddress(b); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params []json.RawMessage
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "eth_call":
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x00ff"}`))
+		default:
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{Endpoint: srv.URL}
+	out, err := c.EthCall(context.Background(), "0xdca7ef03e98e0dc2b855be647c39abe984fcf21b", Selector("changed(address)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "\x00\xff" {
+		t.Errorf("got output %#x, want 0x00ff", out)
+	}
+
+	_, err = c.BlockTime(context.Background(), 1)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Errorf("got error %v, want node error -32601", err)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	if got := EncodeHex(Selector("transfer(address,uint256)")); got != "0xa9059cbb" {
+		t.Errorf("got selector %s, want 0xa9059cbb", got)
+	}
+}
@@ -0,0 +1,113 @@
+// Command genmethods generates the table of registered DID methods from a
+// checkout of the DID Specification Registries, which has a JSON file for
+// each method in its methods directory.
+// https://github.com/w3c/did-spec-registries
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	registryFlag = flag.String("registry", "did-spec-registries", "path to the registries `checkout`")
+	outputFlag   = flag.String("o", "methods_table.go", "output `file`")
+)
+
+// registration is the JSON of a method file.
+type registration struct {
+	Name                   string `json:"name"`
+	Status                 string `json:"status"`
+	VerifiableDataRegistry string `json:"verifiableDataRegistry"`
+	Specification          string `json:"specification"`
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	paths, err := filepath.Glob(filepath.Join(*registryFlag, "methods", "*.json"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(paths) == 0 {
+		log.Fatalf("genmethods: no method files in %s", filepath.Join(*registryFlag, "methods"))
+	}
+
+	var regs []registration
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var r registration
+		if err := json.Unmarshal(data, &r); err != nil {
+			log.Fatalf("genmethods: %s: %s", path, err)
+		}
+		r.Name = strings.TrimPrefix(r.Name, "did:")
+		if !validName(r.Name) {
+			log.Printf("genmethods: %s: method name %q skipped", path, r.Name)
+			continue
+		}
+		regs = append(regs, r)
+	}
+	slices.SortFunc(regs, func(a, b registration) int { return strings.Compare(a.Name, b.Name) })
+	regs = slices.CompactFunc(regs, func(a, b registration) bool { return a.Name == b.Name })
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by genmethods; DO NOT EDIT.\n\npackage did\n\n")
+	fmt.Fprintf(&buf, "var registeredMethods = [...]RegisteredMethod{\n")
+	for _, r := range regs {
+		fmt.Fprintf(&buf, "\t{%q, %q, %q, %s},\n", r.Name, r.Status, strings.TrimSpace(r.Specification), classify(r.VerifiableDataRegistry))
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*outputFlag, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// validName returns whether name consists of method-chars only.
+func validName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// classify maps the free-form verifiable data registry to a Network
+// constant. The order of the checks matters, as registrations commonly name
+// more than one technology.
+func classify(registry string) string {
+	s := strings.ToLower(registry)
+	switch {
+	case s == "", s == "none", s == "n/a", strings.Contains(s, "self-certifying"), strings.Contains(s, "peer"):
+		return "NetworkNone"
+	case strings.Contains(s, "ipfs"):
+		return "NetworkIPFS"
+	case strings.Contains(s, "dns"):
+		return "NetworkDNS"
+	case strings.Contains(s, "web"), strings.Contains(s, "http"):
+		return "NetworkWeb"
+	case strings.Contains(s, "chain"), strings.Contains(s, "ledger"), strings.Contains(s, "dlt"),
+		strings.Contains(s, "bitcoin"), strings.Contains(s, "ethereum"), strings.Contains(s, "hashgraph"),
+		strings.Contains(s, "tangle"), strings.Contains(s, "indy"), strings.Contains(s, "sovrin"):
+		return "NetworkLedger"
+	default:
+		return "NetworkOther"
+	}
+}
@@ -0,0 +1,30 @@
+package jcs
+
+import "testing"
+
+func TestTransform(t *testing.T) {
+	tests := []struct{ in, out string }{
+		{`{ "b" : 2, "a" : [ true, null, "x" ] }`, `{"a":[true,null,"x"],"b":2}`},
+		{`{"\u20ac":1,"\r":2,"\ud83d\ude00":3,"\u0080":4,"1":5,"10":6}`, "{\"\\r\":2,\"1\":5,\"10\":6,\"\u0080\":4,\"\u20ac\":1,\"\U0001F600\":3}"},
+		{`"<\u2028>\u001f"`, "\"<\u2028>\\u001f\""},
+		// numbers from RFC 8785, appendix B
+		{`[333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001, -0, 1e21, 1e20, 1e-7, 1e-6]`,
+			`[333333333.3333333,1e+30,4.5,0.002,1e-27,0,1e+21,100000000000000000000,1e-7,0.000001]`},
+	}
+	for _, test := range tests {
+		got, err := Transform([]byte(test.in))
+		if err != nil {
+			t.Errorf("%s got error: %s", test.in, err)
+			continue
+		}
+		if string(got) != test.out {
+			t.Errorf("%s got %s, want %s", test.in, got, test.out)
+		}
+	}
+
+	for _, in := range []string{`{`, `1 2`, `1e400`} {
+		if _, err := Transform([]byte(in)); err == nil {
+			t.Errorf("%s got no error", in)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package keccak
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSum256(t *testing.T) {
+	tests := []struct{ data, hash string }{
+		{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+		{"transfer(address,uint256)", "a9059cbb2ab09eb219583f4a59a5d0623ade346d962bcd4e46b11da047c9049b"},
+	}
+	for _, test := range tests {
+		sum := Sum256([]byte(test.data))
+		if got := hex.EncodeToString(sum[:]); got != test.hash {
+			t.Errorf("%q got %s, want %s", test.data, got, test.hash)
+		}
+	}
+
+	// multiple blocks
+	long := strings.Repeat("a", 3*rate+7)
+	a, b := Sum256([]byte(long)), Sum256([]byte(long[:len(long)-1]))
+	if a == b {
+		t.Error("collision on block boundary")
+	}
+}
@@ -0,0 +1,140 @@
+// Package multibase implements the multibase encodings in use with DIDs.
+// https://datatracker.ietf.org/doc/html/draft-multiformats-multibase
+package multibase
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ockam-network/did/internal/base58"
+)
+
+// Encoding prefixes.
+const (
+	Base32       = 'b'
+	Base36       = 'k'
+	Base58BTC    = 'z'
+	Base64URL    = 'u'
+	Base64URLPad = 'U'
+)
+
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// ErrEmpty means the encoding has no prefix.
+var ErrEmpty = errors.New("multibase: empty encoding")
+
+// Encode returns the encoding of data with the given prefix. It panics on
+// unsupported prefixes.
+func Encode(prefix byte, data []byte) string {
+	switch prefix {
+	case Base32:
+		return string(prefix) + base32Lower.EncodeToString(data)
+	case Base36:
+		return string(prefix) + encodeBase36(data)
+	case Base58BTC:
+		return string(prefix) + base58.Encode(data)
+	case Base64URL:
+		return string(prefix) + base64.RawURLEncoding.EncodeToString(data)
+	case Base64URLPad:
+		return string(prefix) + base64.URLEncoding.EncodeToString(data)
+	default:
+		panic(fmt.Sprintf("multibase: unsupported prefix %q", prefix))
+	}
+}
+
+// Decode returns the data of an encoding.
+func Decode(s string) ([]byte, error) {
+	if s == "" {
+		return nil, ErrEmpty
+	}
+	switch s[0] {
+	case Base32:
+		return base32Lower.DecodeString(s[1:])
+	case Base36:
+		return decodeBase36(s[1:])
+	case Base58BTC:
+		return base58.Decode(s[1:])
+	case Base64URL:
+		return base64.RawURLEncoding.DecodeString(s[1:])
+	case Base64URLPad:
+		return base64.URLEncoding.DecodeString(s[1:])
+	default:
+		return nil, fmt.Errorf("multibase: unsupported prefix %q", s[0])
+	}
+}
+
+const base36Alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// encodeBase36 returns the lower-case base36 of data. Each leading zero byte
+// encodes as a leading '0'.
+func encodeBase36(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	var digits []byte
+	n := new(big.Int).SetBytes(data[zeros:])
+	radix, mod := big.NewInt(36), new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		digits = append(digits, base36Alphabet[mod.Int64()])
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return strings.Repeat("0", zeros) + string(digits)
+}
+
+// decodeBase36 returns the data of a lower-case base36 encoding.
+func decodeBase36(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '0' {
+		zeros++
+	}
+	n := new(big.Int)
+	radix := big.NewInt(36)
+	for i := zeros; i < len(s); i++ {
+		v := strings.IndexByte(base36Alphabet, s[i])
+		if v < 0 {
+			return nil, fmt.Errorf("multibase: invalid base36 character %q", s[i])
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(v)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// Multicodec identifiers of public keys.
+// https://github.com/multiformats/multicodec/blob/master/table.csv
+const (
+	Secp256k1Pub = 0xe7
+	X25519Pub    = 0xec
+	Ed25519Pub   = 0xed
+	P256Pub      = 0x1200
+	P384Pub      = 0x1201
+)
+
+// AppendCodec appends the unsigned varint of a multicodec identifier.
+func AppendCodec(dst []byte, codec uint64) []byte {
+	for codec >= 0x80 {
+		dst = append(dst, byte(codec)|0x80)
+		codec >>= 7
+	}
+	return append(dst, byte(codec))
+}
+
+// SplitCodec returns the multicodec identifier in front of the data, and the
+// remainder.
+func SplitCodec(data []byte) (codec uint64, rest []byte, err error) {
+	for i, shift := 0, 0; i < len(data) && i < 9; i, shift = i+1, shift+7 {
+		codec |= uint64(data[i]&0x7f) << shift
+		if data[i] < 0x80 {
+			return codec, data[i+1:], nil
+		}
+	}
+	return 0, nil, errors.New("multibase: malformed multicodec varint")
+}
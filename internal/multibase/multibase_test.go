@@ -0,0 +1,52 @@
+package multibase
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	data := []byte("Decentralized")
+	for _, prefix := range []byte{Base32, Base36, Base58BTC, Base64URL, Base64URLPad} {
+		s := Encode(prefix, data)
+		if s[0] != prefix {
+			t.Errorf("got encoding %q, want prefix %q", s, prefix)
+		}
+		got, err := Decode(s)
+		if err != nil {
+			t.Errorf("%q got error: %s", s, err)
+		} else if !bytes.Equal(got, data) {
+			t.Errorf("%q got %q, want %q", s, got, data)
+		}
+	}
+
+	// leading zeros
+	if got := Encode(Base36, []byte{0, 0, 1}); got != "k001" {
+		t.Errorf("got %q, want \"k001\"", got)
+	}
+
+	for _, s := range []string{"", "fabc", "z0", "k_", "b1"} {
+		if _, err := Decode(s); err == nil {
+			t.Errorf("%q got no error", s)
+		}
+	}
+}
+
+func TestCodec(t *testing.T) {
+	for _, codec := range []uint64{Ed25519Pub, P256Pub, 0, 1 << 40} {
+		data := AppendCodec(nil, codec)
+		got, rest, err := SplitCodec(append(data, 'x'))
+		if err != nil {
+			t.Errorf("%#x got error: %s", codec, err)
+		} else if got != codec || string(rest) != "x" {
+			t.Errorf("%#x got %#x with remainder %q", codec, got, rest)
+		}
+	}
+	if got := AppendCodec(nil, Ed25519Pub); !bytes.Equal(got, []byte{0xed, 0x01}) {
+		t.Errorf("Ed25519 got varint %#x, want 0xed01", got)
+	}
+
+	if _, _, err := SplitCodec([]byte{0x80}); err == nil {
+		t.Error("truncated varint got no error")
+	}
+}
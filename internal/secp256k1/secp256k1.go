@@ -0,0 +1,135 @@
+// Package secp256k1 implements ECDSA verification on the secp256k1 curve,
+// which Go's standard library lacks. It is not constant-time, and it must not
+// be used with private keys.
+package secp256k1
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// P is the field prime.
+	P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	// N is the group order.
+	N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+
+	gx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	gy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+)
+
+// A Point is an affine coordinate pair. The nil X represents infinity.
+type Point struct {
+	X, Y *big.Int
+}
+
+// G is the generator.
+var G = Point{gx, gy}
+
+// Decompress returns the point of a 33-byte compressed key.
+func Decompress(key []byte) (Point, error) {
+	if len(key) != 33 || (key[0] != 2 && key[0] != 3) {
+		return Point{}, errors.New("secp256k1: key not in compressed form")
+	}
+	x := new(big.Int).SetBytes(key[1:])
+	if x.Cmp(P) >= 0 {
+		return Point{}, errors.New("secp256k1: coordinate out of range")
+	}
+	// y² = x³ + 7
+	y2 := new(big.Int).Exp(x, big.NewInt(3), P)
+	y2.Add(y2, big.NewInt(7))
+	y2.Mod(y2, P)
+	y := new(big.Int).ModSqrt(y2, P)
+	if y == nil {
+		return Point{}, errors.New("secp256k1: key not on curve")
+	}
+	if y.Bit(0) != uint(key[0]&1) {
+		y.Sub(P, y)
+	}
+	return Point{x, y}, nil
+}
+
+// Compress returns the 33-byte encoding of p.
+func Compress(p Point) []byte {
+	key := make([]byte, 33)
+	key[0] = 2 | byte(p.Y.Bit(0))
+	p.X.FillBytes(key[1:])
+	return key
+}
+
+// Add returns a + b.
+func Add(a, b Point) Point {
+	switch {
+	case a.X == nil:
+		return b
+	case b.X == nil:
+		return a
+	}
+	var slope *big.Int
+	if a.X.Cmp(b.X) == 0 {
+		if a.Y.Cmp(b.Y) != 0 || a.Y.Sign() == 0 {
+			return Point{} // inverse
+		}
+		// 3x² / 2y
+		num := new(big.Int).Mul(a.X, a.X)
+		num.Mul(num, big.NewInt(3))
+		den := new(big.Int).Lsh(a.Y, 1)
+		slope = num.Mul(num, den.ModInverse(den, P))
+	} else {
+		num := new(big.Int).Sub(b.Y, a.Y)
+		den := new(big.Int).Sub(b.X, a.X)
+		den.Mod(den, P)
+		slope = num.Mul(num, den.ModInverse(den, P))
+	}
+	slope.Mod(slope, P)
+
+	x := new(big.Int).Mul(slope, slope)
+	x.Sub(x, a.X)
+	x.Sub(x, b.X)
+	x.Mod(x, P)
+	y := new(big.Int).Sub(a.X, x)
+	y.Mul(y, slope)
+	y.Sub(y, a.Y)
+	y.Mod(y, P)
+	return Point{x, y}
+}
+
+// ScalarMult returns k × p.
+func ScalarMult(p Point, k *big.Int) Point {
+	var r Point
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = Add(r, r)
+		if k.Bit(i) == 1 {
+			r = Add(r, p)
+		}
+	}
+	return r
+}
+
+// Verify returns whether (r, s) is a valid signature of hash by pub.
+func Verify(pub Point, hash []byte, r, s *big.Int) bool {
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(N) >= 0 || s.Cmp(N) >= 0 {
+		return false
+	}
+	e := hashToInt(hash)
+	w := new(big.Int).ModInverse(s, N)
+	u1 := e.Mul(e, w)
+	u1.Mod(u1, N)
+	u2 := w.Mul(r, w)
+	u2.Mod(u2, N)
+
+	x := Add(ScalarMult(G, u1), ScalarMult(pub, u2))
+	if x.X == nil {
+		return false
+	}
+	v := new(big.Int).Mod(x.X, N)
+	return v.Cmp(r) == 0
+}
+
+// hashToInt truncates the hash to the bit length of N.
+func hashToInt(hash []byte) *big.Int {
+	if len(hash) > 32 {
+		hash = hash[:32]
+	}
+	return new(big.Int).SetBytes(hash)
+}
@@ -0,0 +1,64 @@
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestScalarMult(t *testing.T) {
+	tests := []struct {
+		k    int64
+		want string
+	}{
+		{1, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+		{2, "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"},
+		{3, "02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9"},
+	}
+	for _, test := range tests {
+		p := ScalarMult(G, big.NewInt(test.k))
+		if got := hex.EncodeToString(Compress(p)); got != test.want {
+			t.Errorf("%d×G got %s, want %s", test.k, got, test.want)
+		}
+		q, err := Decompress(Compress(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.X.Cmp(p.X) != 0 || q.Y.Cmp(p.Y) != 0 {
+			t.Errorf("%d×G decompress mismatch", test.k)
+		}
+	}
+}
+
+// sign is a non-constant time ECDSA for testing only.
+func sign(d *big.Int, hash []byte, k *big.Int) (r, s *big.Int) {
+	r = new(big.Int).Mod(ScalarMult(G, k).X, N)
+	s = new(big.Int).Mul(r, d)
+	s.Add(s, hashToInt(hash))
+	s.Mul(s, new(big.Int).ModInverse(k, N))
+	s.Mod(s, N)
+	return r, s
+}
+
+func TestVerify(t *testing.T) {
+	d := big.NewInt(0xc0ffee)
+	pub := ScalarMult(G, d)
+	hash := sha256.Sum256([]byte("hello"))
+	r, s := sign(d, hash[:], big.NewInt(0x5eed))
+
+	if !Verify(pub, hash[:], r, s) {
+		t.Error("valid signature denied")
+	}
+	// s and N - s are both valid
+	if !Verify(pub, hash[:], r, new(big.Int).Sub(N, s)) {
+		t.Error("valid high-S signature denied")
+	}
+	other := sha256.Sum256([]byte("world"))
+	if Verify(pub, other[:], r, s) {
+		t.Error("signature of other hash accepted")
+	}
+	if Verify(ScalarMult(G, big.NewInt(2)), hash[:], r, s) {
+		t.Error("signature of other key accepted")
+	}
+}
@@ -0,0 +1,91 @@
+package did
+
+import (
+	"errors"
+	"time"
+)
+
+// Metadata is the output of a resolution, next to the document. The JSON
+// names match the DID Resolution result.
+type Metadata struct {
+	Resolution ResolutionMetadata `json:"didResolutionMetadata"`
+	Document   DocumentMetadata   `json:"didDocumentMetadata"`
+}
+
+// ResolutionMetadata describes the resolution process.
+type ResolutionMetadata struct {
+	// ContentType is the media type of the representation.
+	ContentType string `json:"contentType,omitempty"`
+
+	// Error has the code of the failure, if any, like ErrorNotFound.
+	Error string `json:"error,omitempty"`
+}
+
+// Err returns the reason which matches the Error code, or nil when Error is
+// empty. Unknown codes get a generic error with the code as its message.
+func (m *ResolutionMetadata) Err() error {
+	if m.Error == "" {
+		return nil
+	}
+	for err, code := range errorCodes {
+		if code == m.Error {
+			return err
+		}
+	}
+	return errors.New(m.Error)
+}
+
+// DocumentMetadata describes the resolved document. Timestamps should be in
+// UTC, without sub-second precision.
+type DocumentMetadata struct {
+	Created time.Time `json:"created,omitzero"`
+	Updated time.Time `json:"updated,omitzero"`
+
+	// Deactivated is set when the DID is deactivated.
+	Deactivated bool `json:"deactivated,omitempty"`
+
+	// NextUpdate is the time of the next version, if any, for
+	// resolutions of a historical version.
+	NextUpdate time.Time `json:"nextUpdate,omitzero"`
+
+	VersionID     string `json:"versionId,omitempty"`
+	NextVersionID string `json:"nextVersionId,omitempty"`
+
+	// EquivalentID has logically equivalent DIDs, as asserted by the
+	// method.
+	EquivalentID []string `json:"equivalentId,omitempty"`
+
+	// CanonicalID is the canonical DID, as asserted by the method.
+	CanonicalID string `json:"canonicalId,omitempty"`
+}
+
+// Error codes for ResolutionMetadata conform the DID Resolution specification.
+const (
+	ErrorInvalidDID                 = "invalidDid"
+	ErrorNotFound                   = "notFound"
+	ErrorMethodNotSupported         = "methodNotSupported"
+	ErrorRepresentationNotSupported = "representationNotSupported"
+	ErrorInternal                   = "internalError"
+)
+
+// errorCodes has the code for each reason.
+var errorCodes = map[error]string{
+	ErrInvalidDID:                 ErrorInvalidDID,
+	ErrNotFound:                   ErrorNotFound,
+	ErrMethodNotSupported:         ErrorMethodNotSupported,
+	ErrRepresentationNotSupported: ErrorRepresentationNotSupported,
+}
+
+// ErrorCode returns the code for a resolution failure, or the empty string
+// for nil. Errors which do not wrap any of the reasons get ErrorInternal.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	for reason, code := range errorCodes {
+		if errors.Is(err, reason) {
+			return code
+		}
+	}
+	return ErrorInternal
+}
@@ -0,0 +1,49 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMetadataJSON(t *testing.T) {
+	m := Metadata{
+		Resolution: ResolutionMetadata{ContentType: "application/did+json"},
+		Document: DocumentMetadata{
+			Created:      time.Date(2019, 3, 23, 6, 35, 22, 0, time.UTC),
+			VersionID:    "1",
+			EquivalentID: []string{"did:example:abc"},
+		},
+	}
+	const sample = `{"didResolutionMetadata":{"contentType":"application/did+json"},"didDocumentMetadata":{"created":"2019-03-23T06:35:22Z","versionId":"1","equivalentId":["did:example:abc"]}}`
+	bytes, err := json.Marshal(&m)
+	assert(t, nil, err)
+	assert(t, sample, string(bytes))
+
+	var got Metadata
+	assert(t, nil, json.Unmarshal(bytes, &got))
+	assert(t, m, got)
+
+	bytes, err = json.Marshal(&Metadata{})
+	assert(t, nil, err)
+	assert(t, `{"didResolutionMetadata":{},"didDocumentMetadata":{}}`, string(bytes))
+}
+
+func TestErrorCode(t *testing.T) {
+	assert(t, "", ErrorCode(nil))
+	assert(t, ErrorInternal, ErrorCode(errors.New("other")))
+	for reason, code := range errorCodes {
+		wrapped := fmt.Errorf("did: resolve did:example:123: %w", reason)
+		assert(t, code, ErrorCode(wrapped))
+
+		m := ResolutionMetadata{Error: code}
+		assert(t, reason, m.Err())
+	}
+
+	m := ResolutionMetadata{}
+	assert(t, nil, m.Err())
+	m.Error = "unknownCode"
+	assert(t, "unknownCode", m.Err().Error())
+}
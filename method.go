@@ -0,0 +1,156 @@
+package did
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// A MethodSpec constrains the method-specific-id of a DID method beyond the
+// generic grammar. The zero value has no constraints.
+type MethodSpec struct {
+	// MinLength and MaxLength bound the number of bytes in the
+	// method-specific-id, if not zero.
+	MinLength, MaxLength int
+
+	// Alphabet lists the characters permitted in the idstrings, if not
+	// empty. The ':' separators are always permitted. Include '%' to
+	// permit pct-encoded octets.
+	Alphabet string
+
+	// Checksum verifies the method-specific-id, if not nil. It applies
+	// after the other constraints passed.
+	Checksum func(id string) error
+
+	// IDChars replaces the idchar set of the grammar, if not empty, and
+	// ExtraIDChars adds characters to the set, e.g., "=" for base64 with
+	// padding. Neither may contain ':', '%', the delimiters "/?#", nor
+	// any characters outside of printable ASCII.
+	IDChars, ExtraIDChars string
+}
+
+// methodSpec is a MethodSpec in lookup form.
+type methodSpec struct {
+	MethodSpec
+	alphabet       charset // only valid with Alphabet set
+	idChars        charset
+	idOrColonChars charset
+}
+
+// The method registry is copy-on-write, such that parsing needs no locks.
+var (
+	methodsMutex sync.Mutex // serializes writes
+	methodSpecs  atomic.Pointer[map[string]*methodSpec]
+)
+
+// RegisterMethod attaches spec to the DID method. Parse, ParseURL, the Parser
+// methods, Valid and ValidURL apply the constraints to each DID with the
+// method. RegisterMethod panics when name is not a valid method name, when
+// the method already has a spec, or when the idchars of spec are invalid.
+// Registration typically happens from an init function.
+func RegisterMethod(name string, spec MethodSpec) {
+	if !validMethod(name) {
+		panic(fmt.Sprintf("did: invalid method name %q", name))
+	}
+	s := &methodSpec{MethodSpec: spec, idChars: idChars}
+	if spec.Alphabet != "" {
+		s.alphabet = makeCharset(spec.Alphabet + ":")
+	}
+	if spec.IDChars != "" {
+		s.idChars = makeCharset(spec.IDChars)
+	}
+	for _, set := range []string{spec.IDChars, spec.ExtraIDChars} {
+		for i := 0; i < len(set); i++ {
+			if c := set[i]; c <= ' ' || c >= 0x7f || strings.IndexByte(":%/?#", c) >= 0 {
+				panic(fmt.Sprintf("did: method %s has idchar %q", name, c))
+			}
+		}
+	}
+	extra, colon := makeCharset(spec.ExtraIDChars), makeCharset(":")
+	for i := range s.idChars {
+		s.idChars[i] |= extra[i]
+		s.idOrColonChars[i] = s.idChars[i] | colon[i]
+	}
+
+	methodsMutex.Lock()
+	defer methodsMutex.Unlock()
+	var m map[string]*methodSpec
+	if p := methodSpecs.Load(); p != nil {
+		if _, ok := (*p)[name]; ok {
+			panic("did: multiple registrations for method " + name)
+		}
+		m = maps.Clone(*p)
+	} else {
+		m = make(map[string]*methodSpec)
+	}
+	m[name] = s
+	methodSpecs.Store(&m)
+}
+
+// lookupMethod returns the spec of a method, or nil for none.
+func lookupMethod(name string) *methodSpec {
+	p := methodSpecs.Load()
+	if p == nil {
+		return nil
+	}
+	return (*p)[name]
+}
+
+// idCharsOf returns the characters of an idstring of a method, excluding
+// pct-encoded.
+func idCharsOf(spec *methodSpec) *charset {
+	if spec == nil {
+		return &idChars
+	}
+	return &spec.idChars
+}
+
+// idOrColonCharsOf returns the characters of the method-specific-id of a
+// method, excluding pct-encoded.
+func idOrColonCharsOf(spec *methodSpec) *charset {
+	if spec == nil {
+		return &idOrColonChars
+	}
+	return &spec.idOrColonChars
+}
+
+// IsMethodIDChar is like IsIDChar, with the IDChars and ExtraIDChars of the
+// MethodSpec applied, if the method has one.
+func IsMethodIDChar(method string, c byte) bool {
+	if spec := lookupMethod(method); spec != nil {
+		return spec.idChars.contains(c)
+	}
+	return idChars.contains(c)
+}
+
+// check verifies the method-specific-id in input[start:end]. Problems are
+// recorded in errs, and ok is false when parsing should halt.
+func (s *methodSpec) check(input string, start, end int, errs *parseErrors) (ok bool) {
+	if n := end - start; (s.MinLength != 0 && n < s.MinLength) || (s.MaxLength != 0 && n > s.MaxLength) {
+		if errs.add(newParseError(input, start, ComponentID, ErrIDLength)) {
+			return false
+		}
+	}
+	if s.Alphabet != "" {
+		for i := start; i < end; i++ {
+			if !s.alphabet.contains(input[i]) && errs.add(newParseError(input, i, ComponentID, ErrInvalidIDChar)) {
+				return false
+			}
+		}
+	}
+	if len(errs.list) == 0 && s.Checksum != nil {
+		if err := s.Checksum(input[start:end]); err != nil {
+			return !errs.add(newParseError(input, start, ComponentID, fmt.Errorf("%w: %w", ErrChecksum, err)))
+		}
+	}
+	return true
+}
+
+// valid is like check, without error reporting.
+func (s *methodSpec) valid(id string) bool {
+	errs := parseErrors{}
+	s.check(id, 0, len(id), &errs)
+	return len(errs.list) == 0
+}
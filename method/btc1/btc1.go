@@ -0,0 +1,106 @@
+// Package btc1 implements the identifier encoding of the did:btc1 method.
+// Resolution, with its beacons and sidecar data, is not covered.
+// https://dcdpr.github.io/did-btc1/
+package btc1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/bech32"
+)
+
+// Networks by their identifier value.
+var Networks = [...]string{"bitcoin", "signet", "regtest", "testnet3", "testnet4", "mutinynet"}
+
+// Identifier is the content of a did:btc1.
+type Identifier struct {
+	Version int    // starting at 1
+	Network string // one of Networks
+
+	// Genesis is a compressed secp256k1 public key for key-based
+	// identifiers ("k"), or the SHA-256 hash of the initial document
+	// for externally-based identifiers ("x").
+	Genesis []byte
+}
+
+// KeyBased returns whether the genesis is a public key.
+func (id Identifier) KeyBased() bool { return len(id.Genesis) == 33 }
+
+// New returns the did:btc1 of an identifier.
+func New(id Identifier) (*did.DID, error) {
+	if id.Version != 1 {
+		return nil, fmt.Errorf("did:btc1: version %d not supported", id.Version)
+	}
+	network := -1
+	for i, name := range Networks {
+		if name == id.Network {
+			network = i
+		}
+	}
+	if network < 0 {
+		return nil, fmt.Errorf("did:btc1: network %q unknown", id.Network)
+	}
+	hrp := "x"
+	switch {
+	case id.KeyBased():
+		if id.Genesis[0] != 2 && id.Genesis[0] != 3 {
+			return nil, errors.New("did:btc1: genesis key not compressed")
+		}
+		hrp = "k"
+	case len(id.Genesis) != 32:
+		return nil, fmt.Errorf("did:btc1: %d-byte genesis, want a 33-byte key or a 32-byte hash", len(id.Genesis))
+	}
+
+	data := append([]byte{byte(id.Version-1)<<4 | byte(network)}, id.Genesis...)
+	groups, err := bech32.ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return nil, err
+	}
+	s := bech32.Encode(hrp, groups, bech32.Bech32m)
+	return &did.DID{Method: "btc1", ID: s, IDStrings: []string{s}}, nil
+}
+
+// Parse returns the content of a did:btc1.
+func Parse(d *did.DID) (Identifier, error) {
+	if d.Method != "btc1" {
+		return Identifier{}, fmt.Errorf("did: %s not a did:btc1", d)
+	}
+	s := strings.TrimPrefix(d.String(), "did:btc1:")
+	hrp, groups, err := bech32.Decode(s, bech32.Bech32m)
+	if err != nil {
+		return Identifier{}, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	data, err := bech32.ConvertBits(groups, 5, 8, false)
+	if err != nil {
+		return Identifier{}, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if len(data) == 0 {
+		return Identifier{}, fmt.Errorf("did: %s: %w: no data", d, did.ErrInvalidDID)
+	}
+
+	id := Identifier{Version: int(data[0]>>4) + 1, Genesis: data[1:]}
+	if id.Version != 1 {
+		return Identifier{}, fmt.Errorf("did: %s: %w: version %d not supported", d, did.ErrInvalidDID, id.Version)
+	}
+	if n := int(data[0] & 0xf); n < len(Networks) {
+		id.Network = Networks[n]
+	} else {
+		return Identifier{}, fmt.Errorf("did: %s: %w: network %#x unknown", d, did.ErrInvalidDID, n)
+	}
+	switch hrp {
+	case "k":
+		if len(id.Genesis) != 33 || id.Genesis[0] != 2 && id.Genesis[0] != 3 {
+			return Identifier{}, fmt.Errorf("did: %s: %w: genesis not a compressed key", d, did.ErrInvalidDID)
+		}
+	case "x":
+		if len(id.Genesis) != 32 {
+			return Identifier{}, fmt.Errorf("did: %s: %w: %d-byte genesis hash", d, did.ErrInvalidDID, len(id.Genesis))
+		}
+	default:
+		return Identifier{}, fmt.Errorf("did: %s: %w: identifier type %q unknown", d, did.ErrInvalidDID, hrp)
+	}
+	return id, nil
+}
@@ -0,0 +1,53 @@
+package btc1
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+func TestIdentifier(t *testing.T) {
+	tests := []Identifier{
+		{Version: 1, Network: "bitcoin", Genesis: append([]byte{2}, bytes.Repeat([]byte{0xab}, 32)...)},
+		{Version: 1, Network: "regtest", Genesis: bytes.Repeat([]byte{0xcd}, 32)},
+	}
+	for _, want := range tests {
+		d, err := New(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prefix := map[bool]string{true: "k1", false: "x1"}[want.KeyBased()]; d.ID[:2] != prefix {
+			t.Errorf("got %s, want %s prefix", d, prefix)
+		}
+		parsed, err := did.Parse(d.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Parse(parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Version != want.Version || got.Network != want.Network || !bytes.Equal(got.Genesis, want.Genesis) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+
+	// from the specification
+	d, err := did.Parse("did:btc1:k1qqpuwwde82nennsavvf0lqfnlvx7frrgzs57lchr02q8mz49qzaaxmqphnvcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := Parse(d); err != nil {
+		t.Error(err)
+	} else if id.Network != "bitcoin" || !id.KeyBased() {
+		t.Errorf("got %+v", id)
+	}
+
+	for _, s := range []string{"k1qqqqqqqq", "q1qqpuwwde82nennsavvf0lqfnlvx7frrgzs57lchr02q8mz49qzaaxmqphnvcx"} {
+		if _, err := Parse(&did.DID{Method: "btc1", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Package btcr implements the did:btcr method, with the Bitcoin transaction
+// of a DID referenced with TxRef encoding (BIP 136).
+// https://w3c-ccg.github.io/didm-btcr/
+package btcr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/bech32"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// Magic codes of the TxRef encoding.
+const (
+	MagicMain         = 0x3
+	MagicMainExtended = 0x4
+	MagicTest         = 0x6
+	MagicTestExtended = 0x7
+)
+
+// Limits of the TxRef encoding.
+const (
+	MaxHeight   = 1<<24 - 1
+	MaxPosition = 1<<15 - 1
+	MaxOutput   = 1<<15 - 1
+)
+
+// TxRef is a reference to a transaction (output) on the blockchain.
+type TxRef struct {
+	Testnet  bool
+	Height   int  // block height
+	Position int  // transaction index within the block
+	Extended bool // whether Output applies
+	Output   int  // transaction output index
+}
+
+// hrp returns the human readable part of the bech32 encoding.
+func (ref TxRef) hrp() string {
+	if ref.Testnet {
+		return "txtest"
+	}
+	return "tx"
+}
+
+// MethodSpecificID returns the TxRef notation without the human readable
+// part, e.g., "xyv2-xzpq-q63z-7p4".
+func (ref TxRef) MethodSpecificID() string {
+	magic := byte(MagicMain)
+	switch {
+	case ref.Testnet && ref.Extended:
+		magic = MagicTestExtended
+	case ref.Testnet:
+		magic = MagicTest
+	case ref.Extended:
+		magic = MagicMainExtended
+	}
+	h, p, o := ref.Height, ref.Position, ref.Output
+	data := []byte{
+		magic,
+		byte(h&0xf) << 1, // version 0 in the lowest bit
+		byte(h >> 4 & 0x1f),
+		byte(h >> 9 & 0x1f),
+		byte(h >> 14 & 0x1f),
+		byte(h >> 19 & 0x1f),
+		byte(p & 0x1f),
+		byte(p >> 5 & 0x1f),
+		byte(p >> 10 & 0x1f),
+	}
+	if ref.Extended {
+		data = append(data, byte(o&0x1f), byte(o>>5&0x1f), byte(o>>10&0x1f))
+	}
+	s := strings.TrimPrefix(bech32.Encode(ref.hrp(), data, bech32.Bech32), ref.hrp()+"1")
+
+	var buf strings.Builder
+	for i := 0; i < len(s); i += 4 {
+		if i != 0 {
+			buf.WriteByte('-')
+		}
+		buf.WriteString(s[i:min(i+4, len(s))])
+	}
+	return buf.String()
+}
+
+// String returns the TxRef notation, e.g., "tx1:rqqq-qqqq-qmhu-qhp".
+func (ref TxRef) String() string {
+	return ref.hrp() + "1:" + ref.MethodSpecificID()
+}
+
+// New returns the did:btcr of a transaction reference. It panics when a
+// value exceeds the limits.
+func New(ref TxRef) *did.DID {
+	if ref.Height < 0 || ref.Height > MaxHeight || ref.Position < 0 || ref.Position > MaxPosition || ref.Output < 0 || ref.Output > MaxOutput {
+		panic(fmt.Sprintf("did:btcr: reference %+v exceeds TxRef limits", ref))
+	}
+	id := ref.MethodSpecificID()
+	return &did.DID{Method: "btcr", ID: id, IDStrings: []string{id}}
+}
+
+// Parse returns the transaction reference of a did:btcr.
+func Parse(d *did.DID) (TxRef, error) {
+	if d.Method != "btcr" {
+		return TxRef{}, fmt.Errorf("did: %s not a did:btcr", d)
+	}
+	ref, err := ParseTxRef(strings.TrimPrefix(d.String(), "did:btcr:"))
+	if err != nil {
+		return TxRef{}, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	return ref, nil
+}
+
+// ParseTxRef reads the TxRef notation, with or without the human readable
+// part. Hyphens are optional.
+func ParseTxRef(s string) (TxRef, error) {
+	s = strings.ReplaceAll(strings.ToLower(s), "-", "")
+	if i := strings.LastIndexByte(s, ':'); i >= 0 {
+		s = s[i+1:]
+		// human readable part verified with checksum
+	}
+	if s == "" {
+		return TxRef{}, errors.New("empty TxRef")
+	}
+	var ref TxRef
+	switch s[0] {
+	case bech32.Charset[MagicMain]:
+	case bech32.Charset[MagicMainExtended]:
+		ref.Extended = true
+	case bech32.Charset[MagicTest]:
+		ref.Testnet = true
+	case bech32.Charset[MagicTestExtended]:
+		ref.Testnet, ref.Extended = true, true
+	default:
+		return TxRef{}, fmt.Errorf("TxRef magic %q unknown", s[0])
+	}
+	_, data, err := bech32.Decode(ref.hrp()+"1"+s, bech32.Bech32)
+	if err != nil {
+		return TxRef{}, err
+	}
+	want := 9
+	if ref.Extended {
+		want = 12
+	}
+	if len(data) != want {
+		return TxRef{}, fmt.Errorf("TxRef with %d data characters, want %d", len(data), want)
+	}
+	if data[1]&1 != 0 {
+		return TxRef{}, errors.New("TxRef version not supported")
+	}
+	ref.Height = int(data[1]>>1) | int(data[2])<<4 | int(data[3])<<9 | int(data[4])<<14 | int(data[5])<<19
+	ref.Position = int(data[6]) | int(data[7])<<5 | int(data[8])<<10
+	if ref.Extended {
+		ref.Output = int(data[9]) | int(data[10])<<5 | int(data[11])<<10
+	}
+	return ref, nil
+}
+
+// Tx is the content of a transaction, as needed for resolution.
+type Tx struct {
+	ID string // hexadecimal transaction hash
+
+	// SignerKey is the compressed secp256k1 public key which signed the
+	// first input.
+	SignerKey []byte
+
+	// Data is the OP_RETURN payload, if any. It locates a continuation
+	// document when present.
+	Data []byte
+
+	// SpentBy is the ID of the transaction which spends the DID output,
+	// with the empty string for unspent.
+	SpentBy string
+}
+
+// Source provides blockchain data. Implementations may query a full node,
+// an indexer or some block explorer API.
+type Source interface {
+	// TxByRef returns the transaction at the reference. Errors should
+	// wrap did.ErrNotFound for absent transactions.
+	TxByRef(ctx context.Context, ref TxRef) (*Tx, error)
+	// TxByID returns the transaction with the hash on the same network.
+	TxByID(ctx context.Context, testnet bool, id string) (*Tx, error)
+}
+
+// MaxUpdates limits the number of transactions followed during resolution.
+const MaxUpdates = 1000
+
+// Resolver resolves did:btcr DIDs with a Source.
+type Resolver struct {
+	Source Source
+}
+
+// Resolve implements the did.Resolver interface. The DID document is the
+// implicit one, derived from the tip of the transaction chain. Continuation
+// documents are not fetched. Their location is in the "#continuation"
+// service instead.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "btcr" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	ref, err := Parse(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := r.Source.TxByRef(ctx, ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	for i := 0; tx.SpentBy != "" && tx.ID != opts.VersionID; i++ {
+		if i >= MaxUpdates {
+			return nil, nil, fmt.Errorf("did: resolve %s: more than %d updates", d, MaxUpdates)
+		}
+		tx, err = r.Source.TxByID(ctx, ref.Testnet, tx.SpentBy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("did: resolve %s: transaction chain: %w", d, err)
+		}
+	}
+	if opts.VersionID != "" && tx.ID != opts.VersionID {
+		return nil, nil, fmt.Errorf("did: resolve %s: version %q: %w", d, opts.VersionID, did.ErrNotFound)
+	}
+	if len(tx.SignerKey) != 33 {
+		return nil, nil, fmt.Errorf("did: resolve %s: transaction %s without compressed signer key", d, tx.ID)
+	}
+
+	id := d.String()
+	satoshi := id + "#satoshi"
+	doc := &did.Document{
+		Context: []any{did.ContextV1, did.ContextMultikey},
+		ID:      id,
+		VerificationMethod: []did.VerificationMethod{{
+			ID:                 satoshi,
+			Type:               did.TypeMultikey,
+			Controller:         id,
+			PublicKeyMultibase: multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, multibase.Secp256k1Pub), tx.SignerKey...)),
+		}},
+		Authentication:  []did.Relationship{{Reference: satoshi}},
+		AssertionMethod: []did.Relationship{{Reference: satoshi}},
+	}
+	if len(tx.Data) != 0 {
+		doc.Service = []did.Service{{
+			ID:              id + "#continuation",
+			Type:            "BTCRContinuationDocument",
+			ServiceEndpoint: did.ServiceEndpoint{URI: string(tx.Data)},
+		}}
+	}
+
+	meta := new(did.Metadata)
+	meta.Resolution.ContentType = contentType
+	meta.Document.VersionID = tx.ID
+	return doc, meta, nil
+}
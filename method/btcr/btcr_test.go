@@ -0,0 +1,103 @@
+package btcr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+func TestTxRef(t *testing.T) {
+	// examples from BIP 136
+	tests := []struct {
+		s   string
+		ref TxRef
+	}{
+		{"tx1:rqqq-qqqq-qmhu-qhp", TxRef{}},
+		{"tx1:rqqq-qqll-l8xh-jkg", TxRef{Position: MaxPosition}},
+		{"tx1:r7ll-llqq-qghq-qr8", TxRef{Height: MaxHeight}},
+		{"tx1:r7ll-llll-l5xt-jzw", TxRef{Height: MaxHeight, Position: MaxPosition}},
+		{"tx1:rjk0-uqay-zsrw-hqe", TxRef{Height: 466793, Position: 2205}},
+	}
+	for _, test := range tests {
+		if got := test.ref.String(); got != test.s {
+			t.Errorf("%+v got %q, want %q", test.ref, got, test.s)
+		}
+		got, err := ParseTxRef(test.s)
+		if err != nil {
+			t.Errorf("%q got error: %s", test.s, err)
+		} else if got != test.ref {
+			t.Errorf("%q got %+v, want %+v", test.s, got, test.ref)
+		}
+	}
+
+	ext := TxRef{Testnet: true, Height: 1201739, Position: 2, Extended: true, Output: 1}
+	got, err := ParseTxRef(ext.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != ext {
+		t.Errorf("got %+v, want %+v", got, ext)
+	}
+
+	for _, s := range []string{"", "qqqq-qqqq-qmhu-qhp", "rqqq-qqqq-qmhu-qhq", "rqqq-qqqq-qmh"} {
+		if _, err := ParseTxRef(s); err == nil {
+			t.Errorf("%q got no error", s)
+		}
+	}
+}
+
+type chain map[string]*Tx
+
+func (c chain) TxByRef(ctx context.Context, ref TxRef) (*Tx, error) {
+	if tx, ok := c[ref.String()]; ok {
+		return tx, nil
+	}
+	return nil, fmt.Errorf("transaction %s: %w", ref, did.ErrNotFound)
+}
+
+func (c chain) TxByID(ctx context.Context, testnet bool, id string) (*Tx, error) {
+	for _, tx := range c {
+		if tx.ID == id {
+			return tx, nil
+		}
+	}
+	return nil, fmt.Errorf("transaction %s: %w", id, did.ErrNotFound)
+}
+
+func TestResolve(t *testing.T) {
+	ref := TxRef{Testnet: true, Height: 1201739, Position: 2}
+	key1 := append([]byte{2}, bytes.Repeat([]byte{1}, 32)...)
+	key2 := append([]byte{3}, bytes.Repeat([]byte{2}, 32)...)
+	r := &Resolver{Source: chain{
+		ref.String(): {ID: "aa", SignerKey: key1, SpentBy: "bb"},
+		"next":       {ID: "bb", SignerKey: key2, Data: []byte("https://example.com/ddo.jsonld")},
+	}}
+
+	doc, meta, err := r.Resolve(context.Background(), New(ref), did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Document.VersionID != "bb" {
+		t.Errorf("got version %q, want the tip", meta.Document.VersionID)
+	}
+	if len(doc.Service) != 1 || doc.Service[0].ServiceEndpoint.URI != "https://example.com/ddo.jsonld" {
+		t.Errorf("got services %+v, want continuation", doc.Service)
+	}
+
+	doc, _, err = r.Resolve(context.Background(), New(ref), did.ResolutionOptions{VersionID: "aa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Service) != 0 {
+		t.Errorf("version aa got services %+v", doc.Service)
+	}
+
+	_, _, err = r.Resolve(context.Background(), New(TxRef{Height: 1}), did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, did.ErrNotFound)
+	}
+}
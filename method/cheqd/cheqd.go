@@ -0,0 +1,130 @@
+// Package cheqd implements the did:cheqd method with the REST API of a cheqd
+// DID resolver.
+// https://docs.cheqd.io/product/architecture/adr-list/adr-001-cheqd-did-method
+package cheqd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/base58"
+)
+
+// DefaultEndpoint is the public resolver of the cheqd network.
+const DefaultEndpoint = "https://resolver.cheqd.net"
+
+// resultMediaType requests the full resolution result.
+const resultMediaType = `application/ld+json;profile="https://w3id.org/did-resolution"`
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Validate returns an error when the DID is not a did:cheqd with a network
+// and a UUID or a base58 identifier of 16 bytes.
+func Validate(d *did.DID) error {
+	if d.Method != "cheqd" {
+		return fmt.Errorf("did: %s not a did:cheqd", d)
+	}
+	network, id, ok := strings.Cut(strings.TrimPrefix(d.String(), "did:cheqd:"), ":")
+	if !ok {
+		return fmt.Errorf("did: %s: %w: no network", d, did.ErrInvalidDID)
+	}
+	if network != "mainnet" && network != "testnet" {
+		return fmt.Errorf("did: %s: %w: network %q unknown", d, did.ErrInvalidDID, network)
+	}
+	if uuidPattern.MatchString(id) {
+		return nil
+	}
+	if b, err := base58.Decode(id); err != nil || len(b) != 16 {
+		return fmt.Errorf("did: %s: %w: identifier not a UUID nor base58 of 16 bytes", d, did.ErrInvalidDID)
+	}
+	return nil
+}
+
+// Resolver resolves did:cheqd DIDs with a DID resolver endpoint. Versions
+// resolve with the VersionID or the VersionTime option.
+type Resolver struct {
+	// Endpoint is the base URL, with DefaultEndpoint for the empty
+	// string.
+	Endpoint string
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "cheqd" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := Validate(d); err != nil {
+		return nil, nil, err
+	}
+
+	base := r.Endpoint
+	if base == "" {
+		base = DefaultEndpoint
+	}
+	location := strings.TrimSuffix(base, "/") + "/1.0/identifiers/" + url.PathEscape(d.String())
+	query := make(url.Values)
+	if opts.VersionID != "" {
+		query.Set("versionId", opts.VersionID)
+	}
+	if !opts.VersionTime.IsZero() {
+		query.Set("versionTime", opts.VersionTime.UTC().Format(time.RFC3339))
+	}
+	if len(query) != 0 {
+		location += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	req.Header.Set("Accept", resultMediaType)
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	defer resp.Body.Close()
+
+	// The envelope carries the error codes on failure too.
+	var result struct {
+		Document *did.Document `json:"didDocument"`
+		did.Metadata
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result)
+	if code := result.Resolution.Error; code != "" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, result.Resolution.Err())
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, fmt.Errorf("did: resolve %s: cheqd resolver got HTTP %q", d, resp.Status)
+	case err != nil:
+		return nil, nil, fmt.Errorf("did: resolve %s: malformed resolution result: %w", d, err)
+	case result.Document == nil:
+		return nil, nil, fmt.Errorf("did: resolve %s: resolution result without document", d)
+	}
+
+	meta := &result.Metadata
+	meta.Resolution.ContentType = contentType
+	return result.Document, meta, nil
+}
@@ -0,0 +1,81 @@
+package cheqd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+)
+
+const id = "did:cheqd:mainnet:a0f5ef2c-9ebb-4d2f-a7c3-4a5f2d5f7c19"
+
+func TestValidate(t *testing.T) {
+	for _, s := range []string{id, "did:cheqd:testnet:6xA5cTR1239iti1EFMiXoT"} {
+		d, err := did.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Validate(d); err != nil {
+			t.Errorf("%s got error: %s", s, err)
+		}
+	}
+	for _, s := range []string{"a0f5ef2c-9ebb-4d2f-a7c3-4a5f2d5f7c19", "devnet:a0f5ef2c-9ebb-4d2f-a7c3-4a5f2d5f7c19", "mainnet:A0F5EF2C-9EBB-4D2F-A7C3-4A5F2D5F7C19", "mainnet:zF7rhDBfUt9d1gJ"} {
+		if err := Validate(&did.DID{Method: "cheqd", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.0/identifiers/"+id {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"didResolutionMetadata":{"error":"notFound"},"didDocument":null,"didDocumentMetadata":{}}`))
+			return
+		}
+		versionID := r.URL.Query().Get("versionId")
+		if versionTime := r.URL.Query().Get("versionTime"); versionTime != "" {
+			if versionTime != "2023-01-01T00:00:00Z" {
+				t.Errorf("got version time %q", versionTime)
+			}
+			versionID = "1"
+		}
+		if versionID == "" {
+			versionID = "2"
+		}
+		w.Write([]byte(`{"@context":"https://w3id.org/did-resolution/v1","didResolutionMetadata":{"contentType":"application/did+ld+json"},"didDocument":{"id":"` + id + `"},"didDocumentMetadata":{"created":"2022-12-01T00:00:00Z","versionId":"` + versionID + `"}}`))
+	}))
+	defer srv.Close()
+
+	r := &Resolver{Endpoint: srv.URL}
+	d, err := did.Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		opts did.ResolutionOptions
+		want string
+	}{
+		{did.ResolutionOptions{}, "2"},
+		{did.ResolutionOptions{VersionID: "1"}, "1"},
+		{did.ResolutionOptions{VersionTime: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}, "1"},
+	}
+	for _, test := range tests {
+		doc, meta, err := r.Resolve(context.Background(), d, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.ID != id || meta.Document.VersionID != test.want {
+			t.Errorf("%+v got id %q with version %q, want version %q", test.opts, doc.ID, meta.Document.VersionID, test.want)
+		}
+	}
+
+	_, _, err = r.Resolve(context.Background(), &did.DID{Method: "cheqd", ID: "testnet:a0f5ef2c-9ebb-4d2f-a7c3-4a5f2d5f7c19"}, did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, did.ErrNotFound)
+	}
+}
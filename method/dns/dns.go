@@ -0,0 +1,111 @@
+// Package dns implements DNS-based DID discovery, and the did:dns method on
+// top of it. A domain names its DIDs with TXT records at the "_did" label.
+// https://datatracker.ietf.org/doc/draft-mayrhofer-did-dns/
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ockam-network/did"
+)
+
+// Lookup resolves TXT records. The standard *net.Resolver implements it.
+type Lookup interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// ValidDomain returns whether s is a fully qualified host name without the
+// trailing dot.
+func ValidDomain(s string) bool {
+	if len(s) == 0 || len(s) > 253 || !strings.Contains(s, ".") {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Discover returns the DIDs published for the domain, in order of appearance.
+// TXT values which do not parse as a DID are ignored. The error wraps
+// did.ErrNotFound when no DID is found.
+func Discover(ctx context.Context, lookup Lookup, domain string) ([]*did.DID, error) {
+	if !ValidDomain(domain) {
+		return nil, fmt.Errorf("did: discovery on domain %q: not a host name", domain)
+	}
+	if lookup == nil {
+		lookup = net.DefaultResolver
+	}
+	records, err := lookup.LookupTXT(ctx, "_did."+domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, fmt.Errorf("did: discovery on domain %q: %w", domain, did.ErrNotFound)
+		}
+		return nil, fmt.Errorf("did: discovery on domain %q: %w", domain, err)
+	}
+
+	var dids []*did.DID
+	for _, record := range records {
+		for _, field := range strings.Fields(record) {
+			d, err := did.Parse(field)
+			if err != nil {
+				continue
+			}
+			dids = append(dids, d)
+		}
+	}
+	if len(dids) == 0 {
+		return nil, fmt.Errorf("did: discovery on domain %q: no DID in TXT records: %w", domain, did.ErrNotFound)
+	}
+	return dids, nil
+}
+
+// Resolver resolves did:dns DIDs with discovery. The document of a did:dns
+// lists the discovered DIDs as alsoKnownAs. The zero value is ready for use.
+type Resolver struct {
+	// Lookup has a nil default of net.DefaultResolver.
+	Lookup Lookup
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "dns" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	domain := strings.TrimPrefix(d.String(), "did:dns:")
+	if !ValidDomain(domain) {
+		return nil, nil, fmt.Errorf("did: %s: %w: domain not a host name", d, did.ErrInvalidDID)
+	}
+
+	dids, err := Discover(ctx, r.Lookup, domain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	doc := &did.Document{Context: []any{did.ContextV1}, ID: d.String()}
+	for _, found := range dids {
+		if found.String() != doc.ID {
+			doc.AlsoKnownAs = append(doc.AlsoKnownAs, found.String())
+		}
+	}
+
+	meta := new(did.Metadata)
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
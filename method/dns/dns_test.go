@@ -0,0 +1,55 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+type zone map[string][]string
+
+func (z zone) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := z[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestDiscover(t *testing.T) {
+	lookup := zone{
+		"_did.example.com": {"did:web:example.com did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", "v=spf1 -all", "did:dns:example.com"},
+		"_did.example.org": {"did:bogus"},
+	}
+
+	dids, err := Discover(context.Background(), lookup, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dids) != 3 || dids[0].Method != "web" || dids[1].Method != "key" {
+		t.Errorf("got %v, want the 3 DIDs", dids)
+	}
+
+	for _, domain := range []string{"example.org", "example.net"} {
+		if _, err := Discover(context.Background(), lookup, domain); !errors.Is(err, did.ErrNotFound) {
+			t.Errorf("%s got error %v, want %v", domain, err, did.ErrNotFound)
+		}
+	}
+	for _, domain := range []string{"", "localhost", "-a.example.com", "a..com", "a_b.example.com"} {
+		if ValidDomain(domain) {
+			t.Errorf("%q is valid", domain)
+		}
+	}
+
+	r := &Resolver{Lookup: lookup}
+	doc, _, err := r.Resolve(context.Background(), &did.DID{Method: "dns", ID: "example.com"}, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.AlsoKnownAs) != 2 || doc.AlsoKnownAs[0] != "did:web:example.com" {
+		t.Errorf("got alsoKnownAs %q, want the other 2 DIDs", doc.AlsoKnownAs)
+	}
+}
@@ -0,0 +1,139 @@
+// Package ebsi implements the did:ebsi method for legal entities with the
+// DID Registry API of the European Blockchain Services Infrastructure.
+// https://hub.ebsi.eu/vc-framework/did/legal-entities
+package ebsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// Version is the leading byte of legal entity identifiers.
+const Version = 0x01
+
+// DefaultRegistry is the DID Registry API of the EBSI pilot network.
+const DefaultRegistry = "https://api-pilot.ebsi.eu/did-registry/v5"
+
+// MaxDocumentSize is the limit for DID Documents in bytes.
+const MaxDocumentSize = 1 << 20
+
+// New returns the did:ebsi of a (random) subject identifier.
+func New(subject [16]byte) *did.DID {
+	id := multibase.Encode(multibase.Base58BTC, append([]byte{Version}, subject[:]...))
+	return &did.DID{Method: "ebsi", ID: id, IDStrings: []string{id}}
+}
+
+// Subject returns the subject identifier of a did:ebsi.
+func Subject(d *did.DID) (subject [16]byte, err error) {
+	if d.Method != "ebsi" {
+		return subject, fmt.Errorf("did: %s not a did:ebsi", d)
+	}
+	id := strings.TrimPrefix(d.String(), "did:ebsi:")
+	if id == "" || id[0] != multibase.Base58BTC || strings.IndexByte(id, ':') >= 0 {
+		return subject, fmt.Errorf("did: %s: %w: want a base58btc multibase", d, did.ErrInvalidDID)
+	}
+	data, err := multibase.Decode(id)
+	if err != nil {
+		return subject, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if len(data) != 1+len(subject) {
+		return subject, fmt.Errorf("did: %s: %w: %d-byte identifier, want %d", d, did.ErrInvalidDID, len(data), 1+len(subject))
+	}
+	if data[0] != Version {
+		return subject, fmt.Errorf("did: %s: %w: version %#x not supported", d, did.ErrInvalidDID, data[0])
+	}
+	copy(subject[:], data[1:])
+	return subject, nil
+}
+
+// Validate returns an error when the DID is not a valid did:ebsi.
+func Validate(d *did.DID) error {
+	_, err := Subject(d)
+	return err
+}
+
+// Resolver resolves did:ebsi DIDs with the DID Registry API.
+type Resolver struct {
+	// Registry is the base URL of the API, with DefaultRegistry for
+	// the empty string.
+	Registry string
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "ebsi" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := Validate(d); err != nil {
+		return nil, nil, err
+	}
+
+	base := r.Registry
+	if base == "" {
+		base = DefaultRegistry
+	}
+	location := strings.TrimSuffix(base, "/") + "/identifiers/" + url.PathEscape(d.String())
+	if !opts.VersionTime.IsZero() {
+		location += "?" + url.Values{"valid-at": {opts.VersionTime.UTC().Format("2006-01-02T15:04:05Z")}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	req.Header.Set("Accept", "application/did+ld+json, application/json;q=0.5")
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotFound:
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrNotFound)
+	case http.StatusBadRequest:
+		return nil, nil, fmt.Errorf("did: resolve %s: registry got HTTP %q: %w", d, resp.Status, did.ErrInvalidDID)
+	default:
+		return nil, nil, fmt.Errorf("did: resolve %s: registry got HTTP %q", d, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	if len(body) > MaxDocumentSize {
+		return nil, nil, fmt.Errorf("did: resolve %s: document exceeds %d bytes", d, MaxDocumentSize)
+	}
+	doc := new(did.Document)
+	if err := json.Unmarshal(body, doc); err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: malformed document: %w", d, err)
+	}
+	if doc.ID != d.String() {
+		return nil, nil, fmt.Errorf("did: resolve %s: document has id %q", d, doc.ID)
+	}
+
+	meta := new(did.Metadata)
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
@@ -0,0 +1,59 @@
+package ebsi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+func TestSubject(t *testing.T) {
+	// example from the EBSI documentation
+	d, err := did.Parse("did:ebsi:zfEmvX5twhXjQJiCWsukvQA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := Subject(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := New(subject).String(); got != d.String() {
+		t.Errorf("got %q, want %q", got, d)
+	}
+
+	for _, s := range []string{"fEmvX5twhXjQJiCWsukvQA", "zfEmvX5twhXjQJiCWsukv", "z0EmvX5twhXjQJiCWsukvQA", "zfEmvX5twhXjQJiCWsukvQA:1"} {
+		if _, err := Subject(&did.DID{Method: "ebsi", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	d := New([16]byte{1, 2, 3})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/identifiers/"+d.String() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/did+ld+json")
+		w.Write([]byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"` + d.String() + `","controller":["` + d.String() + `"]}`))
+	}))
+	defer srv.Close()
+
+	r := &Resolver{Registry: srv.URL}
+	doc, meta, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != d.String() || meta.Resolution.ContentType != "application/did+ld+json" {
+		t.Errorf("got id %q with content type %q", doc.ID, meta.Resolution.ContentType)
+	}
+
+	_, _, err = r.Resolve(context.Background(), New([16]byte{4}), did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, did.ErrNotFound)
+	}
+}
@@ -0,0 +1,408 @@
+// Package ens implements the did:ens method, which reads the Ethereum Name
+// Service over the Ethereum JSON-RPC API.
+// https://github.com/veramolabs/did-ens-spec
+package ens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/ethrpc"
+	"github.com/ockam-network/did/internal/keccak"
+)
+
+// DefaultRegistry is the address of the ENS registry on most networks.
+const DefaultRegistry = "0x00000000000c2e074ec69a0dfb2997ba6c7d2e1e"
+
+// DefaultCacheTTL is the lifespan of lookups when Resolver.CacheTTL is zero.
+const DefaultCacheTTL = 5 * time.Minute
+
+// A Network is an Ethereum chain with an ENS deployment.
+type Network struct {
+	// Name is the network in DIDs, like "sepolia". The name "mainnet"
+	// applies to DIDs without network too.
+	Name string
+
+	// ChainID is the EIP-155 chain identifier.
+	ChainID uint64
+
+	// RPCURL is the endpoint of the JSON-RPC API.
+	RPCURL string
+
+	// Registry is the contract address, with DefaultRegistry for the
+	// empty string.
+	Registry string
+
+	// NameWrapper is the contract address of the ERC-1155 wrapper, if
+	// any. Wrapped names have their owner in the NameWrapper.
+	NameWrapper string
+}
+
+// Identifier is the decomposition of a did:ens.
+type Identifier struct {
+	Network string // optional
+	Name    string // normalized, like "vitalik.eth"
+}
+
+// Parse returns the decomposition of a did:ens. Names must be in
+// normalized form (lower case) already. Non-ASCII labels are percent-encoded
+// UTF-8 in DIDs.
+func Parse(d *did.DID) (*Identifier, error) {
+	if d.Method != "ens" {
+		return nil, fmt.Errorf("did: %s not a did:ens", d)
+	}
+	segments := strings.Split(strings.TrimPrefix(d.String(), "did:ens:"), ":")
+	id := new(Identifier)
+	switch len(segments) {
+	case 1:
+		break
+	case 2:
+		id.Network = segments[0]
+	default:
+		return nil, fmt.Errorf("did: %s: %w: too many segments", d, did.ErrInvalidDID)
+	}
+	name, err := url.PathUnescape(segments[len(segments)-1])
+	if err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if err := ValidName(name); err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	id.Name = name
+	return id, nil
+}
+
+// ValidName returns an error when name is not a normalized ENS name with at
+// least two labels. It checks the common ASCII rules of ENSIP-15, without
+// the Unicode confusable tables.
+func ValidName(name string) error {
+	if !utf8.ValidString(name) {
+		return errors.New("ENS name not UTF-8")
+	}
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return errors.New("ENS name without top-level domain")
+	}
+	for _, label := range labels {
+		switch {
+		case label == "":
+			return errors.New("ENS name with empty label")
+		case strings.ToLower(label) != label:
+			return fmt.Errorf("ENS label %q not normalized", label)
+		case len(label) >= 4 && label[2:4] == "--" && label[:2] != "xn":
+			return fmt.Errorf("ENS label %q has hyphens at positions 3 and 4", label)
+		}
+		for _, r := range label {
+			if r < 0x80 && !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-' || r == '_' || r == '$') {
+				return fmt.Errorf("ENS label %q has disallowed character %q", label, r)
+			}
+		}
+		if i := strings.LastIndexByte(label, '_'); i > 0 && strings.Trim(label[:i+1], "_") != "" {
+			return fmt.Errorf("ENS label %q has underscores after the start", label)
+		}
+	}
+	return nil
+}
+
+// Namehash returns the node of a name, as defined by EIP-137.
+func Namehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := keccak.Sum256([]byte(labels[i]))
+		node = keccak.Sum256(append(node[:], labelHash[:]...))
+	}
+	return node
+}
+
+// Record is the outcome of an ENS lookup.
+type Record struct {
+	Owner   string // checksummed address
+	Address string // checksummed address from the resolver, if any
+	DID     string // text record "did", if any
+}
+
+type cacheEntry struct {
+	record  *Record
+	expires time.Time
+}
+
+// Resolver resolves did:ens DIDs on the configured networks. Lookups are
+// cached for CacheTTL.
+type Resolver struct {
+	Networks []Network
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// CacheTTL is the lifespan of lookups, with DefaultCacheTTL for zero.
+	// Negative values disable the cache.
+	CacheTTL time.Duration
+
+	// Now is the clock for the cache. The nil value defaults to
+	// time.Now.
+	Now func() time.Time
+
+	mutex sync.Mutex
+	cache map[string]cacheEntry // network + name
+}
+
+func (r *Resolver) network(name string) (*Network, bool) {
+	if name == "" {
+		name = "mainnet"
+	}
+	for i := range r.Networks {
+		n := &r.Networks[i]
+		if n.Name == name || (name == "mainnet" && n.ChainID == 1) {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// function selectors of ENS contracts
+var (
+	selectorResolver = ethrpc.Selector("resolver(bytes32)")
+	selectorOwner    = ethrpc.Selector("owner(bytes32)")
+	selectorOwnerOf  = ethrpc.Selector("ownerOf(uint256)")
+	selectorAddr     = ethrpc.Selector("addr(bytes32)")
+	selectorText     = ethrpc.Selector("text(bytes32,string)")
+)
+
+// errNoName means the registry has no owner for the name.
+var errNoName = errors.New("ENS name not registered")
+
+// Lookup returns the record of a name, possibly from cache.
+func (r *Resolver) Lookup(ctx context.Context, network *Network, name string) (*Record, error) {
+	return r.cachedLookup(ctx, network, name, false)
+}
+
+// cachedLookup is Lookup, with an option to bypass the cache. Fresh records
+// still update the cache.
+func (r *Resolver) cachedLookup(ctx context.Context, network *Network, name string, fresh bool) (*Record, error) {
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	ttl := r.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	key := network.Name + ":" + name
+	if ttl > 0 && !fresh {
+		r.mutex.Lock()
+		entry, ok := r.cache[key]
+		r.mutex.Unlock()
+		if ok && now().Before(entry.expires) {
+			return entry.record, nil
+		}
+	}
+
+	record, err := r.lookup(ctx, network, name)
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		r.mutex.Lock()
+		if r.cache == nil {
+			r.cache = make(map[string]cacheEntry)
+		}
+		for k, e := range r.cache {
+			if !now().Before(e.expires) {
+				delete(r.cache, k)
+			}
+		}
+		r.cache[key] = cacheEntry{record, now().Add(ttl)}
+		r.mutex.Unlock()
+	}
+	return record, nil
+}
+
+func (r *Resolver) lookup(ctx context.Context, network *Network, name string) (*Record, error) {
+	registry := network.Registry
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+	c := &ethrpc.Client{Endpoint: network.RPCURL, HTTP: r.Client}
+	node := Namehash(name)
+
+	owner, err := callAddress(ctx, c, registry, append(selectorOwner, node[:]...))
+	if err != nil {
+		return nil, fmt.Errorf("ENS registry owner: %w", err)
+	}
+	if owner == nil {
+		return nil, errNoName
+	}
+	if network.NameWrapper != "" && ethrpc.ChecksumAddress(owner) == checksum(network.NameWrapper) {
+		owner, err = callAddress(ctx, c, network.NameWrapper, append(selectorOwnerOf, node[:]...))
+		if err != nil {
+			return nil, fmt.Errorf("ENS name wrapper owner: %w", err)
+		}
+		if owner == nil {
+			return nil, errNoName
+		}
+	}
+	record := &Record{Owner: ethrpc.ChecksumAddress(owner)}
+
+	resolver, err := callAddress(ctx, c, registry, append(selectorResolver, node[:]...))
+	if err != nil {
+		return nil, fmt.Errorf("ENS registry resolver: %w", err)
+	}
+	if resolver == nil {
+		return record, nil
+	}
+	resolverAddr := ethrpc.EncodeHex(resolver)
+	addr, err := callAddress(ctx, c, resolverAddr, append(selectorAddr, node[:]...))
+	if err != nil {
+		return nil, fmt.Errorf("ENS resolver addr: %w", err)
+	}
+	if addr != nil {
+		record.Address = ethrpc.ChecksumAddress(addr)
+	}
+
+	// text(node, "did")
+	call := append(append(selectorText, node[:]...), word(64)...)
+	call = append(append(call, word(3)...), padRight([]byte("did"))...)
+	out, err := c.EthCall(ctx, resolverAddr, call)
+	if err != nil {
+		return nil, fmt.Errorf("ENS resolver text: %w", err)
+	}
+	text, err := decodeString(out)
+	if err != nil {
+		return nil, fmt.Errorf("ENS resolver text: %w", err)
+	}
+	record.DID = text
+	return record, nil
+}
+
+// callAddress returns the address output of a contract call, with nil for
+// the zero address.
+func callAddress(ctx context.Context, c *ethrpc.Client, to string, data []byte) ([]byte, error) {
+	out, err := c.EthCall(ctx, to, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("%d-byte address output", len(out))
+	}
+	addr := out[12:32]
+	for _, b := range addr {
+		if b != 0 {
+			return addr, nil
+		}
+	}
+	return nil, nil
+}
+
+func checksum(address string) string {
+	b, err := ethrpc.DecodeHex(address)
+	if err != nil {
+		return ""
+	}
+	return ethrpc.ChecksumAddress(b)
+}
+
+// word returns the ABI encoding of n.
+func word(n int) []byte {
+	w := make([]byte, 32)
+	big.NewInt(int64(n)).FillBytes(w)
+	return w
+}
+
+// padRight returns b with zero padding to a multiple of 32 bytes.
+func padRight(b []byte) []byte {
+	return append(b, make([]byte, (32-len(b)%32)%32)...)
+}
+
+// decodeString returns the string of a single dynamic ABI output.
+func decodeString(out []byte) (string, error) {
+	if len(out) == 0 {
+		return "", nil
+	}
+	if len(out) < 64 {
+		return "", fmt.Errorf("%d-byte string output", len(out))
+	}
+	offset := new(big.Int).SetBytes(out[:32])
+	if !offset.IsInt64() || offset.Int64() > int64(len(out)-32) {
+		return "", errors.New("string offset out of bounds")
+	}
+	start := int(offset.Int64())
+	size := new(big.Int).SetBytes(out[start : start+32])
+	if !size.IsInt64() || size.Int64() > int64(len(out)-start-32) {
+		return "", errors.New("string size out of bounds")
+	}
+	return string(out[start+32 : start+32+int(size.Int64())]), nil
+}
+
+// Resolve implements the did.Resolver interface. The owner of the name
+// controls the DID. A "did" text record adds the DID to alsoKnownAs.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "ens" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := Parse(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	network, ok := r.network(id.Network)
+	if !ok {
+		return nil, nil, fmt.Errorf("did: resolve %s: network %q not configured: %w", d, id.Network, did.ErrNotFound)
+	}
+
+	record, err := r.cachedLookup(ctx, network, id.Name, opts.NoCache)
+	if errors.Is(err, errNoName) {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w: %w", d, err, did.ErrNotFound)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+
+	s := d.String()
+	doc := &did.Document{
+		Context: []any{did.ContextV1, "https://w3id.org/security/suites/secp256k1recovery-2020/v2"},
+		ID:      s,
+		VerificationMethod: []did.VerificationMethod{{
+			ID:                  s + "#owner",
+			Type:                "EcdsaSecp256k1RecoveryMethod2020",
+			Controller:          s,
+			BlockchainAccountID: fmt.Sprintf("eip155:%d:%s", network.ChainID, record.Owner),
+		}},
+		Authentication:  []did.Relationship{{Reference: s + "#owner"}},
+		AssertionMethod: []did.Relationship{{Reference: s + "#owner"}},
+	}
+	if record.Address != "" && record.Address != record.Owner {
+		doc.VerificationMethod = append(doc.VerificationMethod, did.VerificationMethod{
+			ID:                  s + "#ethereumAddress",
+			Type:                "EcdsaSecp256k1RecoveryMethod2020",
+			Controller:          s,
+			BlockchainAccountID: fmt.Sprintf("eip155:%d:%s", network.ChainID, record.Address),
+		})
+		doc.AssertionMethod = append(doc.AssertionMethod, did.Relationship{Reference: s + "#ethereumAddress"})
+	}
+	if record.DID != "" {
+		if _, err := did.Parse(record.DID); err == nil && record.DID != s {
+			doc.AlsoKnownAs = []string{record.DID}
+		}
+	}
+
+	meta := new(did.Metadata)
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
@@ -0,0 +1,135 @@
+package ens
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/didtest"
+	"github.com/ockam-network/did/internal/ethrpc"
+)
+
+func TestNamehash(t *testing.T) {
+	// examples from EIP-137
+	tests := map[string]string{
+		"":        "0x0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+	for name, want := range tests {
+		got := Namehash(name)
+		if s := ethrpc.EncodeHex(got[:]); s != want {
+			t.Errorf("%q got %s, want %s", name, s, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"vitalik.eth", "sepolia:_dev.vitalik.eth", "%F0%9F%A6%8A.eth"} {
+		if _, err := Parse(didtest.Must(did.Parse("did:ens:" + s))); err != nil {
+			t.Errorf("%s got error: %s", s, err)
+		}
+	}
+	for _, s := range []string{"eth", "Vitalik.eth", "a..eth", "ab--c.eth", "a_b.eth", "a:b:c.eth", "a%20b.eth"} {
+		if _, err := Parse(didtest.Must(did.Parse("did:ens:" + s))); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+const (
+	owner    = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	resolver = "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"
+)
+
+func TestResolve(t *testing.T) {
+	addressWord := func(hex string) string {
+		return "0x" + strings.Repeat("0", 24) + strings.TrimPrefix(hex, "0x")
+	}
+	text := "did:web:example.com"
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		var call struct{ To, Data string }
+		json.Unmarshal(req.Params[0], &call)
+		data, _ := ethrpc.DecodeHex(call.Data)
+		node := Namehash("alice.eth")
+
+		result := "0x"
+		switch {
+		case !bytes.Equal(data[4:36], node[:]):
+			result = addressWord(strings.Repeat("0", 40))
+		case bytes.Equal(data[:4], selectorOwner):
+			result = addressWord(owner)
+		case bytes.Equal(data[:4], selectorResolver):
+			result = addressWord(resolver)
+		case call.To == resolver && bytes.Equal(data[:4], selectorAddr):
+			result = addressWord(owner)
+		case call.To == resolver && bytes.Equal(data[:4], selectorText):
+			tail := make([]byte, 32)
+			tail[31] = byte(len(text))
+			tail = append(tail, padRight([]byte(text))...)
+			result = ethrpc.EncodeHex(append(word(32), tail...))
+		}
+		calls++
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	r := &Resolver{
+		Networks: []Network{{Name: "mainnet", ChainID: 1, RPCURL: srv.URL}},
+		Now:      func() time.Time { return now },
+	}
+	d := &did.DID{Method: "ens", ID: "alice.eth"}
+	doc, _, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "eip155:1:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"; len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].BlockchainAccountID != want {
+		t.Errorf("got verification methods %+v, want owner %s only", doc.VerificationMethod, want)
+	}
+	if len(doc.AlsoKnownAs) != 1 || doc.AlsoKnownAs[0] != text {
+		t.Errorf("got alsoKnownAs %q, want %q", doc.AlsoKnownAs, text)
+	}
+
+	n := calls
+	if _, _, err := r.Resolve(context.Background(), d, did.ResolutionOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != n {
+		t.Errorf("cached lookup did %d calls", calls-n)
+	}
+	now = now.Add(DefaultCacheTTL)
+	if _, _, err := r.Resolve(context.Background(), d, did.ResolutionOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if calls == n {
+		t.Error("expired lookup not renewed")
+	}
+	n = calls
+	if _, _, err := r.Resolve(context.Background(), d, did.ResolutionOptions{NoCache: true}); err != nil {
+		t.Fatal(err)
+	}
+	if calls == n {
+		t.Error("lookup with NoCache served from cache")
+	}
+
+	_, _, err = r.Resolve(context.Background(), &did.DID{Method: "ens", ID: "bob.eth"}, did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, did.ErrNotFound)
+	}
+}
@@ -0,0 +1,545 @@
+// Package ethr implements the did:ethr method, which reads the ERC-1056
+// registry over the Ethereum JSON-RPC API.
+// https://github.com/decentralized-identity/ethr-did-resolver/blob/master/doc/did-method-spec.md
+package ethr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/base58"
+	"github.com/ockam-network/did/internal/ethrpc"
+	"github.com/ockam-network/did/internal/keccak"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// DefaultRegistry is the address of the ERC-1056 deployment on most networks.
+const DefaultRegistry = "0xdca7ef03e98e0dc2b855be647c39abe984fcf21b"
+
+// A Network is an Ethereum chain with a registry.
+type Network struct {
+	// Name is the network in DIDs, like "sepolia". The name "mainnet"
+	// applies to DIDs without network too.
+	Name string
+
+	// ChainID is the EIP-155 chain identifier. DIDs may also use the
+	// hexadecimal notation as their network, like "0xaa36a7".
+	ChainID uint64
+
+	// RPCURL is the endpoint of the JSON-RPC API.
+	RPCURL string
+
+	// Registry is the contract address, with DefaultRegistry for the
+	// empty string.
+	Registry string
+}
+
+// Resolver resolves did:ethr DIDs on the configured networks.
+type Resolver struct {
+	Networks []Network
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// Now is the clock for expiry of delegates and attributes. The nil
+	// value defaults to time.Now.
+	Now func() time.Time
+}
+
+// Identifier is the decomposition of a did:ethr.
+type Identifier struct {
+	// Network is the name or the hexadecimal chain ID, if any.
+	Network string
+
+	// Address is the Ethereum address in lower case.
+	Address string
+
+	// PublicKey is the compressed secp256k1 key, if the DID has one
+	// instead of an address.
+	PublicKey []byte
+}
+
+// Parse returns the decomposition of a did:ethr.
+func Parse(d *did.DID) (*Identifier, error) {
+	if d.Method != "ethr" {
+		return nil, fmt.Errorf("did: %s not a did:ethr", d)
+	}
+	segments := strings.Split(strings.TrimPrefix(d.String(), "did:ethr:"), ":")
+	id := new(Identifier)
+	switch len(segments) {
+	case 1:
+		break
+	case 2:
+		id.Network = segments[0]
+	default:
+		return nil, fmt.Errorf("did: %s: %w: too many segments", d, did.ErrInvalidDID)
+	}
+
+	s := segments[len(segments)-1]
+	b, err := ethrpc.DecodeHex(s)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	case len(b) == 20:
+		id.Address = strings.ToLower(s)
+	case len(b) == 33 && (b[0] == 2 || b[0] == 3):
+		id.PublicKey = b
+		x, y, err := decompress(b)
+		if err != nil {
+			return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+		}
+		var xy [64]byte
+		x.FillBytes(xy[:32])
+		y.FillBytes(xy[32:])
+		sum := keccak.Sum256(xy[:])
+		id.Address = ethrpc.EncodeHex(sum[12:])
+	default:
+		return nil, fmt.Errorf("did: %s: %w: not an address nor a compressed public key", d, did.ErrInvalidDID)
+	}
+	return id, nil
+}
+
+// secp256k1 field prime
+var secp256k1P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+
+// decompress returns the point of a compressed secp256k1 key.
+func decompress(key []byte) (x, y *big.Int, err error) {
+	x = new(big.Int).SetBytes(key[1:])
+	if x.Cmp(secp256k1P) >= 0 {
+		return nil, nil, errors.New("secp256k1 coordinate out of range")
+	}
+	// y² = x³ + 7
+	y2 := new(big.Int).Exp(x, big.NewInt(3), secp256k1P)
+	y2.Add(y2, big.NewInt(7))
+	y2.Mod(y2, secp256k1P)
+	y = new(big.Int).ModSqrt(y2, secp256k1P)
+	if y == nil {
+		return nil, nil, errors.New("secp256k1 key not on curve")
+	}
+	if y.Bit(0) != uint(key[0]&1) {
+		y.Sub(secp256k1P, y)
+	}
+	return x, y, nil
+}
+
+func (r *Resolver) network(name string) (*Network, bool) {
+	if name == "" {
+		name = "mainnet"
+	}
+	for i := range r.Networks {
+		n := &r.Networks[i]
+		if n.Name == name || (name == "mainnet" && n.ChainID == 1) {
+			return n, true
+		}
+		if strings.HasPrefix(name, "0x") {
+			if id, err := strconv.ParseUint(name[2:], 16, 64); err == nil && id == n.ChainID {
+				return n, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// event signatures of ERC-1056
+var (
+	topicOwnerChanged     = ethrpc.Topic("DIDOwnerChanged(address,address,uint256)")
+	topicDelegateChanged  = ethrpc.Topic("DIDDelegateChanged(address,bytes32,address,uint256,uint256)")
+	topicAttributeChanged = ethrpc.Topic("DIDAttributeChanged(address,bytes32,bytes,uint256,uint256)")
+	selectorChanged       = ethrpc.Selector("changed(address)")
+)
+
+// An event is a decoded log of the registry.
+type event struct {
+	topic string
+	block uint64
+
+	owner      string // DIDOwnerChanged
+	delegate   string // DIDDelegateChanged
+	name       string // delegate type or attribute name
+	value      []byte // DIDAttributeChanged
+	validTo    uint64
+	prevChange uint64
+}
+
+// word returns the 32-byte slot of an ABI encoding.
+func word(data []byte, i int) []byte {
+	if len(data) < (i+1)*32 {
+		return nil
+	}
+	return data[i*32 : (i+1)*32]
+}
+
+// wordUint returns a slot as a number, saturated to the uint64 range.
+func wordUint(w []byte) uint64 {
+	if len(w) != 32 {
+		return 0
+	}
+	for _, b := range w[:24] {
+		if b != 0 {
+			return ^uint64(0)
+		}
+	}
+	var n uint64
+	for _, b := range w[24:] {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
+
+func wordAddress(w []byte) string {
+	return ethrpc.EncodeHex(w[12:])
+}
+
+func wordString(w []byte) string {
+	return string(bytes.TrimRight(w, "\x00"))
+}
+
+func decodeEvent(l *ethrpc.Log) (*event, error) {
+	if len(l.Topics) == 0 {
+		return nil, errors.New("ethr: log without topics")
+	}
+	data, err := ethrpc.DecodeHex(l.Data)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ethrpc.DecodeUint(l.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	e := &event{topic: l.Topics[0], block: block}
+	switch e.topic {
+	case topicOwnerChanged:
+		if len(data) < 64 {
+			return nil, errors.New("ethr: DIDOwnerChanged data truncated")
+		}
+		e.owner = wordAddress(word(data, 0))
+		e.prevChange = wordUint(word(data, 1))
+	case topicDelegateChanged:
+		if len(data) < 128 {
+			return nil, errors.New("ethr: DIDDelegateChanged data truncated")
+		}
+		e.name = wordString(word(data, 0))
+		e.delegate = wordAddress(word(data, 1))
+		e.validTo = wordUint(word(data, 2))
+		e.prevChange = wordUint(word(data, 3))
+	case topicAttributeChanged:
+		if len(data) < 160 {
+			return nil, errors.New("ethr: DIDAttributeChanged data truncated")
+		}
+		e.name = wordString(word(data, 0))
+		offset := wordUint(word(data, 1))
+		e.validTo = wordUint(word(data, 2))
+		e.prevChange = wordUint(word(data, 3))
+		if offset > uint64(len(data)-32) {
+			return nil, errors.New("ethr: DIDAttributeChanged value offset out of range")
+		}
+		n := wordUint(data[offset : offset+32])
+		if n > uint64(len(data))-offset-32 {
+			return nil, errors.New("ethr: DIDAttributeChanged value truncated")
+		}
+		e.value = data[offset+32 : offset+32+n]
+	default:
+		return nil, nil // not of interest
+	}
+	return e, nil
+}
+
+// history returns the registry events of an identity in chronological order.
+func history(ctx context.Context, c *ethrpc.Client, registry, address string) ([]*event, error) {
+	arg, err := ethrpc.AddressWord(address)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.EthCall(ctx, registry, append(selectorChanged[:4:4], arg...))
+	if err != nil {
+		return nil, err
+	}
+	block := wordUint(word(out, 0))
+
+	var events []*event
+	for block != 0 {
+		logs, err := c.Logs(ctx, registry, block, nil, ethrpc.EncodeHex(arg))
+		if err != nil {
+			return nil, err
+		}
+		prev := uint64(0)
+		for i := range logs {
+			e, err := decodeEvent(&logs[i])
+			if err != nil {
+				return nil, err
+			}
+			if e == nil {
+				continue
+			}
+			events = append(events, e)
+			if e.prevChange < block {
+				prev = e.prevChange
+			}
+		}
+		if prev >= block {
+			break // malformed chain
+		}
+		block = prev
+	}
+
+	// reverse into chronological order
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "ethr" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := Parse(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	network, ok := r.network(id.Network)
+	if !ok {
+		return nil, nil, fmt.Errorf("did: resolve %s: network %q not configured: %w", d, id.Network, did.ErrNotFound)
+	}
+	registry := network.Registry
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+
+	c := &ethrpc.Client{Endpoint: network.RPCURL, HTTP: r.Client}
+	events, err := history(ctx, c, registry, id.Address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	doc, meta := build(d.String(), id, network.ChainID, events, now())
+
+	if len(events) != 0 {
+		last := events[len(events)-1].block
+		meta.Document.VersionID = strconv.FormatUint(last, 10)
+		ts, err := c.BlockTime(ctx, last)
+		if err != nil {
+			return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+		}
+		meta.Document.Updated = time.Unix(ts, 0).UTC()
+	}
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
+
+// ContextSecp256k1Recovery is the JSON-LD context of the
+// EcdsaSecp256k1RecoveryMethod2020 type.
+const ContextSecp256k1Recovery = "https://w3id.org/security/suites/secp256k1recovery-2020/v2"
+
+const nullAddress = "0x0000000000000000000000000000000000000000"
+
+// An entry is a document addition from a delegate or from an attribute.
+type entry struct {
+	vm      *did.VerificationMethod
+	service *did.Service
+
+	// verification relationships
+	auth, assert, agree bool
+}
+
+// build applies the registry events to the default document.
+func build(id string, ident *Identifier, chainID uint64, events []*event, now time.Time) (*did.Document, *did.Metadata) {
+	meta := new(did.Metadata)
+	owner := ident.Address
+
+	var entries []entry
+	active := make(map[string]int) // index in entries by key
+	var index int
+	for _, e := range events {
+		index++
+		switch e.topic {
+		case topicOwnerChanged:
+			owner = e.owner
+			continue
+		case topicDelegateChanged:
+			key := e.topic + e.name + e.delegate
+			if e.validTo <= uint64(now.Unix()) {
+				if i, ok := active[key]; ok {
+					entries[i] = entry{}
+					delete(active, key)
+				}
+				continue
+			}
+			if _, ok := active[key]; ok {
+				continue
+			}
+			en := entry{vm: &did.VerificationMethod{
+				ID:                  fmt.Sprintf("%s#delegate-%d", id, index),
+				Type:                "EcdsaSecp256k1RecoveryMethod2020",
+				Controller:          id,
+				BlockchainAccountID: fmt.Sprintf("eip155:%d:%s", chainID, checksum(e.delegate)),
+			}}
+			switch e.name {
+			case "sigAuth":
+				en.auth, en.assert = true, true
+			case "veriKey":
+				en.assert = true
+			default:
+				continue
+			}
+			active[key] = len(entries)
+			entries = append(entries, en)
+		case topicAttributeChanged:
+			key := e.topic + e.name + string(e.value)
+			if e.validTo <= uint64(now.Unix()) {
+				if i, ok := active[key]; ok {
+					entries[i] = entry{}
+					delete(active, key)
+				}
+				continue
+			}
+			if _, ok := active[key]; ok {
+				continue
+			}
+			en, ok := attribute(id, index, e)
+			if !ok {
+				continue
+			}
+			active[key] = len(entries)
+			entries = append(entries, en)
+		}
+	}
+
+	doc := &did.Document{
+		Context: []any{did.ContextV1, ContextSecp256k1Recovery},
+		ID:      id,
+	}
+	if owner == nullAddress {
+		meta.Document.Deactivated = true
+		return doc, meta
+	}
+
+	controller := did.VerificationMethod{
+		ID:                  id + "#controller",
+		Type:                "EcdsaSecp256k1RecoveryMethod2020",
+		Controller:          id,
+		BlockchainAccountID: fmt.Sprintf("eip155:%d:%s", chainID, checksum(owner)),
+	}
+	doc.VerificationMethod = append(doc.VerificationMethod, controller)
+	doc.Authentication = append(doc.Authentication, did.Relationship{Reference: controller.ID})
+	doc.AssertionMethod = append(doc.AssertionMethod, did.Relationship{Reference: controller.ID})
+	if ident.PublicKey != nil && owner == ident.Address {
+		key := did.VerificationMethod{
+			ID:                 id + "#controllerKey",
+			Type:               did.TypeMultikey,
+			Controller:         id,
+			PublicKeyMultibase: multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, multibase.Secp256k1Pub), ident.PublicKey...)),
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, key)
+		doc.Authentication = append(doc.Authentication, did.Relationship{Reference: key.ID})
+		doc.AssertionMethod = append(doc.AssertionMethod, did.Relationship{Reference: key.ID})
+	}
+
+	for _, en := range entries {
+		switch {
+		case en.vm != nil:
+			doc.VerificationMethod = append(doc.VerificationMethod, *en.vm)
+			ref := did.Relationship{Reference: en.vm.ID}
+			if en.auth {
+				doc.Authentication = append(doc.Authentication, ref)
+			}
+			if en.assert {
+				doc.AssertionMethod = append(doc.AssertionMethod, ref)
+			}
+			if en.agree {
+				doc.KeyAgreement = append(doc.KeyAgreement, ref)
+			}
+		case en.service != nil:
+			doc.Service = append(doc.Service, *en.service)
+		}
+	}
+	return doc, meta
+}
+
+// keyTypes has the verification method type per attribute algorithm.
+var keyTypes = map[string]string{
+	"Secp256k1": "EcdsaSecp256k1VerificationKey2019",
+	"Ed25519":   "Ed25519VerificationKey2018",
+	"X25519":    "X25519KeyAgreementKey2019",
+}
+
+// attribute returns the document entry of an attribute, like
+// "did/pub/Ed25519/veriKey/base64" or "did/svc/MessagingService".
+func attribute(id string, index int, e *event) (en entry, ok bool) {
+	parts := strings.Split(e.name, "/")
+	if len(parts) < 3 || parts[0] != "did" {
+		return en, false
+	}
+	switch parts[1] {
+	case "pub":
+		if len(parts) < 4 {
+			return en, false
+		}
+		keyType, ok := keyTypes[parts[2]]
+		if !ok {
+			return en, false
+		}
+		// The value has the key bytes. The encoding part of the name
+		// is for presentation only, and it does not apply to Multikey.
+		en.vm = &did.VerificationMethod{
+			ID:         fmt.Sprintf("%s#delegate-%d", id, index),
+			Type:       keyType,
+			Controller: id,
+		}
+		if keyType == "EcdsaSecp256k1VerificationKey2019" {
+			en.vm.PublicKeyMultibase = multibase.Encode(multibase.Base58BTC, e.value)
+		} else {
+			// the legacy types carry the raw key in base58
+			en.vm.PublicKeyBase58 = base58.Encode(e.value)
+		}
+		switch parts[3] {
+		case "sigAuth":
+			en.auth, en.assert = true, true
+		case "veriKey":
+			en.assert = true
+		case "enc":
+			en.agree = true
+		default:
+			return en, false
+		}
+		return en, true
+
+	case "svc":
+		s := &did.Service{
+			ID:   fmt.Sprintf("%s#service-%d", id, index),
+			Type: parts[2],
+		}
+		if json.Unmarshal(e.value, &s.ServiceEndpoint) != nil || s.ServiceEndpoint.IsZero() {
+			s.ServiceEndpoint = did.ServiceEndpoint{URI: string(e.value)}
+		}
+		en.service = s
+		return en, true
+	}
+	return en, false
+}
+
+func checksum(address string) string {
+	b, err := ethrpc.DecodeHex(address)
+	if err != nil {
+		return address
+	}
+	return ethrpc.ChecksumAddress(b)
+}
@@ -0,0 +1,173 @@
+package ethr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/ethrpc"
+)
+
+func TestParse(t *testing.T) {
+	// public key of private key 1
+	d := &did.DID{Method: "ethr", ID: "0x1:0x0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"}
+	id, err := Parse(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Network != "0x1" || id.Address != "0x7e5f4552091a69125d5dfcb7b8c2659029395bdf" {
+		t.Errorf("got network %q and address %q", id.Network, id.Address)
+	}
+
+	for _, s := range []string{"0xb9c5714089478a327f09197987f16f9e5d936e", "mainnet:dev:0xb9c5714089478a327f09197987f16f9e5d936e8a", "b9c5714089478a327f09197987f16f9e5d936e8a"} {
+		if _, err := Parse(&did.DID{Method: "ethr", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+// words returns the ABI encoding of the arguments.
+func words(args ...any) string {
+	var buf []byte
+	for _, a := range args {
+		w := make([]byte, 32)
+		switch a := a.(type) {
+		case int:
+			big.NewInt(int64(a)).FillBytes(w)
+		case string:
+			if b, err := ethrpc.DecodeHex(a); err == nil {
+				copy(w[32-len(b):], b)
+			} else {
+				copy(w, a)
+			}
+		case []byte: // dynamic tail
+			n := make([]byte, 32)
+			big.NewInt(int64(len(a))).FillBytes(n)
+			w = append(n, a...)
+			for len(w)%32 != 0 {
+				w = append(w, 0)
+			}
+		}
+		buf = append(buf, w...)
+	}
+	return ethrpc.EncodeHex(buf)
+}
+
+const (
+	identity = "0xb9c5714089478a327f09197987f16f9e5d936e8a"
+	delegate = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	forever  = 1 << 40
+)
+
+// node serves the registry logs per block.
+func node(t *testing.T, latest int, logs map[int]ethrpc.Log) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		var result any
+		switch req.Method {
+		case "eth_call":
+			result = words(latest)
+		case "eth_getLogs":
+			var filter struct {
+				FromBlock string `json:"fromBlock"`
+			}
+			json.Unmarshal(req.Params[0], &filter)
+			block, _ := ethrpc.DecodeUint(filter.FromBlock)
+			result = []ethrpc.Log{logs[int(block)]}
+		case "eth_getBlockByNumber":
+			result = map[string]string{"timestamp": "0x5f5e1000"}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+}
+
+func TestResolve(t *testing.T) {
+	topic := words(identity)
+	srv := node(t, 30, map[int]ethrpc.Log{
+		10: {Topics: []string{topicDelegateChanged, topic}, Data: words("sigAuth", delegate, forever, 0), BlockNumber: "0xa"},
+		20: {Topics: []string{topicAttributeChanged, topic}, Data: words("did/svc/HubService", 128, forever, 10, []byte("https://hubs.example.com")), BlockNumber: "0x14"},
+		30: {Topics: []string{topicAttributeChanged, topic}, Data: words("did/pub/Ed25519/veriKey/base64", 128, forever, 20, make([]byte, 32)), BlockNumber: "0x1e"},
+	})
+	defer srv.Close()
+
+	r := &Resolver{Networks: []Network{{Name: "mainnet", ChainID: 1, RPCURL: srv.URL}}}
+	d := &did.DID{Method: "ethr", ID: identity}
+	doc, meta, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Document.VersionID != "30" || !meta.Document.Updated.Equal(time.Unix(0x5f5e1000, 0)) {
+		t.Errorf("got version %q updated at %s", meta.Document.VersionID, meta.Document.Updated)
+	}
+
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const id = "did:ethr:" + identity
+	const want = `{"@context":["https://www.w3.org/ns/did/v1","https://w3id.org/security/suites/secp256k1recovery-2020/v2"],"id":"` + id + `","verificationMethod":[` +
+		`{"id":"` + id + `#controller","type":"EcdsaSecp256k1RecoveryMethod2020","controller":"` + id + `","blockchainAccountId":"eip155:1:0xB9C5714089478a327F09197987f16f9E5d936E8a"},` +
+		`{"id":"` + id + `#delegate-1","type":"EcdsaSecp256k1RecoveryMethod2020","controller":"` + id + `","blockchainAccountId":"eip155:1:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},` +
+		`{"id":"` + id + `#delegate-3","type":"Ed25519VerificationKey2018","controller":"` + id + `","publicKeyBase58":"11111111111111111111111111111111"}],` +
+		`"authentication":["` + id + `#controller","` + id + `#delegate-1"],` +
+		`"assertionMethod":["` + id + `#controller","` + id + `#delegate-1","` + id + `#delegate-3"],` +
+		`"service":[{"id":"` + id + `#service-2","type":"HubService","serviceEndpoint":"https://hubs.example.com"}]}`
+	if string(got) != want {
+		t.Errorf("got document %s\nwant %s", got, want)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := r.Resolve(context.Background(), &did.DID{Method: "ethr", ID: "sepolia:" + identity}, did.ResolutionOptions{})
+		if !errors.Is(err, did.ErrNotFound) {
+			t.Errorf("unknown network got error %v, want %v", err, did.ErrNotFound)
+		}
+	})
+}
+
+func TestRevocation(t *testing.T) {
+	topic := words(identity)
+	srv := node(t, 20, map[int]ethrpc.Log{
+		10: {Topics: []string{topicDelegateChanged, topic}, Data: words("veriKey", delegate, forever, 0), BlockNumber: "0xa"},
+		20: {Topics: []string{topicDelegateChanged, topic}, Data: words("veriKey", delegate, 0, 10), BlockNumber: "0x14"},
+	})
+	defer srv.Close()
+
+	r := &Resolver{Networks: []Network{{Name: "dev", ChainID: 1337, RPCURL: srv.URL}}}
+	doc, _, err := r.Resolve(context.Background(), &did.DID{Method: "ethr", ID: "0x539:" + identity}, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.VerificationMethod) != 1 || !strings.HasSuffix(doc.VerificationMethod[0].ID, "#controller") {
+		t.Errorf("revoked delegate remains in %+v", doc.VerificationMethod)
+	}
+}
+
+func TestDeactivated(t *testing.T) {
+	srv := node(t, 10, map[int]ethrpc.Log{
+		10: {Topics: []string{topicOwnerChanged, words(identity)}, Data: words(nullAddress, 0), BlockNumber: "0xa"},
+	})
+	defer srv.Close()
+
+	r := &Resolver{Networks: []Network{{Name: "mainnet", ChainID: 1, RPCURL: srv.URL}}}
+	doc, meta, err := r.Resolve(context.Background(), &did.DID{Method: "ethr", ID: identity}, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.Document.Deactivated || len(doc.VerificationMethod) != 0 {
+		t.Errorf("got deactivated %t with %d verification methods", meta.Document.Deactivated, len(doc.VerificationMethod))
+	}
+}
@@ -0,0 +1,482 @@
+// Package hedera implements the did:hedera method, with DID messages from the
+// Hedera Consensus Service (HCS) as read from a mirror node.
+// https://github.com/hashgraph/did-method/blob/master/hedera-did-method-specification.md
+//
+// The legacy form with ";hedera:<network>:tid=" parameters does not parse, as
+// the DID syntax denies semicolons in the method-specific identifier. The
+// topic identifier follows the key with an underscore instead.
+package hedera
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/base58"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// ContextEd25519 is the JSON-LD context of Ed25519VerificationKey2020.
+const ContextEd25519 = "https://w3id.org/security/suites/ed25519-2020/v1"
+
+// DefaultMirrors has the public mirror node per network.
+var DefaultMirrors = map[string]string{
+	"mainnet":    "https://mainnet-public.mirrornode.hedera.com",
+	"testnet":    "https://testnet.mirrornode.hedera.com",
+	"previewnet": "https://previewnet.mirrornode.hedera.com",
+}
+
+var entityIDPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)$`)
+
+// Identifier is the method-specific content of a did:hedera.
+type Identifier struct {
+	Network   string            // e.g., "mainnet"
+	PublicKey ed25519.PublicKey // DID root key
+	TopicID   string            // HCS topic as shard.realm.num
+}
+
+// New returns the did:hedera of a root key, with its DID messages on the
+// topic.
+func New(id Identifier) (*did.DID, error) {
+	if _, ok := DefaultMirrors[id.Network]; !ok {
+		return nil, fmt.Errorf("did:hedera: network %q unknown", id.Network)
+	}
+	if len(id.PublicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("did:hedera: %d-byte public key", len(id.PublicKey))
+	}
+	if !entityIDPattern.MatchString(id.TopicID) {
+		return nil, fmt.Errorf("did:hedera: topic ID %q not in shard.realm.num notation", id.TopicID)
+	}
+	s := id.Network + ":" + base58.Encode(id.PublicKey) + "_" + id.TopicID
+	return &did.DID{Method: "hedera", ID: s, IDStrings: []string{id.Network, base58.Encode(id.PublicKey) + "_" + id.TopicID}}, nil
+}
+
+// Parse returns the content of a did:hedera.
+func Parse(d *did.DID) (Identifier, error) {
+	if d.Method != "hedera" {
+		return Identifier{}, fmt.Errorf("did: %s not a did:hedera", d)
+	}
+	network, s, ok := strings.Cut(strings.TrimPrefix(d.String(), "did:hedera:"), ":")
+	if !ok {
+		return Identifier{}, fmt.Errorf("did: %s: %w: no network", d, did.ErrInvalidDID)
+	}
+	if _, ok := DefaultMirrors[network]; !ok {
+		return Identifier{}, fmt.Errorf("did: %s: %w: network %q unknown", d, did.ErrInvalidDID, network)
+	}
+	key, topic, ok := strings.Cut(s, "_")
+	if !ok {
+		return Identifier{}, fmt.Errorf("did: %s: %w: no topic ID", d, did.ErrInvalidDID)
+	}
+	if !entityIDPattern.MatchString(topic) {
+		return Identifier{}, fmt.Errorf("did: %s: %w: topic ID %q not in shard.realm.num notation", d, did.ErrInvalidDID, topic)
+	}
+	pub, err := base58.Decode(key)
+	if err != nil {
+		return Identifier{}, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return Identifier{}, fmt.Errorf("did: %s: %w: %d-byte public key", d, did.ErrInvalidDID, len(pub))
+	}
+	return Identifier{Network: network, PublicKey: pub, TopicID: topic}, nil
+}
+
+// Operations of a Message.
+const (
+	Create = "create"
+	Update = "update"
+	Revoke = "revoke"
+	Delete = "delete"
+)
+
+// Message is the content of a DID message.
+type Message struct {
+	Timestamp time.Time `json:"timestamp"`
+	Operation string    `json:"operation"`
+	DID       string    `json:"did"`
+	// Event is the base64 encoding of JSON.
+	Event string `json:"event"`
+}
+
+// Envelope is the submission of a Message to a topic.
+type Envelope struct {
+	// Message is signed as is.
+	Message json.RawMessage `json:"message"`
+	// Signature is the base64 encoding of the Ed25519 signature.
+	Signature string `json:"signature"`
+}
+
+// Seal returns the envelope of a message with a signature from the DID owner.
+func Seal(m *Message, owner ed25519.PrivateKey) ([]byte, error) {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&Envelope{
+		Message:   raw,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(owner, raw)),
+	})
+}
+
+// Event is the content of a message. Exactly one of the fields is set.
+type Event struct {
+	DIDOwner                 *did.VerificationMethod `json:"DIDOwner,omitempty"`
+	VerificationMethod       *did.VerificationMethod `json:"VerificationMethod,omitempty"`
+	VerificationRelationship *Relationship           `json:"VerificationRelationship,omitempty"`
+	Service                  *did.Service            `json:"Service,omitempty"`
+}
+
+// Relationship is a verification method with its purpose.
+type Relationship struct {
+	did.VerificationMethod
+	// RelationshipType is the name of the property in the DID document,
+	// e.g., "authentication".
+	RelationshipType string `json:"relationshipType"`
+}
+
+// EncodeEvent returns the Message.Event value.
+func EncodeEvent(e *Event) (string, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+var errSignature = errors.New("did:hedera: message signature not from the DID owner")
+
+// state is the DID document under construction.
+type state struct {
+	id    string
+	owner ed25519.PublicKey
+
+	methods       []did.VerificationMethod
+	relationships map[string][]string // type → method IDs
+	services      []did.Service
+
+	deactivated bool
+	created     time.Time
+	updated     time.Time
+	versionID   string
+}
+
+// apply processes the content of an HCS message, as received at the
+// consensus timestamp.
+func (s *state) apply(content []byte, consensus string, at time.Time) error {
+	var env Envelope
+	if err := json.Unmarshal(content, &env); err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(env.Signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(s.owner, env.Message, sig) {
+		return errSignature
+	}
+	var m Message
+	if err := json.Unmarshal(env.Message, &m); err != nil {
+		return err
+	}
+	if m.DID != s.id {
+		return fmt.Errorf("did:hedera: message for %q", m.DID)
+	}
+
+	if m.Operation == Delete {
+		s.methods, s.services = nil, nil
+		clear(s.relationships)
+		s.deactivated = true
+	} else if err := s.applyEvent(m.Operation, m.Event); err != nil {
+		return err
+	}
+
+	if s.created.IsZero() {
+		s.created = at
+	}
+	s.updated, s.versionID = at, consensus
+	return nil
+}
+
+// applyEvent processes the event of a message with its operation.
+func (s *state) applyEvent(operation, event string) error {
+	eventJSON, err := base64.StdEncoding.DecodeString(event)
+	if err != nil {
+		return err
+	}
+	var e Event
+	if err := json.Unmarshal(eventJSON, &e); err != nil {
+		return err
+	}
+
+	switch operation {
+	case Create, Update:
+		switch {
+		case e.DIDOwner != nil:
+			pub, err := decodeKey(e.DIDOwner.PublicKeyMultibase)
+			if err != nil {
+				return err
+			}
+			s.owner = pub
+		case e.VerificationMethod != nil:
+			s.putMethod(*e.VerificationMethod)
+		case e.VerificationRelationship != nil:
+			r := e.VerificationRelationship
+			s.putMethod(r.VerificationMethod)
+			s.dropRelationship(r.ID)
+			s.relationships[r.RelationshipType] = append(s.relationships[r.RelationshipType], r.ID)
+		case e.Service != nil:
+			s.dropService(e.Service.ID)
+			s.services = append(s.services, *e.Service)
+		default:
+			return errors.New("did:hedera: event unknown")
+		}
+	case Revoke:
+		switch {
+		case e.VerificationMethod != nil:
+			s.dropMethod(e.VerificationMethod.ID)
+			s.dropRelationship(e.VerificationMethod.ID)
+		case e.VerificationRelationship != nil:
+			s.dropMethod(e.VerificationRelationship.ID)
+			s.dropRelationship(e.VerificationRelationship.ID)
+		case e.Service != nil:
+			s.dropService(e.Service.ID)
+		default:
+			return errors.New("did:hedera: revoke event unknown")
+		}
+	default:
+		return fmt.Errorf("did:hedera: operation %q unknown", operation)
+	}
+	return nil
+}
+
+func (s *state) putMethod(vm did.VerificationMethod) {
+	s.dropMethod(vm.ID)
+	s.methods = append(s.methods, vm)
+}
+
+func (s *state) dropMethod(id string) {
+	for i := range s.methods {
+		if s.methods[i].ID == id {
+			s.methods = append(s.methods[:i], s.methods[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *state) dropRelationship(id string) {
+	for name, ids := range s.relationships {
+		for i := range ids {
+			if ids[i] == id {
+				s.relationships[name] = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (s *state) dropService(id string) {
+	for i := range s.services {
+		if s.services[i].ID == id {
+			s.services = append(s.services[:i], s.services[i+1:]...)
+			return
+		}
+	}
+}
+
+// decodeKey reads an Ed25519 publicKeyMultibase, with or without multicodec.
+func decodeKey(s string) (ed25519.PublicKey, error) {
+	data, err := multibase.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.PublicKeySize {
+		codec, pub, err := multibase.SplitCodec(data)
+		if err != nil || codec != multibase.Ed25519Pub || len(pub) != ed25519.PublicKeySize {
+			return nil, errors.New("did:hedera: owner key not Ed25519")
+		}
+		data = pub
+	}
+	return data, nil
+}
+
+// document returns the DID document of the state.
+func (s *state) document() *did.Document {
+	doc := &did.Document{
+		Context: []any{did.ContextV1, ContextEd25519},
+		ID:      s.id,
+	}
+	if s.deactivated {
+		return doc
+	}
+	root := s.id + "#did-root-key"
+	doc.VerificationMethod = append(doc.VerificationMethod, did.VerificationMethod{
+		ID:                 root,
+		Type:               "Ed25519VerificationKey2020",
+		Controller:         s.id,
+		PublicKeyMultibase: multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, multibase.Ed25519Pub), s.owner...)),
+	})
+	doc.VerificationMethod = append(doc.VerificationMethod, s.methods...)
+	doc.Authentication = []did.Relationship{{Reference: root}}
+	doc.AssertionMethod = []did.Relationship{{Reference: root}}
+	for name, dst := range map[string]*[]did.Relationship{
+		"authentication":       &doc.Authentication,
+		"assertionMethod":      &doc.AssertionMethod,
+		"keyAgreement":         &doc.KeyAgreement,
+		"capabilityInvocation": &doc.CapabilityInvocation,
+		"capabilityDelegation": &doc.CapabilityDelegation,
+	} {
+		for _, id := range s.relationships[name] {
+			*dst = append(*dst, did.Relationship{Reference: id})
+		}
+	}
+	doc.Service = s.services
+	return doc
+}
+
+// Resolver resolves did:hedera DIDs with mirror nodes. The zero value is
+// ready for use.
+type Resolver struct {
+	// Mirrors has the base URL per network, with DefaultMirrors for
+	// networks absent.
+	Mirrors map[string]string
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// mirrorMessages is the topic messages response of a mirror node.
+type mirrorMessages struct {
+	Messages []struct {
+		ConsensusTimestamp string `json:"consensus_timestamp"`
+		Message            []byte `json:"message"`
+	} `json:"messages"`
+	Links struct {
+		Next *string `json:"next"`
+	} `json:"links"`
+}
+
+// Resolve implements the did.Resolver interface. Messages which fail to
+// apply, including those not signed by the DID owner, are ignored.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "hedera" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := Parse(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base, ok := r.Mirrors[id.Network]
+	if !ok {
+		base = DefaultMirrors[id.Network]
+	}
+	base = strings.TrimSuffix(base, "/")
+	query := url.Values{"order": {"asc"}, "limit": {"100"}}
+	if !opts.VersionTime.IsZero() {
+		t := opts.VersionTime
+		query.Set("timestamp", fmt.Sprintf("lte:%d.%09d", t.Unix(), t.Nanosecond()))
+	}
+	next := "/api/v1/topics/" + id.TopicID + "/messages?" + query.Encode()
+
+	s := state{id: d.String(), owner: id.PublicKey, relationships: make(map[string][]string)}
+	for next != "" {
+		var page mirrorMessages
+		if err := r.get(ctx, base+next, &page); err != nil {
+			return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+		}
+		for _, m := range page.Messages {
+			at, err := parseTimestamp(m.ConsensusTimestamp)
+			if err != nil {
+				return nil, nil, fmt.Errorf("did: resolve %s: mirror node consensus timestamp: %w", d, err)
+			}
+			_ = s.apply(m.Message, m.ConsensusTimestamp, at)
+			if s.deactivated || opts.VersionID != "" && s.versionID == opts.VersionID {
+				next = ""
+				break
+			}
+		}
+		if next == "" || page.Links.Next == nil {
+			break
+		}
+		next = *page.Links.Next
+	}
+	if s.created.IsZero() {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrNotFound)
+	}
+	if opts.VersionID != "" && s.versionID != opts.VersionID {
+		return nil, nil, fmt.Errorf("did: resolve %s: version %q: %w", d, opts.VersionID, did.ErrNotFound)
+	}
+
+	meta := new(did.Metadata)
+	meta.Resolution.ContentType = contentType
+	meta.Document.Created = s.created
+	meta.Document.Updated = s.updated
+	meta.Document.VersionID = s.versionID
+	meta.Document.Deactivated = s.deactivated
+	return s.document(), meta, nil
+}
+
+func (r *Resolver) get(ctx context.Context, location string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotFound:
+		return fmt.Errorf("topic absent: %w", did.ErrNotFound)
+	default:
+		return fmt.Errorf("mirror node got HTTP %q", resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<22)).Decode(v); err != nil {
+		return fmt.Errorf("malformed mirror node response: %w", err)
+	}
+	return nil
+}
+
+// parseTimestamp reads the seconds.nanoseconds notation.
+func parseTimestamp(s string) (time.Time, error) {
+	sec, nsec, _ := strings.Cut(s, ".")
+	secs, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var nsecs int64
+	if nsec != "" {
+		if len(nsec) > 9 {
+			return time.Time{}, fmt.Errorf("timestamp %q exceeds nanosecond precision", s)
+		}
+		nsecs, err = strconv.ParseInt(nsec+strings.Repeat("0", 9-len(nsec)), 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(secs, nsecs).UTC(), nil
+}
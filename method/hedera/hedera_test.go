@@ -0,0 +1,144 @@
+package hedera
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+)
+
+func TestParse(t *testing.T) {
+	pub := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	pub[0] = 42
+	d, err := New(Identifier{Network: "testnet", PublicKey: pub, TopicID: "0.0.29613327"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := did.Parse(d.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := Parse(d2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Network != "testnet" || !pub.Equal(id.PublicKey) || id.TopicID != "0.0.29613327" {
+		t.Errorf("got %+v", id)
+	}
+
+	for _, s := range []string{
+		"testnet:8LjUL78kFVnWV9rFnNCTE5bZdRmjm2obqJwS892jVLak",
+		"devnet:8LjUL78kFVnWV9rFnNCTE5bZdRmjm2obqJwS892jVLak_0.0.1",
+		"testnet:8LjUL78kFVnWV9rFnNCTE5bZdRmjm2obqJwS892jVLak_0.0",
+		"testnet:8LjUL78kFVnWV9rFnNCTE5bZdRmjm2obqJwS892jVL_0.0.1",
+	} {
+		if _, err := Parse(&did.DID{Method: "hedera", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, forger, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := New(Identifier{Network: "testnet", PublicKey: pub, TopicID: "0.0.7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm := &did.VerificationMethod{ID: d.String() + "#key-1", Type: "Ed25519VerificationKey2020", Controller: d.String(), PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}
+	svc := &did.Service{ID: d.String() + "#service-1", Type: "LinkedDomains", ServiceEndpoint: did.ServiceEndpoint{URI: "https://example.com/"}}
+
+	var contents [][]byte
+	for _, step := range []struct {
+		op  string
+		e   Event
+		key ed25519.PrivateKey
+	}{
+		{Create, Event{VerificationRelationship: &Relationship{VerificationMethod: *vm, RelationshipType: "keyAgreement"}}, priv},
+		{Create, Event{Service: svc}, priv},
+		{Revoke, Event{Service: svc}, forger},
+		{Create, Event{VerificationMethod: &did.VerificationMethod{ID: d.String() + "#key-2"}}, forger},
+	} {
+		event, err := EncodeEvent(&step.e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Seal(&Message{Timestamp: time.Now(), Operation: step.op, DID: d.String(), Event: event}, step.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, b)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/topics/0.0.7/messages" {
+			http.NotFound(w, r)
+			return
+		}
+		// one message per page
+		i, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page := map[string]any{
+			"messages": []map[string]any{{
+				"consensus_timestamp": fmt.Sprintf("1700000000.%09d", i+1),
+				"message":             contents[i],
+			}},
+			"links": map[string]any{"next": nil},
+		}
+		if i+1 < len(contents) {
+			page["links"] = map[string]any{"next": fmt.Sprintf("/api/v1/topics/0.0.7/messages?page=%d", i+1)}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	r := &Resolver{Mirrors: map[string]string{"testnet": srv.URL}}
+	doc, meta, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.VerificationMethod) != 2 || doc.VerificationMethod[1].ID != vm.ID {
+		t.Errorf("got verification methods %+v, want root key and %s", doc.VerificationMethod, vm.ID)
+	}
+	if len(doc.KeyAgreement) != 1 || doc.KeyAgreement[0].Reference != vm.ID {
+		t.Errorf("got key agreement %+v, want %s", doc.KeyAgreement, vm.ID)
+	}
+	if len(doc.Service) != 1 || doc.Service[0].ID != svc.ID {
+		t.Errorf("got services %+v, want %s only (forged revoke ignored)", doc.Service, svc.ID)
+	}
+	if meta.Document.VersionID != "1700000000.000000002" {
+		t.Errorf("got version ID %q, want the last signed message", meta.Document.VersionID)
+	}
+	if want := time.Unix(1700000000, 1).UTC(); !meta.Document.Created.Equal(want) {
+		t.Errorf("got created %s, want %s", meta.Document.Created, want)
+	}
+
+	doc, _, err = r.Resolve(context.Background(), d, did.ResolutionOptions{VersionID: "1700000000.000000001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Service) != 0 {
+		t.Errorf("version 1 got services %+v", doc.Service)
+	}
+
+	other, err := New(Identifier{Network: "testnet", PublicKey: pub, TopicID: "0.0.8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.Resolve(context.Background(), other, did.ResolutionOptions{}); !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, did.ErrNotFound)
+	}
+}
@@ -0,0 +1,108 @@
+// Package indy implements the identifier syntax of did:indy, and of the
+// legacy did:sov for the Sovrin networks.
+// https://hyperledger.github.io/indy-did-method/
+// https://sovrin-foundation.github.io/sovrin/spec/did-method-spec-template.html
+package indy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/base58"
+)
+
+// An Identifier is a DID on an Indy network.
+type Identifier struct {
+	// Namespace is the network, with an optional sub-namespace, like
+	// "sovrin" or "sovrin:staging".
+	Namespace string
+
+	// ID is the base58 of 16 bytes.
+	ID string
+}
+
+var namespacePattern = regexp.MustCompile(`^[a-z0-9_-]+(:[a-z0-9_-]+)?$`)
+
+// validID returns whether s is the base58 of 16 bytes.
+func validID(s string) error {
+	b, err := base58.Decode(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != 16 {
+		return fmt.Errorf("identifier of %d bytes, want 16", len(b))
+	}
+	return nil
+}
+
+// ParseIndy returns the decomposition of a did:indy.
+func ParseIndy(d *did.DID) (*Identifier, error) {
+	if d.Method != "indy" {
+		return nil, fmt.Errorf("did: %s not a did:indy", d)
+	}
+	s := strings.TrimPrefix(d.String(), "did:indy:")
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("did: %s: %w: no namespace", d, did.ErrInvalidDID)
+	}
+	id := &Identifier{Namespace: s[:i], ID: s[i+1:]}
+	if !namespacePattern.MatchString(id.Namespace) {
+		return nil, fmt.Errorf("did: %s: %w: malformed namespace %q", d, did.ErrInvalidDID, id.Namespace)
+	}
+	if err := validID(id.ID); err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	return id, nil
+}
+
+// ParseSov returns the decomposition of a did:sov. The namespace is "sovrin"
+// for DIDs without network, or "sovrin:" followed by the network otherwise.
+func ParseSov(d *did.DID) (*Identifier, error) {
+	if d.Method != "sov" {
+		return nil, fmt.Errorf("did: %s not a did:sov", d)
+	}
+	s := strings.TrimPrefix(d.String(), "did:sov:")
+	id := &Identifier{Namespace: "sovrin", ID: s}
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		id.Namespace += ":" + s[:i]
+		id.ID = s[i+1:]
+	}
+	if !namespacePattern.MatchString(id.Namespace) {
+		return nil, fmt.Errorf("did: %s: %w: malformed network", d, did.ErrInvalidDID)
+	}
+	if err := validID(id.ID); err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	return id, nil
+}
+
+// IndyDID returns the did:indy form.
+func (id *Identifier) IndyDID() *did.DID {
+	s := id.Namespace + ":" + id.ID
+	return &did.DID{Method: "indy", ID: s, IDStrings: strings.Split(s, ":")}
+}
+
+// SovDID returns the did:sov form. Only namespaces of the Sovrin networks
+// have one.
+func (id *Identifier) SovDID() (*did.DID, error) {
+	network, ok := strings.CutPrefix(id.Namespace, "sovrin")
+	if !ok || (network != "" && network[0] != ':') {
+		return nil, fmt.Errorf("did:indy namespace %q has no did:sov equivalent", id.Namespace)
+	}
+	s := strings.TrimPrefix(network+":"+id.ID, ":")
+	return &did.DID{Method: "sov", ID: s, IDStrings: strings.Split(s, ":")}, nil
+}
+
+// SelfCertifies returns whether the identifier derives from the Ed25519
+// verification key, either with the SHA-256 of did:indy, or with the first 16
+// bytes of the key, as in legacy did:sov.
+func (id *Identifier) SelfCertifies(verkey []byte) bool {
+	if len(verkey) != 32 {
+		return false
+	}
+	sum := sha256.Sum256(verkey)
+	return id.ID == base58.Encode(sum[:16]) || id.ID == base58.Encode(verkey[:16])
+}
@@ -0,0 +1,91 @@
+package indy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/base58"
+)
+
+func TestMapping(t *testing.T) {
+	tests := []struct{ sov, indy string }{
+		{"did:sov:WRfXPg8dantKVubE3HX8pw", "did:indy:sovrin:WRfXPg8dantKVubE3HX8pw"},
+		{"did:sov:staging:WRfXPg8dantKVubE3HX8pw", "did:indy:sovrin:staging:WRfXPg8dantKVubE3HX8pw"},
+		{"did:sov:builder:WRfXPg8dantKVubE3HX8pw", "did:indy:sovrin:builder:WRfXPg8dantKVubE3HX8pw"},
+	}
+	for _, test := range tests {
+		sov, err := did.Parse(test.sov)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := ParseSov(sov)
+		if err != nil {
+			t.Errorf("%s got error: %s", test.sov, err)
+			continue
+		}
+		if got := id.IndyDID().String(); got != test.indy {
+			t.Errorf("%s got %s, want %s", test.sov, got, test.indy)
+		}
+
+		indy, err := did.Parse(test.indy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err = ParseIndy(indy)
+		if err != nil {
+			t.Errorf("%s got error: %s", test.indy, err)
+			continue
+		}
+		back, err := id.SovDID()
+		if err != nil {
+			t.Errorf("%s got error: %s", test.indy, err)
+		} else if back.String() != test.sov {
+			t.Errorf("%s got %s, want %s", test.indy, back, test.sov)
+		}
+	}
+
+	id, err := ParseIndy(&did.DID{Method: "indy", ID: "idunion:test:WRfXPg8dantKVubE3HX8pw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := id.SovDID(); err == nil {
+		t.Error("IDunion got did:sov equivalent")
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	for _, d := range []*did.DID{
+		{Method: "indy", ID: "WRfXPg8dantKVubE3HX8pw"},
+		{Method: "indy", ID: "sovrin:WRfXPg8dantKVubE3HX8"},
+		{Method: "indy", ID: "sovrin:WRfXPg8dantKVubE3HX8pwWRfXPg8d"},
+		{Method: "indy", ID: "Sovrin:WRfXPg8dantKVubE3HX8pw"},
+		{Method: "indy", ID: "sovrin:a:b:WRfXPg8dantKVubE3HX8pw"},
+		{Method: "sov", ID: "WRfXPg8dantKVubE3HX8p0"},
+	} {
+		var err error
+		if d.Method == "sov" {
+			_, err = ParseSov(d)
+		} else {
+			_, err = ParseIndy(d)
+		}
+		if !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", d, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+func TestSelfCertifies(t *testing.T) {
+	verkey := make([]byte, 32)
+	for i := range verkey {
+		verkey[i] = byte(i)
+	}
+	legacy := &Identifier{Namespace: "sovrin", ID: base58.Encode(verkey[:16])}
+	if !legacy.SelfCertifies(verkey) {
+		t.Error("legacy identifier not self-certifying")
+	}
+	other := &Identifier{Namespace: "sovrin", ID: "WRfXPg8dantKVubE3HX8pw"}
+	if other.SelfCertifies(verkey) {
+		t.Error("foreign identifier self-certifying")
+	}
+}
@@ -0,0 +1,422 @@
+// Package ion implements the did:ion method of Sidetree. Long-form DIDs
+// resolve offline to their initial state. Short-form DIDs resolve with an ION
+// node.
+// https://identity.foundation/sidetree/spec/
+package ion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/jcs"
+)
+
+// A PublicKey is a key entry of a Sidetree document.
+type PublicKey struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	PublicKeyJWK json.RawMessage `json:"publicKeyJwk"`
+	// Purposes has verification relationships, like "authentication".
+	Purposes []string `json:"purposes,omitempty"`
+}
+
+// A Service is a service entry of a Sidetree document.
+type Service struct {
+	ID              string              `json:"id"`
+	Type            string              `json:"type"`
+	ServiceEndpoint did.ServiceEndpoint `json:"serviceEndpoint"`
+}
+
+// PatchDocument is the content of a replace patch.
+type PatchDocument struct {
+	PublicKeys []PublicKey `json:"publicKeys,omitempty"`
+	Services   []Service   `json:"services,omitempty"`
+}
+
+// Patch actions.
+const (
+	ActionReplace          = "replace"
+	ActionAddPublicKeys    = "add-public-keys"
+	ActionRemovePublicKeys = "remove-public-keys"
+	ActionAddServices      = "add-services"
+	ActionRemoveServices   = "remove-services"
+)
+
+// A Patch is a document modification.
+type Patch struct {
+	Action     string         `json:"action"`
+	Document   *PatchDocument `json:"document,omitempty"`
+	PublicKeys []PublicKey    `json:"publicKeys,omitempty"`
+	Services   []Service      `json:"services,omitempty"`
+	IDs        []string       `json:"ids,omitempty"`
+}
+
+// Delta is the set of patches of an operation.
+type Delta struct {
+	Patches          []Patch `json:"patches"`
+	UpdateCommitment string  `json:"updateCommitment"`
+}
+
+// SuffixData is the hash input of the DID suffix.
+type SuffixData struct {
+	DeltaHash          string `json:"deltaHash"`
+	RecoveryCommitment string `json:"recoveryCommitment"`
+}
+
+// InitialState is the create operation, as encoded in long-form DIDs.
+type InitialState struct {
+	SuffixData SuffixData `json:"suffixData"`
+	Delta      Delta      `json:"delta"`
+}
+
+// hashEncode returns the base64url of the SHA2-256 multihash.
+func hashEncode(data []byte) string {
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(append([]byte{0x12, 0x20}, sum[:]...))
+}
+
+// canonicalHash returns the hashEncode of the JCS of v.
+func canonicalHash(v any) (string, error) {
+	data, err := jcs.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return hashEncode(data), nil
+}
+
+// RevealValue returns the value which reveals a Commitment of the key.
+func RevealValue(jwk json.RawMessage) (string, error) {
+	data, err := jcs.Transform(jwk)
+	if err != nil {
+		return "", err
+	}
+	return hashEncode(data), nil
+}
+
+// Commitment returns the commitment to a JWK, which is the double hash of
+// its canonical form.
+func Commitment(jwk json.RawMessage) (string, error) {
+	data, err := jcs.Transform(jwk)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hashEncode(sum[:]), nil
+}
+
+// NewCreate returns the create operation of a document, with the long-form
+// and the short-form DID.
+func NewCreate(updateKey, recoveryKey json.RawMessage, doc PatchDocument) (state *InitialState, long, short *did.DID, err error) {
+	state = new(InitialState)
+	state.Delta.Patches = []Patch{{Action: ActionReplace, Document: &doc}}
+	state.Delta.UpdateCommitment, err = Commitment(updateKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("did:ion update key: %w", err)
+	}
+	state.SuffixData.RecoveryCommitment, err = Commitment(recoveryKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("did:ion recovery key: %w", err)
+	}
+	state.SuffixData.DeltaHash, err = canonicalHash(&state.Delta)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	suffix, err := canonicalHash(&state.SuffixData)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	encoded, err := jcs.Marshal(state)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	suffixState := base64.RawURLEncoding.EncodeToString(encoded)
+	short = &did.DID{Method: "ion", ID: suffix, IDStrings: []string{suffix}}
+	long = &did.DID{Method: "ion", ID: suffix + ":" + suffixState, IDStrings: []string{suffix, suffixState}}
+	return state, long, short, nil
+}
+
+// UpdateOperation is a Sidetree update request.
+type UpdateOperation struct {
+	Type        string `json:"type"`
+	DIDSuffix   string `json:"didSuffix"`
+	RevealValue string `json:"revealValue"`
+	Delta       Delta  `json:"delta"`
+	SignedData  string `json:"signedData"`
+}
+
+// NewUpdate returns an update operation, signed with the current update key.
+// The sign function gets the JWS signing input, and it returns the ES256K
+// signature, as Go's standard library has no secp256k1.
+func NewUpdate(suffix string, updateKey, nextUpdateKey json.RawMessage, patches []Patch, sign func(signingInput []byte) ([]byte, error)) (*UpdateOperation, error) {
+	op := &UpdateOperation{Type: "update", DIDSuffix: suffix}
+	var err error
+	op.RevealValue, err = RevealValue(updateKey)
+	if err != nil {
+		return nil, fmt.Errorf("did:ion update key: %w", err)
+	}
+	op.Delta.Patches = patches
+	op.Delta.UpdateCommitment, err = Commitment(nextUpdateKey)
+	if err != nil {
+		return nil, fmt.Errorf("did:ion next update key: %w", err)
+	}
+	deltaHash, err := canonicalHash(&op.Delta)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := jcs.Marshal(map[string]any{"updateKey": updateKey, "deltaHash": deltaHash})
+	if err != nil {
+		return nil, err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256K"}`)) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := sign([]byte(signingInput))
+	if err != nil {
+		return nil, fmt.Errorf("did:ion update signature: %w", err)
+	}
+	op.SignedData = signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+	return op, nil
+}
+
+// validSuffix returns whether s is a base64url SHA2-256 multihash.
+func validSuffix(s string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	return err == nil && len(b) == 34 && b[0] == 0x12 && b[1] == 0x20
+}
+
+// Split returns the short-form suffix and the long-form initial state, if
+// any, of a did:ion. Long-form DIDs are verified against their suffix.
+func Split(d *did.DID) (suffix string, state *InitialState, err error) {
+	if d.Method != "ion" {
+		return "", nil, fmt.Errorf("did: %s not a did:ion", d)
+	}
+	segments := strings.Split(strings.TrimPrefix(d.String(), "did:ion:"), ":")
+	if segments[0] == "test" && len(segments) > 1 {
+		segments = segments[1:] // testnet
+	}
+	suffix = segments[0]
+	if !validSuffix(suffix) {
+		return "", nil, fmt.Errorf("did: %s: %w: suffix not a SHA2-256 multihash", d, did.ErrInvalidDID)
+	}
+	switch len(segments) {
+	case 1:
+		return suffix, nil, nil
+	case 2:
+		break
+	default:
+		return "", nil, fmt.Errorf("did: %s: %w: too many segments", d, did.ErrInvalidDID)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(segments[1])
+	if err != nil {
+		return "", nil, fmt.Errorf("did: %s: %w: long-form encoding: %w", d, did.ErrInvalidDID, err)
+	}
+	state = new(InitialState)
+	if err := json.Unmarshal(data, state); err != nil {
+		return "", nil, fmt.Errorf("did: %s: %w: long-form state: %w", d, did.ErrInvalidDID, err)
+	}
+	if h, err := canonicalHash(&state.SuffixData); err != nil || h != suffix {
+		return "", nil, fmt.Errorf("did: %s: %w: suffix data mismatch", d, did.ErrInvalidDID)
+	}
+	// hash the delta as encoded, rather than its Go representation
+	var raw struct {
+		Delta json.RawMessage `json:"delta"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return "", nil, err
+	}
+	canonical, err := jcs.Transform(raw.Delta)
+	if err != nil || hashEncode(canonical) != state.SuffixData.DeltaHash {
+		return "", nil, fmt.Errorf("did: %s: %w: delta hash mismatch", d, did.ErrInvalidDID)
+	}
+	return suffix, state, nil
+}
+
+// Document returns the initial DID Document of a long-form did:ion.
+// Short-form DIDs get ErrNotFound, as they need an ION node.
+func Document(d *did.DID) (*did.Document, error) {
+	_, state, err := Split(d)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("did: %s: short form needs an ION node: %w", d, did.ErrNotFound)
+	}
+
+	var keys []PublicKey
+	var services []Service
+	for _, p := range state.Delta.Patches {
+		switch p.Action {
+		case ActionReplace:
+			keys, services = nil, nil
+			if p.Document != nil {
+				keys = append(keys, p.Document.PublicKeys...)
+				services = append(services, p.Document.Services...)
+			}
+		case ActionAddPublicKeys:
+			keys = append(keys, p.PublicKeys...)
+		case ActionAddServices:
+			services = append(services, p.Services...)
+		case ActionRemovePublicKeys:
+			keys = removeIDs(keys, p.IDs, func(k PublicKey) string { return k.ID })
+		case ActionRemoveServices:
+			services = removeIDs(services, p.IDs, func(s Service) string { return s.ID })
+		default:
+			return nil, fmt.Errorf("did: %s: %w: patch action %q unknown", d, did.ErrInvalidDID, p.Action)
+		}
+	}
+
+	id := d.String()
+	doc := &did.Document{
+		Context: []any{did.ContextV1, map[string]any{"@base": id}},
+		ID:      id,
+	}
+	for _, k := range keys {
+		vm := did.VerificationMethod{
+			ID:           id + "#" + k.ID,
+			Type:         k.Type,
+			Controller:   id,
+			PublicKeyJWK: k.PublicKeyJWK,
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, vm)
+		ref := did.Relationship{Reference: vm.ID}
+		for _, purpose := range k.Purposes {
+			switch purpose {
+			case "authentication":
+				doc.Authentication = append(doc.Authentication, ref)
+			case "assertionMethod":
+				doc.AssertionMethod = append(doc.AssertionMethod, ref)
+			case "keyAgreement":
+				doc.KeyAgreement = append(doc.KeyAgreement, ref)
+			case "capabilityInvocation":
+				doc.CapabilityInvocation = append(doc.CapabilityInvocation, ref)
+			case "capabilityDelegation":
+				doc.CapabilityDelegation = append(doc.CapabilityDelegation, ref)
+			}
+		}
+	}
+	for _, s := range services {
+		doc.Service = append(doc.Service, did.Service{
+			ID:              id + "#" + s.ID,
+			Type:            s.Type,
+			ServiceEndpoint: s.ServiceEndpoint,
+		})
+	}
+	return doc, nil
+}
+
+func removeIDs[T any](list []T, ids []string, idOf func(T) string) []T {
+	var kept []T
+	for _, e := range list {
+		removed := false
+		for _, id := range ids {
+			if idOf(e) == id {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// Resolver resolves long-form did:ion DIDs offline, and short-form ones with
+// an ION node, if any.
+type Resolver struct {
+	// Node is the base URL of the ION node API, like
+	// "https://ion.example.com/". Short-form DIDs get ErrNotFound when
+	// empty.
+	Node string
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "ion" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	suffix, state, err := Split(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if state == nil || r.Node != "" {
+		if r.Node == "" {
+			return nil, nil, fmt.Errorf("did: resolve %s: short form without ION node: %w", d, did.ErrNotFound)
+		}
+		doc, meta, err := r.resolveNode(ctx, d)
+		if err == nil || state == nil || !errors.Is(err, did.ErrNotFound) {
+			return doc, meta, err
+		}
+		// unpublished long form
+	}
+
+	doc, err := Document(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := &did.Metadata{Resolution: did.ResolutionMetadata{ContentType: contentType}}
+	meta.Document.EquivalentID = []string{"did:ion:" + suffix}
+	if strings.HasPrefix(d.String(), "did:ion:test:") {
+		meta.Document.EquivalentID[0] = "did:ion:test:" + suffix
+	}
+	return doc, meta, nil
+}
+
+// resolveNode fetches a resolution result from the ION node.
+func (r *Resolver) resolveNode(ctx context.Context, d *did.DID) (*did.Document, *did.Metadata, error) {
+	location := strings.TrimSuffix(r.Node, "/") + "/identifiers/" + url.PathEscape(d.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotFound:
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrNotFound)
+	default:
+		return nil, nil, fmt.Errorf("did: resolve %s: ION node got HTTP %q", d, resp.Status)
+	}
+
+	var result struct {
+		Document *did.Document        `json:"didDocument"`
+		Metadata did.DocumentMetadata `json:"didDocumentMetadata"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: malformed resolution result: %w", d, err)
+	}
+	if result.Document == nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: resolution result without document", d)
+	}
+	meta := &did.Metadata{Document: result.Metadata}
+	meta.Resolution.ContentType = did.MediaTypeJSONLD
+	return result.Document, meta, nil
+}
@@ -0,0 +1,139 @@
+package ion
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+var (
+	updateKey   = json.RawMessage(`{"kty":"EC","crv":"secp256k1","x":"nIqlRCx0eyBSXcQnqDpReSv4zuWvwKFAMqlEsvFXpKE","y":"w4CY7y8xQjhCe8mqEK4D_UM9RSgUUqAhpTlPbpRtwbk"}`)
+	recoveryKey = json.RawMessage(`{"kty":"EC","crv":"secp256k1","x":"QwB-cZd8mtgvpo8kNQbVIe4KXlQIAZCsp3RlmhPo6RI","y":"8VLbRc4VTvXbMMSV_DOrMd1qKS1K0gfkr0dkqOtBfM8"}`)
+)
+
+func testCreate(t *testing.T) (long, short *did.DID) {
+	_, long, short, err := NewCreate(updateKey, recoveryKey, PatchDocument{
+		PublicKeys: []PublicKey{{
+			ID:           "key-1",
+			Type:         "EcdsaSecp256k1VerificationKey2019",
+			PublicKeyJWK: updateKey,
+			Purposes:     []string{"authentication", "assertionMethod"},
+		}},
+		Services: []Service{{ID: "domain-1", Type: "LinkedDomains", ServiceEndpoint: did.ServiceEndpoint{URI: "https://foo.example.com"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return long, short
+}
+
+func TestLongForm(t *testing.T) {
+	long, short := testCreate(t)
+	if !did.Valid(long.String()) || !did.Valid(short.String()) {
+		t.Fatalf("invalid DIDs %s and %s", long, short)
+	}
+	if !strings.HasPrefix(long.String(), short.String()+":") {
+		t.Errorf("long form %s does not extend %s", long, short)
+	}
+
+	doc, meta, err := new(Resolver).Resolve(context.Background(), long, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Document.EquivalentID[0] != short.String() {
+		t.Errorf("got equivalent id %q, want %q", meta.Document.EquivalentID, short)
+	}
+	id := long.String()
+	if len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].ID != id+"#key-1" {
+		t.Fatalf("got verification methods %+v", doc.VerificationMethod)
+	}
+	if len(doc.Authentication) != 1 || len(doc.AssertionMethod) != 1 || doc.KeyAgreement != nil {
+		t.Errorf("got relationships %v, %v and %v", doc.Authentication, doc.AssertionMethod, doc.KeyAgreement)
+	}
+	if len(doc.Service) != 1 || doc.Service[0].ServiceEndpoint.URI != "https://foo.example.com" {
+		t.Errorf("got services %+v", doc.Service)
+	}
+
+	_, err = Document(short)
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("short form got error %v, want %v", err, did.ErrNotFound)
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		suffix, encoded, _ := strings.Cut(long.ID, ":")
+		data, _ := base64.RawURLEncoding.DecodeString(encoded)
+		data = []byte(strings.Replace(string(data), "foo.example.com", "bar.example.com", 1))
+		tampered := &did.DID{Method: "ion", ID: suffix + ":" + base64.RawURLEncoding.EncodeToString(data)}
+		if _, err := Document(tampered); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("got error %v, want %v", err, did.ErrInvalidDID)
+		}
+
+		if _, _, err := Split(&did.DID{Method: "ion", ID: "EiAb"}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("short suffix got error %v, want %v", err, did.ErrInvalidDID)
+		}
+	})
+}
+
+func TestNewUpdate(t *testing.T) {
+	var signed []byte
+	op, err := NewUpdate("EiDyOQbbZAa3aiRzeCkV7LOx3SERjjH93EXoIM3UoN4oWg", updateKey, recoveryKey, []Patch{{Action: ActionRemoveServices, IDs: []string{"domain-1"}}}, func(signingInput []byte) ([]byte, error) {
+		signed = signingInput
+		return []byte("signature"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reveal, _ := RevealValue(updateKey)
+	if op.RevealValue != reveal {
+		t.Errorf("got reveal value %q, want %q", op.RevealValue, reveal)
+	}
+	if !strings.HasPrefix(op.SignedData, string(signed)+".") {
+		t.Errorf("signed data %q does not start with signing input %q", op.SignedData, signed)
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(strings.Split(op.SignedData, ".")[1])
+	if !strings.Contains(string(payload), `"updateKey":{"crv":"secp256k1"`) {
+		t.Errorf("got payload %s", payload)
+	}
+
+	commitment, _ := Commitment(updateKey)
+	if commitment == reveal || len(commitment) != 46 {
+		t.Errorf("got commitment %q for reveal value %q", commitment, reveal)
+	}
+}
+
+func TestNode(t *testing.T) {
+	long, short := testCreate(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/identifiers/"+short.String() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"didDocument":{"id":"` + short.String() + `"},"didDocumentMetadata":{"canonicalId":"` + short.String() + `"}}`))
+	}))
+	defer srv.Close()
+
+	r := &Resolver{Node: srv.URL}
+	doc, meta, err := r.Resolve(context.Background(), short, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != short.String() || meta.Document.CanonicalID != short.String() {
+		t.Errorf("got id %q with canonical id %q", doc.ID, meta.Document.CanonicalID)
+	}
+
+	// unpublished long form falls back to the initial state
+	doc, _, err = r.Resolve(context.Background(), long, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != long.String() {
+		t.Errorf("got id %q, want long form", doc.ID)
+	}
+}
@@ -0,0 +1,179 @@
+// Package iota implements the did:iota method, with DID documents in the
+// state metadata of Alias Outputs on an IOTA network.
+// https://wiki.iota.org/identity.rs/references/specifications/iota-did-method-spec/
+//
+// The package has no IOTA node dependency. Resolution goes through the Client
+// interface instead.
+package iota
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ockam-network/did"
+)
+
+// DefaultNetwork applies when the DID has no network name.
+const DefaultNetwork = "iota"
+
+var networkPattern = regexp.MustCompile(`^[a-z0-9]{1,6}$`)
+
+// Identifier is the content of a did:iota.
+type Identifier struct {
+	Network string   // network name
+	AliasID [32]byte // Alias Output identifier
+}
+
+// Tag returns the hexadecimal notation of the Alias ID, with 0x prefix.
+func (id Identifier) Tag() string {
+	return "0x" + hex.EncodeToString(id.AliasID[:])
+}
+
+// New returns the did:iota of an identifier. The network name is omitted
+// for the DefaultNetwork.
+func New(id Identifier) (*did.DID, error) {
+	if id.Network == "" || id.Network == DefaultNetwork {
+		s := id.Tag()
+		return &did.DID{Method: "iota", ID: s, IDStrings: []string{s}}, nil
+	}
+	if !networkPattern.MatchString(id.Network) {
+		return nil, fmt.Errorf("did:iota: network name %q not 1–6 lower-case alphanumerics", id.Network)
+	}
+	return &did.DID{Method: "iota", ID: id.Network + ":" + id.Tag(), IDStrings: []string{id.Network, id.Tag()}}, nil
+}
+
+// Parse returns the content of a did:iota.
+func Parse(d *did.DID) (Identifier, error) {
+	if d.Method != "iota" {
+		return Identifier{}, fmt.Errorf("did: %s not a did:iota", d)
+	}
+	id := Identifier{Network: DefaultNetwork}
+	tag := strings.TrimPrefix(d.String(), "did:iota:")
+	if network, rest, ok := strings.Cut(tag, ":"); ok {
+		if !networkPattern.MatchString(network) {
+			return Identifier{}, fmt.Errorf("did: %s: %w: network name %q not 1–6 lower-case alphanumerics", d, did.ErrInvalidDID, network)
+		}
+		id.Network, tag = network, rest
+	}
+	if len(tag) != 66 || !strings.HasPrefix(tag, "0x") || strings.ToLower(tag) != tag {
+		return Identifier{}, fmt.Errorf("did: %s: %w: tag not a 0x-prefixed lower-case hex of 32 bytes", d, did.ErrInvalidDID)
+	}
+	if _, err := hex.Decode(id.AliasID[:], []byte(tag[2:])); err != nil {
+		return Identifier{}, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	return id, nil
+}
+
+// Client reads the ledger. Implementations may wrap the node API of choice.
+type Client interface {
+	// StateMetadata returns the state metadata of the Alias Output on
+	// the network. Errors should wrap did.ErrNotFound when the output
+	// does not exist.
+	StateMetadata(ctx context.Context, id Identifier) ([]byte, error)
+}
+
+// placeholder is the DID in state metadata, before publication.
+const placeholder = "did:0:0"
+
+// ErrStateMetadata means the Alias Output does not have a DID document.
+var ErrStateMetadata = errors.New("did:iota: malformed state metadata")
+
+// DecodeStateMetadata returns the DID document packed in state metadata.
+// Empty state metadata means deactivated.
+func DecodeStateMetadata(d *did.DID, data []byte) (*did.Document, *did.DocumentMetadata, error) {
+	if len(data) == 0 {
+		return &did.Document{ID: d.String()}, &did.DocumentMetadata{Deactivated: true}, nil
+	}
+	// "DID" ‖ version 1 ‖ encoding 0 (JSON) ‖ length (u16 little-endian)
+	if len(data) < 7 || string(data[:3]) != "DID" {
+		return nil, nil, fmt.Errorf("%w: no DID marker", ErrStateMetadata)
+	}
+	if data[3] != 1 {
+		return nil, nil, fmt.Errorf("%w: version %d not supported", ErrStateMetadata, data[3])
+	}
+	if data[4] != 0 {
+		return nil, nil, fmt.Errorf("%w: encoding %d not supported", ErrStateMetadata, data[4])
+	}
+	payload := data[7:]
+	if n := int(binary.LittleEndian.Uint16(data[5:7])); n != len(payload) {
+		return nil, nil, fmt.Errorf("%w: length %d for %d bytes", ErrStateMetadata, n, len(payload))
+	}
+
+	idJSON, err := json.Marshal(d.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	payload = bytes.ReplaceAll(payload, []byte(`"`+placeholder), idJSON[:len(idJSON)-1])
+	var content struct {
+		Doc  *did.Document        `json:"doc"`
+		Meta did.DocumentMetadata `json:"meta"`
+	}
+	if err := json.Unmarshal(payload, &content); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrStateMetadata, err)
+	}
+	if content.Doc == nil {
+		return nil, nil, fmt.Errorf("%w: no document", ErrStateMetadata)
+	}
+	content.Doc.ID = d.String()
+	return content.Doc, &content.Meta, nil
+}
+
+// EncodeStateMetadata returns the state metadata of a document, with the
+// placeholder for its DID.
+func EncodeStateMetadata(doc *did.Document, meta *did.DocumentMetadata) ([]byte, error) {
+	payload, err := json.Marshal(map[string]any{"doc": doc, "meta": meta})
+	if err != nil {
+		return nil, err
+	}
+	if doc.ID != "" {
+		idJSON, err := json.Marshal(doc.ID)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.ReplaceAll(payload, idJSON[:len(idJSON)-1], []byte(`"`+placeholder))
+	}
+	if len(payload) > 0xffff {
+		return nil, fmt.Errorf("did:iota: %d-byte document exceeds state metadata", len(payload))
+	}
+	data := append([]byte("DID\x01\x00"), byte(len(payload)), byte(len(payload)>>8))
+	return append(data, payload...), nil
+}
+
+// Resolver resolves did:iota DIDs with a Client.
+type Resolver struct {
+	Client Client
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "iota" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := Parse(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := r.Client.StateMetadata(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	doc, docMeta, err := DecodeStateMetadata(d, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+
+	meta := &did.Metadata{Document: *docMeta}
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
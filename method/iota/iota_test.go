@@ -0,0 +1,105 @@
+package iota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s       string
+		network string
+	}{
+		{"did:iota:0xe4edef97da1257e83cbeb49159cfdd2da6ac971ac447f233f8439cf29376ebfe", "iota"},
+		{"did:iota:smr:0xe4edef97da1257e83cbeb49159cfdd2da6ac971ac447f233f8439cf29376ebfe", "smr"},
+	}
+	for _, test := range tests {
+		d, err := did.Parse(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := Parse(d)
+		if err != nil {
+			t.Errorf("%s got error: %s", test.s, err)
+			continue
+		}
+		if id.Network != test.network || id.AliasID[0] != 0xe4 {
+			t.Errorf("%s got %+v", test.s, id)
+		}
+		if got, err := New(id); err != nil {
+			t.Error(err)
+		} else if got.String() != test.s {
+			t.Errorf("%s got %s", test.s, got)
+		}
+	}
+
+	for _, s := range []string{
+		"0xe4edef97da1257e83cbeb49159cfdd2da6ac971ac447f233f8439cf29376eb",
+		"0xE4EDEF97DA1257E83CBEB49159CFDD2DA6AC971AC447F233F8439CF29376EBFE",
+		"network:0xe4edef97da1257e83cbeb49159cfdd2da6ac971ac447f233f8439cf29376ebfe",
+		"e4edef97da1257e83cbeb49159cfdd2da6ac971ac447f233f8439cf29376ebfe00",
+	} {
+		if _, err := Parse(&did.DID{Method: "iota", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+type ledger map[Identifier][]byte
+
+func (l ledger) StateMetadata(ctx context.Context, id Identifier) ([]byte, error) {
+	data, ok := l[id]
+	if !ok {
+		return nil, fmt.Errorf("alias output %s: %w", id.Tag(), did.ErrNotFound)
+	}
+	return data, nil
+}
+
+func TestResolve(t *testing.T) {
+	id := Identifier{Network: "smr", AliasID: [32]byte{1, 2, 3}}
+	d, err := New(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	data, err := EncodeStateMetadata(&did.Document{
+		ID:                 d.String(),
+		VerificationMethod: []did.VerificationMethod{{ID: d.String() + "#key-1", Type: "JsonWebKey", Controller: d.String()}},
+	}, &did.DocumentMetadata{Created: created, Updated: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"controller":"did:0:0"`; !strings.Contains(string(data), want) {
+		t.Errorf("state metadata %s misses %s", data, want)
+	}
+
+	r := &Resolver{Client: ledger{id: data, {Network: "smr"}: nil}}
+	doc, meta, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vm := doc.VerificationMethod; len(vm) != 1 || vm[0].ID != d.String()+"#key-1" || vm[0].Controller != d.String() {
+		t.Errorf("got verification methods %+v", vm)
+	}
+	if !meta.Document.Created.Equal(created) {
+		t.Errorf("got created %s, want %s", meta.Document.Created, created)
+	}
+
+	deactivated, _ := New(Identifier{Network: "smr"})
+	if _, meta, err := r.Resolve(context.Background(), deactivated, did.ResolutionOptions{}); err != nil {
+		t.Error(err)
+	} else if !meta.Document.Deactivated {
+		t.Error("empty state metadata not deactivated")
+	}
+
+	absent, _ := New(Identifier{AliasID: [32]byte{9}})
+	if _, _, err := r.Resolve(context.Background(), absent, did.ResolutionOptions{}); !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, did.ErrNotFound)
+	}
+}
@@ -0,0 +1,142 @@
+// Package ipid implements the did:ipid method, with DID documents published
+// on IPNS, and validation of content identifiers (CIDs) in DIDs.
+// https://did-ipid.github.io/ipid-did-method/
+package ipid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/cid"
+)
+
+// DefaultGateway is the public IPFS gateway.
+const DefaultGateway = "https://ipfs.io"
+
+// MaxDocumentSize is the limit for DID Documents in bytes.
+const MaxDocumentSize = 1 << 20
+
+// knownCodecs are the content types accepted by ValidCID.
+var knownCodecs = map[uint64]bool{
+	cid.Raw:       true,
+	cid.DagPB:     true,
+	cid.DagCBOR:   true,
+	cid.LibP2PKey: true,
+	cid.DagJSON:   true,
+	cid.JSON:      true,
+}
+
+// ValidCID returns an error when s is not a CIDv0, nor a multibase CIDv1
+// with a known content codec and a consistent multihash. Methods which embed
+// content identifiers may use it for validation.
+func ValidCID(s string) error {
+	c, err := cid.Parse(s)
+	if err != nil {
+		return err
+	}
+	if !knownCodecs[c.Codec] {
+		return fmt.Errorf("cid: content codec %#x unknown", c.Codec)
+	}
+	return nil
+}
+
+// Validate returns an error when the DID is not a did:ipid with an IPNS
+// name, i.e., a libp2p-key CID or a legacy peer ID.
+func Validate(d *did.DID) error {
+	if d.Method != "ipid" {
+		return fmt.Errorf("did: %s not a did:ipid", d)
+	}
+	name := strings.TrimPrefix(d.String(), "did:ipid:")
+	var c *cid.CID
+	var err error
+	if strings.HasPrefix(name, "Qm") || strings.HasPrefix(name, "1") {
+		c, err = cid.ParseMultihash(name)
+	} else {
+		c, err = cid.Parse(name)
+	}
+	if err != nil {
+		return fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if c.Codec != cid.LibP2PKey {
+		return fmt.Errorf("did: %s: %w: CID with content codec %#x, want libp2p-key", d, did.ErrInvalidDID, c.Codec)
+	}
+	return nil
+}
+
+// Resolver resolves did:ipid DIDs with an IPFS gateway. The zero value is
+// ready for use.
+type Resolver struct {
+	// Gateway is the base URL, with DefaultGateway for the empty string.
+	Gateway string
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "ipid" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := Validate(d); err != nil {
+		return nil, nil, err
+	}
+
+	base := r.Gateway
+	if base == "" {
+		base = DefaultGateway
+	}
+	location := strings.TrimSuffix(base, "/") + "/ipns/" + strings.TrimPrefix(d.String(), "did:ipid:")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	req.Header.Set("Accept", "application/did+ld+json, application/json;q=0.9, application/vnd.ipld.dag-json;q=0.8")
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotFound, http.StatusGone:
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrNotFound)
+	default:
+		return nil, nil, fmt.Errorf("did: resolve %s: %s got HTTP %q", d, location, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	if len(body) > MaxDocumentSize {
+		return nil, nil, fmt.Errorf("did: resolve %s: %s exceeds %d bytes", d, location, MaxDocumentSize)
+	}
+	doc := new(did.Document)
+	if err := json.Unmarshal(body, doc); err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: malformed document: %w", d, err)
+	}
+	if doc.ID != d.String() {
+		return nil, nil, fmt.Errorf("did: resolve %s: document has id %q", d, doc.ID)
+	}
+
+	meta := new(did.Metadata)
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
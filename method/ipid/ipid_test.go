@@ -0,0 +1,55 @@
+package ipid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+const name = "k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8"
+
+func TestValidate(t *testing.T) {
+	for _, s := range []string{name, "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG", "12D3KooWD3eckifWpRn9wQpMG9R9hX3sD158z7EqHWmweQAJU5SA"} {
+		if err := Validate(&did.DID{Method: "ipid", ID: s}); err != nil {
+			t.Errorf("%s got error: %s", s, err)
+		}
+	}
+	for _, s := range []string{"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi", "k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46", "Qm0"} {
+		if err := Validate(&did.DID{Method: "ipid", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+
+	if err := ValidCID("bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ipns/"+name {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"did:ipid:` + name + `"}`))
+	}))
+	defer srv.Close()
+
+	r := &Resolver{Gateway: srv.URL}
+	doc, _, err := r.Resolve(context.Background(), &did.DID{Method: "ipid", ID: name}, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != "did:ipid:"+name {
+		t.Errorf("got document id %q", doc.ID)
+	}
+
+	_, _, err = r.Resolve(context.Background(), &did.DID{Method: "ipid", ID: "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"}, did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, did.ErrNotFound)
+	}
+}
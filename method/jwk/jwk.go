@@ -0,0 +1,138 @@
+// Package jwk implements the did:jwk method. Resolution happens offline, as
+// the DID encodes the public key.
+// https://github.com/quartzjer/did-jwk/blob/main/spec.md
+package jwk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ockam-network/did"
+)
+
+// ContextJWS2020 is the JSON-LD context of the JsonWebKey2020 type.
+const ContextJWS2020 = "https://w3id.org/security/suites/jws-2020/v1"
+
+// privateMembers are the JWK parameters of private keys.
+var privateMembers = []string{"d", "p", "q", "dp", "dq", "qi", "oth", "k"}
+
+// requiredMembers has the mandatory public key parameters per key type.
+var requiredMembers = map[string][]string{
+	"EC":  {"crv", "x", "y"},
+	"OKP": {"crv", "x"},
+	"RSA": {"n", "e"},
+}
+
+// Validate returns an error when the JSON is not a public JWK.
+func Validate(key []byte) error {
+	var members map[string]any
+	if err := json.Unmarshal(key, &members); err != nil {
+		return fmt.Errorf("malformed JWK: %w", err)
+	}
+	kty, _ := members["kty"].(string)
+	required, ok := requiredMembers[kty]
+	if !ok {
+		return fmt.Errorf("JWK key type %q not supported", kty)
+	}
+	for _, name := range required {
+		if s, _ := members[name].(string); s == "" {
+			return fmt.Errorf("JWK of type %s without %q", kty, name)
+		}
+	}
+	for _, name := range privateMembers {
+		if _, ok := members[name]; ok {
+			return fmt.Errorf("JWK has private key parameter %q", name)
+		}
+	}
+	if use, ok := members["use"]; ok && use != "sig" && use != "enc" {
+		return fmt.Errorf("JWK use %q not supported", use)
+	}
+	return nil
+}
+
+// New returns the did:jwk of a public JWK.
+func New(key []byte) (*did.DID, error) {
+	if err := Validate(key); err != nil {
+		return nil, err
+	}
+	id := base64.RawURLEncoding.EncodeToString(key)
+	return &did.DID{Method: "jwk", ID: id, IDStrings: []string{id}}, nil
+}
+
+// PublicKey returns the JWK of a did:jwk.
+func PublicKey(d *did.DID) ([]byte, error) {
+	if d.Method != "jwk" {
+		return nil, fmt.Errorf("did: %s not a did:jwk", d)
+	}
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(d.String(), "did:jwk:"))
+	if err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if err := Validate(key); err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	return key, nil
+}
+
+// Document returns the DID Document of a did:jwk. The "use" of the key limits
+// the verification relationships, with "sig" for anything but key agreement,
+// and with "enc" for key agreement only.
+func Document(d *did.DID) (*did.Document, error) {
+	key, err := PublicKey(d)
+	if err != nil {
+		return nil, err
+	}
+	var params struct {
+		Use string `json:"use"`
+	}
+	if err := json.Unmarshal(key, &params); err != nil {
+		return nil, err // validated
+	}
+
+	id := d.String()
+	ref := []did.Relationship{{Reference: id + "#0"}}
+	doc := &did.Document{
+		Context: []any{did.ContextV1, ContextJWS2020},
+		ID:      id,
+		VerificationMethod: []did.VerificationMethod{{
+			ID:           id + "#0",
+			Type:         "JsonWebKey2020",
+			Controller:   id,
+			PublicKeyJWK: key,
+		}},
+	}
+	if params.Use != "enc" {
+		doc.Authentication = ref
+		doc.AssertionMethod = ref
+		doc.CapabilityInvocation = ref
+		doc.CapabilityDelegation = ref
+	}
+	if params.Use != "sig" {
+		doc.KeyAgreement = ref
+	}
+	return doc, nil
+}
+
+// Resolver resolves did:jwk DIDs with Document. The zero value is ready for
+// use.
+type Resolver struct{}
+
+// Resolve implements the did.Resolver interface.
+func (Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "jwk" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := Document(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := &did.Metadata{Resolution: did.ResolutionMetadata{ContentType: contentType}}
+	return doc, meta, nil
+}
@@ -0,0 +1,76 @@
+package jwk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+func TestDocument(t *testing.T) {
+	// example from the specification
+	const id = "did:jwk:eyJjcnYiOiJQLTI1NiIsImt0eSI6IkVDIiwieCI6ImFjYklRaXVNczNpOF91c3pFakoydHBUdFJNNEVVM3l6OTFQSDZDZEgyVjAiLCJ5IjoiX0tjeUxqOXZXTXB0bm1LdG00NkdxRHo4d2Y3NEk1TEtncmwyR3pIM25TRSJ9"
+	d, err := did.Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, _, err := Resolver{}.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"@context":["https://www.w3.org/ns/did/v1","https://w3id.org/security/suites/jws-2020/v1"],"id":"` + id + `","verificationMethod":[{"id":"` + id + `#0","type":"JsonWebKey2020","controller":"` + id + `","publicKeyJwk":{"crv":"P-256","kty":"EC","x":"acbIQiuMs3i8_uszEjJ2tpTtRM4EU3yz91PH6CdH2V0","y":"_KcyLj9vWMptnmKtm46GqDz8wf74I5LKgrl2GzH3nSE"}}],"authentication":["` + id + `#0"],"assertionMethod":["` + id + `#0"],"keyAgreement":["` + id + `#0"],"capabilityInvocation":["` + id + `#0"],"capabilityDelegation":["` + id + `#0"]}`
+	if string(got) != want {
+		t.Errorf("got document %s\nwant %s", got, want)
+	}
+}
+
+func TestUse(t *testing.T) {
+	sig, err := New([]byte(`{"kty":"OKP","crv":"Ed25519","use":"sig","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Document(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.KeyAgreement != nil || len(doc.Authentication) != 1 {
+		t.Errorf("use sig got key agreement %v and authentication %v", doc.KeyAgreement, doc.Authentication)
+	}
+
+	enc, err := New([]byte(`{"kty":"OKP","crv":"X25519","use":"enc","x":"3p7bfXt9wbTTW2HC7OQ1Nz-DQ8hbeGdNrfx-FG-IK08"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = Document(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.KeyAgreement) != 1 || doc.Authentication != nil {
+		t.Errorf("use enc got key agreement %v and authentication %v", doc.KeyAgreement, doc.Authentication)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	for _, key := range []string{
+		`[]`,
+		`{"kty":"oct","k":"GawgguFyGrWKav7AX4VKUg"}`,
+		`{"kty":"EC","crv":"P-256","x":"acbIQiuMs3i8_uszEjJ2tpTtRM4EU3yz91PH6CdH2V0"}`,
+		`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`,
+		`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","use":"wrap"}`,
+	} {
+		if _, err := New([]byte(key)); err == nil {
+			t.Errorf("%s got no error", key)
+		}
+	}
+
+	_, err := PublicKey(&did.DID{Method: "jwk", ID: "e30"})
+	if !errors.Is(err, did.ErrInvalidDID) {
+		t.Errorf("empty object got error %v, want %v", err, did.ErrInvalidDID)
+	}
+}
@@ -0,0 +1,167 @@
+// Package key implements the did:key method. Resolution happens offline, as
+// the DID encodes the public key.
+// https://w3c-ccg.github.io/did-key-spec/
+package key
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// keySizes has the public key size in bytes for each supported multicodec.
+// The elliptic curves other than the Edwards ones are in compressed form.
+var keySizes = map[uint64]int{
+	multibase.Ed25519Pub:   32,
+	multibase.X25519Pub:    32,
+	multibase.Secp256k1Pub: 33,
+	multibase.P256Pub:      33,
+	multibase.P384Pub:      49,
+}
+
+// New returns the did:key of a public key, identified by its multicodec. It
+// panics when the codec is not supported or when the size does not match.
+func New(codec uint64, pub []byte) *did.DID {
+	if n, ok := keySizes[codec]; !ok || n != len(pub) {
+		panic(fmt.Sprintf("did:key: unsupported %d-byte key with multicodec %#x", len(pub), codec))
+	}
+	id := multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, codec), pub...))
+	return &did.DID{Method: "key", ID: id, IDStrings: []string{id}}
+}
+
+// PublicKey returns the public key of a did:key, with its multicodec.
+func PublicKey(d *did.DID) (codec uint64, pub []byte, err error) {
+	if d.Method != "key" {
+		return 0, nil, fmt.Errorf("did: %s not a did:key", d)
+	}
+	id := strings.TrimPrefix(d.String(), "did:key:")
+	if id == "" || id[0] != multibase.Base58BTC || strings.IndexByte(id, ':') >= 0 {
+		return 0, nil, fmt.Errorf("did: %s: %w: want a base58btc multibase", d, did.ErrInvalidDID)
+	}
+	data, err := multibase.Decode(id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	codec, pub, err = multibase.SplitCodec(data)
+	if err != nil {
+		return 0, nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	n, ok := keySizes[codec]
+	if !ok {
+		return 0, nil, fmt.Errorf("did: %s: %w: unsupported multicodec %#x", d, did.ErrInvalidDID, codec)
+	}
+	if len(pub) != n {
+		return 0, nil, fmt.Errorf("did: %s: %w: %d-byte key with multicodec %#x", d, did.ErrInvalidDID, len(pub), codec)
+	}
+	if n&1 == 1 && pub[0] != 2 && pub[0] != 3 {
+		return 0, nil, fmt.Errorf("did: %s: %w: key not in compressed form", d, did.ErrInvalidDID)
+	}
+	return codec, pub, nil
+}
+
+// Document returns the DID Document of a did:key. Ed25519 keys get an X25519
+// key for key agreement, derived with the birational map from RFC 7748.
+func Document(d *did.DID) (*did.Document, error) {
+	codec, pub, err := PublicKey(d)
+	if err != nil {
+		return nil, err
+	}
+	id := d.String()
+	fragment := strings.TrimPrefix(id, "did:key:")
+	vm := did.VerificationMethod{
+		ID:                 id + "#" + fragment,
+		Type:               did.TypeMultikey,
+		Controller:         id,
+		PublicKeyMultibase: fragment,
+	}
+	doc := &did.Document{
+		Context:            []any{did.ContextV1, did.ContextMultikey},
+		ID:                 id,
+		VerificationMethod: []did.VerificationMethod{vm},
+	}
+
+	if codec == multibase.X25519Pub {
+		doc.KeyAgreement = []did.Relationship{{Reference: vm.ID}}
+		return doc, nil
+	}
+	ref := []did.Relationship{{Reference: vm.ID}}
+	doc.Authentication = ref
+	doc.AssertionMethod = ref
+	doc.CapabilityInvocation = ref
+	doc.CapabilityDelegation = ref
+
+	if codec == multibase.Ed25519Pub {
+		x, err := edwardsToMontgomery(pub)
+		if err != nil {
+			return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+		}
+		agreement := New(multibase.X25519Pub, x).ID
+		doc.VerificationMethod = append(doc.VerificationMethod, did.VerificationMethod{
+			ID:                 id + "#" + agreement,
+			Type:               did.TypeMultikey,
+			Controller:         id,
+			PublicKeyMultibase: agreement,
+		})
+		doc.KeyAgreement = []did.Relationship{{Reference: id + "#" + agreement}}
+	}
+	return doc, nil
+}
+
+// Resolver resolves did:key DIDs with Document. The zero value is ready for
+// use.
+type Resolver struct{}
+
+// Resolve implements the did.Resolver interface.
+func (Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "key" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := Document(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := &did.Metadata{Resolution: did.ResolutionMetadata{ContentType: contentType}}
+	return doc, meta, nil
+}
+
+// curve25519P is the field prime 2²⁵⁵ − 19.
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// edwardsToMontgomery maps an Ed25519 public key to its X25519 equivalent,
+// with u = (1 + y) / (1 − y).
+func edwardsToMontgomery(pub []byte) ([]byte, error) {
+	le := make([]byte, 32)
+	for i := range le {
+		le[i] = pub[31-i]
+	}
+	le[0] &= 0x7f // sign bit of x
+	y := new(big.Int).SetBytes(le)
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, errors.New("non-canonical Ed25519 key")
+	}
+
+	denominator := new(big.Int).Sub(big.NewInt(1), y)
+	denominator.Mod(denominator, curve25519P)
+	if denominator.Sign() == 0 {
+		return nil, errors.New("Ed25519 key without X25519 equivalent")
+	}
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, denominator.ModInverse(denominator, curve25519P))
+	u.Mod(u, curve25519P)
+
+	out := make([]byte, 32)
+	u.FillBytes(out)
+	for i, j := 0, 31; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
@@ -0,0 +1,92 @@
+package key
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+func TestDocument(t *testing.T) {
+	d, err := did.Parse("did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, meta, err := Resolver{}.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Resolution.ContentType != "application/did+ld+json" {
+		t.Errorf("got content type %q", meta.Resolution.ContentType)
+	}
+
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"@context":["https://www.w3.org/ns/did/v1","https://w3id.org/security/multikey/v1"],"id":"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK","verificationMethod":[{"id":"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK","type":"Multikey","controller":"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"},{"id":"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p","type":"Multikey","controller":"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK","publicKeyMultibase":"z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p"}],"authentication":["did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"],"assertionMethod":["did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"],"keyAgreement":["did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p"],"capabilityInvocation":["did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"],"capabilityDelegation":["did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"]}`
+	if string(got) != want {
+		t.Errorf("got document %s\nwant %s", got, want)
+	}
+}
+
+func TestEdwardsToMontgomery(t *testing.T) {
+	for seed := byte(0); seed < 8; seed++ {
+		priv := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+		h := sha512.Sum512(priv.Seed())
+		x, err := ecdh.X25519().NewPrivateKey(h[:32])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := edwardsToMontgomery(priv.Public().(ed25519.PublicKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := x.PublicKey().Bytes(); !bytes.Equal(got, want) {
+			t.Errorf("seed %d got X25519 %#x, want %#x", seed, got, want)
+		}
+	}
+}
+
+func TestPublicKey(t *testing.T) {
+	pub := bytes.Repeat([]byte{2}, 33)
+	d := New(multibase.P256Pub, pub)
+	if d.ID[:4] != "zDna" {
+		t.Errorf("P-256 got %s, want zDna prefix", d)
+	}
+	codec, got, err := PublicKey(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codec != multibase.P256Pub || !bytes.Equal(got, pub) {
+		t.Errorf("got codec %#x with key %#x", codec, got)
+	}
+
+	doc, err := Document(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.VerificationMethod) != 1 || doc.KeyAgreement != nil {
+		t.Errorf("P-256 got %d verification methods, with key agreement %v", len(doc.VerificationMethod), doc.KeyAgreement)
+	}
+
+	for _, s := range []string{
+		"did:key:6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp",
+		"did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooW",
+		"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK:x",
+		"did:key:z0",
+	} {
+		_, _, err := PublicKey(&did.DID{Method: "key", ID: s[len("did:key:"):]})
+		if !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package ockam
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The change history is CBOR in the layout of minicbor: structures encode as
+// arrays, and enumerations as a variant index followed by the fields.
+
+var errCBOR = errors.New("did:ockam: malformed CBOR")
+
+// CBOR major types
+const (
+	majorUint  = 0
+	majorBytes = 2
+	majorText  = 3
+	majorArray = 4
+)
+
+const (
+	cborFalse = 0xf4
+	cborTrue  = 0xf5
+	cborNull  = 0xf6
+)
+
+func appendHead(dst []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= 0xff:
+		return append(dst, major|24, byte(n))
+	case n <= 0xffff:
+		return append(dst, major|25, byte(n>>8), byte(n))
+	case n <= 0xffffffff:
+		return append(dst, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major|27), n)
+	}
+}
+
+func appendBytes(dst, b []byte) []byte {
+	return append(appendHead(dst, majorBytes, uint64(len(b))), b...)
+}
+
+// decode returns the first value of data as uint64, []byte, string, []any,
+// bool or nil, and the remainder.
+func decode(data []byte, depth int) (v any, rest []byte, err error) {
+	if depth > 16 {
+		return nil, nil, fmt.Errorf("%w: nesting too deep", errCBOR)
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+	}
+	switch data[0] {
+	case cborFalse:
+		return false, data[1:], nil
+	case cborTrue:
+		return true, data[1:], nil
+	case cborNull:
+		return nil, data[1:], nil
+	}
+
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < size {
+			return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+		}
+		for _, b := range data[:size] {
+			n = n<<8 | uint64(b)
+		}
+		data = data[size:]
+	default:
+		return nil, nil, fmt.Errorf("%w: indefinite length not supported", errCBOR)
+	}
+
+	switch major {
+	case majorUint:
+		return n, data, nil
+	case majorBytes, majorText:
+		if uint64(len(data)) < n {
+			return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+		}
+		if major == majorText {
+			return string(data[:n]), data[n:], nil
+		}
+		return data[:n:n], data[n:], nil
+	case majorArray:
+		if n > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+		}
+		a := make([]any, n)
+		for i := range a {
+			a[i], data, err = decode(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return a, data, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: major type %d not supported", errCBOR, major)
+	}
+}
+
+// decodeAll returns the only value of data.
+func decodeAll(data []byte) (any, error) {
+	v, rest, err := decode(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: data after value", errCBOR)
+	}
+	return v, nil
+}
+
+// variant reads an enumeration with a single byte string field.
+func variant(v any) (index uint64, field []byte, err error) {
+	a, ok := v.([]any)
+	if !ok || len(a) != 2 {
+		return 0, nil, fmt.Errorf("%w: enumeration not an array of 2", errCBOR)
+	}
+	index, ok = a[0].(uint64)
+	fields, ok2 := a[1].([]any)
+	if !ok || !ok2 || len(fields) != 1 {
+		return 0, nil, fmt.Errorf("%w: enumeration not a variant with one field", errCBOR)
+	}
+	field, ok = fields[0].([]byte)
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: enumeration field not bytes", errCBOR)
+	}
+	return index, field, nil
+}
+
+func appendVariant(dst []byte, index uint64, field []byte) []byte {
+	dst = appendHead(dst, majorArray, 2)
+	dst = appendHead(dst, majorUint, index)
+	dst = appendHead(dst, majorArray, 1)
+	return appendBytes(dst, field)
+}
@@ -0,0 +1,396 @@
+// Package ockam implements the did:ockam method, which maps Ockam identity
+// identifiers to DIDs. The DID document derives from the change history of
+// the identity.
+// https://docs.ockam.io/reference/protocols/identities
+package ockam
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// Identifier is the hash of the first change of an identity.
+type Identifier [20]byte
+
+// String returns the Ockam notation, which is "I" followed by lower-case
+// hexadecimals.
+func (id Identifier) String() string {
+	return "I" + hex.EncodeToString(id[:])
+}
+
+// ParseIdentifier reads the Ockam notation.
+func ParseIdentifier(s string) (Identifier, error) {
+	var id Identifier
+	if len(s) != 1+2*len(id) || s[0] != 'I' || strings.ToLower(s[1:]) != s[1:] {
+		return id, fmt.Errorf("did:ockam: identifier %q not I with %d lower-case hexadecimals", s, 2*len(id))
+	}
+	if _, err := hex.Decode(id[:], []byte(s[1:])); err != nil {
+		return id, fmt.Errorf("did:ockam: identifier %q: %w", s, err)
+	}
+	return id, nil
+}
+
+// New returns the did:ockam of an identifier.
+func New(id Identifier) *did.DID {
+	s := id.String()
+	return &did.DID{Method: "ockam", ID: s, IDStrings: []string{s}}
+}
+
+// Parse returns the identifier of a did:ockam.
+func Parse(d *did.DID) (Identifier, error) {
+	if d.Method != "ockam" {
+		return Identifier{}, fmt.Errorf("did: %s not a did:ockam", d)
+	}
+	id, err := ParseIdentifier(strings.TrimPrefix(d.String(), "did:ockam:"))
+	if err != nil {
+		return Identifier{}, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	return id, nil
+}
+
+// Key types, as enumeration variants.
+const (
+	EdDSACurve25519      = 0
+	ECDSASHA256CurveP256 = 1
+)
+
+// PublicKey is a primary key of an identity.
+type PublicKey struct {
+	Type  uint64 // EdDSACurve25519 or ECDSASHA256CurveP256
+	Bytes []byte // 32 bytes or uncompressed SEC 1 point
+}
+
+// Change is an element of the change history.
+type Change struct {
+	// Data is the encoded content, as hashed and signed.
+	Data []byte
+
+	// content of Data
+	PreviousChange       []byte // hash, or nil for the first change
+	PrimaryKey           PublicKey
+	RevokeAllPurposeKeys bool
+	CreatedAt            time.Time
+	ExpiresAt            time.Time
+
+	Signature         []byte // by PrimaryKey
+	PreviousSignature []byte // by the primary key of the previous change
+}
+
+// Hash returns the change hash, which is the SHA-256 of Data, truncated to
+// 20 bytes.
+func (c *Change) Hash() [20]byte {
+	sum := sha256.Sum256(c.Data)
+	var h [20]byte
+	copy(h[:], sum[:])
+	return h
+}
+
+// ErrHistory means the change history failed verification.
+var ErrHistory = errors.New("did:ockam: invalid change history")
+
+// DecodeHistory reads the CBOR of a change history.
+func DecodeHistory(data []byte) ([]*Change, error) {
+	v, err := decodeAll(data)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("%w: not a non-empty array", ErrHistory)
+	}
+	changes := make([]*Change, len(list))
+	for i, v := range list {
+		changes[i], err = decodeChange(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: change %d: %w", ErrHistory, i, err)
+		}
+	}
+	return changes, nil
+}
+
+// decodeChange reads [data, signature, previous signature or null].
+func decodeChange(v any) (*Change, error) {
+	fields, ok := v.([]any)
+	if !ok || len(fields) != 3 {
+		return nil, fmt.Errorf("%w: change not an array of 3", errCBOR)
+	}
+	c := new(Change)
+	if c.Data, ok = fields[0].([]byte); !ok {
+		return nil, fmt.Errorf("%w: change data not bytes", errCBOR)
+	}
+	var err error
+	if _, c.Signature, err = variant(fields[1]); err != nil {
+		return nil, err
+	}
+	if fields[2] != nil {
+		if _, c.PreviousSignature, err = variant(fields[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	// versioned data: [version, data]
+	v, err = decodeAll(c.Data)
+	if err != nil {
+		return nil, err
+	}
+	versioned, ok := v.([]any)
+	if !ok || len(versioned) != 2 {
+		return nil, fmt.Errorf("%w: versioned data not an array of 2", errCBOR)
+	}
+	if version, _ := versioned[0].(uint64); version != 1 {
+		return nil, fmt.Errorf("change data version %v not supported", versioned[0])
+	}
+	raw, ok := versioned[1].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: versioned data not bytes", errCBOR)
+	}
+
+	// change data: [previous change or null, primary key, revoke, created, expires]
+	v, err = decodeAll(raw)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := v.([]any)
+	if !ok || len(data) != 5 {
+		return nil, fmt.Errorf("%w: change data not an array of 5", errCBOR)
+	}
+	if data[0] != nil {
+		if c.PreviousChange, ok = data[0].([]byte); !ok || len(c.PreviousChange) != 20 {
+			return nil, fmt.Errorf("%w: previous change not a 20-byte hash", errCBOR)
+		}
+	}
+	if c.PrimaryKey.Type, c.PrimaryKey.Bytes, err = variant(data[1]); err != nil {
+		return nil, err
+	}
+	revoke, ok1 := data[2].(bool)
+	created, ok2 := data[3].(uint64)
+	expires, ok3 := data[4].(uint64)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, fmt.Errorf("%w: change data fields", errCBOR)
+	}
+	c.RevokeAllPurposeKeys = revoke
+	c.CreatedAt = time.Unix(int64(created), 0).UTC()
+	c.ExpiresAt = time.Unix(int64(expires), 0).UTC()
+	return c, nil
+}
+
+// EncodeHistory returns the CBOR of a change history. Each Data must be set.
+func EncodeHistory(changes []*Change) []byte {
+	buf := appendHead(nil, majorArray, uint64(len(changes)))
+	for i, c := range changes {
+		buf = appendHead(buf, majorArray, 3)
+		buf = appendBytes(buf, c.Data)
+		buf = appendVariant(buf, c.PrimaryKey.Type, c.Signature)
+		if c.PreviousSignature == nil {
+			buf = append(buf, cborNull)
+		} else {
+			// previous signature has the type of the previous key
+			var prevType uint64
+			if i != 0 {
+				prevType = changes[i-1].PrimaryKey.Type
+			}
+			buf = appendVariant(buf, prevType, c.PreviousSignature)
+		}
+	}
+	return buf
+}
+
+// NewChange returns a change to an Ed25519 primary key, signed by the key
+// and, if any, by the previous primary key.
+func NewChange(prev *Change, prevKey, key ed25519.PrivateKey, created, expires time.Time) *Change {
+	c := &Change{
+		PrimaryKey: PublicKey{Type: EdDSACurve25519, Bytes: key.Public().(ed25519.PublicKey)},
+		CreatedAt:  created.Truncate(time.Second).UTC(),
+		ExpiresAt:  expires.Truncate(time.Second).UTC(),
+	}
+	data := appendHead(nil, majorArray, 5)
+	if prev == nil {
+		data = append(data, cborNull)
+	} else {
+		h := prev.Hash()
+		c.PreviousChange = h[:]
+		data = appendBytes(data, h[:])
+	}
+	data = appendVariant(data, c.PrimaryKey.Type, c.PrimaryKey.Bytes)
+	data = append(data, cborFalse)
+	data = appendHead(data, majorUint, uint64(c.CreatedAt.Unix()))
+	data = appendHead(data, majorUint, uint64(c.ExpiresAt.Unix()))
+
+	versioned := appendHead(nil, majorArray, 2)
+	versioned = appendHead(versioned, majorUint, 1)
+	c.Data = appendBytes(versioned, data)
+
+	sum := sha256.Sum256(c.Data)
+	c.Signature = ed25519.Sign(key, sum[:])
+	if prev != nil {
+		c.PreviousSignature = ed25519.Sign(prevKey, sum[:])
+	}
+	return c
+}
+
+// verify checks a signature over the hash of data. ECDSA signatures are the
+// raw concatenation of r and s.
+func verify(key PublicKey, data, sig []byte) bool {
+	sum := sha256.Sum256(data)
+	switch key.Type {
+	case EdDSACurve25519:
+		return len(key.Bytes) == ed25519.PublicKeySize && ed25519.Verify(key.Bytes, sum[:], sig)
+	case ECDSASHA256CurveP256:
+		x, y := elliptic.Unmarshal(elliptic.P256(), key.Bytes)
+		if x == nil || len(sig) != 64 {
+			return false
+		}
+		digest := sha256.Sum256(sum[:])
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		return ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+	default:
+		return false
+	}
+}
+
+// VerifyHistory checks the hash links and the signatures of the changes,
+// and it returns the identifier of the identity.
+func VerifyHistory(changes []*Change) (Identifier, error) {
+	if len(changes) == 0 {
+		return Identifier{}, fmt.Errorf("%w: no changes", ErrHistory)
+	}
+	for i, c := range changes {
+		if !verify(c.PrimaryKey, c.Data, c.Signature) {
+			return Identifier{}, fmt.Errorf("%w: change %d signature", ErrHistory, i)
+		}
+		if i == 0 {
+			if c.PreviousChange != nil || c.PreviousSignature != nil {
+				return Identifier{}, fmt.Errorf("%w: first change has a predecessor", ErrHistory)
+			}
+			continue
+		}
+		prev := changes[i-1]
+		if h := prev.Hash(); string(c.PreviousChange) != string(h[:]) {
+			return Identifier{}, fmt.Errorf("%w: change %d not linked to change %d", ErrHistory, i, i-1)
+		}
+		if !verify(prev.PrimaryKey, c.Data, c.PreviousSignature) {
+			return Identifier{}, fmt.Errorf("%w: change %d signature by previous key", ErrHistory, i)
+		}
+		if c.CreatedAt.Before(prev.CreatedAt) {
+			return Identifier{}, fmt.Errorf("%w: change %d created before change %d", ErrHistory, i, i-1)
+		}
+	}
+	return Identifier(changes[0].Hash()), nil
+}
+
+// Document returns the DID document of a verified change history. The
+// primary key of the last change is the only verification method.
+func Document(changes []*Change) (*did.Document, *did.DocumentMetadata, error) {
+	id, err := VerifyHistory(changes)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := changes[len(changes)-1]
+
+	var multikey []byte
+	switch last.PrimaryKey.Type {
+	case EdDSACurve25519:
+		multikey = append(multibase.AppendCodec(nil, multibase.Ed25519Pub), last.PrimaryKey.Bytes...)
+	case ECDSASHA256CurveP256:
+		x, y := elliptic.Unmarshal(elliptic.P256(), last.PrimaryKey.Bytes)
+		if x == nil {
+			return nil, nil, fmt.Errorf("%w: P-256 primary key not on the curve", ErrHistory)
+		}
+		multikey = append(multibase.AppendCodec(nil, multibase.P256Pub), elliptic.MarshalCompressed(elliptic.P256(), x, y)...)
+	default:
+		return nil, nil, fmt.Errorf("%w: primary key type %d not supported", ErrHistory, last.PrimaryKey.Type)
+	}
+
+	s := New(id).String()
+	primary := s + "#primary"
+	doc := &did.Document{
+		Context: []any{did.ContextV1, did.ContextMultikey},
+		ID:      s,
+		VerificationMethod: []did.VerificationMethod{{
+			ID:                 primary,
+			Type:               did.TypeMultikey,
+			Controller:         s,
+			PublicKeyMultibase: multibase.Encode(multibase.Base58BTC, multikey),
+		}},
+		Authentication:       []did.Relationship{{Reference: primary}},
+		AssertionMethod:      []did.Relationship{{Reference: primary}},
+		CapabilityInvocation: []did.Relationship{{Reference: primary}},
+		CapabilityDelegation: []did.Relationship{{Reference: primary}},
+	}
+	h := last.Hash()
+	meta := &did.DocumentMetadata{
+		Created:   changes[0].CreatedAt,
+		Updated:   last.CreatedAt,
+		VersionID: hex.EncodeToString(h[:]),
+	}
+	return doc, meta, nil
+}
+
+// Source provides the change history of identities, e.g., from an Ockam
+// node or an identity store.
+type Source interface {
+	// ChangeHistory returns the CBOR of the history. Errors should wrap
+	// did.ErrNotFound for unknown identities.
+	ChangeHistory(ctx context.Context, id Identifier) ([]byte, error)
+}
+
+// Resolver resolves did:ockam DIDs with a Source.
+type Resolver struct {
+	Source Source
+
+	// Now is the clock for expiry. The nil value defaults to time.Now.
+	Now func() time.Time
+}
+
+// Resolve implements the did.Resolver interface. Identities with an expired
+// primary key resolve as deactivated.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "ockam" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := Parse(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := r.Source.ChangeHistory(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	changes, err := DecodeHistory(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	doc, docMeta, err := Document(changes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	if doc.ID != d.String() {
+		return nil, nil, fmt.Errorf("did: resolve %s: change history of %s", d, doc.ID)
+	}
+
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	meta := &did.Metadata{Document: *docMeta}
+	meta.Document.Deactivated = !now().Before(changes[len(changes)-1].ExpiresAt)
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
@@ -0,0 +1,148 @@
+package ockam
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+)
+
+func TestParse(t *testing.T) {
+	const s = "did:ockam:I2c3f3d4ba1f8e7e0a6c27eca4bd5c8f3b0bf9a2c"
+	d, err := did.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := Parse(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := New(id).String(); got != s {
+		t.Errorf("got %s, want %s", got, s)
+	}
+
+	for _, s := range []string{
+		"2c3f3d4ba1f8e7e0a6c27eca4bd5c8f3b0bf9a2c",
+		"I2C3F3D4BA1F8E7E0A6C27ECA4BD5C8F3B0BF9A2C",
+		"I2c3f3d4ba1f8e7e0a6c27eca4bd5c8f3b0bf9a",
+		"I2c3f3d4ba1f8e7e0a6c27eca4bd5c8f3b0bf9a2g",
+	} {
+		if _, err := Parse(&did.DID{Method: "ockam", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+func testHistory(t *testing.T) (changes []*Change, keys []ed25519.PrivateKey) {
+	t.Helper()
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var prev *Change
+	var prevKey ed25519.PrivateKey
+	for i := range 3 {
+		key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{byte(i + 1)}, ed25519.SeedSize))
+		c := NewChange(prev, prevKey, key, created.AddDate(0, i, 0), created.AddDate(10, 0, 0))
+		changes = append(changes, c)
+		keys = append(keys, key)
+		prev, prevKey = c, key
+	}
+	return changes, keys
+}
+
+func TestHistory(t *testing.T) {
+	changes, keys := testHistory(t)
+	decoded, err := DecodeHistory(EncodeHistory(changes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := VerifyHistory(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != Identifier(changes[0].Hash()) {
+		t.Errorf("got identifier %s, want hash of first change", id)
+	}
+	last := decoded[len(decoded)-1]
+	if !bytes.Equal(last.PrimaryKey.Bytes, keys[2].Public().(ed25519.PublicKey)) {
+		t.Error("last primary key mismatch")
+	}
+	if !last.CreatedAt.Equal(changes[2].CreatedAt) || !last.ExpiresAt.Equal(changes[2].ExpiresAt) {
+		t.Errorf("got times %s and %s", last.CreatedAt, last.ExpiresAt)
+	}
+
+	// change signed by a key other than the previous one
+	forged := NewChange(changes[1], keys[0], keys[2], changes[2].CreatedAt, changes[2].ExpiresAt)
+	if _, err := VerifyHistory([]*Change{changes[0], changes[1], forged}); !errors.Is(err, ErrHistory) {
+		t.Errorf("forged previous signature got error %v, want %v", err, ErrHistory)
+	}
+	// change skipped
+	if _, err := VerifyHistory([]*Change{changes[0], changes[2]}); !errors.Is(err, ErrHistory) {
+		t.Errorf("broken link got error %v, want %v", err, ErrHistory)
+	}
+	// data altered
+	altered := *changes[0]
+	altered.Data = append([]byte(nil), altered.Data...)
+	altered.Data[len(altered.Data)-1]++
+	if _, err := VerifyHistory([]*Change{&altered}); !errors.Is(err, ErrHistory) {
+		t.Errorf("altered data got error %v, want %v", err, ErrHistory)
+	}
+}
+
+type store map[Identifier][]byte
+
+func (s store) ChangeHistory(ctx context.Context, id Identifier) ([]byte, error) {
+	data, ok := s[id]
+	if !ok {
+		return nil, fmt.Errorf("identity %s: %w", id, did.ErrNotFound)
+	}
+	return data, nil
+}
+
+func TestResolve(t *testing.T) {
+	changes, _ := testHistory(t)
+	id := Identifier(changes[0].Hash())
+	r := &Resolver{
+		Source: store{id: EncodeHistory(changes)},
+		Now:    func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) },
+	}
+
+	d := New(id)
+	doc, meta, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != d.String() {
+		t.Errorf("got document ID %q, want %q", doc.ID, d)
+	}
+	if len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].ID != d.String()+"#primary" {
+		t.Errorf("got verification methods %+v", doc.VerificationMethod)
+	}
+	if !meta.Document.Created.Equal(changes[0].CreatedAt) || !meta.Document.Updated.Equal(changes[2].CreatedAt) {
+		t.Errorf("got created %s and updated %s", meta.Document.Created, meta.Document.Updated)
+	}
+	h := changes[2].Hash()
+	if want := fmt.Sprintf("%x", h); meta.Document.VersionID != want {
+		t.Errorf("got version ID %q, want %q", meta.Document.VersionID, want)
+	}
+	if meta.Document.Deactivated {
+		t.Error("got deactivated before expiry")
+	}
+
+	r.Now = func() time.Time { return time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC) }
+	_, meta, err = r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.Document.Deactivated {
+		t.Error("got active after expiry")
+	}
+
+	var unknown Identifier
+	if _, _, err := r.Resolve(context.Background(), New(unknown), did.ResolutionOptions{}); !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("unknown identity got error %v, want %v", err, did.ErrNotFound)
+	}
+}
@@ -0,0 +1,440 @@
+// Package peer implements the did:peer method with numalgo 0, 2 and 4.
+// Resolution happens offline, as the DIDs encode their document.
+// https://identity.foundation/peer-did-method-spec/
+package peer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/multibase"
+	"github.com/ockam-network/did/method/key"
+)
+
+// Purpose codes of numalgo 2 elements.
+const (
+	PurposeAssertion    = 'A'
+	PurposeEncryption   = 'E' // key agreement
+	PurposeVerification = 'V' // authentication
+	PurposeInvocation   = 'I'
+	PurposeDelegation   = 'D'
+	PurposeService      = 'S'
+)
+
+// An Element is a key or a service of a numalgo 2 DID.
+type Element struct {
+	// Purpose is one of the Purpose codes.
+	Purpose byte
+
+	// Value is a multibase encoding of a multicodec key, or the
+	// abbreviated JSON of a service with PurposeService.
+	Value string
+}
+
+// Numalgo returns the variant of a did:peer, which is the first character of
+// the method-specific-id.
+func Numalgo(d *did.DID) (byte, error) {
+	if d.Method != "peer" {
+		return 0, fmt.Errorf("did: %s not a did:peer", d)
+	}
+	id := strings.TrimPrefix(d.String(), "did:peer:")
+	if id == "" {
+		return 0, fmt.Errorf("did: %s: %w", d, did.ErrInvalidDID)
+	}
+	return id[0], nil
+}
+
+// NewNumalgo0 returns the did:peer of an inception key.
+func NewNumalgo0(codec uint64, pub []byte) *did.DID {
+	id := "0" + key.New(codec, pub).ID
+	return &did.DID{Method: "peer", ID: id, IDStrings: []string{id}}
+}
+
+// NewNumalgo2 returns the did:peer of keys and services. Service values are
+// abbreviated and encoded when they do not start with a base64url character.
+func NewNumalgo2(elements []Element) (*did.DID, error) {
+	var buf strings.Builder
+	buf.WriteByte('2')
+	for _, e := range elements {
+		value := e.Value
+		switch e.Purpose {
+		case PurposeService:
+			if strings.HasPrefix(value, "{") {
+				var service map[string]any
+				if err := json.Unmarshal([]byte(value), &service); err != nil {
+					return nil, fmt.Errorf("did:peer service: %w", err)
+				}
+				abbreviated, err := json.Marshal(abbreviate(service))
+				if err != nil {
+					return nil, err
+				}
+				value = base64.RawURLEncoding.EncodeToString(abbreviated)
+			}
+		case PurposeAssertion, PurposeEncryption, PurposeVerification, PurposeInvocation, PurposeDelegation:
+			if _, _, err := decodeKey(value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("did:peer purpose %q unknown", e.Purpose)
+		}
+		buf.WriteByte('.')
+		buf.WriteByte(e.Purpose)
+		buf.WriteString(value)
+	}
+	id := buf.String()
+	return &did.DID{Method: "peer", ID: id, IDStrings: []string{id}}, nil
+}
+
+// NewNumalgo4 returns the long form and the short form of the did:peer for an
+// input document in JSON, without id.
+func NewNumalgo4(doc []byte) (long, short *did.DID, err error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return nil, nil, fmt.Errorf("did:peer input document: %w", err)
+	}
+	if _, ok := fields["id"]; ok {
+		return nil, nil, errors.New("did:peer input document has an id")
+	}
+
+	encoded := multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, codecJSON), doc...))
+	hash := hashDocument(encoded)
+	short = &did.DID{Method: "peer", ID: "4" + hash, IDStrings: []string{"4" + hash}}
+	long = &did.DID{Method: "peer", ID: "4" + hash + ":" + encoded, IDStrings: []string{"4" + hash, encoded}}
+	return long, short, nil
+}
+
+// codecJSON is the multicodec of JSON.
+const codecJSON = 0x0200
+
+// hashDocument returns the multibase of the SHA2-256 multihash.
+func hashDocument(encoded string) string {
+	sum := sha256.Sum256([]byte(encoded))
+	return multibase.Encode(multibase.Base58BTC, append([]byte{0x12, 0x20}, sum[:]...))
+}
+
+// Document returns the DID Document of a did:peer.
+func Document(d *did.DID) (*did.Document, error) {
+	numalgo, err := Numalgo(d)
+	if err != nil {
+		return nil, err
+	}
+	switch numalgo {
+	case '0':
+		return documentNumalgo0(d)
+	case '2':
+		return documentNumalgo2(d)
+	case '4':
+		return documentNumalgo4(d)
+	default:
+		return nil, fmt.Errorf("did: %s: %w: numalgo %q not supported", d, did.ErrInvalidDID, numalgo)
+	}
+}
+
+func documentNumalgo0(d *did.DID) (*did.Document, error) {
+	id := d.String()
+	doc, err := key.Document(&did.DID{Method: "key", ID: strings.TrimPrefix(id, "did:peer:0")})
+	if err != nil {
+		return nil, fmt.Errorf("did: %s: %w", d, errors.Unwrap(err))
+	}
+	old := doc.ID
+	doc.ID = id
+	for i := range doc.VerificationMethod {
+		vm := &doc.VerificationMethod[i]
+		vm.ID = id + strings.TrimPrefix(vm.ID, old)
+		vm.Controller = id
+	}
+	for _, refs := range relationships(doc) {
+		for i := range *refs {
+			(*refs)[i].Reference = id + strings.TrimPrefix((*refs)[i].Reference, old)
+		}
+	}
+	return doc, nil
+}
+
+func documentNumalgo2(d *did.DID) (*did.Document, error) {
+	id := d.String()
+	doc := &did.Document{
+		Context: []any{did.ContextV1, did.ContextMultikey},
+		ID:      id,
+	}
+	var keyCount, serviceCount int
+	for _, e := range strings.Split(strings.TrimPrefix(id, "did:peer:2"), ".")[1:] {
+		if e == "" {
+			return nil, fmt.Errorf("did: %s: %w: empty element", d, did.ErrInvalidDID)
+		}
+
+		if e[0] == PurposeService {
+			s, err := decodeService(e[1:])
+			if err != nil {
+				return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+			}
+			if s.ID == "" {
+				s.ID = "#service"
+				if serviceCount != 0 {
+					s.ID += fmt.Sprintf("-%d", serviceCount)
+				}
+			}
+			if strings.HasPrefix(s.ID, "#") {
+				s.ID = id + s.ID
+			}
+			serviceCount++
+			doc.Service = append(doc.Service, *s)
+			continue
+		}
+
+		var refs *[]did.Relationship
+		switch e[0] {
+		case PurposeAssertion:
+			refs = &doc.AssertionMethod
+		case PurposeEncryption:
+			refs = &doc.KeyAgreement
+		case PurposeVerification:
+			refs = &doc.Authentication
+		case PurposeInvocation:
+			refs = &doc.CapabilityInvocation
+		case PurposeDelegation:
+			refs = &doc.CapabilityDelegation
+		default:
+			return nil, fmt.Errorf("did: %s: %w: purpose %q unknown", d, did.ErrInvalidDID, e[0])
+		}
+		if _, _, err := decodeKey(e[1:]); err != nil {
+			return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+		}
+		keyCount++
+		vm := did.VerificationMethod{
+			ID:                 fmt.Sprintf("%s#key-%d", id, keyCount),
+			Type:               did.TypeMultikey,
+			Controller:         id,
+			PublicKeyMultibase: e[1:],
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, vm)
+		*refs = append(*refs, did.Relationship{Reference: vm.ID})
+	}
+	return doc, nil
+}
+
+// ErrShortForm means a numalgo 4 DID can not resolve without its long form.
+var ErrShortForm = fmt.Errorf("%w: did:peer:4 short form requires the long form", did.ErrNotFound)
+
+func documentNumalgo4(d *did.DID) (*did.Document, error) {
+	id := d.String()
+	hash, encoded, ok := strings.Cut(strings.TrimPrefix(id, "did:peer:4"), ":")
+	if !ok {
+		return nil, fmt.Errorf("did: %s: %w", d, ErrShortForm)
+	}
+	if hashDocument(encoded) != hash {
+		return nil, fmt.Errorf("did: %s: %w: hash mismatch", d, did.ErrInvalidDID)
+	}
+	data, err := multibase.Decode(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	codec, input, err := multibase.SplitCodec(data)
+	if err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if codec != codecJSON {
+		return nil, fmt.Errorf("did: %s: %w: multicodec %#x of input document", d, did.ErrInvalidDID, codec)
+	}
+	doc := new(did.Document)
+	if err := json.Unmarshal(input, doc); err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+
+	// contextualize
+	doc.ID = id
+	doc.AlsoKnownAs = append(doc.AlsoKnownAs, "did:peer:4"+hash)
+	for i := range doc.VerificationMethod {
+		vm := &doc.VerificationMethod[i]
+		if strings.HasPrefix(vm.ID, "#") {
+			vm.ID = id + vm.ID
+		}
+		if vm.Controller == "" {
+			vm.Controller = id
+		}
+	}
+	for _, refs := range relationships(doc) {
+		for i := range *refs {
+			r := &(*refs)[i]
+			if r.Embedded != nil {
+				if strings.HasPrefix(r.Embedded.ID, "#") {
+					r.Embedded.ID = id + r.Embedded.ID
+				}
+				if r.Embedded.Controller == "" {
+					r.Embedded.Controller = id
+				}
+			} else if strings.HasPrefix(r.Reference, "#") {
+				r.Reference = id + r.Reference
+			}
+		}
+	}
+	for i := range doc.Service {
+		if strings.HasPrefix(doc.Service[i].ID, "#") {
+			doc.Service[i].ID = id + doc.Service[i].ID
+		}
+	}
+	return doc, nil
+}
+
+// relationships returns each verification relationship of doc.
+func relationships(doc *did.Document) []*[]did.Relationship {
+	return []*[]did.Relationship{
+		&doc.Authentication,
+		&doc.AssertionMethod,
+		&doc.KeyAgreement,
+		&doc.CapabilityInvocation,
+		&doc.CapabilityDelegation,
+	}
+}
+
+// decodeKey returns the multicodec key of a multibase.
+func decodeKey(s string) (codec uint64, pub []byte, err error) {
+	if s == "" || s[0] != multibase.Base58BTC {
+		return 0, nil, errors.New("did:peer key not in base58btc multibase")
+	}
+	return key.PublicKey(&did.DID{Method: "key", ID: s})
+}
+
+// abbreviations of service keys and values
+var abbreviations = map[string]string{
+	"type":             "t",
+	"serviceEndpoint":  "s",
+	"routingKeys":      "r",
+	"accept":           "a",
+	"DIDCommMessaging": "dm",
+}
+
+// abbreviate replaces the common keys and values of a service.
+func abbreviate(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			if a, ok := abbreviations[k]; ok {
+				k = a
+			}
+			m[k] = abbreviate(e)
+		}
+		return m
+	case []any:
+		for i := range v {
+			v[i] = abbreviate(v[i])
+		}
+		return v
+	case string:
+		if v == "DIDCommMessaging" {
+			return "dm"
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// expand reverts abbreviate.
+func expand(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			for full, a := range abbreviations {
+				if k == a {
+					k = full
+					break
+				}
+			}
+			m[k] = expand(e)
+		}
+		return m
+	case []any:
+		for i := range v {
+			v[i] = expand(v[i])
+		}
+		return v
+	case string:
+		if v == "dm" {
+			return "DIDCommMessaging"
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// decodeService returns the service of an encoded element. The legacy form,
+// with routingKeys and accept next to a string serviceEndpoint, moves into an
+// endpoint object with uri.
+func decodeService(encoded string) (*did.Service, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return nil, fmt.Errorf("did:peer service encoding: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("did:peer service: %w", err)
+	}
+	fields := expand(raw).(map[string]any)
+
+	s := new(did.Service)
+	s.ID, _ = fields["id"].(string)
+	s.Type, _ = fields["type"].(string)
+	if s.Type == "" {
+		return nil, errors.New("did:peer service without type")
+	}
+	endpoint := fields["serviceEndpoint"]
+	if uri, ok := endpoint.(string); ok {
+		m := map[string]any{"uri": uri}
+		for _, name := range []string{"routingKeys", "accept"} {
+			if v, ok := fields[name]; ok {
+				m[name] = v
+			}
+		}
+		if len(m) > 1 {
+			endpoint = m
+		}
+	}
+	if endpoint == nil {
+		return nil, errors.New("did:peer service without endpoint")
+	}
+	data, err = json.Marshal(endpoint)
+	if err == nil {
+		err = json.Unmarshal(data, &s.ServiceEndpoint)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("did:peer service: %w", err)
+	}
+	return s, nil
+}
+
+// Resolver resolves did:peer DIDs with Document. The zero value is ready for
+// use.
+type Resolver struct{}
+
+// Resolve implements the did.Resolver interface.
+func (Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "peer" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := Document(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := &did.Metadata{Resolution: did.ResolutionMetadata{ContentType: contentType}}
+	if len(doc.AlsoKnownAs) != 0 && strings.HasPrefix(doc.ID, "did:peer:4") {
+		meta.Document.EquivalentID = []string{doc.AlsoKnownAs[len(doc.AlsoKnownAs)-1]}
+	}
+	return doc, meta, nil
+}
@@ -0,0 +1,142 @@
+package peer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/multibase"
+	"github.com/ockam-network/did/method/key"
+)
+
+func testKey(seed byte) string {
+	var s [ed25519.SeedSize]byte
+	s[0] = seed
+	pub := ed25519.NewKeyFromSeed(s[:]).Public().(ed25519.PublicKey)
+	return key.New(multibase.Ed25519Pub, pub).ID
+}
+
+func TestNumalgo0(t *testing.T) {
+	d, err := did.Parse("did:peer:0z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, _, err := Resolver{}.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(got); strings.Contains(s, "did:key") || !strings.Contains(s, `"keyAgreement":["did:peer:0z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p"]`) {
+		t.Errorf("got document %s", s)
+	}
+}
+
+func TestNumalgo2(t *testing.T) {
+	d, err := NewNumalgo2([]Element{
+		{PurposeVerification, testKey(1)},
+		{PurposeEncryption, testKey(2)},
+		{PurposeService, `{"type":"DIDCommMessaging","serviceEndpoint":{"uri":"https://example.com/didcomm","accept":["didcomm/v2"],"routingKeys":["did:example:123#key-1"]}}`},
+		{PurposeService, base64.RawURLEncoding.EncodeToString([]byte(`{"t":"dm","s":"https://example.com/legacy","r":["did:example:123#key-2"]}`))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !did.Valid(d.String()) {
+		t.Fatalf("invalid DID %s", d)
+	}
+	id := d.String()
+
+	doc, err := Document(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.VerificationMethod) != 2 {
+		t.Fatalf("got %d verification methods, want 2", len(doc.VerificationMethod))
+	}
+	if got, want := doc.Authentication[0].Reference, id+"#key-1"; got != want {
+		t.Errorf("got authentication %q, want %q", got, want)
+	}
+	if got, want := doc.KeyAgreement[0].Reference, id+"#key-2"; got != want {
+		t.Errorf("got key agreement %q, want %q", got, want)
+	}
+	if len(doc.Service) != 2 {
+		t.Fatalf("got %d services, want 2", len(doc.Service))
+	}
+
+	got, err := json.Marshal(doc.Service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"id":"` + id + `#service","type":"DIDCommMessaging","serviceEndpoint":{"accept":["didcomm/v2"],"routingKeys":["did:example:123#key-1"],"uri":"https://example.com/didcomm"}},{"id":"` + id + `#service-1","type":"DIDCommMessaging","serviceEndpoint":{"routingKeys":["did:example:123#key-2"],"uri":"https://example.com/legacy"}}]`
+	if string(got) != want {
+		t.Errorf("got services %s\nwant %s", got, want)
+	}
+
+	for _, s := range []string{
+		"did:peer:2.Xz6Mk",
+		"did:peer:2.Vz6Mk",
+		"did:peer:2..Vz6Mk",
+		"did:peer:2.Se30",
+	} {
+		d, err := did.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Document(d); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+func TestNumalgo4(t *testing.T) {
+	input := `{"@context":["https://www.w3.org/ns/did/v1","https://w3id.org/security/multikey/v1"],"verificationMethod":[{"id":"#key-1","type":"Multikey","publicKeyMultibase":"` + testKey(1) + `"}],"authentication":["#key-1"],"service":[{"id":"#didcomm","type":"DIDCommMessaging","serviceEndpoint":"https://example.com/didcomm"}]}`
+	long, short, err := NewNumalgo4([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(long.String(), short.String()+":z") {
+		t.Errorf("long form %s does not extend short form %s", long, short)
+	}
+
+	doc, meta, err := Resolver{}.Resolve(context.Background(), long, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := long.String()
+	if doc.ID != id || doc.AlsoKnownAs[0] != short.String() {
+		t.Errorf("got id %q, also known as %q", doc.ID, doc.AlsoKnownAs)
+	}
+	if meta.Document.EquivalentID[0] != short.String() {
+		t.Errorf("got equivalent id %q, want %q", meta.Document.EquivalentID, short)
+	}
+	if vm := doc.VerificationMethod[0]; vm.ID != id+"#key-1" || vm.Controller != id {
+		t.Errorf("got verification method %q with controller %q", vm.ID, vm.Controller)
+	}
+	if got := doc.Authentication[0].Reference; got != id+"#key-1" {
+		t.Errorf("got authentication %q", got)
+	}
+	if got := doc.Service[0].ID; got != id+"#didcomm" {
+		t.Errorf("got service %q", got)
+	}
+
+	if _, err := Document(short); !errors.Is(err, ErrShortForm) || !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("short form got error %v, want %v", err, ErrShortForm)
+	}
+
+	tampered := &did.DID{Method: "peer", ID: strings.Replace(long.ID, "4z", "4zz", 1)}
+	if _, err := Document(tampered); !errors.Is(err, did.ErrInvalidDID) {
+		t.Errorf("hash mismatch got error %v, want %v", err, did.ErrInvalidDID)
+	}
+
+	if _, _, err := NewNumalgo4([]byte(`{"id":"did:example:123"}`)); err == nil {
+		t.Error("input document with id got no error")
+	}
+}
@@ -0,0 +1,175 @@
+// Package pkh implements the did:pkh method. Resolution happens offline, as
+// the DID encodes a blockchain account.
+// https://github.com/w3c-ccg/did-pkh/blob/main/did-pkh-method-draft.md
+package pkh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/base58"
+)
+
+// An Account is a blockchain account conform CAIP-10.
+// https://github.com/ChainAgnostic/CAIPs/blob/main/CAIPs/caip-10.md
+type Account struct {
+	// Namespace is the class of blockchains, like "eip155".
+	Namespace string
+	// Reference identifies the blockchain within the namespace.
+	Reference string
+	// Address identifies the account on the blockchain.
+	Address string
+}
+
+// String returns the CAIP-10 encoding.
+func (a Account) String() string {
+	return a.Namespace + ":" + a.Reference + ":" + a.Address
+}
+
+var (
+	namespacePattern = regexp.MustCompile(`^[-a-z0-9]{3,8}$`)
+	referencePattern = regexp.MustCompile(`^[-_a-zA-Z0-9]{1,32}$`)
+	addressPattern   = regexp.MustCompile(`^[-.%a-zA-Z0-9]{1,128}$`)
+)
+
+// addressValidators have namespace specifics.
+var addressValidators = map[string]func(a Account) error{
+	"eip155": func(a Account) error {
+		if strings.TrimLeft(a.Reference, "0123456789") != "" {
+			return errors.New("EIP-155 chain ID not a decimal")
+		}
+		if len(a.Address) != 42 || !strings.HasPrefix(a.Address, "0x") || strings.Trim(a.Address[2:], "0123456789abcdefABCDEF") != "" {
+			return errors.New("Ethereum address not 0x with 40 hexadecimals")
+		}
+		return nil
+	},
+	"bip122": func(a Account) error {
+		if len(a.Reference) != 32 || strings.Trim(a.Reference, "0123456789abcdef") != "" {
+			return errors.New("BIP-122 chain ID not 32 lower-case hexadecimals")
+		}
+		if strings.HasPrefix(a.Address, "bc1") || strings.HasPrefix(a.Address, "tb1") {
+			return nil // bech32
+		}
+		if _, err := base58.Decode(a.Address); err != nil || len(a.Address) < 26 || len(a.Address) > 35 {
+			return errors.New("Bitcoin address not in base58 nor in bech32")
+		}
+		return nil
+	},
+	"solana": func(a Account) error {
+		if key, err := base58.Decode(a.Address); err != nil || len(key) != 32 {
+			return errors.New("Solana address not a base58 public key")
+		}
+		return nil
+	},
+}
+
+// ParseAccount parses a CAIP-10 account, with validation of the address for
+// the eip155, bip122 and solana namespaces.
+func ParseAccount(s string) (Account, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return Account{}, fmt.Errorf("CAIP-10 account %q not namespace:reference:address", s)
+	}
+	a := Account{Namespace: parts[0], Reference: parts[1], Address: parts[2]}
+	switch {
+	case !namespacePattern.MatchString(a.Namespace):
+		return Account{}, fmt.Errorf("CAIP-10 account %q has invalid namespace", s)
+	case !referencePattern.MatchString(a.Reference):
+		return Account{}, fmt.Errorf("CAIP-10 account %q has invalid reference", s)
+	case !addressPattern.MatchString(a.Address):
+		return Account{}, fmt.Errorf("CAIP-10 account %q has invalid address", s)
+	}
+	if validate, ok := addressValidators[a.Namespace]; ok {
+		if err := validate(a); err != nil {
+			return Account{}, fmt.Errorf("CAIP-10 account %q: %w", s, err)
+		}
+	}
+	return a, nil
+}
+
+// New returns the did:pkh of an account.
+func New(a Account) (*did.DID, error) {
+	a, err := ParseAccount(a.String())
+	if err != nil {
+		return nil, err
+	}
+	return &did.DID{
+		Method:    "pkh",
+		ID:        a.String(),
+		IDStrings: []string{a.Namespace, a.Reference, a.Address},
+	}, nil
+}
+
+// AccountOf returns the account of a did:pkh.
+func AccountOf(d *did.DID) (Account, error) {
+	if d.Method != "pkh" {
+		return Account{}, fmt.Errorf("did: %s not a did:pkh", d)
+	}
+	a, err := ParseAccount(strings.TrimPrefix(d.String(), "did:pkh:"))
+	if err != nil {
+		return Account{}, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	return a, nil
+}
+
+// methodTypes has the verification method type per namespace.
+var methodTypes = map[string]string{
+	"eip155": "EcdsaSecp256k1RecoveryMethod2020",
+	"bip122": "EcdsaSecp256k1RecoveryMethod2020",
+	"solana": "Ed25519VerificationKey2018",
+}
+
+// Document returns the DID Document of a did:pkh.
+func Document(d *did.DID) (*did.Document, error) {
+	a, err := AccountOf(d)
+	if err != nil {
+		return nil, err
+	}
+	methodType, ok := methodTypes[a.Namespace]
+	if !ok {
+		methodType = "BlockchainVerificationMethod2021"
+	}
+
+	id := d.String()
+	ref := []did.Relationship{{Reference: id + "#blockchainAccountId"}}
+	return &did.Document{
+		Context: []any{did.ContextV1, map[string]any{
+			"blockchainAccountId": "https://w3id.org/security#blockchainAccountId",
+			methodType:            "https://w3id.org/security#" + methodType,
+		}},
+		ID: id,
+		VerificationMethod: []did.VerificationMethod{{
+			ID:                  id + "#blockchainAccountId",
+			Type:                methodType,
+			Controller:          id,
+			BlockchainAccountID: a.String(),
+		}},
+		Authentication:  ref,
+		AssertionMethod: ref,
+	}, nil
+}
+
+// Resolver resolves did:pkh DIDs with Document. The zero value is ready for
+// use.
+type Resolver struct{}
+
+// Resolve implements the did.Resolver interface.
+func (Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "pkh" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := Document(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := &did.Metadata{Resolution: did.ResolutionMetadata{ContentType: contentType}}
+	return doc, meta, nil
+}
@@ -0,0 +1,75 @@
+package pkh
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+func TestDocument(t *testing.T) {
+	const id = "did:pkh:eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a"
+	d, err := did.Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Document(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"@context":["https://www.w3.org/ns/did/v1",{"EcdsaSecp256k1RecoveryMethod2020":"https://w3id.org/security#EcdsaSecp256k1RecoveryMethod2020","blockchainAccountId":"https://w3id.org/security#blockchainAccountId"}],"id":"` + id + `","verificationMethod":[{"id":"` + id + `#blockchainAccountId","type":"EcdsaSecp256k1RecoveryMethod2020","controller":"` + id + `","blockchainAccountId":"eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a"}],"authentication":["` + id + `#blockchainAccountId"],"assertionMethod":["` + id + `#blockchainAccountId"]}`
+	if string(got) != want {
+		t.Errorf("got document %s\nwant %s", got, want)
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	valid := []string{
+		"eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a",
+		"bip122:000000000019d6689c085ae165831e93:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6",
+		"bip122:000000000019d6689c085ae165831e93:bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+		"solana:4sGjMW1sUnHzSxGspuhpqLDx6wiyjNtZ:CKg5d12Jhpej1JqtmxLJgaFqqeYjxgPqToJ4LBdvG9Ev",
+		"cosmos:cosmoshub-3:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0",
+	}
+	for _, s := range valid {
+		a, err := ParseAccount(s)
+		if err != nil {
+			t.Errorf("%s got error: %s", s, err)
+			continue
+		}
+		d, err := New(a)
+		if err != nil {
+			t.Errorf("%s got error: %s", s, err)
+			continue
+		}
+		if !did.Valid(d.String()) {
+			t.Errorf("%s got invalid DID %s", s, d)
+		}
+	}
+
+	invalid := []string{
+		"eip155:1",
+		"eip155:one:0xb9c5714089478a327f09197987f16f9e5d936e8a",
+		"eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8",
+		"eip155:1:b9c5714089478a327f09197987f16f9e5d936e8a00",
+		"bip122:mainnet:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6",
+		"bip122:000000000019d6689c085ae165831e93:0OIl",
+		"solana:4sGjMW1sUnHzSxGspuhpqLDx6wiyjNtZ:abc",
+		"EIP155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a",
+		"ab:1:x",
+	}
+	for _, s := range invalid {
+		if _, err := ParseAccount(s); err == nil {
+			t.Errorf("%s got no error", s)
+		}
+		_, err := AccountOf(&did.DID{Method: "pkh", ID: s})
+		if !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("did:pkh:%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+}
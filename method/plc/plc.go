@@ -0,0 +1,389 @@
+// Package plc implements the did:plc method of AT Protocol, with verification
+// of the operation log.
+// https://web.plc.directory/spec/v0.1/did-plc
+package plc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/dagcbor"
+	"github.com/ockam-network/did/internal/multibase"
+	"github.com/ockam-network/did/internal/secp256k1"
+	"github.com/ockam-network/did/method/key"
+)
+
+// DefaultDirectory is the public PLC directory.
+const DefaultDirectory = "https://plc.directory"
+
+// A ServiceEntry is a service of an operation.
+type ServiceEntry struct {
+	Type     string `json:"type"`
+	Endpoint string `json:"endpoint"`
+}
+
+// An Operation is an entry of the log, either of type "plc_operation",
+// "plc_tombstone" or the legacy "create".
+type Operation struct {
+	Type                string                  `json:"type"`
+	RotationKeys        []string                `json:"rotationKeys,omitempty"`
+	VerificationMethods map[string]string       `json:"verificationMethods,omitempty"`
+	AlsoKnownAs         []string                `json:"alsoKnownAs,omitempty"`
+	Services            map[string]ServiceEntry `json:"services,omitempty"`
+
+	// legacy create
+	SigningKey  string `json:"signingKey,omitempty"`
+	RecoveryKey string `json:"recoveryKey,omitempty"`
+	Handle      string `json:"handle,omitempty"`
+	Service     string `json:"service,omitempty"`
+
+	// Prev is the CID of the previous operation, with nil for the
+	// genesis.
+	Prev *string `json:"prev"`
+	Sig  string  `json:"sig"`
+
+	// raw is the JSON from the log
+	raw json.RawMessage
+}
+
+// normalize converts a legacy create into a plc_operation.
+func (op *Operation) normalize() {
+	if op.Type != "create" {
+		return
+	}
+	op.RotationKeys = []string{op.RecoveryKey, op.SigningKey}
+	op.VerificationMethods = map[string]string{"atproto": op.SigningKey}
+	op.AlsoKnownAs = []string{"at://" + op.Handle}
+	op.Services = map[string]ServiceEntry{"atproto_pds": {Type: "AtprotoPersonalDataServer", Endpoint: op.Service}}
+}
+
+// A LogEntry is an element of the audit log.
+type LogEntry struct {
+	DID       string          `json:"did"`
+	Operation json.RawMessage `json:"operation"`
+	CID       string          `json:"cid"`
+	CreatedAt time.Time       `json:"createdAt"`
+
+	// Nullified is as reported by the directory. VerifyLog ignores it,
+	// as it determines the nullified operations itself.
+	Nullified bool `json:"nullified"`
+}
+
+// RecoveryWindow is the time in which a rotation key may nullify the
+// operations of a rotation key with lower priority, as measured from the
+// creation of the first nullified operation.
+const RecoveryWindow = 72 * time.Hour
+
+// unsigned returns the DAG-CBOR of an operation without signature.
+func unsigned(raw json.RawMessage) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "sig")
+	return dagcbor.Marshal(fields)
+}
+
+// ErrSignature means an operation has no valid signature of a rotation key.
+var ErrSignature = errors.New("did:plc operation signature invalid")
+
+// Half orders for the low-S rule of signatures.
+var (
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1.N, 1)
+	p256HalfN      = new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+)
+
+// verifySignature checks the signature of an operation against each key, and
+// it returns the index of the first key which matches. Signatures must have
+// the low-S form, i.e., S may not exceed half the order of the curve.
+func verifySignature(op *Operation, keys []string) (int, error) {
+	data, err := unsigned(op.raw)
+	if err != nil {
+		return 0, err
+	}
+	hash := sha256.Sum256(data)
+	sig, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(op.Sig, "="))
+	if err != nil || len(sig) != 64 {
+		return 0, ErrSignature
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+
+	for i, k := range keys {
+		codec, pub, err := key.PublicKey(&did.DID{Method: "key", ID: strings.TrimPrefix(k, "did:key:")})
+		if err != nil {
+			continue
+		}
+		switch codec {
+		case multibase.Secp256k1Pub:
+			p, err := secp256k1.Decompress(pub)
+			if err == nil && s.Cmp(secp256k1HalfN) <= 0 && secp256k1.Verify(p, hash[:], r, s) {
+				return i, nil
+			}
+		case multibase.P256Pub:
+			x, y := elliptic.UnmarshalCompressed(elliptic.P256(), pub)
+			if x != nil && s.Cmp(p256HalfN) <= 0 && ecdsa.Verify(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, hash[:], r, s) {
+				return i, nil
+			}
+		}
+	}
+	return 0, ErrSignature
+}
+
+// GenesisDID returns the did:plc of a signed genesis operation.
+func GenesisDID(raw json.RawMessage) (string, error) {
+	data, err := dagcbor.MarshalJSON(raw)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "did:plc:" + dagcbor.Base32(sum[:])[:24], nil
+}
+
+// VerifyLog checks the audit log of a DID, and it returns the operation in
+// effect. Each operation must link to an earlier one, and it must have a
+// signature of one of the rotation keys of that operation. Operations which
+// link to the latest operation extend the history. Others nullify each of the
+// operations after the one they link to, which requires a rotation key of
+// higher priority than the one of the first nullified operation, within the
+// RecoveryWindow. The log must be in order of creation.
+func VerifyLog(d *did.DID, log []LogEntry) (*Operation, error) {
+	history, err := verifyLog(d, log)
+	if err != nil {
+		return nil, err
+	}
+	return history[len(history)-1].op, nil
+}
+
+// historyEntry is an operation which is not nullified.
+type historyEntry struct {
+	op    *Operation
+	entry *LogEntry
+	// signer is the index of the rotation key of the signature
+	signer int
+}
+
+// verifyLog returns the history in effect, conform VerifyLog.
+func verifyLog(d *did.DID, log []LogEntry) ([]historyEntry, error) {
+	id := d.String()
+	var history []historyEntry
+	for i := range log {
+		entry := &log[i]
+		if entry.DID != id {
+			return nil, fmt.Errorf("did: %s: log entry %d of %s", d, i, entry.DID)
+		}
+
+		op := &Operation{raw: entry.Operation}
+		if err := json.Unmarshal(entry.Operation, op); err != nil {
+			return nil, fmt.Errorf("did: %s: log entry %d: %w", d, i, err)
+		}
+		encoded, err := dagcbor.MarshalJSON(entry.Operation)
+		if err != nil {
+			return nil, fmt.Errorf("did: %s: log entry %d: %w", d, i, err)
+		}
+		if cid := dagcbor.CID(encoded); cid != entry.CID {
+			return nil, fmt.Errorf("did: %s: log entry %d has CID %s, want %s", d, i, entry.CID, cid)
+		}
+
+		if len(history) == 0 {
+			if op.Prev != nil {
+				return nil, fmt.Errorf("did: %s: genesis operation has a predecessor", d)
+			}
+			if genesis, err := GenesisDID(entry.Operation); err != nil || genesis != id {
+				return nil, fmt.Errorf("did: %s: %w: genesis operation of %s", d, did.ErrInvalidDID, genesis)
+			}
+			op.normalize()
+			signer, err := verifySignature(op, op.RotationKeys)
+			if err != nil {
+				return nil, fmt.Errorf("did: %s: genesis operation: %w", d, err)
+			}
+			history = append(history, historyEntry{op, entry, signer})
+			continue
+		}
+
+		j := len(history) - 1
+		for op.Prev != nil && j >= 0 && history[j].entry.CID != *op.Prev {
+			j--
+		}
+		if op.Prev == nil || j < 0 {
+			return nil, fmt.Errorf("did: %s: log entry %d does not link to an operation in effect", d, i)
+		}
+		prev := history[j].op
+		if prev.Type == "plc_tombstone" {
+			return nil, fmt.Errorf("did: %s: operation after tombstone", d)
+		}
+		signer, err := verifySignature(op, prev.RotationKeys)
+		if err != nil {
+			return nil, fmt.Errorf("did: %s: log entry %d: %w", d, i, err)
+		}
+		if j < len(history)-1 {
+			disputed := history[j+1]
+			if signer >= disputed.signer {
+				return nil, fmt.Errorf("did: %s: log entry %d: %w: rotation key %d can't nullify the operations of rotation key %d", d, i, ErrSignature, signer, disputed.signer)
+			}
+			if lapse := entry.CreatedAt.Sub(disputed.entry.CreatedAt); lapse > RecoveryWindow {
+				return nil, fmt.Errorf("did: %s: log entry %d nullifies operations after %s, beyond the recovery window", d, i, lapse)
+			}
+			history = history[:j+1]
+		}
+		op.normalize()
+		history = append(history, historyEntry{op, entry, signer})
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("did: %s: empty log: %w", d, did.ErrNotFound)
+	}
+	return history, nil
+}
+
+// Document returns the DID Document of an operation in effect. Tombstones get
+// a document without verification methods nor services.
+func (op *Operation) Document(id string) *did.Document {
+	doc := &did.Document{
+		Context: []any{did.ContextV1, did.ContextMultikey, "https://w3id.org/security/suites/secp256k1-2019/v1"},
+		ID:      id,
+	}
+	if op.Type == "plc_tombstone" {
+		return doc
+	}
+	doc.AlsoKnownAs = op.AlsoKnownAs
+
+	names := make([]string, 0, len(op.VerificationMethods))
+	for name := range op.VerificationMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		doc.VerificationMethod = append(doc.VerificationMethod, did.VerificationMethod{
+			ID:                 id + "#" + name,
+			Type:               did.TypeMultikey,
+			Controller:         id,
+			PublicKeyMultibase: strings.TrimPrefix(op.VerificationMethods[name], "did:key:"),
+		})
+	}
+
+	names = names[:0]
+	for name := range op.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := op.Services[name]
+		doc.Service = append(doc.Service, did.Service{
+			ID:              id + "#" + name,
+			Type:            s.Type,
+			ServiceEndpoint: did.ServiceEndpoint{URI: s.Endpoint},
+		})
+	}
+	return doc
+}
+
+// Resolver resolves did:plc DIDs with a PLC directory.
+type Resolver struct {
+	// Directory is the base URL, with DefaultDirectory for the empty
+	// string.
+	Directory string
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// Verify enables verification of the audit log, such that the
+	// directory need not be trusted.
+	Verify bool
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "plc" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	id := strings.TrimPrefix(d.String(), "did:plc:")
+	if len(id) != 24 || strings.Trim(id, "abcdefghijklmnopqrstuvwxyz234567") != "" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w: want 24 base32 characters", d, did.ErrInvalidDID)
+	}
+
+	meta := &did.Metadata{Resolution: did.ResolutionMetadata{ContentType: contentType}}
+	if !r.Verify {
+		doc := new(did.Document)
+		gone, err := r.get(ctx, d, "", doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		if gone {
+			meta.Document.Deactivated = true
+			doc = &did.Document{ID: d.String()}
+		}
+		return doc, meta, nil
+	}
+
+	var log []LogEntry
+	if _, err := r.get(ctx, d, "/log/audit", &log); err != nil {
+		return nil, nil, err
+	}
+	history, err := verifyLog(d, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	first, last := history[0], history[len(history)-1]
+	meta.Document.Created = first.entry.CreatedAt.UTC().Truncate(time.Second)
+	meta.Document.Updated = last.entry.CreatedAt.UTC().Truncate(time.Second)
+	meta.Document.VersionID = last.entry.CID
+	op := last.op
+	meta.Document.Deactivated = op.Type == "plc_tombstone"
+	return op.Document(d.String()), meta, nil
+}
+
+// get decodes a JSON resource of the directory into v, unless the DID is gone.
+func (r *Resolver) get(ctx context.Context, d *did.DID, path string, v any) (gone bool, err error) {
+	base := r.Directory
+	if base == "" {
+		base = DefaultDirectory
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base, "/")+"/"+d.String()+path, nil)
+	if err != nil {
+		return false, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotFound:
+		return false, fmt.Errorf("did: resolve %s: %w", d, did.ErrNotFound)
+	case http.StatusGone:
+		return true, nil
+	default:
+		return false, fmt.Errorf("did: resolve %s: PLC directory got HTTP %q", d, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 8<<20)).Decode(v); err != nil {
+		return false, fmt.Errorf("did: resolve %s: malformed directory response: %w", d, err)
+	}
+	return false, nil
+}
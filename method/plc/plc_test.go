@@ -0,0 +1,256 @@
+package plc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/dagcbor"
+	"github.com/ockam-network/did/internal/multibase"
+	"github.com/ockam-network/did/method/key"
+)
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := elliptic.MarshalCompressed(elliptic.P256(), priv.X, priv.Y)
+	return priv, key.New(multibase.P256Pub, pub).String()
+}
+
+// signEntry returns a log entry of the operation, signed with priv in the
+// low-S form.
+func signEntry(t *testing.T, id string, op map[string]any, priv *ecdsa.PrivateKey) LogEntry {
+	return signEntryS(t, id, op, priv, false)
+}
+
+// signEntryS is signEntry with the choice of a high-S signature instead.
+func signEntryS(t *testing.T, id string, op map[string]any, priv *ecdsa.PrivateKey, highS bool) LogEntry {
+	data, err := json.Marshal(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := dagcbor.MarshalJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(encoded)
+	r, s, err := ecdsa.Sign(rand.Reader, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := elliptic.P256().Params().N
+	if high := s.Cmp(new(big.Int).Rsh(n, 1)) > 0; high != highS {
+		s.Sub(n, s)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	op["sig"] = base64.RawURLEncoding.EncodeToString(sig)
+
+	raw, err := json.Marshal(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == "" {
+		id, err = GenesisDID(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	encoded, err = dagcbor.MarshalJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return LogEntry{DID: id, Operation: raw, CID: dagcbor.CID(encoded), CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func testLog(t *testing.T) (log []LogEntry, rogue LogEntry) {
+	k1, pub1 := newKey(t)
+	_, pub2 := newKey(t)
+	genesis := signEntry(t, "", map[string]any{
+		"type":                "plc_operation",
+		"rotationKeys":        []string{pub1},
+		"verificationMethods": map[string]string{"atproto": pub1},
+		"alsoKnownAs":         []string{"at://alice.example.com"},
+		"services":            map[string]any{"atproto_pds": map[string]string{"type": "AtprotoPersonalDataServer", "endpoint": "https://pds.example.com"}},
+		"prev":                nil,
+	}, k1)
+	rotate := signEntry(t, genesis.DID, map[string]any{
+		"type":                "plc_operation",
+		"rotationKeys":        []string{pub2},
+		"verificationMethods": map[string]string{"atproto": pub2},
+		"alsoKnownAs":         []string{"at://alice.example.com"},
+		"services":            map[string]any{"atproto_pds": map[string]string{"type": "AtprotoPersonalDataServer", "endpoint": "https://pds2.example.com"}},
+		"prev":                genesis.CID,
+	}, k1)
+	rotate.CreatedAt = rotate.CreatedAt.Add(time.Hour)
+	// signed by the rotated-out key
+	rogue = signEntry(t, genesis.DID, map[string]any{
+		"type": "plc_tombstone",
+		"prev": rotate.CID,
+	}, k1)
+	return []LogEntry{genesis, rotate}, rogue
+}
+
+func TestVerifyLog(t *testing.T) {
+	log, rogue := testLog(t)
+	d := &did.DID{Method: "plc", ID: log[0].DID[len("did:plc:"):]}
+
+	op, err := VerifyLog(d, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := op.Document(d.String())
+	if len(doc.Service) != 1 || doc.Service[0].ServiceEndpoint.URI != "https://pds2.example.com" {
+		t.Errorf("got services %+v", doc.Service)
+	}
+	if len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].ID != d.String()+"#atproto" {
+		t.Errorf("got verification methods %+v", doc.VerificationMethod)
+	}
+
+	if _, err := VerifyLog(d, append(log, rogue)); !errors.Is(err, ErrSignature) {
+		t.Errorf("rogue operation got error %v, want %v", err, ErrSignature)
+	}
+	if _, err := VerifyLog(d, log[1:]); err == nil {
+		t.Error("log without genesis got no error")
+	}
+
+	// the nullified flag of the directory is not trusted
+	rogue.Nullified = true
+	if _, err := VerifyLog(d, append(log, rogue)); !errors.Is(err, ErrSignature) {
+		t.Errorf("rogue operation flagged nullified got error %v, want %v", err, ErrSignature)
+	}
+}
+
+func TestVerifyLogHighS(t *testing.T) {
+	k, pub := newKey(t)
+	genesis := map[string]any{
+		"type":                "plc_operation",
+		"rotationKeys":        []string{pub},
+		"verificationMethods": map[string]string{"atproto": pub},
+		"alsoKnownAs":         []string{},
+		"services":            map[string]any{},
+		"prev":                nil,
+	}
+	entry := signEntryS(t, "", genesis, k, true)
+	d := &did.DID{Method: "plc", ID: entry.DID[len("did:plc:"):]}
+	if _, err := VerifyLog(d, []LogEntry{entry}); !errors.Is(err, ErrSignature) {
+		t.Errorf("high-S signature got error %v, want %v", err, ErrSignature)
+	}
+}
+
+func TestVerifyLogRecovery(t *testing.T) {
+	recovery, recoveryPub := newKey(t)
+	signing, signingPub := newKey(t)
+	genesis := signEntry(t, "", map[string]any{
+		"type":                "plc_operation",
+		"rotationKeys":        []string{recoveryPub, signingPub},
+		"verificationMethods": map[string]string{"atproto": signingPub},
+		"alsoKnownAs":         []string{"at://alice.example.com"},
+		"services":            map[string]any{},
+		"prev":                nil,
+	}, recovery)
+	d := &did.DID{Method: "plc", ID: genesis.DID[len("did:plc:"):]}
+	handle := func(name string, k *ecdsa.PrivateKey, prev string, created time.Time) LogEntry {
+		e := signEntry(t, genesis.DID, map[string]any{
+			"type":                "plc_operation",
+			"rotationKeys":        []string{recoveryPub, signingPub},
+			"verificationMethods": map[string]string{"atproto": signingPub},
+			"alsoKnownAs":         []string{"at://" + name},
+			"services":            map[string]any{},
+			"prev":                prev,
+		}, k)
+		e.CreatedAt = created
+		return e
+	}
+	hijack := handle("mallory.example.com", signing, genesis.CID, genesis.CreatedAt.Add(time.Hour))
+
+	restore := handle("alice.example.com", recovery, genesis.CID, hijack.CreatedAt.Add(RecoveryWindow))
+	op, err := VerifyLog(d, []LogEntry{genesis, hijack, restore})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.AlsoKnownAs[0] != "at://alice.example.com" {
+		t.Errorf("got also known as %q after recovery", op.AlsoKnownAs)
+	}
+
+	late := handle("alice.example.com", recovery, genesis.CID, hijack.CreatedAt.Add(RecoveryWindow+time.Second))
+	if _, err := VerifyLog(d, []LogEntry{genesis, hijack, late}); err == nil {
+		t.Error("recovery after the window got no error")
+	}
+
+	// the nullified operation needs a key of lower priority
+	equal := handle("alice.example.com", signing, genesis.CID, hijack.CreatedAt.Add(time.Minute))
+	if _, err := VerifyLog(d, []LogEntry{genesis, hijack, equal}); !errors.Is(err, ErrSignature) {
+		t.Errorf("recovery with an equal key got error %v, want %v", err, ErrSignature)
+	}
+
+	// an operation after recovery must link to the recovered history
+	next := handle("bob.example.com", signing, hijack.CID, restore.CreatedAt.Add(time.Hour))
+	if _, err := VerifyLog(d, []LogEntry{genesis, hijack, restore, next}); err == nil {
+		t.Error("operation on nullified history got no error")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	log, _ := testLog(t)
+	id := log[0].DID
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + id:
+			w.Write([]byte(`{"id":"` + id + `"}`))
+		case "/" + id + "/log/audit":
+			json.NewEncoder(w).Encode(log)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	d, err := did.Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Resolver{Directory: srv.URL, Verify: true}
+	doc, meta, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.AlsoKnownAs[0] != "at://alice.example.com" || meta.Document.VersionID != log[1].CID {
+		t.Errorf("got also known as %q with version %q", doc.AlsoKnownAs, meta.Document.VersionID)
+	}
+	if !meta.Document.Updated.Equal(log[1].CreatedAt) || !meta.Document.Created.Equal(log[0].CreatedAt) {
+		t.Errorf("got created %s and updated %s", meta.Document.Created, meta.Document.Updated)
+	}
+
+	r.Verify = false
+	doc, _, err = r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != id {
+		t.Errorf("got id %q, want %q", doc.ID, id)
+	}
+
+	_, _, err = r.Resolve(context.Background(), &did.DID{Method: "plc", ID: "aaaaaaaaaaaaaaaaaaaaaaaa"}, did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("unknown DID got error %v, want %v", err, did.ErrNotFound)
+	}
+	_, _, err = r.Resolve(context.Background(), &did.DID{Method: "plc", ID: "short"}, did.ResolutionOptions{})
+	if !errors.Is(err, did.ErrInvalidDID) {
+		t.Errorf("malformed DID got error %v, want %v", err, did.ErrInvalidDID)
+	}
+}
@@ -0,0 +1,373 @@
+// Package web implements the did:web method.
+// https://w3c-ccg.github.io/did-method-web/
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ockam-network/did"
+)
+
+// MaxDocumentSize is the limit for DID Documents in bytes.
+const MaxDocumentSize = 1 << 20
+
+// Resolver resolves did:web DIDs over HTTPS. The zero value is ready for use.
+type Resolver struct {
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// InsecureLocalhost resolves the DIDs of localhost, 127.0.0.1 and
+	// [::1] over plain HTTP, on any port, for development and testing.
+	// Other hosts remain HTTPS only.
+	InsecureLocalhost bool
+
+	// NoHTTPCache disables the cache of documents, which otherwise
+	// follows the Cache-Control, ETag and Last-Modified headers of the
+	// responses.
+	NoHTTPCache bool
+
+	// Now is the clock of the cache. The nil value defaults to time.Now.
+	Now func() time.Time
+
+	mutex sync.Mutex
+	cache map[string]*cacheEntry // by location
+}
+
+// cacheEntry is a document response.
+type cacheEntry struct {
+	body         []byte
+	contentType  string
+	expires      time.Time // zero when revalidation is required
+	etag         string
+	lastModified string
+}
+
+// Resolve implements the did.Resolver interface.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "web" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	switch opts.Accept {
+	case "", did.MediaTypeJSON, did.MediaTypeJSONLD, did.MediaTypeCBOR, "application/json":
+		break
+	default:
+		return nil, nil, fmt.Errorf("did: resolve %s: media type %q: %w", d, opts.Accept, did.ErrRepresentationNotSupported)
+	}
+
+	location, err := DIDToHTTPSURL(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.InsecureLocalhost {
+		location = insecureLocalhost(location)
+	}
+	body, contentType, err := r.fetch(ctx, location, opts.NoCache)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	doc, err := did.UnmarshalDocument(body, contentType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %s: %w", d, location, err)
+	}
+	if doc.ID != d.String() {
+		return nil, nil, fmt.Errorf("did: resolve %s: document has id %q", d, doc.ID)
+	}
+
+	meta := new(did.Metadata)
+	switch {
+	case opts.Accept == did.MediaTypeJSONLD:
+		if !hasContext(doc, did.ContextV1) {
+			doc.Context = append([]any{did.ContextV1}, doc.Context...)
+		}
+		meta.Resolution.ContentType = opts.Accept
+	case opts.Accept == did.MediaTypeCBOR:
+		meta.Resolution.ContentType = opts.Accept
+	case opts.Accept == "" && len(doc.Context) != 0:
+		meta.Resolution.ContentType = did.MediaTypeJSONLD
+	default:
+		meta.Resolution.ContentType = did.MediaTypeJSON
+	}
+	return doc, meta, nil
+}
+
+// fetch returns the document at location, with its media type. Fresh cache
+// entries serve without request, and stale ones revalidate with a
+// conditional request. With noCache, each entry revalidates.
+func (r *Resolver) fetch(ctx context.Context, location string, noCache bool) (body []byte, contentType string, err error) {
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+
+	var cached *cacheEntry
+	if !r.NoHTTPCache {
+		r.mutex.Lock()
+		cached = r.cache[location]
+		r.mutex.Unlock()
+		if cached != nil && !noCache && now().Before(cached.expires) {
+			return cached.body, cached.contentType, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/did+json, application/did+ld+json, application/json;q=0.5, application/did+cbor;q=0.3")
+	if noCache {
+		req.Header.Set("Cache-Control", "no-cache")
+	}
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	entry := &cacheEntry{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err = io.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize+1))
+		if err != nil {
+			return nil, "", err
+		}
+		if len(body) > MaxDocumentSize {
+			return nil, "", fmt.Errorf("%s exceeds %d bytes", location, MaxDocumentSize)
+		}
+		contentType = resp.Header.Get("Content-Type")
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != did.MediaTypeCBOR {
+			contentType = did.MediaTypeJSON
+		}
+		entry.body, entry.contentType = body, contentType
+	case http.StatusNotModified:
+		if cached == nil {
+			return nil, "", fmt.Errorf("%s got HTTP %q without conditional request", location, resp.Status)
+		}
+		entry.body, entry.contentType = cached.body, cached.contentType
+		if entry.etag == "" {
+			entry.etag = cached.etag
+		}
+		if entry.lastModified == "" {
+			entry.lastModified = cached.lastModified
+		}
+	case http.StatusNotFound, http.StatusGone:
+		r.forget(location)
+		return nil, "", fmt.Errorf("%s: %w", location, did.ErrNotFound)
+	default:
+		return nil, "", fmt.Errorf("%s got HTTP %q", location, resp.Status)
+	}
+
+	if !r.NoHTTPCache {
+		maxAge, store := cacheControl(resp.Header)
+		if maxAge > 0 {
+			entry.expires = now().Add(maxAge)
+		}
+		if store && (maxAge > 0 || entry.etag != "" || entry.lastModified != "") {
+			r.mutex.Lock()
+			if r.cache == nil {
+				r.cache = make(map[string]*cacheEntry)
+			}
+			// drop entries which can neither serve nor revalidate
+			for k, e := range r.cache {
+				if e.etag == "" && e.lastModified == "" && !now().Before(e.expires) {
+					delete(r.cache, k)
+				}
+			}
+			r.cache[location] = entry
+			r.mutex.Unlock()
+		} else {
+			r.forget(location)
+		}
+	}
+	return entry.body, entry.contentType, nil
+}
+
+// forget removes any cache entry of location.
+func (r *Resolver) forget(location string) {
+	r.mutex.Lock()
+	delete(r.cache, location)
+	r.mutex.Unlock()
+}
+
+// cacheControl returns the freshness lifetime of a response, and whether the
+// response may be stored at all.
+func cacheControl(h http.Header) (maxAge time.Duration, store bool) {
+	store = true
+	for _, v := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(name) {
+			case "no-store":
+				store = false
+			case "no-cache":
+				maxAge = -1
+			case "max-age":
+				if maxAge < 0 {
+					continue
+				}
+				if n, err := strconv.ParseUint(strings.Trim(arg, `"`), 10, 32); err == nil {
+					maxAge = time.Duration(n) * time.Second
+				}
+			}
+		}
+	}
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	return maxAge, store
+}
+
+// hasContext returns whether the document includes the context URL.
+func hasContext(doc *did.Document, s string) bool {
+	for _, c := range doc.Context {
+		if c == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DIDToHTTPSURL returns the location of the DID Document. The domain may
+// have a port with its colon percent-encoded, as in
+// did:web:example.com%3A3000, and IPv6 addresses go in brackets, as in
+// did:web:%5B%3A%3A1%5D%3A8080. A DID with a path maps to path/did.json, and
+// a bare domain maps to /.well-known/did.json.
+func DIDToHTTPSURL(d *did.DID) (string, error) {
+	if d.Method != "web" {
+		return "", fmt.Errorf("did: %s not a did:web", d)
+	}
+	segments := strings.Split(strings.TrimPrefix(d.String(), "did:web:"), ":")
+	host, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return "", fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@") || !validHost(host) {
+		return "", fmt.Errorf("did: %s: %w: host %q", d, did.ErrInvalidDID, host)
+	}
+
+	path := "/.well-known"
+	if len(segments) > 1 {
+		for _, s := range segments[1:] {
+			if s == "" {
+				return "", fmt.Errorf("did: %s: %w: empty path segment", d, did.ErrInvalidDID)
+			}
+		}
+		path = "/" + strings.Join(segments[1:], "/")
+	}
+	return "https://" + host + path + "/did.json", nil
+}
+
+// validHost returns whether s is a host name or an IP address, with an
+// optional port. IPv6 addresses must be in brackets, as in [::1]:8080.
+func validHost(s string) bool {
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		return net.ParseIP(s[1:len(s)-1]) != nil
+	}
+	if !strings.Contains(s, ":") {
+		return !strings.ContainsAny(s, "[]")
+	}
+	name, port, err := net.SplitHostPort(s)
+	if err != nil || name == "" || !validPort(port) {
+		return false
+	}
+	if strings.HasPrefix(s, "[") {
+		return net.ParseIP(name) != nil
+	}
+	return !strings.ContainsAny(name, "[]")
+}
+
+// validPort returns whether s is a decimal port number.
+func validPort(s string) bool {
+	if s == "" || len(s) > 5 || s[0] == '0' {
+		return false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+	return n <= 65535
+}
+
+// HTTPSURLToDID returns the DID of a document location, which is the reverse
+// of DIDToHTTPSURL. The location must be either /.well-known/did.json or a
+// path with a did.json file name. A did.json in the root has no DID.
+func HTTPSURLToDID(location string) (*did.DID, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("did:web: %q not an HTTPS URL with a host only", location)
+	}
+
+	path, ok := strings.CutSuffix(u.EscapedPath(), "/did.json")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("did:web: %q not a /.well-known/did.json or a /path/did.json", location)
+	}
+	if path == "/.well-known" {
+		path = ""
+	}
+	idstrings := []string{u.Host}
+	if path != "" {
+		for _, s := range strings.Split(path[1:], "/") {
+			if s == "" {
+				return nil, fmt.Errorf("did:web: %q has an empty path segment", location)
+			}
+			s, err := url.PathUnescape(s)
+			if err != nil {
+				return nil, err
+			}
+			idstrings = append(idstrings, s)
+		}
+	}
+
+	d, err := did.NewBuilder("web").ID(idstrings...).Build()
+	if err != nil {
+		return nil, fmt.Errorf("did:web: %q: %w", location, err)
+	}
+	return d.Base(), nil
+}
+
+// insecureLocalhost returns location with the http scheme when it points to
+// the local host.
+func insecureLocalhost(location string) string {
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		u.Scheme = "http"
+		return u.String()
+	}
+	return location
+}
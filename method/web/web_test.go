@@ -0,0 +1,293 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/didtest"
+)
+
+func TestDIDToHTTPSURL(t *testing.T) {
+	tests := []struct{ did, url string }{
+		{"did:web:w3c-ccg.github.io", "https://w3c-ccg.github.io/.well-known/did.json"},
+		{"did:web:w3c-ccg.github.io:user:alice", "https://w3c-ccg.github.io/user/alice/did.json"},
+		{"did:web:example.com%3A3000", "https://example.com:3000/.well-known/did.json"},
+		{"did:web:example.com%3A3000:user:alice", "https://example.com:3000/user/alice/did.json"},
+		{"did:web:example.com:u%40ser", "https://example.com/u%40ser/did.json"},
+		{"did:web:%5B%3A%3A1%5D", "https://[::1]/.well-known/did.json"},
+		{"did:web:%5B%3A%3A1%5D%3A8080:user:alice", "https://[::1]:8080/user/alice/did.json"},
+	}
+	for _, test := range tests {
+		d, err := did.Parse(test.did)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DIDToHTTPSURL(d)
+		if err != nil {
+			t.Errorf("%s got error: %s", test.did, err)
+			continue
+		}
+		if got != test.url {
+			t.Errorf("%s got URL %q, want %q", test.did, got, test.url)
+		}
+
+		back, err := HTTPSURLToDID(got)
+		if err != nil {
+			t.Errorf("%s got error: %s", got, err)
+			continue
+		}
+		if back.String() != test.did {
+			t.Errorf("%s got DID %s, want %s", got, back, test.did)
+		}
+	}
+
+	for _, s := range []string{"example.com%2Fevil", "example.com%3A", "example.com%3Ahttp", "example.com%3A65536", "%3A3000", "example.com::alice", "%3A%3A1%3A8080", "%5B%3A%3A1%3A8080", "%5B%3A%3A1%5D%3A", "%5Bexample.com%5D%3A8080"} {
+		d := didtest.Must(did.Parse("did:web:" + s))
+		if _, err := DIDToHTTPSURL(d); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", d, err, did.ErrInvalidDID)
+		}
+	}
+}
+
+func TestHTTPSURLToDID(t *testing.T) {
+	tests := []struct{ url, did string }{
+		{"https://example.com/.well-known/did.json", "did:web:example.com"},
+		{"https://example.com:8443/user/alice/did.json", "did:web:example.com%3A8443:user:alice"},
+		{"https://example.com/user/alice/did.json", "did:web:example.com:user:alice"},
+		{"https://example.com/~alice/did.json", "did:web:example.com:%7Ealice"},
+	}
+	for _, test := range tests {
+		d, err := HTTPSURLToDID(test.url)
+		if err != nil {
+			t.Errorf("%s got error: %s", test.url, err)
+			continue
+		}
+		if d.String() != test.did {
+			t.Errorf("%s got %s, want %s", test.url, d, test.did)
+		}
+	}
+
+	for _, s := range []string{"http://example.com/", "https:///did.json", "https://user@example.com/", "https://example.com/?q", "https://example.com/a//b/did.json", "example.com", "https://example.com", "https://example.com/", "https://example.com/did.json", "https://example.com//did.json", "https://example.com/user/alice", "https://example.com/user/alice/"} {
+		if d, err := HTTPSURLToDID(s); err == nil {
+			t.Errorf("%s got %s, want error", s, d)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	var id string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/did.json":
+			w.Header().Set("Content-Type", "application/did+json")
+			w.Write([]byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"` + id + `"}`))
+		case "/user/alice/did.json":
+			w.Write([]byte(`{"id":"` + id + `:user:alice"}`))
+		case "/user/carol/did.json":
+			w.Header().Set("Content-Type", "application/did+cbor")
+			// {"id": id + ":user:carol"}
+			doc := id + ":user:carol"
+			w.Write(append([]byte{0xa1, 0x62, 'i', 'd', 0x78, byte(len(doc))}, doc...))
+		case "/user/mallory/did.json":
+			w.Write([]byte(`{"id":"did:web:example.com"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	id = "did:web:" + strings.Replace(strings.TrimPrefix(srv.URL, "https://"), ":", "%3A", 1)
+
+	r := &Resolver{Client: srv.Client()}
+	ctx := context.Background()
+
+	doc, meta, err := r.Resolve(ctx, didtest.Must(did.Parse(id)), did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != id {
+		t.Errorf("got document id %q, want %q", doc.ID, id)
+	}
+	if got, want := meta.Resolution.ContentType, "application/did+ld+json"; got != want {
+		t.Errorf("got content type %q, want %q", got, want)
+	}
+
+	alice := didtest.Must(did.Parse(id + ":user:alice"))
+	_, meta, err = r.Resolve(ctx, alice, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := meta.Resolution.ContentType, "application/did+json"; got != want {
+		t.Errorf("got content type %q, want %q", got, want)
+	}
+	doc, meta, err = r.Resolve(ctx, alice, did.ResolutionOptions{Accept: "application/did+ld+json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Context) != 1 || doc.Context[0] != did.ContextV1 {
+		t.Errorf("got context %q, want DID Core v1", doc.Context)
+	}
+
+	carol := didtest.Must(did.Parse(id + ":user:carol"))
+	doc, meta, err = r.Resolve(ctx, carol, did.ResolutionOptions{Accept: did.MediaTypeCBOR})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != carol.String() {
+		t.Errorf("got document id %q, want %q", doc.ID, carol)
+	}
+	if got := meta.Resolution.ContentType; got != did.MediaTypeCBOR {
+		t.Errorf("got content type %q, want %q", got, did.MediaTypeCBOR)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			d    *did.DID
+			opts did.ResolutionOptions
+			want error
+		}{
+			{&did.DID{Method: "example", ID: "123"}, did.ResolutionOptions{}, did.ErrMethodNotSupported},
+			{alice, did.ResolutionOptions{Accept: "text/html"}, did.ErrRepresentationNotSupported},
+			{didtest.Must(did.Parse(id + ":user:bob")), did.ResolutionOptions{}, did.ErrNotFound},
+		}
+		for _, test := range tests {
+			_, _, err := r.Resolve(ctx, test.d, test.opts)
+			if !errors.Is(err, test.want) {
+				t.Errorf("%s got error %v, want %v", test.d, err, test.want)
+			}
+		}
+
+		mallory := didtest.Must(did.Parse(id + ":user:mallory"))
+		if _, _, err := r.Resolve(ctx, mallory, did.ResolutionOptions{}); err == nil {
+			t.Error("document with foreign id accepted")
+		}
+	})
+}
+
+func TestInsecureLocalhost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"did:web:` + strings.Replace(r.Host, ":", "%3A", 1) + `"}`))
+	}))
+	defer srv.Close()
+	d := didtest.Must(did.Parse("did:web:" + strings.Replace(strings.TrimPrefix(srv.URL, "http://"), ":", "%3A", 1)))
+
+	if _, _, err := (&Resolver{}).Resolve(context.Background(), d, did.ResolutionOptions{}); err == nil {
+		t.Error("plain HTTP resolved without InsecureLocalhost")
+	}
+	doc, _, err := (&Resolver{InsecureLocalhost: true}).Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != d.String() {
+		t.Errorf("got document id %q, want %q", doc.ID, d)
+	}
+
+	tests := []struct{ location, want string }{
+		{"https://localhost:8080/.well-known/did.json", "http://localhost:8080/.well-known/did.json"},
+		{"https://127.0.0.1/user/did.json", "http://127.0.0.1/user/did.json"},
+		{"https://[::1]:3000/.well-known/did.json", "http://[::1]:3000/.well-known/did.json"},
+		{"https://localhost.example.com/.well-known/did.json", "https://localhost.example.com/.well-known/did.json"},
+		{"https://127.0.0.2/.well-known/did.json", "https://127.0.0.2/.well-known/did.json"},
+	}
+	for _, test := range tests {
+		if got := insecureLocalhost(test.location); got != test.want {
+			t.Errorf("%s got %s, want %s", test.location, got, test.want)
+		}
+	}
+}
+
+func TestHTTPCache(t *testing.T) {
+	var requests, conditionals int
+	var id string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		if r.URL.Path == "/user/alice/did.json" {
+			w.Header().Set("Cache-Control", "no-store")
+		} else {
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditionals++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"id":"` + id + strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/.well-known"), "/did.json"), "/", ":") + `"}`))
+	}))
+	defer srv.Close()
+	id = "did:web:" + strings.Replace(strings.TrimPrefix(srv.URL, "https://"), ":", "%3A", 1)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &Resolver{Client: srv.Client(), Now: func() time.Time { return now }}
+	d := didtest.Must(did.Parse(id))
+	resolve := func(d *did.DID, opts did.ResolutionOptions) {
+		t.Helper()
+		doc, _, err := r.Resolve(context.Background(), d, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.ID != d.String() {
+			t.Errorf("got document id %q, want %q", doc.ID, d)
+		}
+	}
+
+	resolve(d, did.ResolutionOptions{})
+	resolve(d, did.ResolutionOptions{})
+	if requests != 1 {
+		t.Errorf("got %d requests within max-age, want 1", requests)
+	}
+
+	now = now.Add(time.Minute)
+	resolve(d, did.ResolutionOptions{})
+	if requests != 2 || conditionals != 1 {
+		t.Errorf("got %d requests with %d conditional after max-age, want 2 with 1", requests, conditionals)
+	}
+	resolve(d, did.ResolutionOptions{})
+	if requests != 2 {
+		t.Errorf("got %d requests after revalidation, want 2", requests)
+	}
+	resolve(d, did.ResolutionOptions{NoCache: true})
+	if requests != 3 || conditionals != 2 {
+		t.Errorf("got %d requests with %d conditional on NoCache, want 3 with 2", requests, conditionals)
+	}
+
+	alice := didtest.Must(did.Parse(d.String() + ":user:alice"))
+	resolve(alice, did.ResolutionOptions{})
+	resolve(alice, did.ResolutionOptions{})
+	if requests != 5 || conditionals != 2 {
+		t.Errorf("got %d requests with %d conditional on no-store, want 5 with 2", requests, conditionals)
+	}
+
+	r.NoHTTPCache = true
+	resolve(d, did.ResolutionOptions{})
+	if requests != 6 || conditionals != 2 {
+		t.Errorf("got %d requests with %d conditional on NoHTTPCache, want 6 with 2", requests, conditionals)
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	tests := []struct {
+		header string
+		maxAge time.Duration
+		store  bool
+	}{
+		{"", 0, true},
+		{"max-age=300", 5 * time.Minute, true},
+		{"public, Max-Age=\"10\"", 10 * time.Second, true},
+		{"no-cache, max-age=300", 0, true},
+		{"max-age=300, no-store", 5 * time.Minute, false},
+		{"max-age=-1", 0, true},
+	}
+	for _, test := range tests {
+		h := http.Header{"Cache-Control": {test.header}}
+		maxAge, store := cacheControl(h)
+		if maxAge != test.maxAge || store != test.store {
+			t.Errorf("%q got max-age %s, store %t; want %s, %t", test.header, maxAge, store, test.maxAge, test.store)
+		}
+	}
+}
@@ -0,0 +1,501 @@
+// Package webs implements the did:webs method, which binds documents on the
+// web to a KERI autonomic identifier (AID). The resolver verifies the key
+// event log (KEL) instead of trusting TLS alone.
+// https://trustoverip.github.io/tswg-did-method-webs-specification/
+//
+// The KEL verification covers single-signature and multi-signature
+// controllers with numeric thresholds, and pre-rotation. Delegated and
+// weighted identifiers are not supported. Witness receipts are not verified.
+package webs
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/blake3"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// MaxDocumentSize is the limit for DID Documents in bytes.
+const MaxDocumentSize = 1 << 20
+
+// MaxStreamSize is the limit for KERI event streams in bytes.
+const MaxStreamSize = 4 << 20
+
+// CESR codes of 32-byte primitives, in qualified base64 (qb64).
+const (
+	codeEd25519N = 'B' // non-transferable Ed25519 public key
+	codeEd25519  = 'D' // transferable Ed25519 public key
+	codeBlake3   = 'E' // Blake3-256 digest
+)
+
+// saidDummy substitutes self-addressing identifiers during digestion.
+var saidDummy = bytes.Repeat([]byte{'#'}, 44)
+
+// Digest returns the qb64 of the Blake3-256 hash of data.
+func Digest(data []byte) string {
+	sum := blake3.Sum256(data)
+	return encode32(codeBlake3, sum[:])
+}
+
+// encode32 returns the qb64 of a 32-byte primitive.
+func encode32(code byte, raw []byte) string {
+	s := base64.RawURLEncoding.EncodeToString(append([]byte{0}, raw...))
+	return string(code) + s[1:]
+}
+
+// decode32 returns the code and the raw bytes of a 32-byte primitive.
+func decode32(qb64 string) (code byte, raw []byte, err error) {
+	if len(qb64) != 44 {
+		return 0, nil, fmt.Errorf("KERI primitive %q not 44 characters", qb64)
+	}
+	b, err := base64.RawURLEncoding.DecodeString("A" + qb64[1:])
+	if err != nil {
+		return 0, nil, fmt.Errorf("KERI primitive %q: %w", qb64, err)
+	}
+	return qb64[0], b[1:], nil
+}
+
+// ValidAID returns an error when s is not an autonomic identifier prefix.
+func ValidAID(s string) error {
+	code, _, err := decode32(s)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case codeEd25519N, codeEd25519, codeBlake3:
+		return nil
+	default:
+		return fmt.Errorf("AID code %q not supported", code)
+	}
+}
+
+// Identifier is the decomposition of a did:webs.
+type Identifier struct {
+	Host string   // with optional port
+	Path []string // optional
+	AID  string
+}
+
+// Parse returns the decomposition of a did:webs.
+func Parse(d *did.DID) (*Identifier, error) {
+	if d.Method != "webs" {
+		return nil, fmt.Errorf("did: %s not a did:webs", d)
+	}
+	segments := strings.Split(strings.TrimPrefix(d.String(), "did:webs:"), ":")
+	if len(segments) < 2 {
+		return nil, fmt.Errorf("did: %s: %w: want host and AID", d, did.ErrInvalidDID)
+	}
+	host, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@") {
+		return nil, fmt.Errorf("did: %s: %w: host %q", d, did.ErrInvalidDID, host)
+	}
+	aid := segments[len(segments)-1]
+	if err := ValidAID(aid); err != nil {
+		return nil, fmt.Errorf("did: %s: %w: %w", d, did.ErrInvalidDID, err)
+	}
+	return &Identifier{Host: host, Path: segments[1 : len(segments)-1], AID: aid}, nil
+}
+
+// baseURL returns the directory of the AID, with a trailing slash.
+func (id *Identifier) baseURL() string {
+	var buf strings.Builder
+	buf.WriteString("https://")
+	buf.WriteString(id.Host)
+	for _, s := range id.Path {
+		buf.WriteByte('/')
+		buf.WriteString(s)
+	}
+	buf.WriteByte('/')
+	buf.WriteString(id.AID)
+	buf.WriteByte('/')
+	return buf.String()
+}
+
+// Event is a KERI key event, with its raw serialization.
+type Event struct {
+	Version       string   `json:"v"`
+	Type          string   `json:"t"`
+	SAID          string   `json:"d"`
+	Prefix        string   `json:"i"`
+	Sequence      string   `json:"s"`
+	Prior         string   `json:"p"`
+	KeyThreshold  any      `json:"kt"`
+	Keys          []string `json:"k"`
+	NextThreshold any      `json:"nt"`
+	NextDigests   []string `json:"n"`
+
+	Raw        []byte             `json:"-"`
+	Signatures []IndexedSignature `json:"-"`
+}
+
+// IndexedSignature is a controller signature with the index of its key.
+type IndexedSignature struct {
+	Index int
+	Sig   []byte
+}
+
+// ErrKEL means the key event log failed verification.
+var ErrKEL = errors.New("did:webs: invalid key event log")
+
+const b64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// b64Int returns the value of base64 digits.
+func b64Int(s string) (int, error) {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(b64Alphabet, s[i])
+		if v < 0 {
+			return 0, fmt.Errorf("CESR count %q not base64", s)
+		}
+		n = n<<6 | v
+	}
+	return n, nil
+}
+
+// ParseStream reads KERI events in CESR, with their attachments. Only
+// controller signatures are retained from the attachments.
+func ParseStream(stream []byte) ([]*Event, error) {
+	var events []*Event
+	for len(bytes.TrimSpace(stream)) != 0 {
+		stream = bytes.TrimSpace(stream)
+		if stream[0] != '{' {
+			return nil, fmt.Errorf("%w: attachment without event", ErrKEL)
+		}
+		// {"v":"KERI10JSONhhhhhh_",
+		if len(stream) < 23 || string(stream[:6]) != `{"v":"` || string(stream[6:16]) != "KERI10JSON" {
+			return nil, fmt.Errorf("%w: event without KERI 1.0 JSON version string", ErrKEL)
+		}
+		size, err := strconv.ParseUint(string(stream[16:22]), 16, 32)
+		if err != nil || size > uint64(len(stream)) {
+			return nil, fmt.Errorf("%w: event size in version string %q", ErrKEL, stream[6:23])
+		}
+		e := &Event{Raw: stream[:size]}
+		if err := json.Unmarshal(e.Raw, e); err != nil {
+			return nil, fmt.Errorf("%w: event %d: %w", ErrKEL, len(events), err)
+		}
+		stream = stream[size:]
+
+		// attachments
+		for len(stream) >= 4 && stream[0] == '-' {
+			count, err := b64Int(string(stream[2:4]))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrKEL, err)
+			}
+			code := stream[1]
+			stream = stream[4:]
+			var itemSize int
+			switch code {
+			case 'V': // attachment group of quadlets; items follow
+				continue
+			case 'A': // controller indexed signatures
+				for i := 0; i < count; i++ {
+					if len(stream) < 88 || stream[0] != 'A' {
+						return nil, fmt.Errorf("%w: indexed signature not Ed25519", ErrKEL)
+					}
+					index, err := b64Int(string(stream[1:2]))
+					if err != nil {
+						return nil, fmt.Errorf("%w: %w", ErrKEL, err)
+					}
+					raw, err := base64.RawURLEncoding.DecodeString("AA" + string(stream[2:88]))
+					if err != nil {
+						return nil, fmt.Errorf("%w: indexed signature: %w", ErrKEL, err)
+					}
+					e.Signatures = append(e.Signatures, IndexedSignature{Index: index, Sig: raw[2:]})
+					stream = stream[88:]
+				}
+				continue
+			case 'B': // witness indexed signatures
+				itemSize = 88
+			case 'C': // non-transferable receipt couples
+				itemSize = 44 + 88
+			case 'E': // first seen replay couples
+				itemSize = 24 + 36
+			default:
+				return nil, fmt.Errorf("%w: CESR attachment code -%c not supported", ErrKEL, code)
+			}
+			if len(stream) < count*itemSize {
+				return nil, fmt.Errorf("%w: attachment -%c truncated", ErrKEL, code)
+			}
+			stream = stream[count*itemSize:]
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// threshold returns the numeric value of a hexadecimal threshold.
+func threshold(v any) (int, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, errors.New("weighted thresholds not supported")
+	}
+	n, err := strconv.ParseUint(s, 16, 16)
+	return int(n), err
+}
+
+// KeyState is the outcome of a KEL.
+type KeyState struct {
+	Keys        []string // qb64 public keys
+	NextDigests []string // commitments to the next keys
+	Sequence    int
+	LastSAID    string
+}
+
+// VerifyKEL replays the events of an AID, and it returns the current key
+// state.
+func VerifyKEL(aid string, events []*Event) (*KeyState, error) {
+	var state *KeyState
+	var nextThreshold int
+	for i, e := range events {
+		if e.Prefix != aid {
+			return nil, fmt.Errorf("%w: event %d for AID %q", ErrKEL, i, e.Prefix)
+		}
+		seq, err := strconv.ParseUint(e.Sequence, 16, 32)
+		if err != nil || int(seq) != i {
+			return nil, fmt.Errorf("%w: event %d has sequence number %q", ErrKEL, i, e.Sequence)
+		}
+		if got := Digest(bytes.ReplaceAll(e.Raw, []byte(e.SAID), saidDummy)); got != e.SAID {
+			return nil, fmt.Errorf("%w: event %d SAID mismatch", ErrKEL, i)
+		}
+		if i != 0 && e.Prior != state.LastSAID {
+			return nil, fmt.Errorf("%w: event %d prior %q, want %q", ErrKEL, i, e.Prior, state.LastSAID)
+		}
+
+		var signers []string
+		required := 0
+		switch {
+		case i == 0 && e.Type == "icp":
+			switch aid[0] {
+			case codeBlake3:
+				if e.SAID != aid {
+					return nil, fmt.Errorf("%w: inception SAID not the AID", ErrKEL)
+				}
+			default:
+				if len(e.Keys) != 1 || e.Keys[0] != aid {
+					return nil, fmt.Errorf("%w: basic AID not the inception key", ErrKEL)
+				}
+			}
+			state = new(KeyState)
+			signers = e.Keys
+			if required, err = threshold(e.KeyThreshold); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrKEL, err)
+			}
+		case i != 0 && e.Type == "rot":
+			if len(state.NextDigests) == 0 {
+				return nil, fmt.Errorf("%w: rotation of non-transferable AID", ErrKEL)
+			}
+			committed := 0
+			for _, k := range e.Keys {
+				if slices.Contains(state.NextDigests, Digest([]byte(k))) {
+					committed++
+				}
+			}
+			if committed < nextThreshold {
+				return nil, fmt.Errorf("%w: rotation %d exposes %d committed keys, want %d", ErrKEL, i, committed, nextThreshold)
+			}
+			signers = e.Keys
+			if required, err = threshold(e.KeyThreshold); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrKEL, err)
+			}
+			required = max(required, nextThreshold)
+		case i != 0 && e.Type == "ixn":
+			signers = state.Keys
+			required = len(state.Keys)
+		default:
+			return nil, fmt.Errorf("%w: event %d type %q not supported", ErrKEL, i, e.Type)
+		}
+		if required < 1 {
+			return nil, fmt.Errorf("%w: event %d threshold below one", ErrKEL, i)
+		}
+
+		valid := make(map[int]bool)
+		for _, s := range e.Signatures {
+			if s.Index >= len(signers) || valid[s.Index] {
+				continue
+			}
+			code, pub, err := decode32(signers[s.Index])
+			if err != nil || (code != codeEd25519 && code != codeEd25519N) {
+				continue
+			}
+			if ed25519.Verify(pub, e.Raw, s.Sig) {
+				valid[s.Index] = true
+			}
+		}
+		if len(valid) < required {
+			return nil, fmt.Errorf("%w: event %d has %d valid signatures, want %d", ErrKEL, i, len(valid), required)
+		}
+
+		if e.Type != "ixn" {
+			state.Keys = e.Keys
+			state.NextDigests = e.NextDigests
+			if len(e.NextDigests) != 0 {
+				if nextThreshold, err = threshold(e.NextThreshold); err != nil {
+					return nil, fmt.Errorf("%w: %w", ErrKEL, err)
+				}
+			}
+		}
+		state.Sequence = i
+		state.LastSAID = e.SAID
+	}
+	if state == nil {
+		return nil, fmt.Errorf("%w: no inception", ErrKEL)
+	}
+	return state, nil
+}
+
+// verifyBinding checks that the verification methods are exactly the keys
+// of the key state.
+func verifyBinding(doc *did.Document, state *KeyState) error {
+	seen := make(map[string]bool)
+	for _, vm := range doc.VerificationMethod {
+		_, fragment, _ := strings.Cut(vm.ID, "#")
+		if !slices.Contains(state.Keys, fragment) {
+			return fmt.Errorf("verification method %q not a current key", vm.ID)
+		}
+		_, want, err := decode32(fragment)
+		if err != nil {
+			return err
+		}
+		var got []byte
+		switch {
+		case vm.PublicKeyJWK != nil:
+			var jwk struct{ Kty, Crv, X string }
+			if err := json.Unmarshal(vm.PublicKeyJWK, &jwk); err != nil {
+				return fmt.Errorf("verification method %q: %w", vm.ID, err)
+			}
+			if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" {
+				return fmt.Errorf("verification method %q not an Ed25519 key", vm.ID)
+			}
+			got, _ = base64.RawURLEncoding.DecodeString(jwk.X)
+		case vm.PublicKeyMultibase != "":
+			data, err := multibase.Decode(vm.PublicKeyMultibase)
+			if err != nil {
+				return fmt.Errorf("verification method %q: %w", vm.ID, err)
+			}
+			codec, pub, err := multibase.SplitCodec(data)
+			if err != nil || codec != multibase.Ed25519Pub {
+				return fmt.Errorf("verification method %q not an Ed25519 key", vm.ID)
+			}
+			got = pub
+		}
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("verification method %q has other key material", vm.ID)
+		}
+		seen[fragment] = true
+	}
+	for _, k := range state.Keys {
+		if !seen[k] {
+			return fmt.Errorf("current key %q has no verification method", k)
+		}
+	}
+	return nil
+}
+
+// Resolver resolves did:webs DIDs over HTTPS. The zero value is ready for
+// use.
+type Resolver struct {
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// Resolve implements the did.Resolver interface. The verification methods
+// of the document must match the current keys of the AID.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "webs" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := Parse(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := id.baseURL()
+	docJSON, err := r.get(ctx, base+"did.json", "application/did+json, application/json;q=0.5", MaxDocumentSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	stream, err := r.get(ctx, base+"keri.cesr", "application/cesr", MaxStreamSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+
+	events, err := ParseStream(stream)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	state, err := VerifyKEL(id.AID, events)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+
+	doc := new(did.Document)
+	if err := json.Unmarshal(docJSON, doc); err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: malformed document: %w", d, err)
+	}
+	if doc.ID != d.String() {
+		return nil, nil, fmt.Errorf("did: resolve %s: document has id %q", d, doc.ID)
+	}
+	if err := verifyBinding(doc, state); err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: document not bound to the AID: %w", d, err)
+	}
+
+	meta := new(did.Metadata)
+	meta.Resolution.ContentType = contentType
+	meta.Document.VersionID = strconv.Itoa(state.Sequence)
+	return doc, meta, nil
+}
+
+func (r *Resolver) get(ctx context.Context, location, accept string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotFound, http.StatusGone:
+		return nil, fmt.Errorf("%s: %w", location, did.ErrNotFound)
+	default:
+		return nil, fmt.Errorf("%s got HTTP %q", location, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s exceeds %d bytes", location, limit)
+	}
+	return body, nil
+}
@@ -0,0 +1,140 @@
+package webs
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+// event returns the serialization of fields in order, with the version
+// string and the SAID (also as AID on inception) filled in.
+func event(fields [][2]any) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(f[0])
+		v, _ := json.Marshal(f[1])
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	raw := bytes.Replace(buf.Bytes(), []byte("KERI10JSON000000_"), []byte(fmt.Sprintf("KERI10JSON%06x_", buf.Len())), 1)
+	said := Digest(raw)
+	return bytes.ReplaceAll(raw, saidDummy, []byte(said))
+}
+
+// sign returns the controller signature attachment.
+func sign(keys ...ed25519.PrivateKey) func([]byte) string {
+	return func(raw []byte) string {
+		s := fmt.Sprintf("-AA%c", b64Alphabet[len(keys)])
+		for i, k := range keys {
+			sig := base64.RawURLEncoding.EncodeToString(append([]byte{0, 0}, ed25519.Sign(k, raw)...))
+			s += "A" + string(b64Alphabet[i]) + sig[2:]
+		}
+		return s
+	}
+}
+
+func qb64(key ed25519.PrivateKey) string {
+	return encode32(codeEd25519, key.Public().(ed25519.PublicKey))
+}
+
+func TestResolve(t *testing.T) {
+	_, key0, _ := ed25519.GenerateKey(nil)
+	_, key1, _ := ed25519.GenerateKey(nil)
+	_, key2, _ := ed25519.GenerateKey(nil)
+	dummy := string(saidDummy)
+	version := "KERI10JSON000000_"
+
+	icp := event([][2]any{{"v", version}, {"t", "icp"}, {"d", dummy}, {"i", dummy}, {"s", "0"},
+		{"kt", "1"}, {"k", []string{qb64(key0)}}, {"nt", "1"}, {"n", []string{Digest([]byte(qb64(key1)))}},
+		{"bt", "0"}, {"b", []string{}}, {"c", []string{}}, {"a", []any{}}})
+	var inception Event
+	json.Unmarshal(icp, &inception)
+	aid := inception.SAID
+
+	rot := event([][2]any{{"v", version}, {"t", "rot"}, {"d", dummy}, {"i", aid}, {"s", "1"}, {"p", aid},
+		{"kt", "1"}, {"k", []string{qb64(key1)}}, {"nt", "1"}, {"n", []string{Digest([]byte(qb64(key2)))}},
+		{"bt", "0"}, {"br", []string{}}, {"ba", []string{}}, {"a", []any{}}})
+	// first seen replay couple: sequence number and date-time
+	firstSeen := "-EAB" + "0A" + strings.Repeat("A", 22) + "1AAG" + "2024-01-01T00c00c00d000000p00c00"
+	stream := string(icp) + sign(key0)(icp) + firstSeen + string(rot) + sign(key1)(rot)
+
+	var docJSON string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dids/" + aid + "/did.json":
+			w.Write([]byte(docJSON))
+		case "/dids/" + aid + "/keri.cesr":
+			w.Write([]byte(stream))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	id := "did:webs:" + strings.Replace(strings.TrimPrefix(srv.URL, "https://"), ":", "%3A", 1) + ":dids:" + aid
+	d, err := did.Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm := func(key ed25519.PrivateKey) string {
+		x := base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+		return `{"id":"` + id + `#` + qb64(key) + `","type":"JsonWebKey","controller":"` + id + `","publicKeyJwk":{"kid":"` + qb64(key) + `","kty":"OKP","crv":"Ed25519","x":"` + x + `"}}`
+	}
+	r := &Resolver{Client: srv.Client()}
+
+	docJSON = `{"id":"` + id + `","verificationMethod":[` + vm(key1) + `]}`
+	doc, meta, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.VerificationMethod) != 1 || meta.Document.VersionID != "1" {
+		t.Errorf("got %d verification methods at version %q", len(doc.VerificationMethod), meta.Document.VersionID)
+	}
+
+	// rotated key
+	docJSON = `{"id":"` + id + `","verificationMethod":[` + vm(key0) + `]}`
+	if _, _, err := r.Resolve(context.Background(), d, did.ResolutionOptions{}); err == nil {
+		t.Error("document with stale key resolved")
+	}
+
+	// rotation to a key without commitment
+	docJSON = `{"id":"` + id + `","verificationMethod":[` + vm(key2) + `]}`
+	forged := event([][2]any{{"v", version}, {"t", "rot"}, {"d", dummy}, {"i", aid}, {"s", "1"}, {"p", aid},
+		{"kt", "1"}, {"k", []string{qb64(key2)}}, {"nt", "0"}, {"n", []string{}},
+		{"bt", "0"}, {"br", []string{}}, {"ba", []string{}}, {"a", []any{}}})
+	stream = string(icp) + sign(key0)(icp) + string(forged) + sign(key2)(forged)
+	if _, _, err := r.Resolve(context.Background(), d, did.ResolutionOptions{}); !errors.Is(err, ErrKEL) {
+		t.Errorf("got error %v, want %v", err, ErrKEL)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"example.com", "example.com:EKYGGh-FtAphGmSZbsuBs_t4qpsjYJ2ZqvMKluq9Oxm", "example.com:XKYGGh-FtAphGmSZbsuBs_t4qpsjYJ2ZqvMKluq9OxmP"} {
+		if _, err := Parse(&did.DID{Method: "webs", ID: s}); !errors.Is(err, did.ErrInvalidDID) {
+			t.Errorf("%s got error %v, want %v", s, err, did.ErrInvalidDID)
+		}
+	}
+	id, err := Parse(&did.DID{Method: "webs", ID: "example.com:dids:EKYGGh-FtAphGmSZbsuBs_t4qpsjYJ2ZqvMKluq9OxmP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := id.baseURL(), "https://example.com/dids/EKYGGh-FtAphGmSZbsuBs_t4qpsjYJ2ZqvMKluq9OxmP/"; got != want {
+		t.Errorf("got base URL %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,484 @@
+// Package webvh implements the did:webvh method (formerly did:tdw) with
+// verification of the DID log.
+// https://identity.foundation/didwebvh/v1.0/
+//
+// Logs of specification version 1.0 are supported. Witness proofs are not
+// verified.
+package webvh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/base58"
+	"github.com/ockam-network/did/internal/jcs"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+// MethodVersion is the method parameter of supported logs.
+const MethodVersion = "did:webvh:1.0"
+
+// MaxLogSize is the limit for DID logs in bytes.
+const MaxLogSize = 4 << 20
+
+// scidPlaceholder substitutes the SCID in the genesis entry.
+const scidPlaceholder = "{SCID}"
+
+// Parameters configure the DID from their entry onwards. Absent values
+// retain their previous value.
+type Parameters struct {
+	Method        string    `json:"method,omitempty"`
+	SCID          string    `json:"scid,omitempty"`
+	UpdateKeys    *[]string `json:"updateKeys,omitempty"`
+	NextKeyHashes *[]string `json:"nextKeyHashes,omitempty"`
+	Portable      *bool     `json:"portable,omitempty"`
+	Deactivated   *bool     `json:"deactivated,omitempty"`
+	TTL           *int      `json:"ttl,omitempty"`
+}
+
+// merge applies the changes of p.
+func (active *Parameters) merge(p *Parameters) {
+	if p.Method != "" {
+		active.Method = p.Method
+	}
+	if p.SCID != "" {
+		active.SCID = p.SCID
+	}
+	if p.UpdateKeys != nil {
+		active.UpdateKeys = p.UpdateKeys
+	}
+	if p.NextKeyHashes != nil {
+		active.NextKeyHashes = p.NextKeyHashes
+	}
+	if p.Portable != nil {
+		active.Portable = p.Portable
+	}
+	if p.Deactivated != nil {
+		active.Deactivated = p.Deactivated
+	}
+	if p.TTL != nil {
+		active.TTL = p.TTL
+	}
+}
+
+// Entry is a line from the DID log.
+type Entry struct {
+	VersionID   string          `json:"versionId"`
+	VersionTime time.Time       `json:"versionTime"`
+	Parameters  Parameters      `json:"parameters"`
+	State       json.RawMessage `json:"state"`
+
+	// Active has the parameters in effect after the entry.
+	Active Parameters `json:"-"`
+
+	// fields has the JSON members, for hashing
+	fields map[string]any
+}
+
+// Version returns the number and the hash of VersionID.
+func (e *Entry) Version() (n int, entryHash string, err error) {
+	num, hash, ok := strings.Cut(e.VersionID, "-")
+	n, err = strconv.Atoi(num)
+	if !ok || err != nil || n < 1 || num[0] == '0' || hash == "" {
+		return 0, "", fmt.Errorf("did:webvh: malformed versionId %q", e.VersionID)
+	}
+	return n, hash, nil
+}
+
+// hash returns the base58btc of the SHA2-256 multihash.
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return base58.Encode(append([]byte{0x12, 0x20}, sum[:]...))
+}
+
+// HashKey returns the nextKeyHashes value of an update key.
+func HashKey(multikey string) string { return hash([]byte(multikey)) }
+
+// withoutProof returns the canonical JSON of the entry without proof, and
+// with the versionId set.
+func (e *Entry) withoutProof(versionID string) ([]byte, error) {
+	fields := make(map[string]any, len(e.fields))
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	delete(fields, "proof")
+	fields["versionId"] = versionID
+	return jcs.Marshal(fields)
+}
+
+// SCID returns the self-certifying identifier of a genesis entry.
+func (e *Entry) SCID() (string, error) {
+	canon, err := e.withoutProof(scidPlaceholder)
+	if err != nil {
+		return "", err
+	}
+	canon, err = jcs.Transform(bytes.ReplaceAll(canon, []byte(e.Parameters.SCID), []byte(scidPlaceholder)))
+	if err != nil {
+		return "", err
+	}
+	return hash(canon), nil
+}
+
+// ErrLog means the DID log failed verification.
+var ErrLog = errors.New("did:webvh: invalid log")
+
+// ParseLog reads the JSON Lines of a DID log.
+func ParseLog(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, MaxLogSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		e := new(Entry)
+		if err := json.Unmarshal(line, e); err != nil {
+			return nil, fmt.Errorf("%w: entry %d: %w", ErrLog, len(entries)+1, err)
+		}
+		if err := json.Unmarshal(line, &e.fields); err != nil {
+			return nil, fmt.Errorf("%w: entry %d: %w", ErrLog, len(entries)+1, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// VerifyLog checks the entries in order. It verifies the SCID, the hash
+// chain, the versionTime order, key pre-rotation, and the proofs against the
+// authorized update keys. Each entry gets its Active parameters.
+func VerifyLog(d *did.DID, entries []*Entry, now time.Time) error {
+	scid, _, _ := strings.Cut(strings.TrimPrefix(d.String(), "did:webvh:"), ":")
+	if len(entries) == 0 {
+		return fmt.Errorf("%w: no entries", ErrLog)
+	}
+
+	var active Parameters
+	prevVersionID := scid
+	var prevTime time.Time
+	for i, e := range entries {
+		n, entryHash, err := e.Version()
+		if err != nil {
+			return fmt.Errorf("%w: entry %d: %w", ErrLog, i+1, err)
+		}
+		if n != i+1 {
+			return fmt.Errorf("%w: entry %d has version number %d", ErrLog, i+1, n)
+		}
+		if active.Deactivated != nil && *active.Deactivated {
+			return fmt.Errorf("%w: entry %d after deactivation", ErrLog, i+1)
+		}
+
+		// authorized keys are those before the entry, unless pre-rotated
+		authorized := active.UpdateKeys
+		if i == 0 {
+			if e.Parameters.Method != MethodVersion {
+				return fmt.Errorf("%w: method %q not supported", ErrLog, e.Parameters.Method)
+			}
+			if e.Parameters.SCID != scid {
+				return fmt.Errorf("%w: SCID parameter %q does not match the DID", ErrLog, e.Parameters.SCID)
+			}
+			got, err := e.SCID()
+			if err != nil {
+				return fmt.Errorf("%w: entry 1: %w", ErrLog, err)
+			}
+			if got != scid {
+				return fmt.Errorf("%w: SCID %q does not match the genesis entry", ErrLog, scid)
+			}
+			authorized = e.Parameters.UpdateKeys
+		} else if e.Parameters.SCID != "" && e.Parameters.SCID != scid {
+			return fmt.Errorf("%w: entry %d changes the SCID", ErrLog, i+1)
+		}
+		if active.NextKeyHashes != nil && len(*active.NextKeyHashes) != 0 {
+			if e.Parameters.UpdateKeys == nil {
+				return fmt.Errorf("%w: entry %d without pre-rotated update keys", ErrLog, i+1)
+			}
+			for _, k := range *e.Parameters.UpdateKeys {
+				if !slices.Contains(*active.NextKeyHashes, HashKey(k)) {
+					return fmt.Errorf("%w: entry %d has update key %q not committed to with nextKeyHashes", ErrLog, i+1, k)
+				}
+			}
+			authorized = e.Parameters.UpdateKeys
+		}
+		if authorized == nil || len(*authorized) == 0 {
+			return fmt.Errorf("%w: entry %d without update keys", ErrLog, i+1)
+		}
+
+		canon, err := e.withoutProof(prevVersionID)
+		if err != nil {
+			return fmt.Errorf("%w: entry %d: %w", ErrLog, i+1, err)
+		}
+		if hash(canon) != entryHash {
+			return fmt.Errorf("%w: entry %d hash mismatch", ErrLog, i+1)
+		}
+
+		if e.VersionTime.IsZero() || e.VersionTime.Before(prevTime) || e.VersionTime.After(now) {
+			return fmt.Errorf("%w: entry %d versionTime %s out of order", ErrLog, i+1, e.VersionTime.Format(time.RFC3339))
+		}
+		if err := e.verifyProof(*authorized); err != nil {
+			return fmt.Errorf("%w: entry %d: %w", ErrLog, i+1, err)
+		}
+
+		var state struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(e.State, &state); err != nil {
+			return fmt.Errorf("%w: entry %d state: %w", ErrLog, i+1, err)
+		}
+		if state.ID != d.String() {
+			return fmt.Errorf("%w: entry %d has state of %q", ErrLog, i+1, state.ID)
+		}
+
+		active.merge(&e.Parameters)
+		e.Active = active
+		prevVersionID = e.VersionID
+		prevTime = e.VersionTime
+	}
+	return nil
+}
+
+// Proof is a Data Integrity proof with the eddsa-jcs-2022 cryptosuite.
+type Proof struct {
+	Type               string `json:"type"`
+	Cryptosuite        string `json:"cryptosuite"`
+	VerificationMethod string `json:"verificationMethod"`
+	Created            string `json:"created,omitempty"`
+	ProofPurpose       string `json:"proofPurpose"`
+	ProofValue         string `json:"proofValue,omitempty"`
+}
+
+// SignEntry returns the proof of entry content (without proof) with an
+// update key.
+func SignEntry(entry []byte, updateKey ed25519.PrivateKey, created time.Time) (*Proof, error) {
+	multikey := multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, multibase.Ed25519Pub), updateKey.Public().(ed25519.PublicKey)...))
+	p := &Proof{
+		Type:               "DataIntegrityProof",
+		Cryptosuite:        "eddsa-jcs-2022",
+		VerificationMethod: "did:key:" + multikey + "#" + multikey,
+		Created:            created.UTC().Format(time.RFC3339),
+		ProofPurpose:       "assertionMethod",
+	}
+	data, err := hashData(p, entry)
+	if err != nil {
+		return nil, err
+	}
+	p.ProofValue = multibase.Encode(multibase.Base58BTC, ed25519.Sign(updateKey, data))
+	return p, nil
+}
+
+// hashData returns the input for signatures.
+func hashData(p *Proof, entry []byte) ([]byte, error) {
+	config := *p
+	config.ProofValue = ""
+	canonConfig, err := jcs.Marshal(&config)
+	if err != nil {
+		return nil, err
+	}
+	canonEntry, err := jcs.Transform(entry)
+	if err != nil {
+		return nil, err
+	}
+	configHash := sha256.Sum256(canonConfig)
+	entryHash := sha256.Sum256(canonEntry)
+	return append(configHash[:], entryHash[:]...), nil
+}
+
+// verifyProof checks for a valid proof from any of the update keys.
+func (e *Entry) verifyProof(updateKeys []string) error {
+	raw, err := json.Marshal(e.fields["proof"])
+	if err != nil {
+		return err
+	}
+	var proofs []Proof
+	if bytes.HasPrefix(raw, []byte("{")) {
+		proofs = make([]Proof, 1)
+		err = json.Unmarshal(raw, &proofs[0])
+	} else {
+		err = json.Unmarshal(raw, &proofs)
+	}
+	if err != nil {
+		return fmt.Errorf("malformed proof: %w", err)
+	}
+
+	entry, err := e.withoutProof(e.VersionID)
+	if err != nil {
+		return err
+	}
+	for i := range proofs {
+		p := &proofs[i]
+		if p.Type != "DataIntegrityProof" || p.Cryptosuite != "eddsa-jcs-2022" || p.ProofPurpose != "assertionMethod" {
+			continue
+		}
+		multikey, fragment, _ := strings.Cut(strings.TrimPrefix(p.VerificationMethod, "did:key:"), "#")
+		if multikey != fragment || !slices.Contains(updateKeys, multikey) {
+			continue
+		}
+		data, err := multibase.Decode(multikey)
+		if err != nil {
+			continue
+		}
+		codec, pub, err := multibase.SplitCodec(data)
+		if err != nil || codec != multibase.Ed25519Pub || len(pub) != ed25519.PublicKeySize {
+			continue
+		}
+		sig, err := multibase.Decode(p.ProofValue)
+		if err != nil {
+			continue
+		}
+		signed, err := hashData(p, entry)
+		if err != nil {
+			return err
+		}
+		if ed25519.Verify(pub, signed, sig) {
+			return nil
+		}
+	}
+	return errors.New("no valid proof from an authorized update key")
+}
+
+// baseURL returns the location of the DID log directory, with a trailing
+// slash.
+func baseURL(d *did.DID) (string, error) {
+	segments := strings.Split(strings.TrimPrefix(d.String(), "did:webvh:"), ":")
+	if len(segments) < 2 {
+		return "", fmt.Errorf("%w: no domain", did.ErrInvalidDID)
+	}
+	host, err := url.PathUnescape(segments[1])
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", did.ErrInvalidDID, err)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", fmt.Errorf("%w: host %q", did.ErrInvalidDID, host)
+	}
+	if len(segments) > 2 {
+		return "https://" + host + "/" + strings.Join(segments[2:], "/") + "/", nil
+	}
+	return "https://" + host + "/.well-known/", nil
+}
+
+// Resolver resolves did:webvh DIDs over HTTPS. The zero value is ready for
+// use.
+type Resolver struct {
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// Now is the clock with a nil default of time.Now.
+	Now func() time.Time
+}
+
+// Resolve implements the did.Resolver interface. Options VersionID and
+// VersionTime select an entry other than the last.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	if d.Method != "webvh" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	}
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	base, err := baseURL(d)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"did.jsonl", nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotFound, http.StatusGone:
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrNotFound)
+	default:
+		return nil, nil, fmt.Errorf("did: resolve %s: %sdid.jsonl got HTTP %q", d, base, resp.Status)
+	}
+
+	entries, err := ParseLog(io.LimitReader(resp.Body, MaxLogSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	if err := VerifyLog(d, entries, now()); err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+
+	selected := entries[len(entries)-1]
+	switch {
+	case opts.VersionID != "":
+		selected = nil
+		for _, e := range entries {
+			if e.VersionID == opts.VersionID {
+				selected = e
+			}
+		}
+	case !opts.VersionTime.IsZero():
+		selected = nil
+		for _, e := range entries {
+			if !e.VersionTime.After(opts.VersionTime) {
+				selected = e
+			}
+		}
+	}
+	if selected == nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: version: %w", d, did.ErrNotFound)
+	}
+
+	doc := new(did.Document)
+	if err := json.Unmarshal(selected.State, doc); err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: malformed state: %w", d, err)
+	}
+	addImplicitService(doc, "#files", "relativeRef", base)
+	addImplicitService(doc, "#whois", "LinkedVerifiablePresentation", base+"whois.vp")
+
+	meta := new(did.Metadata)
+	meta.Resolution.ContentType = contentType
+	meta.Document.Created = entries[0].VersionTime
+	meta.Document.Updated = selected.VersionTime
+	meta.Document.VersionID = selected.VersionID
+	meta.Document.Deactivated = selected.Active.Deactivated != nil && *selected.Active.Deactivated
+	if i := slices.Index(entries, selected); i+1 < len(entries) {
+		meta.Document.NextVersionID = entries[i+1].VersionID
+		meta.Document.NextUpdate = entries[i+1].VersionTime
+	}
+	return doc, meta, nil
+}
+
+// addImplicitService adds a service unless the document has the ID already.
+func addImplicitService(doc *did.Document, fragment, typ, endpoint string) {
+	for _, s := range doc.Service {
+		if s.ID == fragment || s.ID == doc.ID+fragment {
+			return
+		}
+	}
+	doc.Service = append(doc.Service, did.Service{ID: doc.ID + fragment, Type: typ, ServiceEndpoint: did.ServiceEndpoint{URI: endpoint}})
+}
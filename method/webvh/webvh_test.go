@@ -0,0 +1,136 @@
+package webvh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ockam-network/did"
+	"github.com/ockam-network/did/internal/jcs"
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+func multikey(key ed25519.PrivateKey) string {
+	return multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, multibase.Ed25519Pub), key.Public().(ed25519.PublicKey)...))
+}
+
+// appendEntry adds a signed line to the log, and it returns the versionId.
+func appendEntry(t *testing.T, log *strings.Builder, prevVersionID string, fields map[string]any, key ed25519.PrivateKey) string {
+	fields["versionId"] = prevVersionID
+	canon, err := jcs.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := strings.Count(log.String(), "\n") + 1
+	versionID := strconv.Itoa(n) + "-" + hash(canon)
+	fields["versionId"] = versionID
+	entry, err := jcs.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := SignEntry(entry, key, time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields["proof"] = []*Proof{proof}
+	line, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Write(line)
+	log.WriteByte('\n')
+	return versionID
+}
+
+func TestResolve(t *testing.T) {
+	_, key1, _ := ed25519.GenerateKey(nil)
+	_, key2, _ := ed25519.GenerateKey(nil)
+
+	var log strings.Builder
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dids/alice/did.jsonl" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/jsonl")
+		w.Write([]byte(log.String()))
+	}))
+	defer srv.Close()
+	host := strings.Replace(strings.TrimPrefix(srv.URL, "https://"), ":", "%3A", 1)
+
+	// genesis with placeholders
+	genesis := `{"versionId":"{SCID}","versionTime":"2024-01-01T00:00:00Z","parameters":{"method":"did:webvh:1.0","scid":"{SCID}","updateKeys":["` + multikey(key1) + `"],"nextKeyHashes":["` + HashKey(multikey(key2)) + `"]},"state":{"@context":["https://www.w3.org/ns/did/v1"],"id":"did:webvh:{SCID}:` + host + `:dids:alice"}}`
+	canon, err := jcs.Transform([]byte(genesis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scid := hash(canon)
+	id := "did:webvh:" + scid + ":" + host + ":dids:alice"
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(strings.ReplaceAll(genesis, scidPlaceholder, scid)), &fields); err != nil {
+		t.Fatal(err)
+	}
+	v1 := appendEntry(t, &log, scid, fields, key1)
+
+	fields = map[string]any{
+		"versionTime": "2024-02-01T00:00:00Z",
+		"parameters":  map[string]any{"updateKeys": []string{multikey(key2)}, "nextKeyHashes": []string{}},
+		"state": map[string]any{"@context": []string{"https://www.w3.org/ns/did/v1"}, "id": id,
+			"service": []map[string]any{{"id": id + "#home", "type": "LinkedDomains", "serviceEndpoint": "https://example.com/"}}},
+	}
+	v2 := appendEntry(t, &log, v1, fields, key2)
+
+	d, err := did.Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Resolver{Client: srv.Client()}
+
+	doc, meta, err := r.Resolve(context.Background(), d, did.ResolutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Document.VersionID != v2 {
+		t.Errorf("got version %q, want %q", meta.Document.VersionID, v2)
+	}
+	if len(doc.Service) != 3 || doc.Service[0].ID != id+"#home" || doc.Service[1].ID != id+"#files" {
+		t.Errorf("got services %+v, want #home and the implicit ones", doc.Service)
+	}
+
+	_, meta, err = r.Resolve(context.Background(), d, did.ResolutionOptions{VersionTime: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Document.VersionID != v1 || meta.Document.NextVersionID != v2 {
+		t.Errorf("got version %q with next %q, want %q with next %q", meta.Document.VersionID, meta.Document.NextVersionID, v1, v2)
+	}
+
+	// update key not pre-rotated
+	_, key3, _ := ed25519.GenerateKey(nil)
+	lines := strings.SplitAfter(log.String(), "\n")
+	log.Reset()
+	log.WriteString(lines[0])
+	fields = map[string]any{
+		"versionTime": "2024-02-01T00:00:00Z",
+		"parameters":  map[string]any{"updateKeys": []string{multikey(key3)}},
+		"state":       map[string]any{"id": id},
+	}
+	appendEntry(t, &log, v1, fields, key3)
+	if _, _, err := r.Resolve(context.Background(), d, did.ResolutionOptions{}); !errors.Is(err, ErrLog) {
+		t.Errorf("got error %v, want %v", err, ErrLog)
+	}
+
+	// tampered genesis
+	log.Reset()
+	log.WriteString(strings.Replace(lines[0], "2024-01-01", "2023-01-01", 1))
+	if _, _, err := r.Resolve(context.Background(), d, did.ResolutionOptions{}); !errors.Is(err, ErrLog) {
+		t.Errorf("tampered got error %v, want %v", err, ErrLog)
+	}
+}
@@ -0,0 +1,125 @@
+package did
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errTestChecksum = errors.New("last digit not the sum")
+
+func init() {
+	RegisterMethod("testspec", MethodSpec{
+		MinLength: 3,
+		MaxLength: 8,
+		Alphabet:  "0123456789",
+		Checksum: func(id string) error {
+			var sum int
+			for _, c := range strings.ReplaceAll(id[:len(id)-1], ":", "") {
+				sum += int(c - '0')
+			}
+			if int(id[len(id)-1]-'0') != sum%10 {
+				return errTestChecksum
+			}
+			return nil
+		},
+	})
+	RegisterMethod("testb64", MethodSpec{ExtraIDChars: "="})
+	RegisterMethod("testhex", MethodSpec{IDChars: "0123456789abcdef"})
+}
+
+func TestRegisterMethod(t *testing.T) {
+	tests := []struct {
+		input  string
+		err    error
+		offset int
+	}{
+		{"did:testspec:1236", nil, 0},
+		{"did:testspec:12:36", nil, 0},
+		{"did:testspec:1235", ErrChecksum, 13},
+		{"did:testspec:11", ErrIDLength, 13},
+		{"did:testspec:123456781", ErrIDLength, 13},
+		{"did:testspec:12a3", ErrInvalidIDChar, 15},
+		{"did:testspec:12%33", ErrInvalidIDChar, 15},
+		// other methods are not affected
+		{"did:example:12a3", nil, 0},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		assert(t, true, errors.Is(err, test.err), "Parse(%q) error %v, want %v", test.input, err, test.err)
+		if test.err != nil {
+			var perr *ParseError
+			assert(t, true, errors.As(err, &perr), "Parse(%q) error type %T", test.input, err)
+			if perr != nil {
+				assert(t, ComponentID, perr.Component, "Parse(%q) error component", test.input)
+				assert(t, test.offset, perr.Offset, "Parse(%q) error offset", test.input)
+			}
+		}
+
+		_, err = ParseURL(test.input + "#key-1")
+		assert(t, true, errors.Is(err, test.err), "ParseURL(%q) error %v, want %v", test.input, err, test.err)
+		assert(t, test.err == nil, Valid(test.input), "Valid(%q)", test.input)
+		assert(t, test.err == nil, ValidURL(test.input+"/path"), "ValidURL(%q)", test.input)
+	}
+
+	_, err := Parse("did:testspec:1235")
+	assert(t, true, errors.Is(err, errTestChecksum), "checksum error %v not wrapped", err)
+
+	t.Run("all errors", func(t *testing.T) {
+		p := Parser{AllErrors: true}
+		_, err := p.Parse("did:testspec:1a2b")
+		assert(t, 2, len(err.(interface{ Unwrap() []error }).Unwrap()), "error %v", err)
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		for _, name := range []string{"testspec", "Test", ""} {
+			func() {
+				defer func() {
+					assert(t, true, recover() != nil, "RegisterMethod(%q) did not panic", name)
+				}()
+				RegisterMethod(name, MethodSpec{})
+			}()
+		}
+	})
+}
+
+func TestMethodIDChars(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{"did:testb64:YWJj", nil},
+		{"did:testb64:YWI=", nil},
+		{"did:testb64:YQ==:YWI=", nil},
+		{"did:example:YWI=", ErrInvalidIDChar},
+		{"did:testhex:c0ffee", nil},
+		{"did:testhex:C0FFEE", ErrInvalidIDChar},
+		{"did:testhex:c0:ff%65", nil},
+	}
+	for _, test := range tests {
+		_, err := ParseURL(test.input + "#key-1")
+		assert(t, true, errors.Is(err, test.err), "ParseURL(%q) error %v, want %v", test.input, err, test.err)
+		assert(t, test.err == nil, Valid(test.input), "Valid(%q)", test.input)
+		d, rest, _ := ParsePrefix(test.input + " x")
+		if test.err == nil {
+			assert(t, test.input, d.String(), "ParsePrefix(%q)", test.input)
+			assert(t, " x", rest, "ParsePrefix(%q) remainder", test.input)
+		}
+	}
+
+	u, err := NewBuilder("testb64").ID("YQ==", "a/b").Build()
+	assert(t, nil, err)
+	assert(t, "did:testb64:YQ==:a%2Fb", u.String())
+	u, err = NewBuilder("testhex").ID("Ab").Build()
+	assert(t, nil, err)
+	assert(t, "did:testhex:%41b", u.String())
+
+	for _, chars := range []string{":", "%", "/", " ", "\x80"} {
+		func() {
+			defer func() {
+				assert(t, true, recover() != nil, "ExtraIDChars %q did not panic", chars)
+			}()
+			RegisterMethod("testpanic", MethodSpec{ExtraIDChars: chars})
+		}()
+	}
+}
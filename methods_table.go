@@ -0,0 +1,29 @@
+package did
+
+// registeredMethods has the seed entries for the methods of this module only,
+// in the output format of genmethods. Run go generate with a checkout of the
+// DID Specification Registries to replace the file with the full table.
+var registeredMethods = [...]RegisteredMethod{
+	{"btc1", "registered", "https://dcdpr.github.io/did-btc1/", NetworkLedger},
+	{"btcr", "registered", "https://w3c-ccg.github.io/didm-btcr/", NetworkLedger},
+	{"cheqd", "registered", "https://docs.cheqd.io/identity/architecture/adr-list/adr-001-cheqd-did-method", NetworkLedger},
+	{"dns", "registered", "https://danubetech.github.io/did-method-dns/", NetworkDNS},
+	{"ebsi", "registered", "https://hub.ebsi.eu/vc-framework/did/legal-entities", NetworkLedger},
+	{"ens", "registered", "https://github.com/veramolabs/did-ens-spec", NetworkLedger},
+	{"ethr", "registered", "https://github.com/decentralized-identity/ethr-did-resolver/blob/master/doc/did-method-spec.md", NetworkLedger},
+	{"hedera", "registered", "https://github.com/hashgraph/did-method/blob/master/hedera-did-method-specification.md", NetworkLedger},
+	{"indy", "registered", "https://hyperledger.github.io/indy-did-method/", NetworkLedger},
+	{"ion", "registered", "https://github.com/decentralized-identity/ion-did-method", NetworkLedger},
+	{"iota", "registered", "https://wiki.iota.org/identity.rs/references/specifications/iota-did-method-spec/", NetworkLedger},
+	{"ipid", "registered", "https://did-ipid.github.io/ipid-did-method/", NetworkIPFS},
+	{"jwk", "registered", "https://github.com/quartzjer/did-jwk/blob/main/spec.md", NetworkNone},
+	{"key", "registered", "https://w3c-ccg.github.io/did-method-key/", NetworkNone},
+	{"ockam", "registered", "https://github.com/ockam-network/did", NetworkOther},
+	{"peer", "registered", "https://identity.foundation/peer-did-method-spec/", NetworkNone},
+	{"pkh", "registered", "https://github.com/w3c-ccg/did-pkh/blob/main/did-pkh-method-draft.md", NetworkOther},
+	{"plc", "registered", "https://web.plc.directory/spec/v0.1/did-plc", NetworkOther},
+	{"sov", "registered", "https://sovrin-foundation.github.io/sovrin/spec/did-method-spec-template.html", NetworkLedger},
+	{"web", "registered", "https://w3c-ccg.github.io/did-method-web/", NetworkWeb},
+	{"webs", "registered", "https://trustoverip.github.io/tswg-did-method-webs-specification/", NetworkWeb},
+	{"webvh", "registered", "https://identity.foundation/didwebvh/", NetworkWeb},
+}
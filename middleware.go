@@ -0,0 +1,144 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"runtime/debug"
+	"time"
+)
+
+// A Middleware wraps a Resolver with additional behaviour, like http.Handler
+// middleware does.
+type Middleware func(Resolver) Resolver
+
+// Chain returns r wrapped in each middleware. The first middleware is the
+// outermost, i.e., it sees the resolution first.
+//
+//	r := did.Chain(mux, did.Recover(), did.Logging(logger), did.Retry(did.RetryOptions{}))
+func Chain(r Resolver, middleware ...Middleware) Resolver {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		r = middleware[i](r)
+	}
+	return r
+}
+
+// RetryOptions configures Retry.
+type RetryOptions struct {
+	// MaxAttempts limits the number of resolutions, including the first
+	// one. Zero defaults to 3.
+	MaxAttempts int
+
+	// BaseDelay is the upper bound of the wait before the first retry,
+	// which doubles with each retry. Zero defaults to 100 ms.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the upper bound of the waits. Zero defaults to 5 s.
+	MaxDelay time.Duration
+
+	// Retryable returns whether a failure is transient. The nil value
+	// defaults to IsTransient.
+	Retryable func(error) bool
+}
+
+// IsTransient returns whether err may be gone on retry. Failures which wrap
+// one of the resolution reasons, like ErrNotFound, are permanent, as are
+// context errors.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return ErrorCode(err) == ErrorInternal
+}
+
+// Retry returns middleware which retries transient failures with exponential
+// backoff. Each wait is a random duration up to the bound, also known as
+// full jitter, such that clients don't retry in lockstep.
+func Retry(opts RetryOptions) Middleware {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = 100 * time.Millisecond
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = 5 * time.Second
+	}
+	if opts.Retryable == nil {
+		opts.Retryable = IsTransient
+	}
+
+	return func(next Resolver) Resolver {
+		return ResolverFunc(func(ctx context.Context, d *DID, ro ResolutionOptions) (*Document, *Metadata, error) {
+			bound := opts.BaseDelay
+			for attempt := 1; ; attempt++ {
+				doc, meta, err := next.Resolve(ctx, d, ro)
+				if err == nil || attempt >= opts.MaxAttempts || !opts.Retryable(err) {
+					return doc, meta, err
+				}
+
+				timer := time.NewTimer(rand.N(bound) + 1)
+				select {
+				case <-timer.C:
+					break
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, nil, err
+				}
+				bound = min(2*bound, opts.MaxDelay)
+			}
+		})
+	}
+}
+
+// Logging returns middleware which logs each resolution to logger, with the
+// DID, the duration and the outcome. Failures log at level warn.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next Resolver) Resolver {
+		return ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+			start := time.Now()
+			doc, meta, err := next.Resolve(ctx, d, opts)
+			attrs := []slog.Attr{
+				slog.String("did", d.String()),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if opts.Accept != "" {
+				attrs = append(attrs, slog.String("accept", opts.Accept))
+			}
+			if opts.VersionID != "" {
+				attrs = append(attrs, slog.String("versionId", opts.VersionID))
+			}
+			if !opts.VersionTime.IsZero() {
+				attrs = append(attrs, slog.Time("versionTime", opts.VersionTime))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", ErrorCode(err)), slog.String("cause", err.Error()))
+				logger.LogAttrs(ctx, slog.LevelWarn, "DID resolution failed", attrs...)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelInfo, "DID resolved", attrs...)
+			}
+			return doc, meta, err
+		})
+	}
+}
+
+// ErrPanic means a resolver panicked, as reported by Recover.
+var ErrPanic = errors.New("resolver panic")
+
+// Recover returns middleware which turns panics into errors which wrap
+// ErrPanic. The message includes the panic value and the stack trace.
+func Recover() Middleware {
+	return func(next Resolver) Resolver {
+		return ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (doc *Document, meta *Metadata, err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					doc, meta = nil, nil
+					err = fmt.Errorf("did: resolve %s: %w: %v\n%s", d, ErrPanic, p, debug.Stack())
+				}
+			}()
+			return next.Resolve(ctx, d, opts)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package did
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChain(t *testing.T) {
+	var trace []string
+	tag := func(name string) Middleware {
+		return func(next Resolver) Resolver {
+			return ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+				trace = append(trace, name)
+				return next.Resolve(ctx, d, opts)
+			})
+		}
+	}
+	r := Chain(staticResolver("x"), tag("a"), tag("b"))
+	_, _, err := r.Resolve(context.Background(), &DID{Method: "example", ID: "1"}, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, []string{"a", "b"}, trace)
+}
+
+func TestRetry(t *testing.T) {
+	var calls int
+	flaky := ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		calls++
+		switch {
+		case d.ID == "missing":
+			return nil, nil, fmt.Errorf("did: resolve %s: %w", d, ErrNotFound)
+		case calls < 3:
+			return nil, nil, errors.New("connection reset")
+		}
+		return &Document{ID: d.String()}, &Metadata{}, nil
+	})
+	r := Retry(RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond})(flaky)
+	ctx := context.Background()
+
+	doc, _, err := r.Resolve(ctx, &DID{Method: "example", ID: "1"}, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "did:example:1", doc.ID)
+	assert(t, 3, calls, "calls until success")
+
+	calls = 0
+	_, _, err = r.Resolve(ctx, &DID{Method: "example", ID: "missing"}, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrNotFound), "got error %v", err)
+	assert(t, 1, calls, "calls for permanent failure")
+
+	calls = -10
+	_, _, err = r.Resolve(ctx, &DID{Method: "example", ID: "1"}, ResolutionOptions{})
+	assert(t, "connection reset", fmt.Sprint(err))
+	assert(t, -7, calls, "calls limited by MaxAttempts")
+
+	calls = -10
+	slow := Retry(RetryOptions{BaseDelay: time.Hour})(flaky)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, _, err = slow.Resolve(ctx, &DID{Method: "example", ID: "1"}, ResolutionOptions{})
+	assert(t, "connection reset", fmt.Sprint(err))
+	assert(t, -9, calls, "calls with expired context")
+}
+
+func TestIsTransient(t *testing.T) {
+	assert(t, true, IsTransient(errors.New("connection reset")))
+	assert(t, false, IsTransient(nil))
+	assert(t, false, IsTransient(fmt.Errorf("x: %w", ErrInvalidDID)))
+	assert(t, false, IsTransient(fmt.Errorf("x: %w", context.DeadlineExceeded)))
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	r := Logging(logger)(ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		if d.ID == "missing" {
+			return nil, nil, fmt.Errorf("did: resolve %s: %w", d, ErrNotFound)
+		}
+		return &Document{ID: d.String()}, &Metadata{}, nil
+	}))
+
+	_, _, err := r.Resolve(context.Background(), &DID{Method: "example", ID: "1"}, ResolutionOptions{VersionID: "7"})
+	assert(t, nil, err)
+	_, _, err = r.Resolve(context.Background(), &DID{Method: "example", ID: "missing"}, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrNotFound))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert(t, 2, len(lines), "log lines %q", lines)
+	for _, want := range []string{"level=INFO", `msg="DID resolved"`, "did=did:example:1", "versionId=7"} {
+		assert(t, true, strings.Contains(lines[0], want), "log line %q misses %q", lines[0], want)
+	}
+	for _, want := range []string{"level=WARN", "did=did:example:missing", "error=notFound"} {
+		assert(t, true, strings.Contains(lines[1], want), "log line %q misses %q", lines[1], want)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	r := Recover()(ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		panic("boom")
+	}))
+	doc, _, err := r.Resolve(context.Background(), &DID{Method: "example", ID: "1"}, ResolutionOptions{})
+	assert(t, true, doc == nil, "got document on panic")
+	assert(t, true, errors.Is(err, ErrPanic), "got error %v", err)
+	assert(t, true, strings.Contains(err.Error(), "boom"), "error %q misses panic value", err)
+}
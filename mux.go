@@ -0,0 +1,85 @@
+package did
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// MethodMux is a Resolver which routes each DID to the resolver registered
+// for its method. The zero value is ready for use. Registration and
+// resolution may happen concurrently.
+type MethodMux struct {
+	mu       sync.RWMutex
+	methods  map[string]Resolver
+	fallback Resolver
+}
+
+// Handle registers r for the DID method. It panics when method is not a valid
+// method name, when r is nil, or when the method already has a resolver.
+func (mux *MethodMux) Handle(method string, r Resolver) {
+	if !validMethod(method) {
+		panic(fmt.Sprintf("did: invalid method name %q", method))
+	}
+	if r == nil {
+		panic("did: nil resolver for method " + method)
+	}
+
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	if _, ok := mux.methods[method]; ok {
+		panic("did: multiple registrations for method " + method)
+	}
+	if mux.methods == nil {
+		mux.methods = make(map[string]Resolver)
+	}
+	mux.methods[method] = r
+}
+
+// HandleFunc registers f for the DID method, like Handle.
+func (mux *MethodMux) HandleFunc(method string, f func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error)) {
+	mux.Handle(method, ResolverFunc(f))
+}
+
+// HandleFallback sets the resolver for any method without registration. A nil
+// r removes the fallback.
+func (mux *MethodMux) HandleFallback(r Resolver) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	mux.fallback = r
+}
+
+// Methods returns the registered method names in alphabetical order.
+func (mux *MethodMux) Methods() []string {
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+	names := make([]string, 0, len(mux.methods))
+	for name := range mux.methods {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Resolver returns the resolver for the DID method, if any. The fallback
+// applies to methods without registration.
+func (mux *MethodMux) Resolver(method string) (r Resolver, ok bool) {
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+	r, ok = mux.methods[method]
+	if !ok && mux.fallback != nil {
+		return mux.fallback, true
+	}
+	return r, ok
+}
+
+// Resolve implements the Resolver interface. Methods without resolver get an
+// error which wraps ErrMethodNotSupported.
+func (mux *MethodMux) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	r, ok := mux.Resolver(d.Method)
+	if !ok {
+		return nil, nil, fmt.Errorf("did: resolve %s: method %q: %w", d, d.Method, ErrMethodNotSupported)
+	}
+	return r.Resolve(ctx, d, opts)
+}
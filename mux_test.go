@@ -0,0 +1,75 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func staticResolver(id string) Resolver {
+	return ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		return &Document{ID: id}, &Metadata{}, nil
+	})
+}
+
+func TestMethodMux(t *testing.T) {
+	var mux MethodMux
+	ctx := context.Background()
+
+	_, _, err := mux.Resolve(ctx, &DID{Method: "example", ID: "1"}, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrMethodNotSupported), "zero value error %v", err)
+
+	mux.Handle("example", staticResolver("example"))
+	mux.HandleFunc("web", staticResolver("web").Resolve)
+	assert(t, []string{"example", "web"}, mux.Methods())
+
+	doc, _, err := mux.Resolve(ctx, &DID{Method: "web", ID: "example.com"}, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "web", doc.ID)
+
+	_, _, err = mux.Resolve(ctx, &DID{Method: "key", ID: "z6Mk"}, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrMethodNotSupported))
+
+	mux.HandleFallback(staticResolver("fallback"))
+	doc, _, err = mux.Resolve(ctx, &DID{Method: "key", ID: "z6Mk"}, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "fallback", doc.ID)
+	doc, _, err = mux.Resolve(ctx, &DID{Method: "example", ID: "1"}, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "example", doc.ID)
+
+	t.Run("panics", func(t *testing.T) {
+		for _, f := range []func(){
+			func() { mux.Handle("example", staticResolver("again")) },
+			func() { mux.Handle("Example", staticResolver("upper")) },
+			func() { mux.Handle("", staticResolver("empty")) },
+			func() { mux.Handle("nil", nil) },
+		} {
+			func() {
+				defer func() {
+					assert(t, true, recover() != nil)
+				}()
+				f()
+			}()
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var mux MethodMux
+		var wg sync.WaitGroup
+		for _, method := range []string{"a", "b", "c", "d"} {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				mux.Handle(method, staticResolver(method))
+			}()
+			go func() {
+				defer wg.Done()
+				mux.Resolve(ctx, &DID{Method: method, ID: "1"}, ResolutionOptions{})
+			}()
+		}
+		wg.Wait()
+		assert(t, []string{"a", "b", "c", "d"}, mux.Methods())
+	})
+}
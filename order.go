@@ -0,0 +1,122 @@
+package did
+
+import "strings"
+
+// Compare returns an integer comparing two DIDs in order of their method
+// first, and their idstrings second. The result is 0 if d == o, -1 if d < o,
+// and +1 if d > o. DIDs which encode to the same string compare equal.
+func (d *DID) Compare(o *DID) int {
+	a, b := d.Key(), o.Key()
+	return compareKeys(&a, &b)
+}
+
+// Compare returns an integer comparing two DID URLs in order of their DID,
+// the segments of their DID Path, their DID Query and their DID Fragment.
+// Absent components order before present ones. The result is 0 if d == o, -1
+// if d < o, and +1 if d > o. DID URLs which encode to the same string compare
+// equal.
+func (d *DIDURL) Compare(o *DIDURL) int {
+	a, b := d.Key(), o.Key()
+	return compareKeys(&a, &b)
+}
+
+func compareKeys(a, b *Key) int {
+	if c := strings.Compare(a.method, b.method); c != 0 {
+		return c
+	}
+	if c := compareSeq(a.id, b.id, a.method != "", b.method != "", ':'); c != 0 {
+		return c
+	}
+	pathA, pathB := strings.TrimPrefix(a.path, "/"), strings.TrimPrefix(b.path, "/")
+	if c := compareSeq(pathA, pathB, a.path != "", b.path != "", '/'); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.query, b.query); c != 0 {
+		return c
+	}
+	return strings.Compare(a.fragment, b.fragment)
+}
+
+// compareSeq compares two sep separated sequences element by element. Absent
+// sequences order before present ones.
+func compareSeq(a, b string, hasA, hasB bool, sep byte) int {
+	for {
+		switch {
+		case !hasA && !hasB:
+			return 0
+		case !hasA:
+			return -1
+		case !hasB:
+			return 1
+		}
+
+		var elemA, elemB string
+		elemA, a, hasA = strings.Cut(a, string(sep))
+		elemB, b, hasB = strings.Cut(b, string(sep))
+		if c := strings.Compare(elemA, elemB); c != 0 {
+			return c
+		}
+	}
+}
+
+// Ordered key encoding bytes. Components consist of characters above these.
+const (
+	orderedEnd  = 0x00 // terminates a component
+	orderedElem = 0x01 // precedes each element of a sequence, or an option
+)
+
+// MarshalOrderedKey returns a binary encoding of the DID of which the
+// byte-wise order matches Compare, for use as a key in ordered stores, such
+// as LevelDB, BoltDB and FoundationDB. The key of a DID is a prefix of the
+// keys of its DID URLs from DIDURL.MarshalOrderedKey, which allows for range
+// scans.
+func (d *DID) MarshalOrderedKey() []byte {
+	k := d.Key()
+	return appendOrderedDID(nil, &k)
+}
+
+// MarshalOrderedKey returns a binary encoding of the DID URL of which the
+// byte-wise order matches Compare, for use as a key in ordered stores. The
+// encoding starts with the key of its DID.
+func (d *DIDURL) MarshalOrderedKey() []byte {
+	k := d.Key()
+	b := appendOrderedDID(nil, &k)
+	if k.path != "" {
+		b = appendOrderedSeq(b, k.path[1:], '/')
+	}
+	b = append(b, orderedEnd)
+	b = appendOrderedOpt(b, k.query)
+	return appendOrderedOpt(b, k.fragment)
+}
+
+func appendOrderedDID(b []byte, k *Key) []byte {
+	b = append(b, k.method...)
+	b = append(b, orderedEnd)
+	if k.method != "" {
+		b = appendOrderedSeq(b, k.id, ':')
+	}
+	return append(b, orderedEnd)
+}
+
+// appendOrderedSeq appends each element of the sep separated sequence s.
+func appendOrderedSeq(b []byte, s string, sep byte) []byte {
+	for {
+		b = append(b, orderedElem)
+		i := strings.IndexByte(s, sep)
+		if i < 0 {
+			return append(b, s...)
+		}
+		b = append(b, s[:i]...)
+		s = s[i+1:]
+	}
+}
+
+// appendOrderedOpt appends an optional component, which is absent when
+// empty, or which starts with its delimiter otherwise.
+func appendOrderedOpt(b []byte, s string) []byte {
+	if s != "" {
+		b = append(b, orderedElem)
+		b = append(b, s[1:]...)
+	}
+	return append(b, orderedEnd)
+}
@@ -0,0 +1,103 @@
+package did
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+var orderSamples = []string{
+	"did:a:1",
+	"did:a:1:2",
+	"did:a:1::2",
+	"did:a:1-2",
+	"did:a:11",
+	"did:a:1/",
+	"did:a:1//",
+	"did:a:1/x",
+	"did:a:1/x/y",
+	"did:a:1/x-y",
+	"did:a:1/x?",
+	"did:a:1/x?q",
+	"did:a:1/x?q#",
+	"did:a:1/x?q#f",
+	"did:a:1/x#f",
+	"did:a:1?",
+	"did:a:1?q=1",
+	"did:a:1?q=1&r",
+	"did:a:1#",
+	"did:a:1#f",
+	"did:a:%41",
+	"did:a:A",
+	"did:ab:1",
+	"did:b:1",
+	"did:0:1",
+}
+
+func TestCompare(t *testing.T) {
+	urls := make([]*DIDURL, len(orderSamples))
+	for i, s := range orderSamples {
+		u, err := ParseURL(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		urls[i] = u
+	}
+
+	for _, a := range urls {
+		for _, b := range urls {
+			c := a.Compare(b)
+			assert(t, -c, b.Compare(a), "%s vs %s", a, b)
+			assert(t, a.String() == b.String(), c == 0, "%s vs %s", a, b)
+			assert(t, c, bytes.Compare(a.MarshalOrderedKey(), b.MarshalOrderedKey()), "%s vs %s", a, b)
+
+			dc := a.DID.Compare(&b.DID)
+			assert(t, dc, bytes.Compare(a.DID.MarshalOrderedKey(), b.DID.MarshalOrderedKey()), "%s vs %s", a, b)
+			if dc != 0 {
+				assert(t, dc, c, "%s vs %s", a, b)
+			}
+		}
+		assert(t, true, bytes.HasPrefix(a.MarshalOrderedKey(), a.DID.MarshalOrderedKey()), "%s", a)
+	}
+
+	slices.SortFunc(urls, (*DIDURL).Compare)
+	var sorted []string
+	for _, u := range urls {
+		sorted = append(sorted, u.String())
+	}
+	assert(t, []string{
+		"did:0:1",
+		"did:a:%41",
+		"did:a:1",
+		"did:a:1#",
+		"did:a:1#f",
+		"did:a:1?",
+		"did:a:1?q=1",
+		"did:a:1?q=1&r",
+		"did:a:1/",
+		"did:a:1//",
+		"did:a:1/x",
+		"did:a:1/x#f",
+		"did:a:1/x?",
+		"did:a:1/x?q",
+		"did:a:1/x?q#",
+		"did:a:1/x?q#f",
+		"did:a:1/x/y",
+		"did:a:1/x-y",
+		"did:a:1::2",
+		"did:a:1:2",
+		"did:a:1-2",
+		"did:a:11",
+		"did:a:A",
+		"did:ab:1",
+		"did:b:1",
+	}, sorted)
+
+	t.Run("assembled equals parsed", func(t *testing.T) {
+		a := &DIDURL{DID: DID{Method: "a", IDStrings: []string{"1", "2"}}, PathSegments: []string{"x y"}}
+		b, err := ParseURL("did:a:1:2/x%20y")
+		assert(t, nil, err)
+		assert(t, 0, a.Compare(b))
+		assert(t, b.MarshalOrderedKey(), a.MarshalOrderedKey())
+	})
+}
@@ -0,0 +1,136 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLimitExceeded is returned when input exceeds one of the Parser limits.
+var ErrLimitExceeded = errors.New("input exceeds limit")
+
+// A Parser parses DIDs and DID URLs with configurable bounds, for use on
+// untrusted input. Limits are checked before validation, such that abusive
+// input fails fast. The zero value has no limits, which is equivalent to the
+// Parse and ParseURL functions.
+type Parser struct {
+	// MaxLength limits the number of bytes in the input, if not zero.
+	MaxLength int
+
+	// MaxSegments limits the number of idstrings in the method-specific-id,
+	// and the number of segments in the DID Path, if not zero.
+	MaxSegments int
+
+	// MaxQueryLength limits the number of bytes in the DID Query, if not
+	// zero.
+	MaxQueryLength int
+
+	// AllErrors continues parsing after the first problem. The error then
+	// joins a *ParseError for each violation, in order of appearance.
+	AllErrors bool
+
+	// Partial returns the components which precede the first problem in
+	// the syntax, together with the error, instead of nil. The component
+	// with the problem, and any components after it, are left empty.
+	Partial bool
+}
+
+// Parse is like the Parse function, with the limits of p applied.
+func (p *Parser) Parse(input string) (*DID, error) {
+	if err := p.checkLimits(input); err != nil {
+		return nil, err
+	}
+	errs := parseErrors{all: p.AllErrors}
+	o, _ := scanDID(input, &errs)
+	if err := errs.err(); err != nil {
+		if p.Partial {
+			return &partialURL(input, o, errs.list[0].(*ParseError).Component).DID, err
+		}
+		return nil, err
+	}
+	a := new(didAlloc)
+	a.d.set(input, o, a.ids[:0])
+	return &a.d, nil
+}
+
+// ParseURL is like the ParseURL function, with the limits of p applied.
+func (p *Parser) ParseURL(input string) (*DIDURL, error) {
+	if err := p.checkLimits(input); err != nil {
+		return nil, err
+	}
+	errs := parseErrors{all: p.AllErrors}
+	o, _ := scanURL(input, &errs)
+	if err := errs.err(); err != nil {
+		if p.Partial {
+			return partialURL(input, o, errs.list[0].(*ParseError).Component), err
+		}
+		return nil, err
+	}
+	a := new(urlAlloc)
+	a.u.set(input, o, a.ids[:0], nil)
+	return &a.u, nil
+}
+
+// partialURL returns the components of input which precede c, with o from
+// scanURL.
+func partialURL(input string, o offsets, c Component) *DIDURL {
+	u := new(DIDURL)
+	switch c {
+	case ComponentScheme, ComponentMethod:
+		return u
+	case ComponentID:
+		u.Method = input[len("did:"):o.methodEnd]
+		return u
+	case ComponentPath:
+		input = input[:o.idEnd]
+		o.pathEnd, o.queryEnd = o.idEnd, o.idEnd
+	case ComponentQuery:
+		input = input[:o.pathEnd]
+		o.queryEnd = o.pathEnd
+	case ComponentFragment:
+		input = input[:o.queryEnd]
+	}
+	u.set(input, o, nil, nil)
+	return u
+}
+
+// checkLimits verifies the bounds of input without any validation.
+func (p *Parser) checkLimits(input string) error {
+	if p.MaxLength != 0 && len(input) > p.MaxLength {
+		return fmt.Errorf("%w: length of %d bytes exceeds maximum of %d", ErrLimitExceeded, len(input), p.MaxLength)
+	}
+
+	didPart, rest := input, ""
+	if i := strings.IndexAny(input, "/?#"); i >= 0 {
+		didPart, rest = input[:i], input[i:]
+	}
+
+	if p.MaxSegments != 0 {
+		// "did:" method ":" idstring *( ":" idstring )
+		if n := strings.Count(didPart, ":") - 1; n > p.MaxSegments {
+			return fmt.Errorf("%w: %d idstrings exceed maximum of %d", ErrLimitExceeded, n, p.MaxSegments)
+		}
+
+		path := rest
+		if i := strings.IndexAny(path, "?#"); i >= 0 {
+			path = path[:i]
+		}
+		if n := strings.Count(path, "/"); n > p.MaxSegments {
+			return fmt.Errorf("%w: %d path segments exceed maximum of %d", ErrLimitExceeded, n, p.MaxSegments)
+		}
+	}
+
+	if p.MaxQueryLength != 0 {
+		if i := strings.IndexByte(rest, '#'); i >= 0 {
+			rest = rest[:i]
+		}
+		if i := strings.IndexByte(rest, '?'); i >= 0 {
+			query := rest[i+1:]
+			if len(query) > p.MaxQueryLength {
+				return fmt.Errorf("%w: query of %d bytes exceeds maximum of %d", ErrLimitExceeded, len(query), p.MaxQueryLength)
+			}
+		}
+	}
+
+	return nil
+}
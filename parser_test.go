@@ -0,0 +1,145 @@
+package did
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParserLimits(t *testing.T) {
+	golden := []struct {
+		parser Parser
+		input  string
+		ok     bool
+	}{
+		{Parser{}, "did:a:" + strings.Repeat("1", 10000), true},
+		{Parser{MaxLength: 9}, "did:a:123", true},
+		{Parser{MaxLength: 8}, "did:a:123", false},
+		{Parser{MaxSegments: 3}, "did:a:1:2:3", true},
+		{Parser{MaxSegments: 2}, "did:a:1:2:3", false},
+		{Parser{MaxSegments: 2}, "did:a:1:2/x/y?a/b/c#d/e/f", true},
+		{Parser{MaxSegments: 2}, "did:a:1/x/y/z", false},
+		{Parser{MaxQueryLength: 3}, "did:a:1?abc#defgh", true},
+		{Parser{MaxQueryLength: 3}, "did:a:1?abcd", false},
+		{Parser{MaxQueryLength: 3}, "did:a:1#?abcd", true},
+	}
+	for _, g := range golden {
+		_, err := g.parser.ParseURL(g.input)
+		if g.ok {
+			assert(t, nil, err, "Input: %s, Parser: %+v", g.input, g.parser)
+		} else {
+			assert(t, true, errors.Is(err, ErrLimitExceeded), "Input: %s, Parser: %+v", g.input, g.parser)
+		}
+	}
+
+	t.Run("checks limits before validation", func(t *testing.T) {
+		p := Parser{MaxLength: 10}
+		_, err := p.Parse("invalid input of some length")
+		assert(t, true, errors.Is(err, ErrLimitExceeded))
+	})
+
+	t.Run("denies DID URLs in Parse", func(t *testing.T) {
+		p := Parser{MaxLength: 100}
+		_, err := p.Parse("did:a:1#f")
+		assert(t, false, err == nil)
+		assert(t, false, errors.Is(err, ErrLimitExceeded))
+	})
+}
+
+func TestParserAllErrors(t *testing.T) {
+	p := Parser{AllErrors: true}
+
+	_, err := p.ParseURL("did:eXample:a!b:/x y%zz?q#f#")
+	var offsets []int
+	var reasons []error
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		perr := err.(*ParseError)
+		offsets = append(offsets, perr.Offset)
+		reasons = append(reasons, perr.Err)
+	}
+	assert(t, []int{5, 13, 16, 18, 20, 27}, offsets)
+	assert(t, []error{
+		ErrInvalidMethodChar,
+		ErrInvalidIDChar,
+		ErrEmptyID,
+		ErrInvalidPathChar,
+		ErrBadPercentEncoding,
+		ErrInvalidFragmentChar,
+	}, reasons)
+	assert(t, true, errors.Is(err, ErrEmptyID))
+
+	t.Run("single error as is", func(t *testing.T) {
+		_, err := p.ParseURL("did:a:1/x y")
+		_, ok := err.(*ParseError)
+		assert(t, true, ok)
+	})
+
+	t.Run("valid input", func(t *testing.T) {
+		u, err := p.ParseURL("did:a:1/x?y#z")
+		assert(t, nil, err)
+		assert(t, "did:a:1/x?y#z", u.String())
+	})
+
+	t.Run("URL denied in order", func(t *testing.T) {
+		_, err := p.Parse("did:a:1/x y")
+		var reasons []error
+		for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+			reasons = append(reasons, err.(*ParseError).Err)
+		}
+		assert(t, []error{ErrURLDenied, ErrInvalidPathChar}, reasons)
+	})
+
+	t.Run("stops without scheme", func(t *testing.T) {
+		_, err := p.ParseURL("urn:a:1/x y")
+		assert(t, true, errors.Is(err, ErrMissingScheme))
+		assert(t, false, errors.Is(err, ErrInvalidPathChar))
+	})
+}
+
+func TestParserPartial(t *testing.T) {
+	golden := []struct {
+		input     string
+		component Component
+		partial   DIDURL
+	}{
+		{"urn:a:1", ComponentScheme, DIDURL{}},
+		{"did:A:1", ComponentMethod, DIDURL{}},
+		{"did:a", ComponentID, DIDURL{DID: DID{Method: "a"}}},
+		{"did:a:1 2", ComponentID, DIDURL{DID: DID{Method: "a"}}},
+		{"did:a:1/x y?q#f", ComponentPath, DIDURL{DID: DID{Method: "a", ID: "1", IDStrings: []string{"1"}}}},
+		{"did:a:1/x/y?q q#f", ComponentQuery, DIDURL{
+			DID:          DID{Method: "a", ID: "1", IDStrings: []string{"1"}},
+			Path:         "x/y",
+			PathSegments: []string{"x", "y"},
+		}},
+		{"did:a:1?q#f#", ComponentFragment, DIDURL{
+			DID:   DID{Method: "a", ID: "1", IDStrings: []string{"1"}},
+			Query: "q",
+		}},
+	}
+	for _, g := range golden {
+		for _, p := range []Parser{{Partial: true}, {Partial: true, AllErrors: true}} {
+			u, err := p.ParseURL(g.input)
+			var perr *ParseError
+			assert(t, true, errors.As(err, &perr), "Input: %q, Parser: %+v", g.input, p)
+			assert(t, g.component, perr.Component, "Input: %q, Parser: %+v", g.input, p)
+			assert(t, g.partial.String(), u.String(), "Input: %q, Parser: %+v", g.input, p)
+			assert(t, g.partial.Method, u.Method, "Input: %q, Parser: %+v", g.input, p)
+			assert(t, g.partial.PathSegments, u.PathSegments, "Input: %q, Parser: %+v", g.input, p)
+		}
+	}
+
+	t.Run("DID URL denied", func(t *testing.T) {
+		p := Parser{Partial: true}
+		d, err := p.Parse("did:a:1?q")
+		assert(t, true, errors.Is(err, ErrURLDenied))
+		assert(t, "did:a:1", d.String())
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		p := Parser{Partial: true, MaxLength: 5}
+		u, err := p.ParseURL("did:a:1")
+		assert(t, true, errors.Is(err, ErrLimitExceeded))
+		assert(t, true, u == nil)
+	})
+}
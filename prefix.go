@@ -0,0 +1,87 @@
+package did
+
+import "strings"
+
+// ParsePrefix parses the longest DID at the start of s, and it returns the
+// remainder of s, which may be empty. Use ParseURLPrefix to include any DID
+// Path, DID Query and DID Fragment. Any error is a *ParseError.
+func ParsePrefix(s string) (d *DID, rest string, err error) {
+	end := didPrefixEnd(s)
+	if end < 0 {
+		_, err := ParseURL(s)
+		return nil, s, err
+	}
+	d, err = Parse(s[:end])
+	if err != nil {
+		return nil, s, err
+	}
+	return d, s[end:], nil
+}
+
+// ParseURLPrefix parses the longest DID URL at the start of s, and it returns
+// the remainder of s, which may be empty. Any error is a *ParseError.
+func ParseURLPrefix(s string) (u *DIDURL, rest string, err error) {
+	end := didPrefixEnd(s)
+	if end < 0 {
+		_, err := ParseURL(s)
+		return nil, s, err
+	}
+	if end < len(s) && s[end] == '/' {
+		end = prefixRunEnd(s, end+1, &pathOrSlash)
+	}
+	if end < len(s) && s[end] == '?' {
+		end = prefixRunEnd(s, end+1, &queryChars)
+	}
+	if end < len(s) && s[end] == '#' {
+		end = prefixRunEnd(s, end+1, &fragmentChars)
+	}
+	u, err = ParseURL(s[:end])
+	if err != nil {
+		return nil, s, err
+	}
+	return u, s[end:], nil
+}
+
+// didPrefixEnd returns the end of the longest DID at the start of s, or -1
+// when s does not start with a DID.
+func didPrefixEnd(s string) int {
+	if !strings.HasPrefix(s, "did:") {
+		return -1
+	}
+
+	i := len("did:")
+	for i < len(s) && methodChars.contains(s[i]) {
+		i++
+	}
+	if i == len("did:") || i >= len(s) || s[i] != ':' {
+		return -1
+	}
+	idStart := i + 1
+	i = prefixRunEnd(s, idStart, idOrColonCharsOf(lookupMethod(s[len("did:"):i])))
+	// trailing colons are not part of the method-specific-id
+	for i > idStart && s[i-1] == ':' {
+		i--
+	}
+	if i == idStart {
+		return -1
+	}
+	return i
+}
+
+// prefixRunEnd returns the offset of the first character from start on which
+// is not in valid, with pct-encoded octets included. Unlike validRunEnd, the
+// run ends at a '%' which is not followed by 2 hex digits.
+func prefixRunEnd(s string, start int, valid *charset) int {
+	i := start
+	for i < len(s) {
+		switch {
+		case valid.contains(s[i]):
+			i++
+		case s[i] == '%' && i+2 < len(s) && hexChars.contains(s[i+1]) && hexChars.contains(s[i+2]):
+			i += 3
+		default:
+			return i
+		}
+	}
+	return i
+}
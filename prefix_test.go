@@ -0,0 +1,62 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePrefix(t *testing.T) {
+	golden := []struct {
+		input, did, rest string
+	}{
+		{"did:a:1", "did:a:1", ""},
+		{"did:a:1 and more", "did:a:1", " and more"},
+		{"did:a:1:2,did:b:3", "did:a:1:2", ",did:b:3"},
+		{"did:a:1::", "did:a:1", "::"},
+		{"did:a:1%2", "did:a:1", "%2"},
+		{"did:a:1%20x/y?z", "did:a:1%20x", "/y?z"},
+		{"did:a:1#f", "did:a:1", "#f"},
+	}
+	for _, g := range golden {
+		d, rest, err := ParsePrefix(g.input)
+		assert(t, nil, err, "Input: %q", g.input)
+		assert(t, g.did, d.String(), "Input: %q", g.input)
+		assert(t, g.rest, rest, "Input: %q", g.input)
+	}
+
+	for _, input := range []string{"", "did:", "did:a", "did:a:", "did:a::", "did:A:1", "did:a: 1", "x did:a:1"} {
+		d, rest, err := ParsePrefix(input)
+		assert(t, true, d == nil, "Input: %q", input)
+		assert(t, input, rest, "Input: %q", input)
+		var perr *ParseError
+		assert(t, true, errors.As(err, &perr), "Input: %q, error: %v", input, err)
+	}
+}
+
+func TestParseURLPrefix(t *testing.T) {
+	golden := []struct {
+		input, url, rest string
+	}{
+		{"did:a:1", "did:a:1", ""},
+		{"did:a:1/x/y?q=1#f rest", "did:a:1/x/y?q=1#f", " rest"},
+		{"did:a:1/x y", "did:a:1/x", " y"},
+		{"did:a:1/x%zz", "did:a:1/x", "%zz"},
+		{"did:a:1?q#f#g", "did:a:1?q#f", "#g"},
+		{"did:a:1#", "did:a:1#", ""},
+		{"<did:a:1/x>", "", ""},
+		{"did:a:1:/x", "did:a:1", ":/x"},
+		{"did:a:1/x\")", "did:a:1/x", "\")"},
+	}
+	for _, g := range golden {
+		u, rest, err := ParseURLPrefix(g.input)
+		if g.url == "" {
+			assert(t, true, err != nil, "Input: %q", g.input)
+			assert(t, true, u == nil, "Input: %q", g.input)
+			assert(t, g.input, rest, "Input: %q", g.input)
+			continue
+		}
+		assert(t, nil, err, "Input: %q", g.input)
+		assert(t, g.url, u.String(), "Input: %q", g.input)
+		assert(t, g.rest, rest, "Input: %q", g.input)
+	}
+}
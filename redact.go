@@ -0,0 +1,33 @@
+package did
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// Redacted returns a pseudonym of the DID, with the method-specific-id
+// replaced by a token derived from key with HMAC-SHA256. Equal DIDs get equal
+// tokens with the same key, such that pseudonyms can be correlated without
+// the original identifier. The method is preserved.
+func (d *DID) Redacted(key []byte) *DID {
+	k := d.Key()
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(k.method))
+	mac.Write([]byte{':'})
+	mac.Write([]byte(k.id))
+	// 128 bits are plenty to avoid collisions
+	token := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+	return &DID{Method: d.Method, ID: token, IDStrings: []string{token}}
+}
+
+// Redacted returns a pseudonym of the DID URL, with the method-specific-id
+// replaced as described by DID.Redacted. The fragment is preserved. The path
+// and query are removed, as they may identify the subject too.
+func (d *DIDURL) Redacted(key []byte) *DIDURL {
+	return &DIDURL{
+		DID:           *d.DID.Redacted(key),
+		Fragment:      d.Fragment,
+		ForceFragment: d.ForceFragment,
+	}
+}
@@ -0,0 +1,27 @@
+package did
+
+import "testing"
+
+func TestRedacted(t *testing.T) {
+	key := []byte("secret")
+	u, err := ParseURL("did:example:123:456/path?q=1#keys-1")
+	assert(t, nil, err)
+
+	r := u.Redacted(key)
+	assert(t, "example", r.Method)
+	assert(t, "keys-1", r.Fragment)
+	assert(t, "", r.Path)
+	assert(t, "", r.Query)
+	assert(t, 22, len(r.ID))
+	if _, err := ParseURL(r.String()); err != nil {
+		t.Errorf("redacted %q got parse error: %s", r, err)
+	}
+
+	same := (&DID{Method: "example", IDStrings: []string{"123", "456"}}).Redacted(key)
+	assert(t, r.ID, same.ID)
+
+	other := u.Base().Redacted([]byte("other secret"))
+	assert(t, false, other.ID == r.ID)
+	otherMethod := (&DID{Method: "example2", ID: "123:456"}).Redacted(key)
+	assert(t, false, otherMethod.ID == r.ID)
+}
@@ -0,0 +1,240 @@
+package did
+
+import (
+	"errors"
+	"strings"
+)
+
+// A Reference is either an absolute DID URL or a relative reference. DID
+// Documents commonly use both forms to refer to verification methods.
+// https://www.w3.org/TR/did-core/#relative-did-urls
+type Reference struct {
+	// URL is the DID URL of an absolute reference. It is nil for relative
+	// references.
+	URL *DIDURL
+
+	// Relative holds the relative reference, like "#keys-1" or
+	// "/path?query", when URL is nil.
+	Relative string
+}
+
+// ParseReference parses the input string into either an absolute or a
+// relative reference.
+func ParseReference(input string) (Reference, error) {
+	if strings.HasPrefix(input, "did:") {
+		u, err := ParseURL(input)
+		if err != nil {
+			return Reference{}, err
+		}
+		return Reference{URL: u}, nil
+	}
+
+	return ParseRelative(input)
+}
+
+// ParseRelative parses the input string into a relative reference, which
+// consists of an optional path, query and fragment only. Absolute DID URLs
+// are denied; see ParseReference to accept both.
+func ParseRelative(input string) (Reference, error) {
+	if strings.HasPrefix(input, "did:") {
+		return Reference{}, errors.New("absolute DID URL denied")
+	}
+	if _, _, _, err := splitRelative(input); err != nil {
+		return Reference{}, err
+	}
+	return Reference{Relative: input}, nil
+}
+
+// IsRelative returns whether the reference needs a base DID URL to resolve.
+func (r Reference) IsRelative() bool {
+	return r.URL == nil
+}
+
+// Resolve returns the DID URL which the reference identifies with base as its
+// base.
+func (r Reference) Resolve(base *DIDURL) (*DIDURL, error) {
+	if r.URL != nil {
+		return r.URL.Clone(), nil
+	}
+	return base.ResolveReference(r.Relative)
+}
+
+// String returns the reference in its textual form.
+func (r Reference) String() string {
+	if r.URL != nil {
+		return r.URL.String()
+	}
+	return r.Relative
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, which makes
+// a Reference encode as a JSON string.
+func (r Reference) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, which
+// makes a Reference decode from a JSON string.
+func (r *Reference) UnmarshalText(text []byte) error {
+	parsed, err := ParseReference(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// ResolveReference resolves a DID URL reference, absolute or relative, with
+// the DID URL as its base, according to RFC 3986, section 5.2. DID Documents
+// commonly contain relative references such as "#keys-1".
+// https://www.w3.org/TR/did-core/#relative-did-urls
+func (d *DIDURL) ResolveReference(ref string) (*DIDURL, error) {
+	if strings.HasPrefix(ref, "did:") {
+		r, err := ParseURL(ref)
+		if err != nil {
+			return nil, err
+		}
+		if r.Path != "" {
+			return r.WithPath(removeDotSegments("/" + r.Path)[1:]), nil
+		}
+		return r, nil
+	}
+
+	path, query, fragment, err := splitRelative(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	t := d.Key()
+	if t.method == "" {
+		return nil, errors.New("reference base is not a DID")
+	}
+	switch {
+	case path == "":
+		if query != "" {
+			t.query = query
+		}
+	case path[0] == '/':
+		t.path = removeDotSegments(path)
+		t.query = query
+	default:
+		// merge with the base path, whereby a DID without path acts
+		// like an authority with an empty path
+		merged := "/" + path
+		if i := strings.LastIndexByte(t.path, '/'); i >= 0 {
+			merged = t.path[:i+1] + path
+		}
+		t.path = removeDotSegments(merged)
+		t.query = query
+	}
+	t.fragment = fragment
+
+	return ParseURL(t.string())
+}
+
+// RelativeTo returns the shortest reference which resolves to the DID URL
+// with base as its base. The absolute DID URL is returned when both have
+// different DIDs, or when no relative reference can express the result.
+// https://www.w3.org/TR/did-core/#relative-did-urls
+func (d *DIDURL) RelativeTo(base *DIDURL) string {
+	u, b := d.Key(), base.Key()
+	if u.method == "" || u.method != b.method || u.id != b.id {
+		return d.String()
+	}
+
+	if u.path == b.path {
+		if u.query == b.query {
+			return u.fragment
+		}
+		if u.query != "" {
+			return u.query + u.fragment
+		}
+	}
+	if u.path == "" {
+		// an empty path always inherits the base path
+		return d.String()
+	}
+
+	ref := u.path
+	dir := b.path[:strings.LastIndexByte(b.path, '/')+1]
+	if dir != "" && strings.HasPrefix(u.path, dir) && len(u.path) > len(dir) {
+		rel := u.path[len(dir):]
+		if i := strings.IndexAny(rel, ":/"); i >= 0 && rel[i] == ':' {
+			// a colon in the first segment would be mistaken for a scheme
+			rel = "./" + rel
+		} else if i == 0 {
+			// an empty first segment would be mistaken for an absolute path
+			rel = "./" + rel
+		}
+		if len(rel) < len(ref) {
+			ref = rel
+		}
+	}
+	return ref + u.query + u.fragment
+}
+
+// splitRelative breaks a relative reference into its path, its query and
+// its fragment. The query and fragment include their leading delimiter,
+// such that an empty string means absent.
+func splitRelative(ref string) (path, query, fragment string, err error) {
+	path = ref
+	if i := strings.IndexByte(path, '#'); i >= 0 {
+		path, fragment = path[:i], path[i:]
+		err := scan(ref, i+1, ComponentFragment, ErrInvalidFragmentChar, &fragmentChars, "")
+		if err != nil {
+			return "", "", "", err
+		}
+	}
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path, query = path[:i], path[i:]
+		err := scan(ref, i+1, ComponentQuery, ErrInvalidQueryChar, &queryChars, "#")
+		if err != nil {
+			return "", "", "", err
+		}
+	}
+
+	// a colon in the first segment would be mistaken for a scheme
+	firstSegment := path
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		firstSegment = path[:i]
+	}
+	if i := strings.IndexByte(firstSegment, ':'); i >= 0 {
+		return "", "", "", newParseError(ref, 0, ComponentScheme, ErrMissingScheme)
+	}
+
+	err = scan(ref, 0, ComponentPath, ErrInvalidPathChar, &pathOrSlash, "?#")
+	if err != nil {
+		return "", "", "", err
+	}
+	return path, query, fragment, nil
+}
+
+// removeDotSegments interprets the "." and ".." segments in path, according
+// to RFC 3986, section 5.2.4.
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	var out []string
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		last := i == len(segments)-1
+		switch s {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, "/")
+}
@@ -0,0 +1,182 @@
+package did
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResolveReference(t *testing.T) {
+	base, err := ParseURL("did:example:123/a/b/c?q#f")
+	assert(t, nil, err)
+
+	golden := []struct{ ref, want string }{
+		{"#keys-1", "did:example:123/a/b/c?q#keys-1"},
+		{"?x=1", "did:example:123/a/b/c?x=1"},
+		{"?", "did:example:123/a/b/c?"},
+		{"", "did:example:123/a/b/c?q"},
+		{"/svc", "did:example:123/svc"},
+		{"/svc?x#y", "did:example:123/svc?x#y"},
+		{"d", "did:example:123/a/b/d"},
+		{"./d/", "did:example:123/a/b/d/"},
+		{"../d", "did:example:123/a/d"},
+		{"../../../../d", "did:example:123/d"},
+		{"/a/./b/../c", "did:example:123/a/c"},
+		{".", "did:example:123/a/b/"},
+		{"did:other:456#k", "did:other:456#k"},
+		{"did:other:456/x/../y", "did:other:456/y"},
+	}
+	for _, g := range golden {
+		got, err := base.ResolveReference(g.ref)
+		if err != nil {
+			t.Errorf("%q got error: %s", g.ref, err)
+			continue
+		}
+		if s := got.String(); s != g.want {
+			t.Errorf("%q got %q, want %q", g.ref, s, g.want)
+		}
+	}
+
+	t.Run("merges against a DID without path", func(t *testing.T) {
+		d, err := ParseURL("did:example:123")
+		assert(t, nil, err)
+		got, err := d.ResolveReference("svc/1")
+		assert(t, nil, err)
+		assert(t, "did:example:123/svc/1", got.String())
+	})
+
+	t.Run("returns error on invalid references", func(t *testing.T) {
+		for _, ref := range []string{"#%A", "?a^b", "/a b", "http://example.com/", "a:b", "did:"} {
+			_, err := base.ResolveReference(ref)
+			assert(t, false, err == nil, "Input: %s", ref)
+		}
+	})
+
+	t.Run("returns error if base is not a DID", func(t *testing.T) {
+		_, err := new(DIDURL).ResolveReference("#keys-1")
+		assert(t, false, err == nil)
+	})
+}
+
+func TestRelativeTo(t *testing.T) {
+	golden := []struct{ target, base, want string }{
+		{"did:example:123#keys-1", "did:example:123", "#keys-1"},
+		{"did:example:123#keys-1", "did:example:123#keys-2", "#keys-1"},
+		{"did:example:123", "did:example:123#keys-2", ""},
+		{"did:example:123?x=1", "did:example:123", "?x=1"},
+		{"did:example:123?x=1#k", "did:example:123?y=2", "?x=1#k"},
+		{"did:example:123/svc?x=1", "did:example:123", "/svc?x=1"},
+		{"did:example:123/a/b", "did:example:123/a/c", "b"},
+		{"did:example:123/a/b:c", "did:example:123/a/c", "./b:c"},
+		{"did:example:123/a//b", "did:example:123/a/c", ".//b"},
+		{"did:example:123/a/b", "did:example:123/a/b?q", "b"},
+		{"did:example:123/x", "did:example:123/a/b", "/x"},
+		{"did:example:123", "did:example:123/a", "did:example:123"},
+		{"did:example:456#k", "did:example:123", "did:example:456#k"},
+	}
+	for _, g := range golden {
+		target, err := ParseURL(g.target)
+		assert(t, nil, err)
+		base, err := ParseURL(g.base)
+		assert(t, nil, err)
+
+		got := target.RelativeTo(base)
+		if got != g.want {
+			t.Errorf("%q relative to %q got %q, want %q", g.target, g.base, got, g.want)
+			continue
+		}
+
+		resolved, err := base.ResolveReference(got)
+		if err != nil {
+			t.Errorf("%q relative to %q got unresolvable %q: %s", g.target, g.base, got, err)
+			continue
+		}
+		if s := resolved.String(); s != g.target {
+			t.Errorf("%q relative to %q resolves to %q", g.target, g.base, s)
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	t.Run("parses absolute references", func(t *testing.T) {
+		r, err := ParseReference("did:example:123#keys-1")
+		assert(t, nil, err)
+		assert(t, false, r.IsRelative())
+		assert(t, "keys-1", r.URL.Fragment)
+		assert(t, "did:example:123#keys-1", r.String())
+	})
+
+	t.Run("parses relative references", func(t *testing.T) {
+		for _, s := range []string{"#keys-1", "/path?query", "?service=agent", "path", ""} {
+			r, err := ParseReference(s)
+			assert(t, nil, err, "Input: %s", s)
+			assert(t, true, r.IsRelative(), "Input: %s", s)
+			assert(t, s, r.String(), "Input: %s", s)
+		}
+	})
+
+	t.Run("returns error on invalid references", func(t *testing.T) {
+		for _, s := range []string{"did:example", "#%", "mailto:a@example.com", "/a b"} {
+			_, err := ParseReference(s)
+			assert(t, false, err == nil, "Input: %s", s)
+		}
+	})
+
+	t.Run("resolves against a base", func(t *testing.T) {
+		base, err := ParseURL("did:example:123")
+		assert(t, nil, err)
+
+		r, err := ParseReference("#keys-1")
+		assert(t, nil, err)
+		u, err := r.Resolve(base)
+		assert(t, nil, err)
+		assert(t, "did:example:123#keys-1", u.String())
+
+		r, err = ParseReference("did:example:456#keys-1")
+		assert(t, nil, err)
+		u, err = r.Resolve(base)
+		assert(t, nil, err)
+		assert(t, "did:example:456#keys-1", u.String())
+	})
+
+	t.Run("marshals JSON", func(t *testing.T) {
+		var refs []Reference
+		err := json.Unmarshal([]byte(`["did:example:123#keys-1", "#keys-2"]`), &refs)
+		assert(t, nil, err)
+		assert(t, 2, len(refs))
+		assert(t, false, refs[0].IsRelative())
+		assert(t, true, refs[1].IsRelative())
+
+		out, err := json.Marshal(refs)
+		assert(t, nil, err)
+		assert(t, `["did:example:123#keys-1","#keys-2"]`, string(out))
+	})
+
+	t.Run("denies invalid JSON", func(t *testing.T) {
+		var r Reference
+		err := json.Unmarshal([]byte(`"did:example"`), &r)
+		assert(t, false, err == nil)
+	})
+}
+
+func TestParseRelative(t *testing.T) {
+	t.Run("succeeds on relative references", func(t *testing.T) {
+		for _, s := range []string{"#keys-1", "#", "/path?q", "/a/b/", "a/b", "?service=agent&relativeRef=%2Fx", "./a:b", ""} {
+			r, err := ParseRelative(s)
+			assert(t, nil, err, "Input: %s", s)
+			assert(t, true, r.IsRelative(), "Input: %s", s)
+			assert(t, s, r.Relative, "Input: %s", s)
+		}
+	})
+
+	t.Run("returns error on absolute references", func(t *testing.T) {
+		_, err := ParseRelative("did:example:123#keys-1")
+		assert(t, false, err == nil)
+	})
+
+	t.Run("returns error on invalid references", func(t *testing.T) {
+		for _, s := range []string{"#%A", "#a#b", "?a^b", "/a%2", "a:b", "https://example.com/"} {
+			_, err := ParseRelative(s)
+			assert(t, false, err == nil, "Input: %s", s)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package did
+
+import "regexp"
+
+// Regular expressions for the DID grammar, equivalent to Parse and ParseURL.
+// The patterns are anchored, and they avoid any syntax beyond the common
+// subset of RE2, PCRE and ECMAScript, such that they can be used in JSON
+// Schema pattern fields and alike.
+const (
+	// Pattern matches a DID.
+	Pattern = `^did:` + methodNamePattern + `:` + methodSpecificIDPattern + `$`
+
+	// URLPattern matches a DID URL, which includes any DID.
+	URLPattern = `^did:` + methodNamePattern + `:` + methodSpecificIDPattern +
+		pathAbemptyPattern + `(?:\?` + queryPattern + `)?(?:#` + fragmentPattern + `)?$`
+)
+
+const (
+	pctEncodedPattern       = `%[0-9A-Fa-f]{2}`
+	methodNamePattern       = `[a-z0-9]+`
+	idcharPattern           = `(?:[A-Za-z0-9._-]|` + pctEncodedPattern + `)`
+	methodSpecificIDPattern = `(?:` + idcharPattern + `*:)*` + idcharPattern + `+`
+	pcharPattern            = `(?:[A-Za-z0-9._~!$&'()*+,;=:@-]|` + pctEncodedPattern + `)`
+	pathAbemptyPattern      = `(?:/` + pcharPattern + `*)*`
+	queryPattern            = `(?:` + pcharPattern + `|[/?])*`
+	fragmentPattern         = `(?:` + pcharPattern + `|[/?])*`
+)
+
+// Compiled forms of Pattern and URLPattern.
+var (
+	Regexp    = regexp.MustCompile(Pattern)
+	URLRegexp = regexp.MustCompile(URLPattern)
+)
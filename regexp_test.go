@@ -0,0 +1,54 @@
+package did
+
+import "testing"
+
+var regexpSamples = []string{
+	"did:a:1",
+	"did:example:q7ckgxeq1lxmra0r",
+	"did:example:123:456",
+	"did:example:123::456",
+	"did:example:%20",
+	"did:web:example.com%3A8443:user:alice",
+	"did:a:1/",
+	"did:a:1/a/b/",
+	"did:a:1//a",
+	"did:a:1/a%20b?q=1&r#frag/?",
+	"did:a:1?",
+	"did:a:1#",
+	"did:a:1?#",
+	"",
+	"did:",
+	"did:a",
+	"did:a:",
+	"did::1",
+	"did:A:1",
+	"did:a-b:1",
+	"did:a:1:",
+	"did:a:1&1",
+	"did:a:%2",
+	"did:a:%zz",
+	"did:a:1/%",
+	"did:a:1/a^b",
+	"did:a:1?%A",
+	"did:a:1#a#b",
+	"did:a:1#a b",
+	"DID:a:1",
+	" did:a:1",
+	"did:a:1 ",
+	"did:a:1\n",
+	"a:12345",
+}
+
+func TestRegexp(t *testing.T) {
+	for _, s := range regexpSamples {
+		_, err := Parse(s)
+		if got, want := Regexp.MatchString(s), err == nil; got != want {
+			t.Errorf("Regexp match %q got %t, want %t (parse error %v)", s, got, want, err)
+		}
+
+		_, err = ParseURL(s)
+		if got, want := URLRegexp.MatchString(s), err == nil; got != want {
+			t.Errorf("URLRegexp match %q got %t, want %t (parse error %v)", s, got, want, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package did
+
+import (
+	"slices"
+	"strings"
+)
+
+//go:generate go run ./internal/genmethods -registry ../did-spec-registries -o methods_table.go
+
+// A RegisteredMethod describes an entry of the DID Specification Registries.
+// https://www.w3.org/TR/did-spec-registries/#did-methods
+type RegisteredMethod struct {
+	// Name is the method name, without the "did:" prefix.
+	Name string
+
+	// Status is the registration status, e.g., "registered" or
+	// "deprecated".
+	Status string
+
+	// Specification is the URL of the method specification.
+	Specification string
+
+	// Network classifies the verifiable data registry.
+	Network Network
+}
+
+// A Network classifies the verifiable data registry of a DID method.
+type Network string
+
+// Network classes, as derived from the free-form verifiable data registry
+// of each registration.
+const (
+	// NetworkLedger is a blockchain or other distributed ledger.
+	NetworkLedger Network = "ledger"
+	// NetworkWeb is a web server, including DNS-bound variants.
+	NetworkWeb Network = "web"
+	// NetworkDNS is the Domain Name System.
+	NetworkDNS Network = "dns"
+	// NetworkIPFS is the InterPlanetary File System.
+	NetworkIPFS Network = "ipfs"
+	// NetworkNone means the DID is self-contained, e.g., did:key.
+	NetworkNone Network = "none"
+	// NetworkOther is any registry not covered by the other classes.
+	NetworkOther Network = "other"
+)
+
+// IsRegisteredMethod returns whether m is a method name in the DID
+// Specification Registries, as of the generation of this package. Note that
+// the registries are unrelated to RegisterMethod.
+func IsRegisteredMethod(m string) bool {
+	_, ok := MethodInfo(m)
+	return ok
+}
+
+// MethodInfo returns the registration of method name m, if any.
+func MethodInfo(m string) (RegisteredMethod, bool) {
+	i, ok := slices.BinarySearchFunc(registeredMethods[:], m, func(e RegisteredMethod, name string) int {
+		return strings.Compare(e.Name, name)
+	})
+	if !ok {
+		return RegisteredMethod{}, false
+	}
+	return registeredMethods[i], true
+}
+
+// RegisteredMethods returns all registrations in alphabetical order.
+func RegisteredMethods() []RegisteredMethod {
+	return slices.Clone(registeredMethods[:])
+}
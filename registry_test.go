@@ -0,0 +1,33 @@
+package did
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMethodInfo(t *testing.T) {
+	info, ok := MethodInfo("web")
+	assert(t, true, ok, "web registered")
+	assert(t, "web", info.Name)
+	assert(t, NetworkWeb, info.Network)
+	assert(t, true, strings.HasPrefix(info.Specification, "https://"), "specification %q", info.Specification)
+
+	assert(t, true, IsRegisteredMethod("key"), "key registered")
+	assert(t, false, IsRegisteredMethod("example"), "example registered")
+	assert(t, false, IsRegisteredMethod(""), "empty registered")
+
+	all := RegisteredMethods()
+	assert(t, true, slices.IsSortedFunc(all, func(a, b RegisteredMethod) int {
+		return strings.Compare(a.Name, b.Name)
+	}), "registrations not in order")
+	for i, m := range all {
+		assert(t, true, validMethod(m.Name), "invalid method name %q", m.Name)
+		if i > 0 {
+			assert(t, true, all[i-1].Name != m.Name, "duplicate method name %q", m.Name)
+		}
+	}
+
+	all[0].Name = "modified"
+	assert(t, false, IsRegisteredMethod("modified"), "table exposed")
+}
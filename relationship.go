@@ -0,0 +1,125 @@
+package did
+
+import (
+	"fmt"
+)
+
+// VerificationMethodByRef returns the verification method which ref, either
+// absolute or relative to the document id, identifies. Both the
+// verificationMethod property and the methods embedded in relationships are
+// searched. The result is nil when absent.
+func (doc *Document) VerificationMethodByRef(ref string) *VerificationMethod {
+	base, err := ParseURL(doc.ID)
+	if err != nil {
+		return nil
+	}
+	target, err := base.ResolveReference(ref)
+	if err != nil {
+		return nil
+	}
+	k := target.Key()
+
+	match := func(vm *VerificationMethod) bool {
+		u, err := base.ResolveReference(vm.ID)
+		return err == nil && u.Key() == k
+	}
+	for i := range doc.VerificationMethod {
+		if match(&doc.VerificationMethod[i]) {
+			return &doc.VerificationMethod[i]
+		}
+	}
+	for _, list := range [...][]Relationship{doc.Authentication, doc.AssertionMethod, doc.KeyAgreement, doc.CapabilityInvocation, doc.CapabilityDelegation} {
+		for _, r := range list {
+			if r.Embedded != nil && match(r.Embedded) {
+				return r.Embedded
+			}
+		}
+	}
+	return nil
+}
+
+// AuthenticationMethods returns the verification methods of the
+// authentication relationship, with references resolved.
+func (doc *Document) AuthenticationMethods() ([]VerificationMethod, error) {
+	return doc.relationshipMethods("authentication", doc.Authentication)
+}
+
+// AssertionMethods returns the verification methods of the assertionMethod
+// relationship, with references resolved.
+func (doc *Document) AssertionMethods() ([]VerificationMethod, error) {
+	return doc.relationshipMethods("assertionMethod", doc.AssertionMethod)
+}
+
+// KeyAgreementMethods returns the verification methods of the keyAgreement
+// relationship, with references resolved.
+func (doc *Document) KeyAgreementMethods() ([]VerificationMethod, error) {
+	return doc.relationshipMethods("keyAgreement", doc.KeyAgreement)
+}
+
+// CapabilityInvocationMethods returns the verification methods of the
+// capabilityInvocation relationship, with references resolved.
+func (doc *Document) CapabilityInvocationMethods() ([]VerificationMethod, error) {
+	return doc.relationshipMethods("capabilityInvocation", doc.CapabilityInvocation)
+}
+
+// CapabilityDelegationMethods returns the verification methods of the
+// capabilityDelegation relationship, with references resolved.
+func (doc *Document) CapabilityDelegationMethods() ([]VerificationMethod, error) {
+	return doc.relationshipMethods("capabilityDelegation", doc.CapabilityDelegation)
+}
+
+// relationshipMethods resolves the entries of a relationship in order. The
+// references must identify a verification method of the document. Those of
+// other documents need resolution of their DID first.
+func (doc *Document) relationshipMethods(name string, list []Relationship) ([]VerificationMethod, error) {
+	methods := make([]VerificationMethod, 0, len(list))
+	for i, r := range list {
+		if r.Embedded != nil {
+			methods = append(methods, *r.Embedded)
+			continue
+		}
+		vm := doc.VerificationMethodByRef(r.Reference)
+		if vm == nil {
+			return nil, fmt.Errorf("did: document %q: %s[%d]: no verification method %q in document", doc.ID, name, i, r.Reference)
+		}
+		methods = append(methods, *vm)
+	}
+	return methods, nil
+}
+
+// Dereference returns the part of the document which ref identifies. The
+// reference is either a fragment, like "#key-1", or a DID URL, possibly
+// relative to the document id. The primary resource, i.e., the DID URL
+// without fragment, must be the DID of the document. The result is a
+// *VerificationMethod or a *Service for a fragment, and the document itself
+// without fragment. Fragments without match get an error which wraps
+// ErrNotFound.
+// https://www.w3.org/TR/did-core/#fragment
+func (doc *Document) Dereference(ref string) (any, error) {
+	base, err := Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("did: document id %q: %w", doc.ID, err)
+	}
+	target, err := base.URL().ResolveReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("did: dereference %q: %w", ref, err)
+	}
+	if target.WithoutFragment().Key() != base.Key() {
+		return nil, fmt.Errorf("did: dereference %s: primary resource is not document %s", target, doc.ID)
+	}
+	if target.Fragment == "" {
+		return doc, nil
+	}
+
+	if vm := doc.VerificationMethodByRef(ref); vm != nil {
+		return vm, nil
+	}
+	k := target.Key()
+	for i := range doc.Service {
+		u, err := base.URL().ResolveReference(doc.Service[i].ID)
+		if err == nil && u.Key() == k {
+			return &doc.Service[i], nil
+		}
+	}
+	return nil, fmt.Errorf("did: dereference %s: %w", target, ErrNotFound)
+}
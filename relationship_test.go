@@ -0,0 +1,70 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRelationshipMethods(t *testing.T) {
+	const sample = `{"id":"did:example:123","verificationMethod":[{"id":"#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6Mkf5rGMoatrSj1f4CyvuHBeXJELe9RPdzo2PKGNCKVtZxP"},{"id":"did:example:123#key-2","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}],"authentication":["did:example:123#key-1","#key-2",{"id":"#key-3","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc"}],"assertionMethod":["#key-3"],"keyAgreement":["did:example:456#key-1"]}`
+	var doc Document
+	assert(t, nil, json.Unmarshal([]byte(sample), &doc))
+
+	methods, err := doc.AuthenticationMethods()
+	assert(t, nil, err)
+	assert(t, 3, len(methods))
+	assert(t, "#key-1", methods[0].ID)
+	assert(t, "did:example:123#key-2", methods[1].ID)
+	assert(t, "#key-3", methods[2].ID)
+
+	// reference to an embedded method
+	methods, err = doc.AssertionMethods()
+	assert(t, nil, err)
+	assert(t, 1, len(methods))
+	assert(t, "#key-3", methods[0].ID)
+
+	_, err = doc.KeyAgreementMethods()
+	assert(t, true, err != nil && strings.Contains(err.Error(), `keyAgreement[0]: no verification method "did:example:456#key-1"`), "error %v", err)
+
+	methods, err = doc.CapabilityInvocationMethods()
+	assert(t, nil, err)
+	assert(t, 0, len(methods))
+
+	assert(t, "did:example:123#key-2", doc.VerificationMethodByRef("#key-2").ID)
+	assert(t, "#key-1", doc.VerificationMethodByRef("did:example:123#key-1").ID)
+	assert(t, (*VerificationMethod)(nil), doc.VerificationMethodByRef("#key-9"))
+}
+
+func TestDocumentDereference(t *testing.T) {
+	const sample = `{"id":"did:example:123","verificationMethod":[{"id":"#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6Mkf5rGMoatrSj1f4CyvuHBeXJELe9RPdzo2PKGNCKVtZxP"}],"keyAgreement":[{"id":"did:example:123#key-2","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc"}],"service":[{"id":"did:example:123#linked","type":"LinkedDomains","serviceEndpoint":"https://example.com/"}]}`
+	var doc Document
+	assert(t, nil, json.Unmarshal([]byte(sample), &doc))
+
+	for _, ref := range []string{"#key-1", "did:example:123#key-1"} {
+		got, err := doc.Dereference(ref)
+		assert(t, nil, err, ref)
+		vm, ok := got.(*VerificationMethod)
+		assert(t, true, ok, "%s got %T", ref, got)
+		if ok {
+			assert(t, "#key-1", vm.ID)
+		}
+	}
+	got, err := doc.Dereference("#key-2")
+	assert(t, nil, err)
+	assert(t, doc.KeyAgreement[0].Embedded, got)
+	got, err = doc.Dereference("#linked")
+	assert(t, nil, err)
+	assert(t, &doc.Service[0], got)
+	got, err = doc.Dereference("did:example:123")
+	assert(t, nil, err)
+	assert(t, &doc, got)
+
+	_, err = doc.Dereference("#key-9")
+	assert(t, true, errors.Is(err, ErrNotFound), "error %v", err)
+	for _, ref := range []string{"did:example:456#key-1", "/path#key-1", "?versionId=1#key-1"} {
+		_, err = doc.Dereference(ref)
+		assert(t, true, err != nil && !errors.Is(err, ErrNotFound), "%s got error %v", ref, err)
+	}
+}
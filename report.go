@@ -0,0 +1,81 @@
+package did
+
+import "errors"
+
+// ValidationReport is a machine-readable summary of Inspect, e.g., for use
+// in problem responses of an API.
+type ValidationReport struct {
+	Valid    bool    `json:"valid"`
+	Errors   []Issue `json:"errors,omitempty"`
+	Warnings []Issue `json:"warnings,omitempty"`
+}
+
+// Issue is the machine-readable form of a ParseError.
+type Issue struct {
+	// Code identifies the reason with a stable name, like
+	// "invalid-method-char" for ErrInvalidMethodChar.
+	Code string `json:"code"`
+
+	// Message describes the reason in plain text.
+	Message string `json:"message"`
+
+	// Component is the name of the syntax component, like "path".
+	Component string `json:"component"`
+
+	// Position is the offset in the input, in bytes.
+	Position int `json:"position"`
+}
+
+// issueCodes has the Code for each reason.
+var issueCodes = map[error]string{
+	ErrMissingScheme:       "missing-scheme",
+	ErrEmptyMethod:         "empty-method",
+	ErrInvalidMethodChar:   "invalid-method-char",
+	ErrEmptyID:             "empty-id",
+	ErrInvalidIDChar:       "invalid-id-char",
+	ErrBadPercentEncoding:  "bad-percent-encoding",
+	ErrInvalidPathChar:     "invalid-path-char",
+	ErrInvalidQueryChar:    "invalid-query-char",
+	ErrInvalidFragmentChar: "invalid-fragment-char",
+	ErrURLDenied:           "url-denied",
+	ErrIDLength:            "id-length",
+	ErrChecksum:            "checksum",
+	ErrLimitExceeded:       "limit-exceeded",
+	WarnLowercaseHex:       "lowercase-hex",
+	WarnEmptySegment:       "empty-segment",
+	WarnLongID:             "long-id",
+	WarnUnknownMethod:      "unknown-method",
+}
+
+// NewIssue returns the machine-readable form of e. Reasons which wrap one of
+// the errors with a code, like those of a checksum, get that code.
+func NewIssue(e *ParseError) Issue {
+	code, ok := issueCodes[e.Err]
+	if !ok {
+		code = "invalid"
+		for err, c := range issueCodes {
+			if errors.Is(e.Err, err) {
+				code = c
+				break
+			}
+		}
+	}
+	return Issue{
+		Code:      code,
+		Message:   e.Err.Error(),
+		Component: e.Component.String(),
+		Position:  e.Offset,
+	}
+}
+
+// Report returns the machine-readable summary of insp.
+func (insp *Inspection) Report() *ValidationReport {
+	r := ValidationReport{Valid: len(insp.Errors) == 0}
+	for _, e := range insp.Errors {
+		r.Errors = append(r.Errors, NewIssue(e))
+	}
+	for _, e := range insp.Warnings {
+		r.Warnings = append(r.Warnings, NewIssue(e))
+	}
+	return &r
+}
@@ -0,0 +1,39 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestValidationReport(t *testing.T) {
+	r := Inspect("did:Example:1/a//b", InspectOptions{}).Report()
+	bytes, err := json.Marshal(r)
+	assert(t, nil, err)
+	assert(t, `{"valid":false,"errors":[{"code":"invalid-method-char","message":"invalid method character","component":"method","position":4}],"warnings":[{"code":"empty-segment","message":"empty path segment","component":"path","position":16}]}`, string(bytes))
+
+	r = Inspect("did:web:example.com", InspectOptions{}).Report()
+	bytes, err = json.Marshal(r)
+	assert(t, nil, err)
+	assert(t, `{"valid":true}`, string(bytes))
+
+	t.Run("codes", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for err, code := range issueCodes {
+			assert(t, false, seen[code], "duplicate code %q", code)
+			seen[code] = true
+			assert(t, code, NewIssue(&ParseError{Err: err}).Code)
+		}
+		assert(t, "invalid", NewIssue(&ParseError{Err: errors.New("other")}).Code)
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		err := fmt.Errorf("%w: %w", ErrChecksum, errors.New("check digit 7, want 3"))
+		issue := NewIssue(&ParseError{Err: err, Component: ComponentID, Offset: 12})
+		assert(t, Issue{Code: "checksum", Message: err.Error(), Component: "method-specific-id", Position: 12}, issue)
+
+		err = fmt.Errorf("%w: length of 99 bytes exceeds maximum of 64", ErrLimitExceeded)
+		assert(t, "limit-exceeded", NewIssue(&ParseError{Err: err}).Code)
+	})
+}
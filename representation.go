@@ -0,0 +1,138 @@
+package did
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+
+	"github.com/ockam-network/did/internal/dagcbor"
+)
+
+// Media types of the DID document representations.
+// https://www.w3.org/TR/did-spec-registries/#representation-specific-entries
+const (
+	MediaTypeJSON   = "application/did+json"
+	MediaTypeJSONLD = "application/did+ld+json"
+	MediaTypeCBOR   = "application/did+cbor"
+)
+
+// baseMediaType returns the media type without parameters, in lower case.
+func baseMediaType(mediaType string) string {
+	if t, _, err := mime.ParseMediaType(mediaType); err == nil {
+		return t
+	}
+	return mediaType
+}
+
+// JSONContentType returns the content type of a resolution of d in one of the
+// JSON representations, as requested with accept. No preference gets
+// MediaTypeJSONLD. Other media types fail with ErrRepresentationNotSupported.
+func JSONContentType(d *DID, accept string) (string, error) {
+	switch accept {
+	case "":
+		return MediaTypeJSONLD, nil
+	case MediaTypeJSON, MediaTypeJSONLD:
+		return accept, nil
+	default:
+		return "", fmt.Errorf("did: resolve %s: media type %q: %w", d, accept, ErrRepresentationNotSupported)
+	}
+}
+
+// MarshalDocument returns the representation of doc in a media type. The
+// JSON-LD representation gets the ContextV1 when absent. Other media types
+// fail with ErrRepresentationNotSupported.
+func MarshalDocument(doc *Document, mediaType string) ([]byte, error) {
+	switch baseMediaType(mediaType) {
+	case MediaTypeJSON, "application/json":
+		return json.Marshal(doc)
+	case MediaTypeJSONLD, "application/ld+json":
+		if !doc.hasContext(ContextV1) {
+			c := *doc
+			c.Context = append([]any{ContextV1}, doc.Context...)
+			doc = &c
+		}
+		return json.Marshal(doc)
+	case MediaTypeCBOR:
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		return dagcbor.MarshalJSON(data)
+	default:
+		return nil, fmt.Errorf("did: media type %q: %w", mediaType, ErrRepresentationNotSupported)
+	}
+}
+
+// UnmarshalDocument parses the representation of a document in a media type.
+// Other media types than the ones of MarshalDocument fail with
+// ErrRepresentationNotSupported.
+func UnmarshalDocument(data []byte, mediaType string) (*Document, error) {
+	switch baseMediaType(mediaType) {
+	case MediaTypeJSON, MediaTypeJSONLD, "application/json", "application/ld+json":
+		break
+	case MediaTypeCBOR:
+		var err error
+		data, err = dagcbor.UnmarshalJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("did: malformed CBOR document: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("did: media type %q: %w", mediaType, ErrRepresentationNotSupported)
+	}
+	doc := new(Document)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("did: malformed document: %w", err)
+	}
+	return doc, nil
+}
+
+// hasContext returns whether the document includes the context URL.
+func (doc *Document) hasContext(s string) bool {
+	for _, c := range doc.Context {
+		if c == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentNegotiation returns middleware which serves each of the
+// representation media types of MarshalDocument, for any resolver which
+// produces the data model. The Accept option reaches the upstream as
+// the empty string, and the result has the requested ContentType in its
+// metadata, with the ContextV1 included for JSON-LD.
+func ContentNegotiation() Middleware {
+	return func(next Resolver) Resolver {
+		return ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+			accept := baseMediaType(opts.Accept)
+			switch accept {
+			case "":
+				return next.Resolve(ctx, d, opts)
+			case MediaTypeJSON, MediaTypeJSONLD, MediaTypeCBOR:
+				break
+			default:
+				return nil, nil, fmt.Errorf("did: resolve %s: media type %q: %w", d, opts.Accept, ErrRepresentationNotSupported)
+			}
+
+			opts.Accept = ""
+			doc, meta, err := next.Resolve(ctx, d, opts)
+			if err != nil {
+				return doc, meta, err
+			}
+			if accept == MediaTypeJSONLD && !doc.hasContext(ContextV1) {
+				c := *doc
+				c.Context = append([]any{ContextV1}, doc.Context...)
+				doc = &c
+			}
+			if meta == nil {
+				meta = new(Metadata)
+			} else {
+				c := *meta
+				meta = &c
+			}
+			meta.Resolution.ContentType = accept
+			return doc, meta, nil
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMarshalDocument(t *testing.T) {
+	doc := &Document{
+		ID:      "did:example:123",
+		Service: []Service{{ID: "#agent", Type: "DIDCommMessaging", ServiceEndpoint: ServiceEndpoint{URI: "https://example.com/"}}},
+	}
+	for _, mediaType := range []string{MediaTypeJSON, MediaTypeJSONLD, MediaTypeCBOR, MediaTypeJSON + "; charset=utf-8"} {
+		data, err := MarshalDocument(doc, mediaType)
+		assert(t, nil, err, "MarshalDocument %s", mediaType)
+		got, err := UnmarshalDocument(data, mediaType)
+		assert(t, nil, err, "UnmarshalDocument %s", mediaType)
+		assert(t, doc.ID, got.ID, "%s id", mediaType)
+		assert(t, doc.Service, got.Service, "%s services", mediaType)
+		assert(t, mediaType == MediaTypeJSONLD, got.hasContext(ContextV1), "%s context", mediaType)
+	}
+	assert(t, 0, len(doc.Context), "document modified")
+
+	_, err := MarshalDocument(doc, "text/html")
+	assert(t, true, errors.Is(err, ErrRepresentationNotSupported), "got error %v", err)
+	_, err = UnmarshalDocument([]byte{0xa1}, MediaTypeCBOR)
+	assert(t, true, err != nil, "truncated CBOR got no error")
+}
+
+func TestContentNegotiation(t *testing.T) {
+	var upstreamAccept []string
+	r := ContentNegotiation()(ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		upstreamAccept = append(upstreamAccept, opts.Accept)
+		return &Document{ID: d.String()}, &Metadata{Resolution: ResolutionMetadata{ContentType: MediaTypeJSON}}, nil
+	}))
+	ctx := context.Background()
+	d := &DID{Method: "example", ID: "123"}
+
+	for _, accept := range []string{"", MediaTypeJSON, MediaTypeJSONLD, MediaTypeCBOR} {
+		doc, meta, err := r.Resolve(ctx, d, ResolutionOptions{Accept: accept})
+		assert(t, nil, err, "accept %q", accept)
+		want := accept
+		if want == "" {
+			want = MediaTypeJSON
+		}
+		assert(t, want, meta.Resolution.ContentType, "accept %q content type", accept)
+		assert(t, accept == MediaTypeJSONLD, doc.hasContext(ContextV1), "accept %q context", accept)
+	}
+	assert(t, []string{"", "", "", ""}, upstreamAccept)
+
+	_, _, err := r.Resolve(ctx, d, ResolutionOptions{Accept: "text/html"})
+	assert(t, true, errors.Is(err, ErrRepresentationNotSupported), "got error %v", err)
+}
+
+func TestJSONContentType(t *testing.T) {
+	d := &DID{Method: "example", ID: "123"}
+	for accept, want := range map[string]string{"": MediaTypeJSONLD, MediaTypeJSON: MediaTypeJSON, MediaTypeJSONLD: MediaTypeJSONLD} {
+		got, err := JSONContentType(d, accept)
+		assert(t, nil, err, "accept %q", accept)
+		assert(t, want, got, "accept %q content type", accept)
+	}
+	for _, accept := range []string{MediaTypeCBOR, "text/html"} {
+		_, err := JSONContentType(d, accept)
+		assert(t, true, errors.Is(err, ErrRepresentationNotSupported), "accept %q got error %v", accept, err)
+	}
+}
@@ -0,0 +1,113 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A Resolver produces the DID Document of a DID, conform the DID Resolution
+// specification. Implementations return an error wrapping ErrNotFound,
+// ErrInvalidDID, ErrMethodNotSupported or ErrRepresentationNotSupported when
+// applicable, such that callers can test with errors.Is.
+type Resolver interface {
+	Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error)
+}
+
+// ResolverFunc adapts an ordinary function to the Resolver interface.
+type ResolverFunc func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error)
+
+// Resolve calls f(ctx, d, opts).
+func (f ResolverFunc) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	return f(ctx, d, opts)
+}
+
+// ResolutionOptions are the input metadata of a resolution. Resolvers which
+// can not produce the requested representation fail with
+// ErrRepresentationNotSupported. Resolvers without version history ignore
+// VersionID and VersionTime, and resolvers without caches ignore NoCache.
+type ResolutionOptions struct {
+	// Accept is the media type of the preferred representation, if any.
+	Accept string
+
+	// NoCache requests a fresh resolution, which bypasses any caches on
+	// the way. Fresh results may still update the caches.
+	NoCache bool
+
+	// VersionID selects a specific version of the document, if any.
+	VersionID string
+
+	// VersionTime selects the version of the document in effect at
+	// the time, if any.
+	VersionTime time.Time
+}
+
+// ResolveAllOptions configures ResolveAll.
+type ResolveAllOptions struct {
+	// ResolutionOptions apply to each DID.
+	ResolutionOptions
+
+	// Concurrency limits the number of parallel resolutions. Zero
+	// defaults to 16.
+	Concurrency int
+}
+
+// A Resolution is the outcome of a resolution.
+type Resolution struct {
+	Document *Document
+	Metadata *Metadata
+	Err      error
+}
+
+// ResolveAll resolves each DID concurrently with r. The results are returned
+// in the same order as the DIDs. DIDs which are pending when ctx expires get
+// the context error. Wrap r in a CachingResolver to resolve duplicates once.
+func ResolveAll(ctx context.Context, r Resolver, dids []*DID, opts ResolveAllOptions) []Resolution {
+	results := make([]Resolution, len(dids))
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = 16
+	}
+	workers = min(workers, len(dids))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(dids) {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				res := &results[i]
+				res.Document, res.Metadata, res.Err = r.Resolve(ctx, dids[i], opts.ResolutionOptions)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Reasons for a resolution failure. Callers can test for them with errors.Is.
+var (
+	// ErrInvalidDID means the DID does not conform to its method.
+	ErrInvalidDID = errors.New("invalid DID")
+	// ErrNotFound means the DID does not exist.
+	ErrNotFound = errors.New("DID not found")
+	// ErrMethodNotSupported means the resolver has no support for the
+	// DID method.
+	ErrMethodNotSupported = errors.New("DID method not supported")
+	// ErrRepresentationNotSupported means the resolver has no support
+	// for the requested representation.
+	ErrRepresentationNotSupported = errors.New("DID representation not supported")
+)
@@ -0,0 +1,77 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolverFunc(t *testing.T) {
+	var r Resolver = ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		if d.Method != "example" {
+			return nil, nil, fmt.Errorf("did: resolve %s: %w", d, ErrMethodNotSupported)
+		}
+		meta := &Metadata{Resolution: ResolutionMetadata{ContentType: opts.Accept}}
+		return &Document{ID: d.String()}, meta, nil
+	})
+
+	doc, meta, err := r.Resolve(context.Background(), &DID{Method: "example", ID: "123"}, ResolutionOptions{Accept: "application/did+json"})
+	assert(t, nil, err)
+	assert(t, "did:example:123", doc.ID)
+	assert(t, "application/did+json", meta.Resolution.ContentType)
+
+	_, _, err = r.Resolve(context.Background(), &DID{Method: "other", ID: "123"}, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrMethodNotSupported))
+}
+
+func TestResolveAll(t *testing.T) {
+	var active, peak atomic.Int64
+	r := ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		if d.ID == "missing" {
+			return nil, nil, fmt.Errorf("did: resolve %s: %w", d, ErrNotFound)
+		}
+		return &Document{ID: d.String()}, &Metadata{Resolution: ResolutionMetadata{ContentType: opts.Accept}}, nil
+	})
+
+	dids := make([]*DID, 20)
+	for i := range dids {
+		dids[i] = &DID{Method: "example", ID: fmt.Sprint(i)}
+	}
+	dids[7].ID = "missing"
+
+	results := ResolveAll(context.Background(), r, dids, ResolveAllOptions{
+		ResolutionOptions: ResolutionOptions{Accept: "application/did+json"},
+		Concurrency:       3,
+	})
+	assert(t, len(dids), len(results))
+	for i, res := range results {
+		if i == 7 {
+			assert(t, true, errors.Is(res.Err, ErrNotFound), "missing DID got error %v", res.Err)
+			continue
+		}
+		assert(t, nil, res.Err)
+		assert(t, dids[i].String(), res.Document.ID)
+		assert(t, "application/did+json", res.Metadata.Resolution.ContentType)
+	}
+	assert(t, true, peak.Load() <= 3, "peak concurrency %d", peak.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, res := range ResolveAll(ctx, r, dids, ResolveAllOptions{}) {
+		assert(t, context.Canceled, res.Err)
+	}
+
+	assert(t, 0, len(ResolveAll(context.Background(), r, nil, ResolveAllOptions{})))
+}
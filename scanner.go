@@ -0,0 +1,91 @@
+package did
+
+import (
+	"bufio"
+	"io"
+)
+
+// A Scanner reads whitespace separated DIDs and DID URLs from a stream, with
+// one token at a time in memory. Parse errors apply to their respective token
+// only; the Scanner moves on to the next token regardless. Tokens may not
+// exceed bufio.MaxScanTokenSize.
+type Scanner struct {
+	s *bufio.Scanner
+
+	line     int // line number of the current token
+	nextLine int // line number at the read position
+
+	url *DIDURL
+	err error
+}
+
+// NewScanner returns a Scanner which reads from r.
+func NewScanner(r io.Reader) *Scanner {
+	s := &Scanner{s: bufio.NewScanner(r), nextLine: 1}
+	s.s.Split(s.split)
+	return s
+}
+
+// Scan advances to the next token, which is then available through the URL
+// method. It returns false when the scan stops, either by reaching the end
+// of the input or by a read error.
+func (s *Scanner) Scan() bool {
+	s.url, s.err = nil, nil
+	if !s.s.Scan() {
+		return false
+	}
+	s.url, s.err = ParseURL(s.s.Text())
+	return true
+}
+
+// URL returns the parse result of the current token.
+func (s *Scanner) URL() (*DIDURL, error) {
+	return s.url, s.err
+}
+
+// Text returns the current token as is.
+func (s *Scanner) Text() string {
+	return s.s.Text()
+}
+
+// Line returns the line number of the current token, starting at 1.
+func (s *Scanner) Line() int {
+	return s.line
+}
+
+// Err returns the first non-EOF read error, if any.
+func (s *Scanner) Err() error {
+	return s.s.Err()
+}
+
+// split implements bufio.SplitFunc with tokens separated by ASCII
+// whitespace, while it keeps track of line numbers.
+func (s *Scanner) split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := 0
+	for ; start < len(data) && isSpace(data[start]); start++ {
+		if data[start] == '\n' {
+			s.nextLine++
+		}
+	}
+
+	for i := start; i < len(data); i++ {
+		if isSpace(data[i]) {
+			s.line = s.nextLine
+			return i, data[start:i], nil
+		}
+	}
+	if atEOF && start < len(data) {
+		s.line = s.nextLine
+		return len(data), data[start:], nil
+	}
+	// request more data
+	return start, nil, nil
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
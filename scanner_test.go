@@ -0,0 +1,57 @@
+package did
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScanner(t *testing.T) {
+	input := "did:a:1\n\ndid:a:2 did:a:3#f\r\n\tnot-a-did\ndid:a:4"
+	want := []struct {
+		text string
+		line int
+		ok   bool
+	}{
+		{"did:a:1", 1, true},
+		{"did:a:2", 3, true},
+		{"did:a:3#f", 3, true},
+		{"not-a-did", 4, false},
+		{"did:a:4", 5, true},
+	}
+
+	// one byte at a time tests the incremental splits
+	for _, r := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input))} {
+		s := NewScanner(r)
+		for _, w := range want {
+			assert(t, true, s.Scan())
+			assert(t, w.text, s.Text())
+			assert(t, w.line, s.Line(), "Token: %s", w.text)
+			u, err := s.URL()
+			assert(t, w.ok, err == nil, "Token: %s", w.text)
+			if w.ok {
+				assert(t, w.text, u.String())
+			} else {
+				assert(t, true, u == nil)
+			}
+		}
+		assert(t, false, s.Scan())
+		assert(t, nil, s.Err())
+	}
+
+	t.Run("reports read errors", func(t *testing.T) {
+		readErr := errors.New("test")
+		s := NewScanner(io.MultiReader(strings.NewReader("did:a:1 "), iotest.ErrReader(readErr)))
+		assert(t, true, s.Scan())
+		assert(t, false, s.Scan())
+		assert(t, readErr, s.Err())
+	})
+
+	t.Run("stops on empty input", func(t *testing.T) {
+		s := NewScanner(strings.NewReader(" \n\t"))
+		assert(t, false, s.Scan())
+		assert(t, nil, s.Err())
+	})
+}
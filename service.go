@@ -0,0 +1,233 @@
+package did
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// A ServiceEndpoint is either a URI, a map, or a set of URIs and maps. At
+// most one of the fields is set. JSON encoding reproduces the decoded input,
+// including the order of map members, minus any insignificant whitespace.
+// https://www.w3.org/TR/did-core/#dfn-serviceendpoint
+type ServiceEndpoint struct {
+	// URI is set for a single URI.
+	URI string
+
+	// Map is set for a single map, like the ones of DIDComm.
+	Map EndpointMap
+
+	// Set is set for a set, whereby each element has either URI or Map.
+	// A set with one element remains a set in JSON.
+	Set []ServiceEndpoint
+}
+
+// An EndpointMap has the members of a map in order of appearance.
+type EndpointMap []EndpointMember
+
+// An EndpointMember is a name–value pair of an EndpointMap.
+type EndpointMember struct {
+	Name  string
+	Value json.RawMessage // JSON
+}
+
+// Get returns the value of the first member with name, or nil when absent.
+func (m EndpointMap) Get(name string) json.RawMessage {
+	for _, member := range m {
+		if member.Name == name {
+			return member.Value
+		}
+	}
+	return nil
+}
+
+// String returns the value of the first member with name, with ok false when
+// absent or when the value is not a JSON string.
+func (m EndpointMap) String(name string) (s string, ok bool) {
+	v := m.Get(name)
+	if v == nil || json.Unmarshal(v, &s) != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// IsZero returns whether the endpoint is absent.
+func (e ServiceEndpoint) IsZero() bool {
+	return e.URI == "" && e.Map == nil && e.Set == nil
+}
+
+// URIs returns each of the URIs in order of appearance. Maps contribute their
+// "uri" member, if any, as with DIDComm.
+func (e ServiceEndpoint) URIs() []string {
+	var uris []string
+	switch {
+	case e.URI != "":
+		uris = append(uris, e.URI)
+	case e.Map != nil:
+		if s, ok := e.Map.String("uri"); ok {
+			uris = append(uris, s)
+		}
+	default:
+		for _, elem := range e.Set {
+			uris = append(uris, elem.URIs()...)
+		}
+	}
+	return uris
+}
+
+// Maps returns each of the maps in order of appearance.
+func (e ServiceEndpoint) Maps() []EndpointMap {
+	if e.Map != nil {
+		return []EndpointMap{e.Map}
+	}
+	var maps []EndpointMap
+	for _, elem := range e.Set {
+		if elem.Map != nil {
+			maps = append(maps, elem.Map)
+		}
+	}
+	return maps
+}
+
+// MarshalJSON implements the json.Marshaler interface. The zero value encodes
+// as null.
+func (e ServiceEndpoint) MarshalJSON() ([]byte, error) {
+	switch {
+	case e.URI != "":
+		return json.Marshal(e.URI)
+	case e.Map != nil:
+		return e.Map.MarshalJSON()
+	case e.Set != nil:
+		buf := []byte{'['}
+		for i, elem := range e.Set {
+			if elem.Set != nil {
+				return nil, errors.New("did: service endpoint set in set")
+			}
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			b, err := elem.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, b...)
+		}
+		return append(buf, ']'), nil
+	default:
+		return []byte("null"), nil
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m EndpointMap) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for i, member := range m {
+		if i != 0 {
+			buf = append(buf, ',')
+		}
+		name, err := json.Marshal(member.Name)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, name...)
+		buf = append(buf, ':')
+		if member.Value == nil {
+			buf = append(buf, "null"...)
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, member.Value); err != nil {
+			return nil, fmt.Errorf("did: service endpoint member %q: %w", member.Name, err)
+		}
+		buf = append(buf, compact.Bytes()...)
+	}
+	return append(buf, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Sets may not be
+// empty nor contain sets, and URIs may not be empty.
+func (e *ServiceEndpoint) UnmarshalJSON(data []byte) error {
+	*e = ServiceEndpoint{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := e.decode(dec, true); err != nil {
+		return fmt.Errorf("did: service endpoint: %w", err)
+	}
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, with the members
+// in order of appearance.
+func (m *EndpointMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("got JSON %v, want an object", tok)
+	}
+	return m.decodeMembers(dec)
+}
+
+// decode reads a value from dec. Sets are allowed at the top level only.
+func (e *ServiceEndpoint) decode(dec *json.Decoder, top bool) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok := tok.(type) {
+	case string:
+		if tok == "" {
+			return errors.New("empty URI")
+		}
+		e.URI = tok
+	case json.Delim:
+		switch {
+		case tok == '{':
+			return e.Map.decodeMembers(dec)
+		case tok == '[' && top:
+			e.Set = []ServiceEndpoint{}
+			for dec.More() {
+				var elem ServiceEndpoint
+				if err := elem.decode(dec, false); err != nil {
+					return err
+				}
+				e.Set = append(e.Set, elem)
+			}
+			if len(e.Set) == 0 {
+				return errors.New("empty set")
+			}
+			_, err := dec.Token() // ']'
+			return err
+		default:
+			return errors.New("set in set")
+		}
+	default:
+		if top && tok == nil {
+			return nil // absent
+		}
+		return fmt.Errorf("got JSON %v, want a URI string or a map", tok)
+	}
+	return nil
+}
+
+// decodeMembers reads the members of a map from dec, after the opening brace.
+func (m *EndpointMap) decodeMembers(dec *json.Decoder) error {
+	members := EndpointMap{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		members = append(members, EndpointMember{Name: name, Value: value})
+	}
+	if _, err := dec.Token(); err != nil { // '}'
+		return err
+	}
+	*m = members
+	return nil
+}
@@ -0,0 +1,55 @@
+package did
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestServiceEndpointJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		uris []string
+		maps int
+	}{
+		{`"https://example.com/"`, []string{"https://example.com/"}, 0},
+		{`{"uri":"https://example.com/didcomm","routingKeys":["did:example:mediator#key-1"],"accept":["didcomm/v2"]}`, []string{"https://example.com/didcomm"}, 1},
+		{`{"origins":["https://example.com/","https://example.org/"]}`, nil, 1},
+		{`["https://example.com/"]`, []string{"https://example.com/"}, 0},
+		{`["https://a.example.com/",{"uri":"https://b.example.com/"},{"z":1,"a":{"nested":[true]}}]`, []string{"https://a.example.com/", "https://b.example.com/"}, 2},
+	}
+	for _, test := range tests {
+		var e ServiceEndpoint
+		if err := json.Unmarshal([]byte(test.json), &e); err != nil {
+			t.Errorf("%s got error: %s", test.json, err)
+			continue
+		}
+		assert(t, test.uris, e.URIs(), test.json)
+		assert(t, test.maps, len(e.Maps()), test.json)
+		assert(t, false, e.IsZero(), test.json)
+
+		got, err := json.Marshal(e)
+		assert(t, nil, err, test.json)
+		assert(t, test.json, string(got), "round trip")
+	}
+
+	var e ServiceEndpoint
+	assert(t, nil, json.Unmarshal([]byte("{ \"a\" : [ 1, 2 ] ,\n\"b\":\"x\" }"), &e))
+	got, _ := json.Marshal(e)
+	assert(t, `{"a":[1,2],"b":"x"}`, string(got), "whitespace")
+	s, ok := e.Map.String("b")
+	assert(t, "x", s)
+	assert(t, true, ok)
+	_, ok = e.Map.String("a")
+	assert(t, false, ok)
+
+	for _, s := range []string{`1`, `""`, `[["https://example.com/"]]`, `[null]`, `[]`, `true`} {
+		assert(t, true, json.Unmarshal([]byte(s), new(ServiceEndpoint)) != nil, "%s accepted", s)
+	}
+
+	// absent
+	var svc Service
+	assert(t, nil, json.Unmarshal([]byte(`{"id":"#s","type":"T","serviceEndpoint":null}`), &svc))
+	assert(t, true, svc.ServiceEndpoint.IsZero())
+	got, _ = json.Marshal(svc)
+	assert(t, `{"id":"#s","type":"T","serviceEndpoint":null}`, string(got))
+}
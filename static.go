@@ -0,0 +1,113 @@
+package did
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// StaticResolver is a Resolver with a fixed set of documents in memory, for
+// tests, air-gapped deployments and trust anchors which must resolve without
+// network access. The zero value is ready for use. Documents may be added and
+// removed concurrently with resolution.
+//
+// Documents are shared between callers, and they must not be modified once
+// added.
+type StaticResolver struct {
+	mu   sync.RWMutex
+	docs map[Key]*staticEntry
+}
+
+// staticEntry is a document with its metadata.
+type staticEntry struct {
+	doc  *Document
+	meta DocumentMetadata
+}
+
+// Add registers doc for the DID in its ID, which replaces any previous
+// document of the DID. The ID must be a DID, without path, query or fragment.
+func (s *StaticResolver) Add(doc *Document) error {
+	return s.AddWithMetadata(doc, DocumentMetadata{})
+}
+
+// AddWithMetadata registers doc for the DID in its ID, like Add, and it
+// resolves with meta as the document metadata.
+func (s *StaticResolver) AddWithMetadata(doc *Document, meta DocumentMetadata) error {
+	d, err := Parse(doc.ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.docs == nil {
+		s.docs = make(map[Key]*staticEntry)
+	}
+	s.docs[d.Key()] = &staticEntry{doc: doc, meta: meta}
+	return nil
+}
+
+// Remove unregisters the document of d, and it returns whether there was one.
+func (s *StaticResolver) Remove(d *DID) bool {
+	k := d.Key()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.docs[k]
+	delete(s.docs, k)
+	return ok
+}
+
+// DIDs returns the registered DIDs in alphabetical order.
+func (s *StaticResolver) DIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dids := make([]string, 0, len(s.docs))
+	for k := range s.docs {
+		dids = append(dids, k.string())
+	}
+	slices.SortFunc(dids, strings.Compare)
+	return dids
+}
+
+// Resolve implements the Resolver interface. DIDs without document get an
+// error which wraps ErrNotFound. The options other than Accept are ignored.
+func (s *StaticResolver) Resolve(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+	accept := baseMediaType(opts.Accept)
+	switch accept {
+	case "", MediaTypeJSON, MediaTypeJSONLD, MediaTypeCBOR:
+		break
+	default:
+		return nil, nil, fmt.Errorf("did: resolve %s: media type %q: %w", d, opts.Accept, ErrRepresentationNotSupported)
+	}
+
+	s.mu.RLock()
+	e, ok := s.docs[d.Key()]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, ErrNotFound)
+	}
+
+	doc, contentType := represent(e.doc, accept)
+	meta := &Metadata{Document: e.meta}
+	meta.Resolution.ContentType = contentType
+	return doc, meta, nil
+}
+
+// represent returns doc for the media type of a resolution, which is one of
+// the representations or the empty string for no preference. JSON-LD gets the
+// ContextV1 in a copy when absent.
+func represent(doc *Document, accept string) (*Document, string) {
+	switch {
+	case accept == MediaTypeJSONLD && !doc.hasContext(ContextV1):
+		c := *doc
+		c.Context = append([]any{ContextV1}, doc.Context...)
+		return &c, accept
+	case accept == "" && len(doc.Context) != 0:
+		return doc, MediaTypeJSONLD
+	case accept == "":
+		return doc, MediaTypeJSON
+	}
+	return doc, accept
+}
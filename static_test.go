@@ -0,0 +1,66 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStaticResolver(t *testing.T) {
+	var s StaticResolver
+	ctx := context.Background()
+	alice := &DID{Method: "example", ID: "alice"}
+
+	_, _, err := s.Resolve(ctx, alice, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrNotFound), "zero value error %v", err)
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert(t, nil, s.AddWithMetadata(&Document{ID: "did:example:alice"}, DocumentMetadata{Created: created}))
+	assert(t, nil, s.Add(&Document{Context: []any{ContextV1}, ID: "did:example:bob"}))
+	assert(t, []string{"did:example:alice", "did:example:bob"}, s.DIDs())
+
+	doc, meta, err := s.Resolve(ctx, alice, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, "did:example:alice", doc.ID)
+	assert(t, created, meta.Document.Created)
+	assert(t, MediaTypeJSON, meta.Resolution.ContentType)
+
+	doc, meta, err = s.Resolve(ctx, alice, ResolutionOptions{Accept: MediaTypeJSONLD})
+	assert(t, nil, err)
+	assert(t, []any{ContextV1}, doc.Context)
+	assert(t, MediaTypeJSONLD, meta.Resolution.ContentType)
+
+	_, meta, err = s.Resolve(ctx, &DID{Method: "example", ID: "bob"}, ResolutionOptions{})
+	assert(t, nil, err)
+	assert(t, MediaTypeJSONLD, meta.Resolution.ContentType)
+
+	_, _, err = s.Resolve(ctx, alice, ResolutionOptions{Accept: "text/html"})
+	assert(t, true, errors.Is(err, ErrRepresentationNotSupported), "error %v", err)
+
+	assert(t, true, errors.Is(s.Add(&Document{ID: "did:example:alice#key-1"}), ErrURLDenied))
+	assert(t, true, s.Add(&Document{ID: "alice"}) != nil)
+
+	assert(t, true, s.Remove(alice))
+	assert(t, false, s.Remove(alice))
+	_, _, err = s.Resolve(ctx, alice, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrNotFound), "removed error %v", err)
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d := &DID{Method: "example", ID: string(rune('a' + i))}
+				for range 100 {
+					s.Add(&Document{ID: d.String()})
+					s.Resolve(ctx, d, ResolutionOptions{})
+					s.Remove(d)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,78 @@
+package did
+
+import (
+	"context"
+	"time"
+)
+
+// Instrumentation receives the telemetry of resolutions, e.g., to produce
+// OpenTelemetry spans and metrics. Adapters live outside of this package,
+// such that the dependency remains opt-in.
+//
+// An OpenTelemetry adapter typically starts a span in StartResolution, with
+// the method as an attribute, and it ends the span in the completion
+// function, together with a latency histogram and an outcome counter.
+type Instrumentation interface {
+	// StartResolution is called before each resolution. The context
+	// returned applies to the resolution, e.g., with a span. The function
+	// returned is called once, on completion.
+	StartResolution(ctx context.Context, d *DID, opts ResolutionOptions) (context.Context, func(ResolutionEvent))
+}
+
+// ResolutionEvent describes a completed resolution.
+type ResolutionEvent struct {
+	// Method is the DID method, which has low cardinality, unlike the
+	// DID itself.
+	Method string
+
+	// Duration is the latency of the resolution.
+	Duration time.Duration
+
+	// Outcome is the error code, like ErrorNotFound, or the empty string
+	// on success.
+	Outcome string
+
+	// Err is the failure, if any.
+	Err error
+
+	// CacheHit is set when a CachingResolver served the resolution from
+	// its cache, or from the upstream call of another resolution. The
+	// ratio of hits follows from counting events.
+	CacheHit bool
+}
+
+// Instrument returns middleware which reports each resolution to inst. Place
+// it outside of any CachingResolver to observe cache hits.
+func Instrument(inst Instrumentation) Middleware {
+	return func(next Resolver) Resolver {
+		return ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+			probe := new(cacheProbe)
+			ctx, done := inst.StartResolution(context.WithValue(ctx, cacheProbeKey{}, probe), d, opts)
+			start := time.Now()
+			doc, meta, err := next.Resolve(ctx, d, opts)
+			done(ResolutionEvent{
+				Method:   d.Method,
+				Duration: time.Since(start),
+				Outcome:  ErrorCode(err),
+				Err:      err,
+				CacheHit: probe.hit,
+			})
+			return doc, meta, err
+		})
+	}
+}
+
+// cacheProbeKey is the context key for a *cacheProbe.
+type cacheProbeKey struct{}
+
+// cacheProbe records whether a cache served the resolution.
+type cacheProbe struct {
+	hit bool
+}
+
+// markCacheHit records a cache hit in the probe of ctx, if any.
+func markCacheHit(ctx context.Context) {
+	if probe, ok := ctx.Value(cacheProbeKey{}).(*cacheProbe); ok {
+		probe.hit = true
+	}
+}
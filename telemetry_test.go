@@ -0,0 +1,50 @@
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// recordingInstrumentation collects the events, and it tags the context.
+type recordingInstrumentation struct {
+	events []ResolutionEvent
+}
+
+type spanKey struct{}
+
+func (r *recordingInstrumentation) StartResolution(ctx context.Context, d *DID, opts ResolutionOptions) (context.Context, func(ResolutionEvent)) {
+	return context.WithValue(ctx, spanKey{}, d.String()), func(e ResolutionEvent) {
+		r.events = append(r.events, e)
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	inst := new(recordingInstrumentation)
+	upstream := ResolverFunc(func(ctx context.Context, d *DID, opts ResolutionOptions) (*Document, *Metadata, error) {
+		assert(t, d.String(), ctx.Value(spanKey{}), "span context")
+		if d.ID == "missing" {
+			return nil, nil, fmt.Errorf("did: resolve %s: %w", d, ErrNotFound)
+		}
+		return &Document{ID: d.String()}, &Metadata{}, nil
+	})
+	r := Chain(&CachingResolver{Resolver: upstream}, Instrument(inst))
+	ctx := context.Background()
+
+	for range 2 {
+		_, _, err := r.Resolve(ctx, &DID{Method: "example", ID: "1"}, ResolutionOptions{})
+		assert(t, nil, err)
+	}
+	_, _, err := r.Resolve(ctx, &DID{Method: "web", ID: "missing"}, ResolutionOptions{})
+	assert(t, true, errors.Is(err, ErrNotFound))
+
+	assert(t, 3, len(inst.events), "events")
+	assert(t, "example", inst.events[0].Method)
+	assert(t, "", inst.events[0].Outcome)
+	assert(t, false, inst.events[0].CacheHit, "first resolution cache hit")
+	assert(t, true, inst.events[1].CacheHit, "second resolution cache hit")
+	assert(t, "web", inst.events[2].Method)
+	assert(t, ErrorNotFound, inst.events[2].Outcome)
+	assert(t, true, errors.Is(inst.events[2].Err, ErrNotFound))
+}
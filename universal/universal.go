@@ -0,0 +1,120 @@
+// Package universal resolves DIDs of any method with a Universal Resolver
+// instance, as per the DID Resolution HTTP(S) binding.
+// https://w3c.github.io/did-resolution/#bindings-https
+package universal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ockam-network/did"
+)
+
+// DefaultEndpoint is the public development instance of the Universal
+// Resolver, which is not meant for production use.
+const DefaultEndpoint = "https://dev.uniresolver.io"
+
+// MaxResultSize is the limit for resolution results in bytes.
+const MaxResultSize = 1 << 20
+
+// resultMediaType requests the full resolution result.
+const resultMediaType = `application/ld+json;profile="https://w3id.org/did-resolution"`
+
+// Resolver resolves DIDs with a Universal Resolver. The zero value uses
+// DefaultEndpoint with http.DefaultClient.
+type Resolver struct {
+	// Endpoint is the base URL, with DefaultEndpoint for the empty
+	// string.
+	Endpoint string
+
+	// Client does the HTTP requests. The nil value defaults to
+	// http.DefaultClient. Set a client with a custom Transport for
+	// proxies, connection pools, mutual TLS or private CAs.
+	Client *http.Client
+
+	// Header has additional request headers, e.g., for authorization of
+	// a private instance.
+	Header http.Header
+}
+
+// Resolve implements the did.Resolver interface. Methods unknown to the
+// instance fail with did.ErrMethodNotSupported.
+func (r *Resolver) Resolve(ctx context.Context, d *did.DID, opts did.ResolutionOptions) (*did.Document, *did.Metadata, error) {
+	contentType, err := did.JSONContentType(d, opts.Accept)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := r.Endpoint
+	if base == "" {
+		base = DefaultEndpoint
+	}
+	location := strings.TrimSuffix(base, "/") + "/1.0/identifiers/" + url.PathEscape(d.String())
+	query := make(url.Values)
+	if opts.VersionID != "" {
+		query.Set("versionId", opts.VersionID)
+	}
+	if !opts.VersionTime.IsZero() {
+		query.Set("versionTime", opts.VersionTime.UTC().Format(time.RFC3339))
+	}
+	if opts.NoCache {
+		query.Set("noCache", "true")
+	}
+	if len(query) != 0 {
+		location += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", resultMediaType)
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, err)
+	}
+	defer resp.Body.Close()
+
+	// The envelope carries the error codes on failure too.
+	var result struct {
+		Document *did.Document `json:"didDocument"`
+		did.Metadata
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, MaxResultSize)).Decode(&result)
+	if code := result.Resolution.Error; code != "" {
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, result.Resolution.Err())
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrNotFound)
+	case resp.StatusCode == http.StatusNotImplemented:
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrMethodNotSupported)
+	case resp.StatusCode == http.StatusBadRequest:
+		return nil, nil, fmt.Errorf("did: resolve %s: %w", d, did.ErrInvalidDID)
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, fmt.Errorf("did: resolve %s: universal resolver got HTTP %q", d, resp.Status)
+	case err != nil:
+		return nil, nil, fmt.Errorf("did: resolve %s: malformed resolution result: %w", d, err)
+	case result.Document == nil:
+		return nil, nil, fmt.Errorf("did: resolve %s: resolution result without document", d)
+	case result.Document.ID != d.String():
+		return nil, nil, fmt.Errorf("did: resolve %s: document has id %q", d, result.Document.ID)
+	}
+
+	meta := &result.Metadata
+	meta.Resolution.ContentType = contentType
+	return result.Document, meta, nil
+}
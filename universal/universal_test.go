@@ -0,0 +1,68 @@
+package universal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ockam-network/did"
+)
+
+func TestResolve(t *testing.T) {
+	const id = "did:example:123"
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("got authorization %q", got)
+		}
+		switch r.URL.Path {
+		case "/1.0/identifiers/" + id:
+			if got := r.URL.Query().Get("noCache"); got != "true" {
+				t.Errorf("got noCache %q, want true", got)
+			}
+			w.Write([]byte(`{"didResolutionMetadata":{"contentType":"application/did+ld+json"},"didDocument":{"@context":["https://www.w3.org/ns/did/v1"],"id":"` + id + `"},"didDocumentMetadata":{"versionId":"3"}}`))
+		case "/1.0/identifiers/did:other:123":
+			w.WriteHeader(http.StatusNotImplemented)
+			w.Write([]byte(`{"didResolutionMetadata":{"error":"methodNotSupported"},"didDocument":null,"didDocumentMetadata":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	// custom transport, as with a proxy or a private CA
+	r := &Resolver{
+		Endpoint: srv.URL,
+		Client:   srv.Client(),
+		Header:   http.Header{"Authorization": {"Bearer secret"}},
+	}
+	doc, meta, err := r.Resolve(context.Background(), &did.DID{Method: "example", ID: "123"}, did.ResolutionOptions{NoCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != id || meta.Document.VersionID != "3" {
+		t.Errorf("got id %q with version %q", doc.ID, meta.Document.VersionID)
+	}
+
+	tests := []struct {
+		d    *did.DID
+		want error
+	}{
+		{&did.DID{Method: "other", ID: "123"}, did.ErrMethodNotSupported},
+		{&did.DID{Method: "example", ID: "456"}, did.ErrNotFound},
+	}
+	for _, test := range tests {
+		_, _, err := r.Resolve(context.Background(), test.d, did.ResolutionOptions{NoCache: true})
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s got error %v, want %v", test.d, err, test.want)
+		}
+	}
+
+	// default client does not trust the test certificate
+	_, _, err = (&Resolver{Endpoint: srv.URL}).Resolve(context.Background(), &did.DID{Method: "example", ID: "123"}, did.ResolutionOptions{})
+	if err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("got error %v, want certificate failure", err)
+	}
+}
@@ -0,0 +1,152 @@
+package did
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ValidateOptions configures ValidateAll.
+type ValidateOptions struct {
+	// Concurrency limits the number of parallel workers. Zero defaults to
+	// GOMAXPROCS.
+	Concurrency int
+
+	// AllowURL accepts DID URLs in addition to DIDs.
+	AllowURL bool
+}
+
+// validateBlockSize is the number of inputs a worker claims at once, which
+// keeps contention on the shared counter low.
+const validateBlockSize = 256
+
+// ValidateAll checks each input concurrently. The errors are returned in the
+// same order as the inputs, with nil for the valid ones.
+func ValidateAll(inputs []string, opts ValidateOptions) []error {
+	errs := make([]error, len(inputs))
+
+	validate := func(s string) error {
+		_, err := Parse(s)
+		return err
+	}
+	if opts.AllowURL {
+		validate = func(s string) error {
+			_, err := ParseURL(s)
+			return err
+		}
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if max := (len(inputs) + validateBlockSize - 1) / validateBlockSize; workers > max {
+		workers = max
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for {
+				end := int(next.Add(validateBlockSize))
+				start := end - validateBlockSize
+				if start >= len(inputs) {
+					return
+				}
+				if end > len(inputs) {
+					end = len(inputs)
+				}
+				for i := start; i < end; i++ {
+					errs[i] = validate(inputs[i])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// Valid reports whether s is a DID, i.e., whether Parse would succeed. No
+// memory is allocated, unless s has a method with a MethodSpec.
+func Valid(s string) bool {
+	return validDIDEnd(s) == len(s)
+}
+
+// ValidURL reports whether s is a DID or a DID URL, i.e., whether ParseURL
+// would succeed. No memory is allocated, unless s has a method with a
+// MethodSpec.
+func ValidURL(s string) bool {
+	i := validDIDEnd(s)
+	if i < 0 {
+		return false
+	}
+	if i < len(s) && s[i] == '/' {
+		i = validRunEnd(s, i+1, &pathOrSlash)
+		if i < 0 {
+			return false
+		}
+	}
+	if i < len(s) && s[i] == '?' {
+		i = validRunEnd(s, i+1, &queryChars)
+		if i < 0 {
+			return false
+		}
+	}
+	if i < len(s) && s[i] == '#' {
+		i = validRunEnd(s, i+1, &fragmentChars)
+	}
+	return i == len(s)
+}
+
+// validDIDEnd returns the offset at which the DID in s ends, or -1 when s
+// does not start with a DID.
+func validDIDEnd(s string) int {
+	if !strings.HasPrefix(s, "did:") {
+		return -1
+	}
+
+	i := len("did:")
+	for i < len(s) && methodChars.contains(s[i]) {
+		i++
+	}
+	if i == len("did:") || i >= len(s) || s[i] != ':' {
+		return -1
+	}
+
+	idStart := i + 1
+	spec := lookupMethod(s[len("did:") : idStart-1])
+	i = validRunEnd(s, idStart, idOrColonCharsOf(spec))
+	if i <= idStart || s[i-1] == ':' {
+		return -1
+	}
+	if spec != nil && !spec.valid(s[idStart:i]) {
+		return -1
+	}
+	return i
+}
+
+// validRunEnd returns the offset of the first character from start on which
+// is not in valid, with pct-encoded octets included, or -1 when a '%' is
+// not followed by 2 hex digits.
+func validRunEnd(s string, start int, valid *charset) int {
+	i := start
+	for i < len(s) {
+		switch {
+		case valid.contains(s[i]):
+			i++
+		case s[i] == '%':
+			if i+2 >= len(s) || !hexChars.contains(s[i+1]) || !hexChars.contains(s[i+2]) {
+				return -1
+			}
+			i += 3
+		default:
+			return i
+		}
+	}
+	return i
+}
@@ -0,0 +1,55 @@
+package did
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidateAll(t *testing.T) {
+	inputs := make([]string, 1000)
+	for i := range inputs {
+		switch i % 3 {
+		case 0:
+			inputs[i] = fmt.Sprintf("did:example:%d", i)
+		case 1:
+			inputs[i] = fmt.Sprintf("did:example:%d#keys-1", i)
+		default:
+			inputs[i] = fmt.Sprintf("did:Example:%d", i)
+		}
+	}
+
+	for _, concurrency := range []int{0, 1, 3, 100} {
+		errs := ValidateAll(inputs, ValidateOptions{Concurrency: concurrency})
+		assert(t, len(inputs), len(errs))
+		for i, err := range errs {
+			assert(t, i%3 == 0, err == nil, "Input: %s", inputs[i])
+		}
+
+		errs = ValidateAll(inputs, ValidateOptions{Concurrency: concurrency, AllowURL: true})
+		for i, err := range errs {
+			assert(t, i%3 != 2, err == nil, "Input: %s", inputs[i])
+		}
+	}
+
+	t.Run("accepts empty input", func(t *testing.T) {
+		assert(t, 0, len(ValidateAll(nil, ValidateOptions{})))
+	})
+}
+
+func TestValid(t *testing.T) {
+	for _, s := range regexpSamples {
+		_, err := Parse(s)
+		assert(t, err == nil, Valid(s), "Input: %q, Parse error: %v", s, err)
+
+		_, err = ParseURL(s)
+		assert(t, err == nil, ValidURL(s), "Input: %q, ParseURL error: %v", s, err)
+	}
+
+	t.Run("no allocation", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			Valid("did:example:123%3A456")
+			ValidURL("did:example:123/a/b?q=1#f")
+		})
+		assert(t, 0.0, allocs)
+	})
+}
@@ -0,0 +1,122 @@
+package did
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// syntaxVectors has positive and negative cases for Parse and ParseURL.
+type syntaxVectors struct {
+	DID struct {
+		Valid   []string `json:"valid"`
+		Invalid []string `json:"invalid"`
+	} `json:"did"`
+	DIDURL struct {
+		Valid   []string `json:"valid"`
+		Invalid []string `json:"invalid"`
+	} `json:"didURL"`
+}
+
+func (v *syntaxVectors) run(t *testing.T) {
+	for _, s := range v.DID.Valid {
+		if _, err := Parse(s); err != nil {
+			t.Errorf("valid DID %q got error: %s", s, err)
+		}
+	}
+	for _, s := range v.DID.Invalid {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("invalid DID %q got no error", s)
+		}
+	}
+	for _, s := range v.DIDURL.Valid {
+		if _, err := ParseURL(s); err != nil {
+			t.Errorf("valid DID URL %q got error: %s", s, err)
+		}
+	}
+	for _, s := range v.DIDURL.Invalid {
+		if _, err := ParseURL(s); err == nil {
+			t.Errorf("invalid DID URL %q got no error", s)
+		}
+	}
+}
+
+// TestSyntaxVectors runs the bundled vectors, which were written for this
+// package after the examples and the ABNF of DID Core. They are no substitute
+// for the W3C DID Test Suite; see TestDIDTestSuite for that.
+func TestSyntaxVectors(t *testing.T) {
+	bytes, err := os.ReadFile(filepath.Join("testdata", "syntax-vectors.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v syntaxVectors
+	if err := json.Unmarshal(bytes, &v); err != nil {
+		t.Fatal(err)
+	}
+	v.run(t)
+}
+
+// TestDIDTestSuite runs the vectors of the W3C DID Test Suite, from the
+// implementation reports in testdata/did-test-suite, as vendored from
+// https://github.com/w3c/did-test-suite (see the README there for the
+// commit). DID_TEST_SUITE may have the location of a checkout instead. The
+// DIDs and the DID URLs of the implementation reports must parse, and the
+// inputs with an invalidDid or an invalidDidUrl outcome in the resolver and
+// dereferencer reports must not.
+func TestDIDTestSuite(t *testing.T) {
+	root := os.Getenv("DID_TEST_SUITE")
+	if root == "" {
+		root = filepath.Join("testdata", "did-test-suite")
+	}
+	files, err := filepath.Glob(filepath.Join(root, "packages", "did-core-test-server", "suites", "implementations", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Skipf("no implementation reports in %s; see testdata/did-test-suite/README", root)
+	}
+
+	for _, file := range files {
+		bytes, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var report struct {
+			DIDs             []string          `json:"dids"`
+			DIDParameters    map[string]string `json:"didParameters"`
+			ExpectedOutcomes map[string][]int  `json:"expectedOutcomes"`
+			Executions       []struct {
+				Function string `json:"function"`
+				Input    struct {
+					DID    string `json:"did"`
+					DIDURL string `json:"didUrl"`
+				} `json:"input"`
+			} `json:"executions"`
+		}
+		if err := json.Unmarshal(bytes, &report); err != nil {
+			// not all files are reports
+			continue
+		}
+
+		var v syntaxVectors
+		v.DID.Valid = report.DIDs
+		for _, s := range report.DIDParameters {
+			if strings.HasPrefix(s, "did:") {
+				v.DIDURL.Valid = append(v.DIDURL.Valid, s)
+			}
+		}
+		for _, i := range report.ExpectedOutcomes["invalidDidErrorOutcome"] {
+			if i >= 0 && i < len(report.Executions) && report.Executions[i].Function == "resolve" {
+				v.DID.Invalid = append(v.DID.Invalid, report.Executions[i].Input.DID)
+			}
+		}
+		for _, i := range report.ExpectedOutcomes["invalidDidUrlErrorOutcome"] {
+			if i >= 0 && i < len(report.Executions) && report.Executions[i].Function == "dereference" {
+				v.DIDURL.Invalid = append(v.DIDURL.Invalid, report.Executions[i].Input.DIDURL)
+			}
+		}
+		t.Run(filepath.Base(file), v.run)
+	}
+}
@@ -0,0 +1,298 @@
+package did
+
+import (
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ockam-network/did/internal/base58"
+	"github.com/ockam-network/did/internal/multibase"
+	"github.com/ockam-network/did/internal/secp256k1"
+)
+
+// Common types of verification methods.
+// https://www.w3.org/TR/did-spec-registries/#verification-method-types
+const (
+	TypeMultikey                          = "Multikey"
+	TypeJSONWebKey                        = "JsonWebKey"
+	TypeJSONWebKey2020                    = "JsonWebKey2020"
+	TypeEd25519VerificationKey2020        = "Ed25519VerificationKey2020"
+	TypeEd25519VerificationKey2018        = "Ed25519VerificationKey2018"
+	TypeEcdsaSecp256k1VerificationKey2019 = "EcdsaSecp256k1VerificationKey2019"
+)
+
+// Curve names as used by JWK.
+// https://www.iana.org/assignments/jose/jose.xhtml#web-key-elliptic-curve
+const (
+	CurveEd25519   = "Ed25519"
+	CurveX25519    = "X25519"
+	CurveSecp256k1 = "secp256k1"
+	CurveP256      = "P-256"
+	CurveP384      = "P-384"
+)
+
+// curveSizes has the size of a coordinate in bytes per curve.
+var curveSizes = map[string]int{
+	CurveEd25519:   32,
+	CurveX25519:    32,
+	CurveSecp256k1: 32,
+	CurveP256:      32,
+	CurveP384:      48,
+}
+
+// multicodecCurves has the curve per multicodec of a public key.
+var multicodecCurves = map[uint64]string{
+	multibase.Ed25519Pub:   CurveEd25519,
+	multibase.X25519Pub:    CurveX25519,
+	multibase.Secp256k1Pub: CurveSecp256k1,
+	multibase.P256Pub:      CurveP256,
+	multibase.P384Pub:      CurveP384,
+}
+
+// A PublicKey is the key material of a verification method in raw form.
+type PublicKey struct {
+	// Curve is one of the curve constants, like CurveEd25519.
+	Curve string
+
+	// Bytes is the key, which has the compressed form for the curves
+	// other than the Edwards and Montgomery ones.
+	Bytes []byte
+}
+
+// A JWK is a public JSON Web Key, conform RFC 7517. Private key members are
+// not supported.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+
+	// D is the private key, which must be absent in DID Documents.
+	D string `json:"d,omitempty"`
+}
+
+// JWK returns the publicKeyJwk. The error is nil without publicKeyJwk, in
+// which case the JWK is nil too.
+func (vm *VerificationMethod) JWK() (*JWK, error) {
+	if len(vm.PublicKeyJWK) == 0 {
+		return nil, nil
+	}
+	k := new(JWK)
+	if err := json.Unmarshal(vm.PublicKeyJWK, k); err != nil {
+		return nil, fmt.Errorf("malformed publicKeyJwk: %w", err)
+	}
+	if k.Kty == "" {
+		return nil, errors.New("publicKeyJwk has no kty")
+	}
+	if k.D != "" {
+		return nil, errors.New("publicKeyJwk has a private key")
+	}
+	return k, nil
+}
+
+// PublicKey returns the key material of the verification method, from the
+// property which applies to its type. Unknown types get the key from any of
+// publicKeyMultibase with a multicodec, publicKeyJwk, or publicKeyBase58 with
+// an Ed25519 key.
+func (vm *VerificationMethod) PublicKey() (PublicKey, error) {
+	switch vm.Type {
+	case TypeMultikey:
+		return multikey(vm.PublicKeyMultibase, "")
+	case TypeEd25519VerificationKey2020:
+		return multikey(vm.PublicKeyMultibase, CurveEd25519)
+	case TypeEd25519VerificationKey2018:
+		return rawKey("publicKeyBase58", vm.PublicKeyBase58, CurveEd25519)
+	case TypeJSONWebKey, TypeJSONWebKey2020:
+		return jwkKey(vm)
+	case TypeEcdsaSecp256k1VerificationKey2019:
+		switch {
+		case len(vm.PublicKeyJWK) != 0:
+			k, err := jwkKey(vm)
+			if err == nil && k.Curve != CurveSecp256k1 {
+				err = fmt.Errorf("publicKeyJwk has curve %q, want %q", k.Curve, CurveSecp256k1)
+			}
+			return k, err
+		case vm.PublicKeyMultibase != "":
+			data, err := multibase.Decode(vm.PublicKeyMultibase)
+			if err != nil {
+				return PublicKey{}, fmt.Errorf("malformed publicKeyMultibase: %w", err)
+			}
+			if len(data) == 33 {
+				return checkKey(PublicKey{CurveSecp256k1, data})
+			}
+			return multikey(vm.PublicKeyMultibase, CurveSecp256k1)
+		default:
+			return rawKey("publicKeyBase58", vm.PublicKeyBase58, CurveSecp256k1)
+		}
+	default:
+		switch {
+		case vm.PublicKeyMultibase != "":
+			return multikey(vm.PublicKeyMultibase, "")
+		case len(vm.PublicKeyJWK) != 0:
+			return jwkKey(vm)
+		case vm.PublicKeyBase58 != "":
+			return rawKey("publicKeyBase58", vm.PublicKeyBase58, CurveEd25519)
+		default:
+			return PublicKey{}, fmt.Errorf("no public key material for type %q", vm.Type)
+		}
+	}
+}
+
+// multikey decodes a publicKeyMultibase with a multicodec header. The curve
+// must match, unless it is the empty string.
+func multikey(s, curve string) (PublicKey, error) {
+	if s == "" {
+		return PublicKey{}, errors.New("no publicKeyMultibase")
+	}
+	data, err := multibase.Decode(s)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("malformed publicKeyMultibase: %w", err)
+	}
+	codec, key, err := multibase.SplitCodec(data)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("malformed publicKeyMultibase: %w", err)
+	}
+	c, ok := multicodecCurves[codec]
+	if !ok {
+		return PublicKey{}, fmt.Errorf("publicKeyMultibase has unsupported multicodec %#x", codec)
+	}
+	if curve != "" && c != curve {
+		return PublicKey{}, fmt.Errorf("publicKeyMultibase has curve %q, want %q", c, curve)
+	}
+	return checkKey(PublicKey{c, key})
+}
+
+// rawKey decodes a base58 property without multicodec header.
+func rawKey(name, s, curve string) (PublicKey, error) {
+	if s == "" {
+		return PublicKey{}, errors.New("no " + name)
+	}
+	key, err := base58.Decode(s)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("malformed %s: %w", name, err)
+	}
+	return checkKey(PublicKey{curve, key})
+}
+
+// jwkKey decodes the publicKeyJwk of vm.
+func jwkKey(vm *VerificationMethod) (PublicKey, error) {
+	k, err := vm.JWK()
+	if err != nil {
+		return PublicKey{}, err
+	}
+	if k == nil {
+		return PublicKey{}, errors.New("no publicKeyJwk")
+	}
+	if _, ok := curveSizes[k.Crv]; !ok || (k.Kty != "OKP" && k.Kty != "EC") {
+		return PublicKey{}, fmt.Errorf("publicKeyJwk with kty %q and crv %q not supported", k.Kty, k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("publicKeyJwk has malformed x: %w", err)
+	}
+	if k.Kty == "OKP" {
+		return checkKey(PublicKey{k.Crv, x})
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil || len(y) == 0 {
+		return PublicKey{}, errors.New("publicKeyJwk has malformed y")
+	}
+	return checkKey(PublicKey{k.Crv, append([]byte{2 | y[len(y)-1]&1}, x...)})
+}
+
+// checkKey verifies the size of a key.
+func checkKey(k PublicKey) (PublicKey, error) {
+	n := curveSizes[k.Curve]
+	if k.Curve != CurveEd25519 && k.Curve != CurveX25519 {
+		n++ // compressed form
+		if len(k.Bytes) == n && k.Bytes[0] != 2 && k.Bytes[0] != 3 {
+			return PublicKey{}, fmt.Errorf("%s key not in compressed form", k.Curve)
+		}
+	}
+	if len(k.Bytes) != n {
+		return PublicKey{}, fmt.Errorf("%d-byte %s key, want %d bytes", len(k.Bytes), k.Curve, n)
+	}
+	return k, nil
+}
+
+// checkMaterial verifies the key material for the type of vm. Methods with
+// just a blockchain account, as with did:pkh, pass for any type.
+func (vm *VerificationMethod) checkMaterial() error {
+	if vm.BlockchainAccountID != "" && vm.PublicKeyMultibase == "" && len(vm.PublicKeyJWK) == 0 && vm.PublicKeyBase58 == "" {
+		return nil
+	}
+	switch vm.Type {
+	case TypeJSONWebKey, TypeJSONWebKey2020:
+		// key types other than those of PublicKey are allowed
+		k, err := vm.JWK()
+		if err == nil && k == nil {
+			err = errors.New("no publicKeyJwk")
+		}
+		return err
+	case TypeMultikey, TypeEd25519VerificationKey2020, TypeEd25519VerificationKey2018, TypeEcdsaSecp256k1VerificationKey2019:
+		_, err := vm.PublicKey()
+		return err
+	default:
+		if vm.PublicKeyMultibase == "" && len(vm.PublicKeyJWK) == 0 && vm.PublicKeyBase58 == "" {
+			return errors.New("no public key material")
+		}
+		if len(vm.PublicKeyJWK) != 0 {
+			_, err := vm.JWK()
+			return err
+		}
+		return nil
+	}
+}
+
+// Multikey returns the publicKeyMultibase of the key for the Multikey type,
+// i.e., base58btc with a multicodec header.
+func (k PublicKey) Multikey() (string, error) {
+	for codec, curve := range multicodecCurves {
+		if curve == k.Curve {
+			return multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, codec), k.Bytes...)), nil
+		}
+	}
+	return "", fmt.Errorf("did: no multicodec for curve %q", k.Curve)
+}
+
+// JWK returns the key as a JSON Web Key. Compressed keys are expanded.
+func (k PublicKey) JWK() (*JWK, error) {
+	if _, err := checkKey(k); err != nil {
+		return nil, fmt.Errorf("did: %w", err)
+	}
+	if k.Curve == CurveEd25519 || k.Curve == CurveX25519 {
+		return &JWK{Kty: "OKP", Crv: k.Curve, X: base64.RawURLEncoding.EncodeToString(k.Bytes)}, nil
+	}
+
+	var x, y *big.Int
+	switch k.Curve {
+	case CurveSecp256k1:
+		p, err := secp256k1.Decompress(k.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("did: %w", err)
+		}
+		x, y = p.X, p.Y
+	case CurveP256:
+		x, y = elliptic.UnmarshalCompressed(elliptic.P256(), k.Bytes)
+	case CurveP384:
+		x, y = elliptic.UnmarshalCompressed(elliptic.P384(), k.Bytes)
+	}
+	if x == nil {
+		return nil, fmt.Errorf("did: %s key not on curve", k.Curve)
+	}
+	size := curveSizes[k.Curve]
+	return &JWK{
+		Kty: "EC",
+		Crv: k.Curve,
+		X:   base64.RawURLEncoding.EncodeToString(x.FillBytes(make([]byte, size))),
+		Y:   base64.RawURLEncoding.EncodeToString(y.FillBytes(make([]byte, size))),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package did
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ockam-network/did/internal/multibase"
+)
+
+func TestVerificationMethodPublicKey(t *testing.T) {
+	// RFC 8037, appendix A.2
+	const edX = "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	const edHex = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	tests := []struct {
+		vm    VerificationMethod
+		curve string
+		key   string // hex
+	}{
+		{VerificationMethod{Type: TypeMultikey, PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}, CurveEd25519, "2e6fcce36701dc791488e0d0b1745cc1e33a4c1c9fcc41c63bd343dbbe0970e6"},
+		{VerificationMethod{Type: TypeEd25519VerificationKey2020, PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}, CurveEd25519, "2e6fcce36701dc791488e0d0b1745cc1e33a4c1c9fcc41c63bd343dbbe0970e6"},
+		{VerificationMethod{Type: TypeEd25519VerificationKey2018, PublicKeyBase58: "FUa8PGAE1y1G9nPBqJAXUzm3M6HPSHFM5jVmGQMFRTRv"}, CurveEd25519, ""},
+		{VerificationMethod{Type: TypeJSONWebKey2020, PublicKeyJWK: []byte(`{"kty":"OKP","crv":"Ed25519","x":"` + edX + `"}`)}, CurveEd25519, edHex},
+		{VerificationMethod{Type: TypeEcdsaSecp256k1VerificationKey2019, PublicKeyJWK: []byte(`{"kty":"EC","crv":"secp256k1","x":"WfY7Px6AgH6x-_dgAoRbg8weYRJA36ON-gQQQ7TkjvQ","y":"ueYGrFZ5eSbHcTl7RZsijDVI5LzwIn4ZaeZ5jOcPMHs"}`)}, CurveSecp256k1, "0359f63b3f1e80807eb1fbf76002845b83cc1e611240dfa38dfa041043b4e48ef4"},
+		{VerificationMethod{Type: "ExampleKey", PublicKeyJWK: []byte(`{"kty":"OKP","crv":"Ed25519","x":"` + edX + `"}`)}, CurveEd25519, edHex},
+	}
+	for _, test := range tests {
+		k, err := test.vm.PublicKey()
+		if err != nil {
+			t.Errorf("%s got error: %s", test.vm.Type, err)
+			continue
+		}
+		assert(t, test.curve, k.Curve, test.vm.Type)
+		if test.key != "" {
+			assert(t, test.key, hex.EncodeToString(k.Bytes), test.vm.Type)
+		}
+	}
+
+	errTests := []struct {
+		vm   VerificationMethod
+		want string
+	}{
+		{VerificationMethod{Type: TypeMultikey}, "no publicKeyMultibase"},
+		{VerificationMethod{Type: TypeMultikey, PublicKeyMultibase: multibase.Encode(multibase.Base58BTC, append(multibase.AppendCodec(nil, multibase.Ed25519Pub), make([]byte, 31)...))}, "31-byte Ed25519 key, want 32 bytes"},
+		{VerificationMethod{Type: TypeEd25519VerificationKey2020, PublicKeyMultibase: "zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme"}, "want \"Ed25519\""},
+		{VerificationMethod{Type: TypeEd25519VerificationKey2018, PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}, "no publicKeyBase58"},
+		{VerificationMethod{Type: TypeJSONWebKey2020, PublicKeyJWK: []byte(`{"kty":"OKP","crv":"Ed25519","x":"` + edX + `","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`)}, "private key"},
+		{VerificationMethod{Type: TypeJSONWebKey2020, PublicKeyJWK: []byte(`{"kty":"RSA","n":"0vx7","e":"AQAB"}`)}, "not supported"},
+		{VerificationMethod{Type: TypeEcdsaSecp256k1VerificationKey2019, PublicKeyJWK: []byte(`{"kty":"OKP","crv":"Ed25519","x":"` + edX + `"}`)}, "want \"secp256k1\""},
+	}
+	for _, test := range errTests {
+		_, err := test.vm.PublicKey()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s got error %v, want %q", test.vm.Type, err, test.want)
+		}
+	}
+}
+
+func TestVerificationMethodCheckMaterial(t *testing.T) {
+	// did:pkh on Solana has no key material
+	vm := VerificationMethod{Type: TypeEd25519VerificationKey2018, BlockchainAccountID: "solana:4sGjMW1sUnHzSxGspuhpqLDx6wiyjNtZ:CKg5d12Jhpej1JqtmxLJgaFqqeYjxgPqToJ4LBdvG9Ev"}
+	assert(t, nil, vm.checkMaterial())
+	// RSA remains valid for the JWK types
+	vm = VerificationMethod{Type: TypeJSONWebKey2020, PublicKeyJWK: []byte(`{"kty":"RSA","n":"0vx7","e":"AQAB"}`)}
+	assert(t, nil, vm.checkMaterial())
+	vm = VerificationMethod{Type: TypeEd25519VerificationKey2018, PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}
+	assert(t, true, vm.checkMaterial() != nil)
+}